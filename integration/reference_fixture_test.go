@@ -1,6 +1,8 @@
 package integration_test
 
 import (
+	"io"
+	"os"
 	"path/filepath"
 	"testing"
 )
@@ -14,6 +16,37 @@ func fixtureDBPath(t *testing.T) string {
 	return filepath.Join(root, "integration", "fixtures", "main.sqlite")
 }
 
+// copyFixtureDB copies the reference database (with its WAL files) into a
+// temporary directory so tests can write to it.
+func copyFixtureDB(t *testing.T) string {
+	t.Helper()
+	src := fixtureDBPath(t)
+	dir := t.TempDir()
+	for _, suffix := range []string{"", "-wal", "-shm"} {
+		in, err := os.Open(src + suffix)
+		if err != nil {
+			if os.IsNotExist(err) && suffix != "" {
+				continue
+			}
+			t.Fatalf("open fixture: %v", err)
+		}
+		out, err := os.Create(filepath.Join(dir, "main.sqlite"+suffix))
+		if err != nil {
+			in.Close()
+			t.Fatalf("create fixture copy: %v", err)
+		}
+		_, err = io.Copy(out, in)
+		in.Close()
+		if cerr := out.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			t.Fatalf("copy fixture: %v", err)
+		}
+	}
+	return filepath.Join(dir, "main.sqlite")
+}
+
 func TestReferenceFixtureBasicCommands(t *testing.T) {
 	dbPath := fixtureDBPath(t)
 
@@ -76,3 +109,82 @@ func TestReferenceFixtureBasicCommands(t *testing.T) {
 	assertContains(t, out, "Completed To-Do in Project")
 }
 
+func TestReferenceFixtureExportICS(t *testing.T) {
+	dbPath := fixtureDBPath(t)
+
+	out, _, code := runThings(t, "", "export", "ics", "--db", dbPath)
+	requireSuccess(t, code)
+	assertContains(t, out, "BEGIN:VCALENDAR")
+	assertContains(t, out, "UID:5pUx6PESj3ctFYbgth1PXY")
+	assertContains(t, out, "UID:N1PJHsbjct4mb1bhcs7aHa")
+	assertContains(t, out, "RRULE:FREQ=WEEKLY;BYDAY=SU")
+	assertContains(t, out, "DUE;VALUE=DATE:20210328")
+}
+
+func TestReferenceFixtureRepeatingRule(t *testing.T) {
+	dbPath := fixtureDBPath(t)
+
+	out, _, code := runThings(t, "", "repeating", "--db", dbPath, "--select", "title,repeat_rule", "--format", "csv")
+	requireSuccess(t, code)
+	assertContains(t, out, "TITLE,REPEAT_RULE")
+	assertContains(t, out, "Repeating To-Do,every week on Sunday (after completion)")
+}
+
+func TestReferenceFixtureRepeatOn(t *testing.T) {
+	dbPath := copyFixtureDB(t)
+	const templateID = "N1PJHsbjct4mb1bhcs7aHa"
+
+	_, _, code := runThings(t, "", "update", "--db", dbPath, "--id", templateID,
+		"--repeat=week", "--repeat-on=mon,wed,fri", "--repeat-mode=schedule", "--repeat-start=2026-01-05")
+	requireSuccess(t, code)
+
+	out, _, code := runThings(t, "", "repeating", "--db", dbPath, "--select", "title,repeat_rule,repeat_next", "--format", "csv")
+	requireSuccess(t, code)
+	assertContains(t, out, "Repeating To-Do,\"every week on Monday, Wednesday and Friday\",2026-01-07")
+
+	out, _, code = runThings(t, "", "export", "ics", "--db", dbPath)
+	requireSuccess(t, code)
+	assertContains(t, out, "RRULE:FREQ=WEEKLY;BYDAY=MO,WE,FR")
+
+	_, _, code = runThings(t, "", "update", "--db", dbPath, "--id", templateID,
+		"--repeat=month", "--repeat-on=2nd-tue", "--repeat-mode=schedule", "--repeat-start=2026-01-14")
+	requireSuccess(t, code)
+
+	out, _, code = runThings(t, "", "repeating", "--db", dbPath, "--select", "repeat_rule,repeat_next", "--format", "csv", "--no-header")
+	requireSuccess(t, code)
+	assertContains(t, out, "every month on the 2nd Tuesday,2026-02-10")
+
+	_, _, code = runThings(t, "", "update", "--db", dbPath, "--id", templateID, "--repeat=day", "--repeat-on=mon")
+	requireFailure(t, code)
+}
+
+func TestReferenceFixtureBackupRestore(t *testing.T) {
+	dbPath := fixtureDBPath(t)
+	archivePath := filepath.Join(t.TempDir(), "backup.json")
+
+	_, _, code := runThings(t, "", "backup", "--db", dbPath, "-o", archivePath)
+	requireSuccess(t, code)
+	data, err := os.ReadFile(archivePath)
+	if err != nil {
+		t.Fatalf("read archive: %v", err)
+	}
+	archive := string(data)
+	assertContains(t, archive, `"version": 1`)
+	assertContains(t, archive, `"title": "Project in Area 1"`)
+	assertContains(t, archive, `"rrule": "FREQ=WEEKLY;BYDAY=SU"`)
+	assertNotContains(t, archive, "Deleted Todo")
+	assertNotContains(t, archive, "K9bx7h1xCJdevvyWardZDq")
+
+	_, errOut, code := runThings(t, "", "restore", "--into-empty", "--db", dbPath, archivePath)
+	requireFailure(t, code)
+	assertContains(t, errOut, "not empty")
+
+	emptyPath := filepath.Join(t.TempDir(), "missing.sqlite")
+	out, errOut, code := runThings(t, "", "--dry-run", "restore", "--into-empty", "--db", emptyPath, archivePath)
+	requireSuccess(t, code)
+	assertContains(t, out, `make new tag with properties {name:"Errand"}`)
+	assertContains(t, out, `make new area with properties {name:"Area 3"}`)
+	assertContains(t, out, "things:///json?data=")
+	assertContains(t, out, enc(`"title":"Project in Area 1"`))
+	assertContains(t, errOut, "repeats are skipped")
+}
@@ -0,0 +1,330 @@
+// Package backup reads the Things library into a versioned JSON archive and
+// plans how to rebuild it through the URL scheme.
+package backup
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ossianhempel/things3-cli/internal/db"
+	"github.com/ossianhempel/things3-cli/internal/repeat"
+)
+
+// Version is the archive format version written by Read.
+const Version = 1
+
+// Archive is a snapshot of the library. Slices keep the order of the
+// library; projects and todos that belong to an area are nested in it.
+type Archive struct {
+	Version int    `json:"version"`
+	Created string `json:"created"`
+	Tags    []Tag  `json:"tags,omitempty"`
+	Areas   []Area `json:"areas,omitempty"`
+	// Projects holds projects without an area.
+	Projects []Project `json:"projects,omitempty"`
+	// Todos holds todos outside any project or area (Inbox, Anytime,
+	// Someday, and their logbook entries).
+	Todos []Todo `json:"todos,omitempty"`
+}
+
+// Tag is a tag with the title of its parent tag.
+type Tag struct {
+	UUID     string `json:"uuid"`
+	Title    string `json:"title"`
+	Parent   string `json:"parent,omitempty"`
+	Shortcut string `json:"shortcut,omitempty"`
+}
+
+// Area is an area with its projects and loose todos.
+type Area struct {
+	UUID     string    `json:"uuid"`
+	Title    string    `json:"title"`
+	Projects []Project `json:"projects,omitempty"`
+	Todos    []Todo    `json:"todos,omitempty"`
+}
+
+// Project is a project with its loose todos and headings.
+type Project struct {
+	UUID      string    `json:"uuid"`
+	Title     string    `json:"title"`
+	Notes     string    `json:"notes,omitempty"`
+	Status    string    `json:"status"`
+	Start     string    `json:"start,omitempty"`
+	StartDate string    `json:"start_date,omitempty"`
+	Deadline  string    `json:"deadline,omitempty"`
+	Created   string    `json:"created,omitempty"`
+	StopDate  string    `json:"stop_date,omitempty"`
+	Tags      []string  `json:"tags,omitempty"`
+	Repeat    *Repeat   `json:"repeat,omitempty"`
+	Todos     []Todo    `json:"todos,omitempty"`
+	Headings  []Heading `json:"headings,omitempty"`
+}
+
+// Heading is a project heading with its todos.
+type Heading struct {
+	UUID     string `json:"uuid"`
+	Title    string `json:"title"`
+	Archived bool   `json:"archived,omitempty"`
+	Todos    []Todo `json:"todos,omitempty"`
+}
+
+// Todo is a todo with its checklist.
+type Todo struct {
+	UUID      string          `json:"uuid"`
+	Title     string          `json:"title"`
+	Notes     string          `json:"notes,omitempty"`
+	Status    string          `json:"status"`
+	Start     string          `json:"start,omitempty"`
+	StartDate string          `json:"start_date,omitempty"`
+	Evening   bool            `json:"evening,omitempty"`
+	Deadline  string          `json:"deadline,omitempty"`
+	Created   string          `json:"created,omitempty"`
+	StopDate  string          `json:"stop_date,omitempty"`
+	Tags      []string        `json:"tags,omitempty"`
+	Checklist []ChecklistItem `json:"checklist,omitempty"`
+	Repeat    *Repeat         `json:"repeat,omitempty"`
+}
+
+// ChecklistItem is a single checklist entry.
+type ChecklistItem struct {
+	Title  string `json:"title"`
+	Status string `json:"status"`
+}
+
+// Repeat holds a recurrence rule. Rule is the raw rule Things stores; the
+// description and RRULE are for readers of the archive.
+type Repeat struct {
+	Rule        []byte `json:"rule"`
+	Description string `json:"description,omitempty"`
+	RRule       string `json:"rrule,omitempty"`
+	Next        string `json:"next,omitempty"`
+}
+
+// Read builds an archive of every area, project, heading, todo, checklist
+// item, tag, and recurrence rule outside the Trash. Todos created by a
+// repeating template are left out; Things creates them again from the
+// template's rule.
+func Read(store *db.Store, now time.Time) (*Archive, error) {
+	archive := &Archive{Version: Version, Created: now.Format(time.RFC3339)}
+
+	tags, err := store.Tags()
+	if err != nil {
+		return nil, err
+	}
+	archive.Tags = orderTags(tags)
+
+	areas, err := store.Areas()
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := store.Tasks(db.TaskFilter{
+		ExcludeTrashedContext: true,
+		IncludeChecklist:      true,
+		IncludeRepeating:      true,
+		Where:                 "t.rt1_repeatingTemplate IS NULL",
+	})
+	if err != nil {
+		return nil, err
+	}
+	var repeatingIDs []string
+	for _, task := range tasks {
+		if task.Repeating {
+			repeatingIDs = append(repeatingIDs, task.UUID)
+		}
+	}
+	rules, err := store.RecurrenceRules(repeatingIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Group todos under their heading, project, or area; headings under
+	// their project; projects under their area. Tasks arrive in index order.
+	headingTodos := map[string][]Todo{}
+	projectTodos := map[string][]Todo{}
+	projectHeadings := map[string][]Heading{}
+	areaTodos := map[string][]Todo{}
+	areaProjects := map[string][]Project{}
+	headingIDs := map[string]bool{}
+	projectIDs := map[string]bool{}
+	for _, task := range tasks {
+		switch task.Type {
+		case "heading":
+			headingIDs[task.UUID] = true
+		case "project":
+			projectIDs[task.UUID] = true
+		}
+	}
+	for _, task := range tasks {
+		if task.Type != "to-do" {
+			continue
+		}
+		todo := newTodo(task, rules)
+		switch {
+		case task.HeadingID != "" && headingIDs[task.HeadingID]:
+			headingTodos[task.HeadingID] = append(headingTodos[task.HeadingID], todo)
+		case task.ProjectID != "" && projectIDs[task.ProjectID]:
+			projectTodos[task.ProjectID] = append(projectTodos[task.ProjectID], todo)
+		case task.AreaID != "":
+			areaTodos[task.AreaID] = append(areaTodos[task.AreaID], todo)
+		default:
+			archive.Todos = append(archive.Todos, todo)
+		}
+	}
+	for _, task := range tasks {
+		if task.Type == "heading" && projectIDs[task.ProjectID] {
+			projectHeadings[task.ProjectID] = append(projectHeadings[task.ProjectID], Heading{
+				UUID:     task.UUID,
+				Title:    task.Title,
+				Archived: task.Status != db.StatusIncomplete,
+				Todos:    headingTodos[task.UUID],
+			})
+		}
+	}
+	for _, task := range tasks {
+		if task.Type != "project" {
+			continue
+		}
+		project := Project{
+			UUID:      task.UUID,
+			Title:     task.Title,
+			Notes:     task.Notes,
+			Status:    db.StatusLabel(task.Status),
+			Start:     task.Start,
+			StartDate: task.StartDate,
+			Deadline:  task.Deadline,
+			Created:   task.Created,
+			StopDate:  task.StopDate,
+			Tags:      task.Tags,
+			Repeat:    newRepeat(rules, task.UUID),
+			Todos:     projectTodos[task.UUID],
+			Headings:  projectHeadings[task.UUID],
+		}
+		if task.AreaID != "" {
+			areaProjects[task.AreaID] = append(areaProjects[task.AreaID], project)
+			continue
+		}
+		archive.Projects = append(archive.Projects, project)
+	}
+
+	for _, area := range areas {
+		archive.Areas = append(archive.Areas, Area{
+			UUID:     area.UUID,
+			Title:    area.Title,
+			Projects: areaProjects[area.UUID],
+			Todos:    areaTodos[area.UUID],
+		})
+	}
+	return archive, nil
+}
+
+func newTodo(task db.Task, rules map[string]db.RecurrenceRule) Todo {
+	todo := Todo{
+		UUID:      task.UUID,
+		Title:     task.Title,
+		Notes:     task.Notes,
+		Status:    db.StatusLabel(task.Status),
+		Start:     task.Start,
+		StartDate: task.StartDate,
+		Evening:   task.Evening,
+		Deadline:  task.Deadline,
+		Created:   task.Created,
+		StopDate:  task.StopDate,
+		Tags:      task.Tags,
+		Repeat:    newRepeat(rules, task.UUID),
+	}
+	for _, item := range task.Checklist {
+		todo.Checklist = append(todo.Checklist, ChecklistItem{Title: item.Title, Status: db.StatusLabel(item.Status)})
+	}
+	return todo
+}
+
+func newRepeat(rules map[string]db.RecurrenceRule, id string) *Repeat {
+	rule, ok := rules[id]
+	if !ok {
+		return nil
+	}
+	r := &Repeat{Rule: rule.Rule, Next: rule.NextStartDate}
+	if spec, err := repeat.Parse(rule.Rule); err == nil {
+		r.Description = spec.Describe()
+		r.RRule = spec.RRule()
+	}
+	return r
+}
+
+// orderTags returns tags sorted by title with every parent before its
+// children.
+func orderTags(tags []db.Tag) []Tag {
+	byID := make(map[string]db.Tag, len(tags))
+	children := map[string][]db.Tag{}
+	for _, tag := range tags {
+		byID[tag.UUID] = tag
+	}
+	for _, tag := range tags {
+		parent := tag.ParentID
+		if _, ok := byID[parent]; !ok {
+			parent = ""
+		}
+		children[parent] = append(children[parent], tag)
+	}
+
+	ordered := make([]Tag, 0, len(tags))
+	var walk func(parent string)
+	walk = func(parent string) {
+		list := children[parent]
+		sort.SliceStable(list, func(i, j int) bool {
+			return strings.ToLower(list[i].Title) < strings.ToLower(list[j].Title)
+		})
+		for _, tag := range list {
+			ordered = append(ordered, Tag{
+				UUID:     tag.UUID,
+				Title:    tag.Title,
+				Parent:   byID[parent].Title,
+				Shortcut: tag.Shortcut,
+			})
+			walk(tag.UUID)
+		}
+	}
+	walk("")
+	return ordered
+}
+
+// Load decodes an archive and checks its version.
+func Load(data []byte) (*Archive, error) {
+	var archive Archive
+	if err := json.Unmarshal(data, &archive); err != nil {
+		return nil, fmt.Errorf("Error: invalid backup archive: %v", err)
+	}
+	if archive.Version == 0 {
+		return nil, fmt.Errorf("Error: invalid backup archive: missing version")
+	}
+	if archive.Version > Version {
+		return nil, fmt.Errorf("Error: backup archive version %d is newer than supported version %d", archive.Version, Version)
+	}
+	return &archive, nil
+}
+
+// Counts returns the number of areas, projects, and todos in the archive.
+func (a *Archive) Counts() (areas, projects, todos int) {
+	countProject := func(project Project) {
+		projects++
+		todos += len(project.Todos)
+		for _, heading := range project.Headings {
+			todos += len(heading.Todos)
+		}
+	}
+	for _, area := range a.Areas {
+		areas++
+		todos += len(area.Todos)
+		for _, project := range area.Projects {
+			countProject(project)
+		}
+	}
+	for _, project := range a.Projects {
+		countProject(project)
+	}
+	todos += len(a.Todos)
+	return areas, projects, todos
+}
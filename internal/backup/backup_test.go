@@ -0,0 +1,84 @@
+package backup
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ossianhempel/things3-cli/internal/things"
+)
+
+func TestItemsKeepOrderAndState(t *testing.T) {
+	archive := &Archive{
+		Version: Version,
+		Areas: []Area{{
+			Title:    "Home",
+			Projects: []Project{{Title: "Garden", Status: "incomplete", Headings: []Heading{{Title: "Done", Archived: true, Todos: []Todo{{Title: "Rake", Status: "completed", StopDate: "2026-10-01 09:30:00"}}}}}},
+			Todos:    []Todo{{Title: "Call plumber", Status: "incomplete", StartDate: "2026-10-17", Evening: true}},
+		}},
+		Todos: []Todo{
+			{Title: "Inbox item", Status: "incomplete", Start: "Inbox"},
+			{Title: "Anytime item", Status: "incomplete", Start: "Anytime", Checklist: []ChecklistItem{{Title: "Step", Status: "completed"}}},
+			{Title: "Weekly", Status: "incomplete", Start: "Someday", Deadline: "4001-01-01", Repeat: &Repeat{Rule: []byte("rule")}},
+		},
+	}
+	items := archive.Items(time.Date(2026, 10, 18, 0, 0, 0, 0, time.Local))
+	if err := things.ValidateJSONItems(items); err != nil {
+		t.Fatalf("invalid items: %v", err)
+	}
+	var got []string
+	for _, item := range items {
+		attrs := item.Attributes
+		got = append(got, item.Type+":"+attrs.Title+"["+attrs.Area+attrs.List+"|"+attrs.When+"|"+attrs.Deadline+"]")
+	}
+	want := "project:Garden[Home||] to-do:Call plumber[Home|evening|] to-do:Inbox item[||] to-do:Anytime item[|anytime|] to-do:Weekly[|someday|]"
+	if strings.Join(got, " ") != want {
+		t.Fatalf("unexpected items:\n%s", strings.Join(got, " "))
+	}
+	garden := items[0].Attributes
+	if len(garden.Items) != 2 || !garden.Items[0].Attributes.Archived || !garden.Items[1].Attributes.Completed || garden.Items[1].Attributes.CompletionDate == "" {
+		t.Fatalf("unexpected project items: %+v", garden.Items)
+	}
+	if !items[3].Attributes.ChecklistItems[0].Attributes.Completed {
+		t.Fatalf("expected completed checklist item")
+	}
+	if repeats := archive.Repeats(); len(repeats) != 1 || repeats[0].Title != "Weekly" {
+		t.Fatalf("unexpected repeats: %+v", repeats)
+	}
+	if areas, projects, todos := archive.Counts(); areas != 1 || projects != 1 || todos != 5 {
+		t.Fatalf("unexpected counts: %d %d %d", areas, projects, todos)
+	}
+}
+
+func TestBatchesRespectLimit(t *testing.T) {
+	todo := things.JSONItem{Type: things.JSONTypeTodo, Attributes: things.JSONAttributes{Title: "t"}}
+	project := things.JSONItem{Type: things.JSONTypeProject, Attributes: things.JSONAttributes{Title: "p", Items: []things.JSONItem{todo, todo, todo}}}
+	items := []things.JSONItem{todo, project, todo, project}
+	if got := batchSizes(Batches(items, 5)); got != "2 2" {
+		t.Fatalf("unexpected batches: %s", got)
+	}
+	// A project over the limit is sent on its own.
+	if got := batchSizes(Batches(items, 3)); got != "1 1 1 1" {
+		t.Fatalf("unexpected batches: %s", got)
+	}
+}
+
+func batchSizes(batches [][]things.JSONItem) string {
+	sizes := make([]string, 0, len(batches))
+	for _, batch := range batches {
+		sizes = append(sizes, strconv.Itoa(len(batch)))
+	}
+	return strings.Join(sizes, " ")
+}
+
+func TestLoadChecksVersion(t *testing.T) {
+	if _, err := Load([]byte(`{"version": 1}`)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, data := range []string{`{}`, `{"version": 2}`, `[]`} {
+		if _, err := Load([]byte(data)); err == nil {
+			t.Fatalf("expected error for %s", data)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package backup
+
+import (
+	"time"
+
+	"github.com/ossianhempel/things3-cli/internal/things"
+)
+
+// BatchLimit is the most items Things accepts in one json URL command.
+const BatchLimit = 250
+
+// Items converts the archive's projects and todos into Things JSON items in
+// library order: each area's projects and loose todos, then projects without
+// an area, then the remaining todos. Areas and tags are not part of the
+// payload; they must exist before the items are created. today resolves
+// start dates that have already passed.
+func (a *Archive) Items(today time.Time) []things.JSONItem {
+	var items []things.JSONItem
+	for _, area := range a.Areas {
+		for _, project := range area.Projects {
+			item := projectItem(project, today)
+			item.Attributes.Area = area.Title
+			items = append(items, item)
+		}
+		for _, todo := range area.Todos {
+			item := todoItem(todo, today)
+			item.Attributes.List = area.Title
+			items = append(items, item)
+		}
+	}
+	for _, project := range a.Projects {
+		items = append(items, projectItem(project, today))
+	}
+	for _, todo := range a.Todos {
+		item := todoItem(todo, today)
+		if item.Attributes.When == "" && todo.Start == "Anytime" {
+			item.Attributes.When = "anytime"
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+func projectItem(project Project, today time.Time) things.JSONItem {
+	attrs := things.JSONAttributes{
+		Title:    project.Title,
+		Notes:    project.Notes,
+		When:     when(project.Start, project.StartDate, false, today),
+		Deadline: project.Deadline,
+		Tags:     project.Tags,
+	}
+	setStatus(&attrs, project.Status, project.Created, project.StopDate)
+	if project.Repeat != nil {
+		// Repeating templates carry a placeholder deadline; the rule sets it.
+		attrs.Deadline = ""
+	}
+	for _, todo := range project.Todos {
+		attrs.Items = append(attrs.Items, todoItem(todo, today))
+	}
+	for _, heading := range project.Headings {
+		attrs.Items = append(attrs.Items, things.JSONItem{
+			Type:       things.JSONTypeHeading,
+			Attributes: things.JSONAttributes{Title: heading.Title, Archived: heading.Archived},
+		})
+		for _, todo := range heading.Todos {
+			attrs.Items = append(attrs.Items, todoItem(todo, today))
+		}
+	}
+	return things.JSONItem{Type: things.JSONTypeProject, Attributes: attrs}
+}
+
+func todoItem(todo Todo, today time.Time) things.JSONItem {
+	attrs := things.JSONAttributes{
+		Title:    todo.Title,
+		Notes:    todo.Notes,
+		When:     when(todo.Start, todo.StartDate, todo.Evening, today),
+		Deadline: todo.Deadline,
+		Tags:     todo.Tags,
+	}
+	setStatus(&attrs, todo.Status, todo.Created, todo.StopDate)
+	if todo.Repeat != nil {
+		attrs.Deadline = ""
+	}
+	for _, item := range todo.Checklist {
+		attrs.ChecklistItems = append(attrs.ChecklistItems, things.JSONItem{
+			Type: things.JSONTypeChecklistItem,
+			Attributes: things.JSONAttributes{
+				Title:     item.Title,
+				Completed: item.Status == "completed",
+				Canceled:  item.Status == "canceled",
+			},
+		})
+	}
+	return things.JSONItem{Type: things.JSONTypeTodo, Attributes: attrs}
+}
+
+// when maps a start bucket and start date onto a json "when" value. Start
+// dates on or before today become today (or this evening).
+func when(start, startDate string, evening bool, today time.Time) string {
+	if startDate != "" {
+		if startDate > today.Format("2006-01-02") {
+			return startDate
+		}
+		if evening {
+			return "evening"
+		}
+		return "today"
+	}
+	if start == "Someday" {
+		return "someday"
+	}
+	return ""
+}
+
+func setStatus(attrs *things.JSONAttributes, status, created, stopped string) {
+	attrs.Completed = status == "completed"
+	attrs.Canceled = status == "canceled"
+	attrs.CreationDate = isoTimestamp(created)
+	if attrs.Completed || attrs.Canceled {
+		attrs.CompletionDate = isoTimestamp(stopped)
+	}
+}
+
+// isoTimestamp converts a local "2006-01-02 15:04:05" timestamp into the
+// ISO 8601 form the json command expects.
+func isoTimestamp(value string) string {
+	if value == "" {
+		return ""
+	}
+	parsed, err := time.ParseInLocation("2006-01-02 15:04:05", value, time.Local)
+	if err != nil {
+		return ""
+	}
+	return parsed.Format(time.RFC3339)
+}
+
+// Batches splits items into json payloads of at most limit items each,
+// counting nested items and checklist items. A project larger than limit is
+// sent on its own.
+func Batches(items []things.JSONItem, limit int) [][]things.JSONItem {
+	var batches [][]things.JSONItem
+	var current []things.JSONItem
+	size := 0
+	for _, item := range items {
+		n := countItems(item)
+		if len(current) > 0 && size+n > limit {
+			batches = append(batches, current)
+			current, size = nil, 0
+		}
+		current = append(current, item)
+		size += n
+	}
+	if len(current) > 0 {
+		batches = append(batches, current)
+	}
+	return batches
+}
+
+func countItems(item things.JSONItem) int {
+	n := 1 + len(item.Attributes.ChecklistItems)
+	for _, child := range item.Attributes.Items {
+		n += countItems(child)
+	}
+	return n
+}
+
+// RepeatTarget is an item whose recurrence rule must be applied after it is
+// created.
+type RepeatTarget struct {
+	Title   string
+	Project bool
+	Rule    []byte
+	// Next is the archived next instance date (YYYY-MM-DD), if any.
+	Next string
+}
+
+// Repeats lists the repeating projects and todos in the archive.
+func (a *Archive) Repeats() []RepeatTarget {
+	var targets []RepeatTarget
+	addTodos := func(todos []Todo) {
+		for _, todo := range todos {
+			if todo.Repeat != nil {
+				targets = append(targets, RepeatTarget{Title: todo.Title, Rule: todo.Repeat.Rule, Next: todo.Repeat.Next})
+			}
+		}
+	}
+	addProject := func(project Project) {
+		if project.Repeat != nil {
+			targets = append(targets, RepeatTarget{Title: project.Title, Project: true, Rule: project.Repeat.Rule, Next: project.Repeat.Next})
+		}
+		addTodos(project.Todos)
+		for _, heading := range project.Headings {
+			addTodos(heading.Todos)
+		}
+	}
+	for _, area := range a.Areas {
+		for _, project := range area.Projects {
+			addProject(project)
+		}
+		addTodos(area.Todos)
+	}
+	for _, project := range a.Projects {
+		addProject(project)
+	}
+	addTodos(a.Todos)
+	return targets
+}
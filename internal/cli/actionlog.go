@@ -3,7 +3,7 @@ package cli
 import (
 	"bufio"
 	"encoding/json"
-	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -17,12 +17,25 @@ type ActionType string
 const (
 	ActionUpdate ActionType = "update"
 	ActionTrash  ActionType = "trash"
+	ActionCreate ActionType = "create"
 )
 
+// ActionEntry is one logged action. Undone entries stay in the log (with
+// UndoneAt set) so they can be redone.
 type ActionEntry struct {
+	ID        int          `json:"id,omitempty"`
 	Timestamp string       `json:"timestamp"`
 	Type      ActionType   `json:"type"`
 	Items     []ActionItem `json:"items"`
+	UndoneAt  string       `json:"undone_at,omitempty"`
+	// UndoSeq orders undone entries; entries undone within the same second
+	// share UndoneAt.
+	UndoSeq int `json:"undo_seq,omitempty"`
+	// Redo holds the item state captured just before an undo, used by redo
+	// to re-apply updates.
+	Redo []ActionItem `json:"redo,omitempty"`
+
+	file string
 }
 
 type ActionItem struct {
@@ -36,9 +49,22 @@ type ActionItem struct {
 	StartDate    string   `json:"start_date,omitempty"`
 	ProjectID    string   `json:"project_id,omitempty"`
 	AreaID       string   `json:"area_id,omitempty"`
+	HeadingID    string   `json:"heading_id,omitempty"`
 	HeadingTitle string   `json:"heading_title,omitempty"`
+	// Checklist is nil when the checklist was not captured.
+	Checklist []ActionChecklistItem `json:"checklist,omitempty"`
+}
+
+type ActionChecklistItem struct {
+	UUID   string `json:"uuid,omitempty"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
 }
 
+// maxActionLogSize caps actions.jsonl. When an append would exceed it, the
+// log is rotated to actions.1.jsonl, replacing any older rotation.
+var maxActionLogSize int64 = 1 << 20
+
 func actionLogPath() (string, error) {
 	dir, err := os.UserConfigDir()
 	if err != nil {
@@ -51,78 +77,227 @@ func actionLogPath() (string, error) {
 	return filepath.Join(path, "actions.jsonl"), nil
 }
 
+func rotatedActionLogPath(path string) string {
+	return strings.TrimSuffix(path, ".jsonl") + ".1.jsonl"
+}
+
 func appendAction(entry ActionEntry) error {
 	path, err := actionLogPath()
 	if err != nil {
 		return err
 	}
+	entries, err := readActions()
+	if err != nil {
+		return err
+	}
+	entry.ID = 1
+	if len(entries) > 0 {
+		entry.ID = entries[len(entries)-1].ID + 1
+	}
 	entry.Timestamp = time.Now().Format(time.RFC3339)
+	// A new action ends the redo chain, so redo cannot overwrite changes
+	// made after the undo.
+	if entries, err = dropUndoneActions(path, entries); err != nil {
+		return err
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	if info, err := os.Stat(path); err == nil && info.Size()+int64(len(line)) > maxActionLogSize {
+		// Persist the IDs of legacy entries before they move.
+		if err := rewriteActionLog(path, entriesInFile(entries, path)); err != nil {
+			return err
+		}
+		if err := os.Rename(path, rotatedActionLogPath(path)); err != nil {
+			return err
+		}
+	}
+
 	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
-	enc := json.NewEncoder(file)
-	return enc.Encode(entry)
+	_, err = file.Write(line)
+	return err
+}
+
+// dropUndoneActions removes undone entries from the log files holding them
+// and returns the remaining entries.
+func dropUndoneActions(path string, entries []ActionEntry) ([]ActionEntry, error) {
+	var kept []ActionEntry
+	changed := map[string]bool{}
+	for _, entry := range entries {
+		if entry.UndoneAt != "" {
+			changed[entry.file] = true
+			continue
+		}
+		kept = append(kept, entry)
+	}
+	for _, file := range []string{rotatedActionLogPath(path), path} {
+		if changed[file] {
+			if err := rewriteActionLog(file, entriesInFile(kept, file)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return kept, nil
 }
 
-func readLastAction() (ActionEntry, error) {
+// readActions returns every logged action, oldest first, including the
+// rotated log. Entries written before IDs existed are numbered by position.
+func readActions() ([]ActionEntry, error) {
 	path, err := actionLogPath()
 	if err != nil {
-		return ActionEntry{}, err
+		return nil, err
+	}
+	var entries []ActionEntry
+	for _, file := range []string{rotatedActionLogPath(path), path} {
+		fileEntries, err := readActionFile(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range fileEntries {
+			if entry.ID == 0 {
+				entry.ID = 1
+				if len(entries) > 0 {
+					entry.ID = entries[len(entries)-1].ID + 1
+				}
+			}
+			entries = append(entries, entry)
+		}
 	}
+	return entries, nil
+}
+
+func readActionFile(path string) ([]ActionEntry, error) {
 	file, err := os.Open(path)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return ActionEntry{}, errors.New("no actions logged")
+			return nil, nil
 		}
-		return ActionEntry{}, err
+		return nil, err
 	}
 	defer file.Close()
 
-	var lastLine string
+	var entries []ActionEntry
 	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), int(maxActionLogSize)+1024*1024)
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
-		if line != "" {
-			lastLine = line
+		if line == "" {
+			continue
+		}
+		var entry ActionEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			return nil, err
 		}
+		entry.file = path
+		entries = append(entries, entry)
 	}
-	if err := scanner.Err(); err != nil {
-		return ActionEntry{}, err
+	return entries, scanner.Err()
+}
+
+// saveAction rewrites the log file holding entry with its updated fields.
+func saveAction(entry ActionEntry) error {
+	entries, err := readActions()
+	if err != nil {
+		return err
 	}
-	if lastLine == "" {
-		return ActionEntry{}, errors.New("no actions logged")
+	for i := range entries {
+		if entries[i].ID == entry.ID {
+			entry.file = entries[i].file
+			entries[i] = entry
+			return rewriteActionLog(entry.file, entriesInFile(entries, entry.file))
+		}
 	}
-	var entry ActionEntry
-	if err := json.Unmarshal([]byte(lastLine), &entry); err != nil {
-		return ActionEntry{}, err
+	return fmt.Errorf("action %d not found", entry.ID)
+}
+
+func entriesInFile(entries []ActionEntry, path string) []ActionEntry {
+	var out []ActionEntry
+	for _, entry := range entries {
+		if entry.file == path {
+			out = append(out, entry)
+		}
 	}
-	return entry, nil
+	return out
 }
 
-func removeLastAction() error {
-	path, err := actionLogPath()
-	if err != nil {
-		return err
+func rewriteActionLog(path string, entries []ActionEntry) error {
+	var b strings.Builder
+	for _, entry := range entries {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		b.Write(line)
+		b.WriteByte('\n')
 	}
-	data, err := os.ReadFile(path)
-	if err != nil {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(b.String()), 0o644); err != nil {
 		return err
 	}
-	lines := strings.Split(string(data), "\n")
-	for i := len(lines) - 1; i >= 0; i-- {
-		if strings.TrimSpace(lines[i]) != "" {
-			lines = append(lines[:i], lines[i+1:]...)
-			break
+	return os.Rename(tmp, path)
+}
+
+// findAction returns the entry with the given ID.
+func findAction(entries []ActionEntry, id int) (ActionEntry, error) {
+	for _, entry := range entries {
+		if entry.ID == id {
+			return entry, nil
 		}
 	}
-	content := strings.Join(lines, "\n")
-	return os.WriteFile(path, []byte(content), 0o644)
+	return ActionEntry{}, fmt.Errorf("no action with id %d", id)
+}
+
+// lastActions returns up to n of the most recent entries matching undone,
+// newest first.
+func lastActions(entries []ActionEntry, n int, undone bool) []ActionEntry {
+	var out []ActionEntry
+	for i := len(entries) - 1; i >= 0 && len(out) < n; i-- {
+		if (entries[i].UndoneAt != "") == undone {
+			out = append(out, entries[i])
+		}
+	}
+	return out
+}
+
+// lastUndone returns the most recently undone entry. Entries undone by one
+// undo share a timestamp, so UndoSeq decides; legacy entries without one fall
+// back to the lowest ID, which undo --steps reaches last.
+func lastUndone(entries []ActionEntry) (ActionEntry, bool) {
+	var last ActionEntry
+	found := false
+	for _, entry := range entries {
+		if entry.UndoneAt == "" {
+			continue
+		}
+		if !found || entry.UndoSeq > last.UndoSeq ||
+			(entry.UndoSeq == last.UndoSeq && (entry.UndoneAt > last.UndoneAt || (entry.UndoneAt == last.UndoneAt && entry.ID < last.ID))) {
+			last = entry
+			found = true
+		}
+	}
+	return last, found
+}
+
+// nextUndoSeq returns the sequence number for the next undone entry.
+func nextUndoSeq(entries []ActionEntry) int {
+	seq := 0
+	for _, entry := range entries {
+		if entry.UndoSeq > seq {
+			seq = entry.UndoSeq
+		}
+	}
+	return seq + 1
 }
 
 func taskToActionItem(task db.Task) ActionItem {
-	return ActionItem{
+	item := ActionItem{
 		UUID:         task.UUID,
 		Title:        task.Title,
 		Status:       task.Status,
@@ -133,6 +308,144 @@ func taskToActionItem(task db.Task) ActionItem {
 		StartDate:    task.StartDate,
 		ProjectID:    task.ProjectID,
 		AreaID:       task.AreaID,
+		HeadingID:    task.HeadingID,
 		HeadingTitle: task.HeadingTitle,
 	}
+	for _, checklistItem := range task.Checklist {
+		item.Checklist = append(item.Checklist, ActionChecklistItem{
+			UUID:   checklistItem.UUID,
+			Title:  checklistItem.Title,
+			Status: checklistItem.Status,
+		})
+	}
+	return item
+}
+
+// actionItemsFromTasks snapshots tasks for the action log, including their
+// checklists when the store can provide them.
+func actionItemsFromTasks(store *db.Store, tasks []db.Task) []ActionItem {
+	var checklists map[string][]db.ChecklistItem
+	if store != nil && len(tasks) > 0 {
+		ids := make([]string, 0, len(tasks))
+		for _, task := range tasks {
+			ids = append(ids, task.UUID)
+		}
+		checklists, _ = store.ChecklistItems(ids)
+	}
+	items := make([]ActionItem, 0, len(tasks))
+	for _, task := range tasks {
+		if checklist, ok := checklists[task.UUID]; ok {
+			task.Checklist = checklist
+		}
+		items = append(items, taskToActionItem(task))
+	}
+	return items
+}
+
+// createdItemTimeout bounds how long creation commands wait for Things to
+// write new items before giving up on logging them.
+var createdItemTimeout = 5 * time.Second
+
+// logCreatedItems looks up the items Things created for titles and records
+// them so undo can move them to Trash. The database is optional; when it is
+// unavailable nothing is logged. A title that matches more recent items than
+// the command created is skipped, since undo could otherwise trash an item
+// created elsewhere.
+func logCreatedItems(app *App, dbPath string, titles []string, taskType int, started time.Time) {
+	if app.DryRun || len(titles) == 0 {
+		return
+	}
+	store, _, err := db.OpenDefault(dbPath)
+	if err != nil {
+		return
+	}
+	defer store.Close()
+
+	// Things matches titles case-insensitively, so count them the same way.
+	expected := map[string]int{}
+	var order []string
+	for _, title := range titles {
+		key := strings.ToLower(title)
+		if expected[key] == 0 {
+			order = append(order, title)
+		}
+		expected[key]++
+	}
+
+	since := float64(started.Unix())
+	found := map[string][]db.TaskMatch{}
+	deadline := time.Now().Add(createdItemTimeout)
+	for {
+		for _, title := range order {
+			key := strings.ToLower(title)
+			if len(found[key]) >= expected[key] {
+				continue
+			}
+			matches, err := store.CreatedTasksByTitle(title, taskType, since)
+			if err != nil {
+				return
+			}
+			found[key] = matches
+		}
+		if allCreatedFound(found, expected) || !time.Now().Before(deadline) {
+			break
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+
+	entry := ActionEntry{Type: ActionCreate}
+	missing := false
+	ambiguous := false
+	for _, title := range order {
+		key := strings.ToLower(title)
+		matches := found[key]
+		switch {
+		case len(matches) > expected[key]:
+			ambiguous = true
+			continue
+		case len(matches) < expected[key]:
+			missing = true
+		}
+		for _, match := range matches {
+			entry.Items = append(entry.Items, ActionItem{UUID: match.UUID, Title: title})
+		}
+	}
+	if missing {
+		fmt.Fprintln(app.Err, "Warning: could not find every created item in the database; undo will not cover them.")
+	}
+	if ambiguous {
+		fmt.Fprintln(app.Err, "Warning: other recent items share a created title; undo will not cover them.")
+	}
+	if len(entry.Items) == 0 {
+		return
+	}
+	if err := appendAction(entry); err != nil {
+		fmt.Fprintf(app.Err, "Warning: failed to write action log: %v\n", err)
+	}
+}
+
+func allCreatedFound(found map[string][]db.TaskMatch, expected map[string]int) bool {
+	for key, count := range expected {
+		if len(found[key]) < count {
+			return false
+		}
+	}
+	return true
+}
+
+// createdTitles lists the titles an add request will create.
+func createdTitles(rawInput string, titlesRaw string) []string {
+	var titles []string
+	if strings.TrimSpace(titlesRaw) != "" {
+		for _, title := range strings.Split(titlesRaw, ",") {
+			if title = strings.TrimSpace(title); title != "" {
+				titles = append(titles, title)
+			}
+		}
+		return titles
+	}
+	if title := extractTitle(rawInput, ""); title != "" {
+		titles = append(titles, title)
+	}
+	return titles
 }
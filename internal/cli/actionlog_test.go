@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAppendActionAssignsIDs(t *testing.T) {
+	setConfigHome(t)
+	for _, title := range []string{"One", "Two", "Three"} {
+		if err := appendAction(ActionEntry{Type: ActionUpdate, Items: []ActionItem{{UUID: title, Title: title}}}); err != nil {
+			t.Fatalf("appendAction failed: %v", err)
+		}
+	}
+	entries, err := readActions()
+	if err != nil {
+		t.Fatalf("readActions failed: %v", err)
+	}
+	if len(entries) != 3 || entries[0].ID != 1 || entries[2].ID != 3 {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+
+	entry := entries[1]
+	entry.UndoneAt = "2026-01-01T00:00:00Z"
+	if err := saveAction(entry); err != nil {
+		t.Fatalf("saveAction failed: %v", err)
+	}
+	if last := lastActions(mustReadActions(t), 5, false); len(last) != 2 || last[0].ID != 3 || last[1].ID != 1 {
+		t.Fatalf("unexpected pending actions: %+v", last)
+	}
+}
+
+func TestReadActionsNumbersLegacyEntries(t *testing.T) {
+	setConfigHome(t)
+	path, err := actionLogPath()
+	if err != nil {
+		t.Fatalf("actionLogPath failed: %v", err)
+	}
+	legacy := `{"timestamp":"2026-01-01T00:00:00Z","type":"update","items":[{"uuid":"A","title":"A","status":0}]}
+{"timestamp":"2026-01-02T00:00:00Z","type":"trash","items":[{"uuid":"B","title":"B","status":0}]}
+`
+	if err := os.WriteFile(path, []byte(legacy), 0o644); err != nil {
+		t.Fatalf("write log: %v", err)
+	}
+	if err := appendAction(ActionEntry{Type: ActionUpdate, Items: []ActionItem{{UUID: "C"}}}); err != nil {
+		t.Fatalf("appendAction failed: %v", err)
+	}
+	entries := mustReadActions(t)
+	if len(entries) != 3 || entries[0].ID != 1 || entries[1].ID != 2 || entries[2].ID != 3 {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+}
+
+func TestAppendActionRotatesLog(t *testing.T) {
+	setConfigHome(t)
+	prev := maxActionLogSize
+	maxActionLogSize = 300
+	t.Cleanup(func() { maxActionLogSize = prev })
+
+	for i := 0; i < 6; i++ {
+		if err := appendAction(ActionEntry{Type: ActionUpdate, Items: []ActionItem{{UUID: "ABCDEFGHIJ", Title: "Rotate me"}}}); err != nil {
+			t.Fatalf("appendAction failed: %v", err)
+		}
+	}
+	path, _ := actionLogPath()
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat log: %v", err)
+	}
+	if info.Size() > maxActionLogSize {
+		t.Fatalf("log exceeds cap: %d bytes", info.Size())
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(path), "actions.1.jsonl")); err != nil {
+		t.Fatalf("expected rotated log: %v", err)
+	}
+
+	entries := mustReadActions(t)
+	if len(entries) == 6 {
+		t.Fatalf("expected oldest entries to be dropped after two rotations")
+	}
+	if last := entries[len(entries)-1]; last.ID != 6 {
+		t.Fatalf("expected IDs to continue across rotation, got %d", last.ID)
+	}
+	for i := 1; i < len(entries); i++ {
+		if entries[i].ID != entries[i-1].ID+1 {
+			t.Fatalf("expected contiguous IDs, got %+v", entries)
+		}
+	}
+}
+
+func mustReadActions(t *testing.T) []ActionEntry {
+	t.Helper()
+	entries, err := readActions()
+	if err != nil {
+		t.Fatalf("readActions failed: %v", err)
+	}
+	return entries
+}
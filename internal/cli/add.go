@@ -16,6 +16,7 @@ func NewAddCommand(app *App) *cobra.Command {
 	repeatOpts := RepeatOptions{}
 	var dbPath string
 	var allowUnsafeTitle bool
+	var exact bool
 
 	cmd := &cobra.Command{
 		Use:   "add [OPTIONS...] [--] [-|TITLE]",
@@ -53,9 +54,20 @@ func NewAddCommand(app *App) *cobra.Command {
 				}
 			}
 
+			if err := resolveListOption(dbPath, exact, &opts.List, &opts.ListID); err != nil {
+				return err
+			}
+
 			url := things.BuildAddURL(opts, rawInput)
 			if !repeatSpec.Enabled {
-				return openURL(app, url)
+				started := time.Now().Add(-2 * time.Second)
+				if err := openURL(app, url); err != nil {
+					return err
+				}
+				if !opts.ShowQuickEntry && opts.UseClipboard == "" {
+					logCreatedItems(app, dbPath, createdTitles(rawInput, opts.TitlesRaw), db.TaskTypeTodo, started)
+				}
+				return nil
 			}
 			if app.DryRun {
 				if err := openURL(app, url); err != nil {
@@ -76,7 +88,7 @@ func NewAddCommand(app *App) *cobra.Command {
 			}
 			defer store.Close()
 
-			taskID, err := waitForCreatedItem(store, title, db.TaskTypeTodo, started)
+			taskID, err := waitForCreatedItem(store, title, db.TaskTypeTodo, started, time.Now().Add(createdItemWait))
 			if err != nil {
 				return formatDBError(err)
 			}
@@ -87,6 +99,9 @@ func NewAddCommand(app *App) *cobra.Command {
 			if err := store.ApplyRepeatRule(taskID, update); err != nil {
 				return formatDBError(err)
 			}
+			if err := appendAction(ActionEntry{Type: ActionCreate, Items: []ActionItem{{UUID: taskID, Title: title}}}); err != nil {
+				fmt.Fprintf(app.Err, "Warning: failed to write action log: %v\n", err)
+			}
 			return nil
 		},
 	}
@@ -104,6 +119,7 @@ func NewAddCommand(app *App) *cobra.Command {
 	flags.StringVar(&opts.CompletionDate, "completion-date", "", "Completion date (ISO8601)")
 	flags.StringVar(&opts.List, "list", "", "Project or area to add to")
 	flags.StringVar(&opts.ListID, "list-id", "", "Project or area ID to add to")
+	flags.BoolVar(&exact, "exact", false, "Match the --list title exactly instead of fuzzily")
 	flags.StringVar(&opts.Heading, "heading", "", "Heading within a project")
 	flags.BoolVar(&opts.Reveal, "reveal", false, "Reveal the newly created todo")
 	flags.BoolVar(&opts.ShowQuickEntry, "show-quick-entry", false, "Show the quick entry dialog")
@@ -1,6 +1,11 @@
 package cli
 
 import (
+	"fmt"
+	"time"
+
+	"github.com/ossianhempel/things3-cli/internal/db"
+	"github.com/ossianhempel/things3-cli/internal/repeat"
 	"github.com/ossianhempel/things3-cli/internal/things"
 	"github.com/spf13/cobra"
 )
@@ -8,6 +13,8 @@ import (
 // NewAddProjectCommand builds the add-project subcommand.
 func NewAddProjectCommand(app *App) *cobra.Command {
 	opts := things.AddProjectOptions{}
+	repeatOpts := RepeatOptions{}
+	var dbPath string
 	var allowUnsafeTitle bool
 
 	cmd := &cobra.Command{
@@ -19,6 +26,10 @@ func NewAddProjectCommand(app *App) *cobra.Command {
 			if err != nil {
 				return err
 			}
+			repeatSpec, err := parseRepeatSpec(cmd, repeatOpts)
+			if err != nil {
+				return err
+			}
 			title := extractTitle(rawInput, "")
 			if err := guardUnsafeTitle(title, allowUnsafeTitle); err != nil {
 				return err
@@ -27,12 +38,64 @@ func NewAddProjectCommand(app *App) *cobra.Command {
 				return err
 			}
 
+			if repeatSpec.Enabled {
+				if repeatSpec.Clear {
+					return fmt.Errorf("Error: --repeat-clear is only valid with update commands")
+				}
+				if title == "" {
+					return fmt.Errorf("Error: repeating add-project requires an explicit title")
+				}
+			}
+
 			url := things.BuildAddProjectURL(opts, rawInput)
-			return openURL(app, url)
+			if !repeatSpec.Enabled {
+				started := time.Now().Add(-2 * time.Second)
+				if err := openURL(app, url); err != nil {
+					return err
+				}
+				logCreatedItems(app, dbPath, createdTitles(rawInput, ""), db.TaskTypeProject, started)
+				return nil
+			}
+			if app.DryRun {
+				if err := openURL(app, url); err != nil {
+					return err
+				}
+				fmt.Fprintln(app.Err, "Note: --repeat is skipped in --dry-run mode.")
+				return nil
+			}
+
+			ensureThingsLaunched(app)
+			started := time.Now().Add(-2 * time.Second)
+			if err := openURL(app, url); err != nil {
+				return err
+			}
+			store, _, err := db.OpenDefaultWritable(dbPath)
+			if err != nil {
+				return formatDBError(err)
+			}
+			defer store.Close()
+
+			projectID, err := waitForCreatedItem(store, title, db.TaskTypeProject, started, time.Now().Add(createdItemWait))
+			if err != nil {
+				return formatDBError(err)
+			}
+			update, err := repeat.BuildUpdate(repeatSpec.Spec)
+			if err != nil {
+				return err
+			}
+			if err := store.ApplyRepeatRule(projectID, update); err != nil {
+				return formatDBError(err)
+			}
+			if err := appendAction(ActionEntry{Type: ActionCreate, Items: []ActionItem{{UUID: projectID, Title: title}}}); err != nil {
+				fmt.Fprintf(app.Err, "Warning: failed to write action log: %v\n", err)
+			}
+			return nil
 		},
 	}
 
 	flags := cmd.Flags()
+	flags.StringVarP(&dbPath, "db", "d", "", "Path to Things database (overrides THINGSDB)")
+	flags.StringVar(&dbPath, "database", "", "Alias for --db")
 	flags.StringVar(&opts.AreaID, "area-id", "", "Area ID to add to")
 	flags.StringVar(&opts.Area, "area", "", "Area to add to")
 	flags.BoolVar(&opts.Canceled, "canceled", false, "Mark the project canceled")
@@ -47,6 +110,7 @@ func NewAddProjectCommand(app *App) *cobra.Command {
 	flags.StringVar(&opts.When, "when", "", "When to schedule the project")
 	flags.StringArrayVar(&opts.Todos, "todo", nil, "Todo title to add (repeatable)")
 	flags.BoolVar(&allowUnsafeTitle, "allow-unsafe-title", false, "Allow titles that look like flag assignments")
+	addRepeatFlags(cmd, &repeatOpts, false)
 
 	return cmd
 }
@@ -80,3 +80,33 @@ func TestCreateProjectAlias(t *testing.T) {
 		t.Fatalf("expected title in url, got %q", url)
 	}
 }
+
+func TestAddProjectCommandRepeatDryRun(t *testing.T) {
+	launcher := &recordLauncher{}
+	errOut := &bytes.Buffer{}
+	app := &App{
+		In:       strings.NewReader(""),
+		Out:      &bytes.Buffer{},
+		Err:      errOut,
+		Launcher: launcher,
+	}
+
+	root := NewRoot(app)
+	root.SetArgs([]string{"--dry-run", "add-project", "Monthly close", "--repeat=month", "--repeat-on=last-day"})
+	root.SetOut(app.Out)
+	root.SetErr(app.Err)
+	if err := root.Execute(); err != nil {
+		t.Fatalf("execute failed: %v", err)
+	}
+	if !strings.Contains(errOut.String(), "--repeat is skipped in --dry-run mode") {
+		t.Fatalf("expected dry-run note, got %q", errOut.String())
+	}
+
+	root = NewRoot(app)
+	root.SetArgs([]string{"add-project", "Monthly close", "--repeat=day", "--repeat-on=mon"})
+	root.SetOut(app.Out)
+	root.SetErr(app.Err)
+	if err := root.Execute(); err == nil {
+		t.Fatalf("expected --repeat-on error for daily rule")
+	}
+}
@@ -149,3 +149,36 @@ func TestAddCommandShowQuickEntryWhenNoTitle(t *testing.T) {
 		t.Fatalf("expected show-quick-entry in url, got %q", url)
 	}
 }
+
+func TestAddCommandResolvesFuzzyList(t *testing.T) {
+	dbPath := writeTestDB(t)
+	out := &bytes.Buffer{}
+	app := &App{
+		In:  strings.NewReader(""),
+		Out: out,
+		Err: &bytes.Buffer{},
+	}
+
+	root := NewRoot(app)
+	root.SetArgs([]string{"--dry-run", "add", "New Todo", "--db", dbPath, "--list", "proj one"})
+	root.SetOut(app.Out)
+	root.SetErr(app.Err)
+	if err := root.Execute(); err != nil {
+		t.Fatalf("execute failed: %v", err)
+	}
+	if url := out.String(); !strings.Contains(url, "list-id=P1") || strings.Contains(url, "list=") {
+		t.Fatalf("expected fuzzy list resolved to list-id, got %q", url)
+	}
+
+	out.Reset()
+	root = NewRoot(app)
+	root.SetArgs([]string{"--dry-run", "add", "New Todo", "--db", dbPath, "--list", "proj one", "--exact"})
+	root.SetOut(app.Out)
+	root.SetErr(app.Err)
+	if err := root.Execute(); err != nil {
+		t.Fatalf("execute failed: %v", err)
+	}
+	if url := out.String(); !strings.Contains(url, "list=proj%20one") {
+		t.Fatalf("expected --exact to pass the title through, got %q", url)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"io"
 	"os"
 
+	"github.com/ossianhempel/things3-cli/internal/config"
 	"github.com/ossianhempel/things3-cli/internal/open"
 	"github.com/ossianhempel/things3-cli/internal/osascript"
 )
@@ -28,6 +29,10 @@ type App struct {
 	Debug      bool
 	Foreground bool
 	DryRun     bool
+	// Profile names the config profile selected with --profile.
+	Profile string
+	// Config holds the settings resolved from config.toml at startup.
+	Config config.Settings
 }
 
 // NewApp builds the default application wiring.
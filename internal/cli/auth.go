@@ -2,6 +2,7 @@ package cli
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
@@ -12,7 +13,8 @@ const authSetupInstructions = `To set up the Things URL scheme token:
   3. Copy the token (or enable "Allow 'things' CLI to access Things").
   4. export THINGS_AUTH_TOKEN=your_token_here
 
-Tip: add the export to your shell profile (e.g. ~/.zshrc) to persist it.`
+Tip: add the export to your shell profile (e.g. ~/.zshrc) to persist it, or
+store it with: things config set auth-token your_token_here`
 
 // NewAuthCommand builds the auth subcommand.
 func NewAuthCommand(app *App) *cobra.Command {
@@ -20,7 +22,12 @@ func NewAuthCommand(app *App) *cobra.Command {
 		Use:   "auth",
 		Short: "Show Things auth token status and setup help",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			source := "THINGS_AUTH_TOKEN"
 			token := authTokenFromEnv()
+			if token == "" {
+				source = "config"
+				token = strings.TrimSpace(app.Config.AuthToken)
+			}
 			if token == "" {
 				fmt.Fprintln(app.Out, "Things auth token: not set.")
 				fmt.Fprintln(app.Out)
@@ -28,7 +35,7 @@ func NewAuthCommand(app *App) *cobra.Command {
 				return nil
 			}
 
-			fmt.Fprintln(app.Out, "Things auth token: set (THINGS_AUTH_TOKEN).")
+			fmt.Fprintf(app.Out, "Things auth token: set (%s).\n", source)
 			fmt.Fprintln(app.Out, "Use update/update-project, or pass --auth-token to override.")
 			return nil
 		},
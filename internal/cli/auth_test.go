@@ -7,6 +7,7 @@ import (
 )
 
 func TestAuthCommandMissingToken(t *testing.T) {
+	setConfigHome(t)
 	t.Setenv("THINGS_AUTH_TOKEN", "")
 
 	out := &bytes.Buffer{}
@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ossianhempel/things3-cli/internal/backup"
+	"github.com/ossianhempel/things3-cli/internal/db"
+	"github.com/spf13/cobra"
+)
+
+// NewBackupCommand builds the backup command.
+func NewBackupCommand(app *App) *cobra.Command {
+	var dbPath string
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "backup [OPTIONS...]",
+		Short: "Write the whole library to a JSON archive",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, _, err := db.OpenDefault(dbPath)
+			if err != nil {
+				return formatDBError(err)
+			}
+			defer store.Close()
+
+			archive, err := backup.Read(store, time.Now())
+			if err != nil {
+				return formatDBError(err)
+			}
+
+			out := app.Out
+			if output != "" && output != "-" {
+				file, err := os.Create(output)
+				if err != nil {
+					return fmt.Errorf("Error: %v", err)
+				}
+				defer file.Close()
+				out = file
+			}
+			enc := json.NewEncoder(out)
+			enc.SetEscapeHTML(false)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(archive); err != nil {
+				return fmt.Errorf("Error: write backup: %v", err)
+			}
+			if output != "" && output != "-" {
+				areas, projects, todos := archive.Counts()
+				fmt.Fprintf(app.Err, "Backed up %d areas, %d projects, %d todos, and %d tags to %s\n", areas, projects, todos, len(archive.Tags), output)
+			}
+			return nil
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVarP(&dbPath, "db", "d", "", "Path to Things database (overrides THINGSDB)")
+	flags.StringVar(&dbPath, "database", "", "Alias for --db")
+	flags.StringVarP(&output, "output", "o", "", "Write the archive to a file instead of stdout")
+
+	return cmd
+}
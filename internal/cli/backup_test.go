@@ -0,0 +1,294 @@
+package cli
+
+import (
+	"bytes"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ossianhempel/things3-cli/internal/backup"
+	"github.com/ossianhempel/things3-cli/internal/repeat"
+)
+
+// thingsStubLauncher stands in for Things: opening a json URL creates the
+// todo named title in the database.
+type thingsStubLauncher struct {
+	t      *testing.T
+	dbPath string
+	title  string
+	urls   []string
+}
+
+func (l *thingsStubLauncher) Open(args ...string) error {
+	url := args[len(args)-1]
+	if !strings.HasPrefix(url, "things:///json") {
+		return nil
+	}
+	l.urls = append(l.urls, url)
+	conn, err := sql.Open("sqlite", l.dbPath)
+	if err != nil {
+		l.t.Fatalf("open db: %v", err)
+	}
+	defer conn.Close()
+	if _, err := conn.Exec(`INSERT INTO TMTask (uuid, type, status, trashed, title, start, creationDate) VALUES ('NEW1', 0, 0, 0, ?, 1, ?)`, l.title, float64(time.Now().Unix())); err != nil {
+		l.t.Fatalf("insert task: %v", err)
+	}
+	return nil
+}
+
+func TestBackupAndRestoreIntoEmptyLibrary(t *testing.T) {
+	dbPath := writeTestDB(t)
+	update, err := repeat.BuildUpdate(repeat.Spec{Mode: repeat.ModeSchedule, Unit: repeat.UnitWeek, Every: 1, Anchor: time.Date(2026, 1, 5, 0, 0, 0, 0, time.Local)})
+	if err != nil {
+		t.Fatalf("BuildUpdate failed: %v", err)
+	}
+	conn, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if _, err := conn.Exec(`UPDATE TMTask SET rt1_recurrenceRule = ? WHERE uuid = 'T1'`, update.RecurrenceRule); err != nil {
+		t.Fatalf("set rule: %v", err)
+	}
+	if _, err := conn.Exec(`INSERT INTO TMTag (uuid, title, parent) VALUES ('TAG2', 'very', 'TAG1'), ('TAG0', 'agenda', NULL)`); err != nil {
+		t.Fatalf("insert tags: %v", err)
+	}
+	conn.Close()
+
+	archivePath := filepath.Join(t.TempDir(), "backup.json")
+	app := &App{In: strings.NewReader(""), Out: &bytes.Buffer{}, Err: &bytes.Buffer{}}
+	root := NewRoot(app)
+	root.SetArgs([]string{"backup", "--db", dbPath, "-o", archivePath})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("backup failed: %v", err)
+	}
+	data, err := os.ReadFile(archivePath)
+	if err != nil {
+		t.Fatalf("read archive: %v", err)
+	}
+	archive, err := backup.Load(data)
+	if err != nil {
+		t.Fatalf("load archive: %v", err)
+	}
+	var tags []string
+	for _, tag := range archive.Tags {
+		tags = append(tags, tag.Title+"<"+tag.Parent)
+	}
+	if got := strings.Join(tags, " "); got != "agenda< urgent< very<urgent" {
+		t.Fatalf("unexpected tags: %s", got)
+	}
+	if len(archive.Areas) != 1 || len(archive.Areas[0].Projects) != 1 {
+		t.Fatalf("unexpected areas: %+v", archive.Areas)
+	}
+	project := archive.Areas[0].Projects[0]
+	if project.Title != "Project One" || len(project.Headings) != 1 || len(project.Headings[0].Todos) != 1 {
+		t.Fatalf("unexpected project: %+v", project)
+	}
+	task := project.Headings[0].Todos[0]
+	if task.Title != "Task One" || len(task.Checklist) != 1 || task.Repeat == nil || task.Repeat.Description != "every week on Monday" {
+		t.Fatalf("unexpected todo: %+v", task)
+	}
+	if len(archive.Todos) == 0 || archive.Todos[0].Title != "Inbox Task" {
+		t.Fatalf("unexpected loose todos: %+v", archive.Todos)
+	}
+
+	emptyPath, emptyConn := createTestDB(t)
+	emptyConn.Close()
+	launcher := &thingsStubLauncher{t: t, dbPath: emptyPath, title: "Task One"}
+	scripter := &recordScriptRunner{}
+	out := &bytes.Buffer{}
+	errOut := &bytes.Buffer{}
+	app = &App{In: strings.NewReader(""), Out: out, Err: errOut, Launcher: launcher, Scripter: scripter}
+	root = NewRoot(app)
+	root.SetArgs([]string{"restore", "--into-empty", "--db", emptyPath, archivePath})
+	root.SetOut(app.Out)
+	root.SetErr(app.Err)
+	if err := root.Execute(); err != nil {
+		t.Fatalf("restore failed: %v\n%s", err, errOut.String())
+	}
+
+	if len(scripter.scripts) != 2 {
+		t.Fatalf("expected tag and area scripts, got %d", len(scripter.scripts))
+	}
+	if !strings.Contains(scripter.scripts[0], "set parent tag of newTag to tag \"urgent\"") || !strings.Contains(scripter.scripts[1], "name:\"Home\"") {
+		t.Fatalf("unexpected scripts:\n%s", strings.Join(scripter.scripts, "\n"))
+	}
+	if len(launcher.urls) != 1 {
+		t.Fatalf("expected one json batch, got %d", len(launcher.urls))
+	}
+	url := launcher.urls[0]
+	for _, want := range []string{"%22area%22%3A%22Home%22", "%22title%22%3A%22Heading%22", "Check%20Item", "%22completed%22%3Atrue", "%22when%22%3A%22someday%22"} {
+		if !strings.Contains(url, want) {
+			t.Fatalf("expected %s in url: %s", want, url)
+		}
+	}
+	if strings.Index(url, "Project%20One") > strings.Index(url, "Inbox%20Task") {
+		t.Fatalf("expected area projects before loose todos: %s", url)
+	}
+	if errOut.Len() != 0 {
+		t.Fatalf("unexpected warnings:\n%s", errOut.String())
+	}
+	if !strings.HasPrefix(out.String(), "Restored 1 areas, 1 projects, ") {
+		t.Fatalf("unexpected summary: %s", out.String())
+	}
+
+	conn, err = sql.Open("sqlite", emptyPath)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer conn.Close()
+	var rule []byte
+	if err := conn.QueryRow(`SELECT rt1_recurrenceRule FROM TMTask WHERE uuid = 'NEW1'`).Scan(&rule); err != nil {
+		t.Fatalf("read rule: %v", err)
+	}
+	if len(rule) == 0 {
+		t.Fatalf("expected repeat rule to be restored")
+	}
+}
+
+func TestRestoreRepeatingRules(t *testing.T) {
+	today := time.Now()
+	future := time.Date(today.Year()+1, 3, 15, 0, 0, 0, 0, time.Local)
+	tests := []struct {
+		name string
+		unit repeat.Unit
+		mode repeat.Mode
+		next time.Time
+		want time.Time
+	}{
+		{"daily", repeat.UnitDay, repeat.ModeSchedule, future, future},
+		{"weekly", repeat.UnitWeek, repeat.ModeSchedule, future, future},
+		{"monthly", repeat.UnitMonth, repeat.ModeSchedule, future, future},
+		{"yearly", repeat.UnitYear, repeat.ModeSchedule, future, future},
+		{"daily after completion", repeat.UnitDay, repeat.ModeAfterCompletion, time.Time{}, time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.Local)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dbPath := writeTestDB(t)
+			anchor := tt.next
+			if anchor.IsZero() {
+				anchor = time.Date(2026, 1, 5, 0, 0, 0, 0, time.Local)
+			}
+			update, err := repeat.BuildUpdate(repeat.Spec{Mode: tt.mode, Unit: tt.unit, Every: 1, Anchor: anchor})
+			if err != nil {
+				t.Fatalf("BuildUpdate failed: %v", err)
+			}
+			var next any
+			if !tt.next.IsZero() {
+				next = thingsDate(tt.next)
+			}
+			conn, err := sql.Open("sqlite", dbPath)
+			if err != nil {
+				t.Fatalf("open db: %v", err)
+			}
+			if _, err := conn.Exec(`UPDATE TMTask SET rt1_recurrenceRule = ?, rt1_nextInstanceStartDate = ? WHERE uuid = 'T1'`, update.RecurrenceRule, next); err != nil {
+				t.Fatalf("set rule: %v", err)
+			}
+			conn.Close()
+
+			archivePath := filepath.Join(t.TempDir(), "backup.json")
+			if _, err := runCommand(t, &App{}, "backup", "--db", dbPath, "-o", archivePath); err != nil {
+				t.Fatalf("backup failed: %v", err)
+			}
+			emptyPath, emptyConn := createTestDB(t)
+			emptyConn.Close()
+			errOut := &bytes.Buffer{}
+			app := &App{Err: errOut, Launcher: &thingsStubLauncher{t: t, dbPath: emptyPath, title: "Task One"}, Scripter: &recordScriptRunner{}}
+			if _, err := runCommand(t, app, "restore", "--into-empty", "--db", emptyPath, archivePath); err != nil {
+				t.Fatalf("restore failed: %v", err)
+			}
+			if errOut.Len() != 0 {
+				t.Fatalf("unexpected warnings:\n%s", errOut.String())
+			}
+
+			conn, err = sql.Open("sqlite", emptyPath)
+			if err != nil {
+				t.Fatalf("open db: %v", err)
+			}
+			defer conn.Close()
+			var rule []byte
+			var start int
+			var nextStart sql.NullInt64
+			if err := conn.QueryRow(`SELECT rt1_recurrenceRule, rt1_instanceCreationStartDate, rt1_nextInstanceStartDate FROM TMTask WHERE uuid = 'NEW1'`).Scan(&rule, &start, &nextStart); err != nil {
+				t.Fatalf("read rule: %v", err)
+			}
+			spec, err := repeat.Parse(rule)
+			if err != nil {
+				t.Fatalf("parse restored rule: %v", err)
+			}
+			if spec.Unit != tt.unit || spec.Mode != tt.mode || !spec.Anchor.Equal(tt.want) {
+				t.Fatalf("unexpected restored rule: %+v", spec)
+			}
+			if start != thingsDate(tt.want) {
+				t.Fatalf("instance creation start = %d, want %d", start, thingsDate(tt.want))
+			}
+			if wantNext := tt.mode == repeat.ModeSchedule; nextStart.Valid != wantNext || wantNext && int(nextStart.Int64) != thingsDate(tt.want) {
+				t.Fatalf("unexpected next instance start: %+v", nextStart)
+			}
+		})
+	}
+}
+
+func TestRestoreRepeatsShareOneDeadline(t *testing.T) {
+	prev := createdItemWait
+	createdItemWait = 300 * time.Millisecond
+	t.Cleanup(func() { createdItemWait = prev })
+
+	dbPath := writeTestDB(t)
+	update, err := repeat.BuildUpdate(repeat.Spec{Mode: repeat.ModeSchedule, Unit: repeat.UnitDay, Every: 1})
+	if err != nil {
+		t.Fatalf("BuildUpdate failed: %v", err)
+	}
+	conn, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if _, err := conn.Exec(`UPDATE TMTask SET rt1_recurrenceRule = ? WHERE uuid IN ('T1', 'P1')`, update.RecurrenceRule); err != nil {
+		t.Fatalf("set rules: %v", err)
+	}
+	conn.Close()
+
+	archivePath := filepath.Join(t.TempDir(), "backup.json")
+	if _, err := runCommand(t, &App{}, "backup", "--db", dbPath, "-o", archivePath); err != nil {
+		t.Fatalf("backup failed: %v", err)
+	}
+	emptyPath, emptyConn := createTestDB(t)
+	emptyConn.Close()
+	// Things never writes the items, as when it rejects a batch.
+	errOut := &bytes.Buffer{}
+	app := &App{Err: errOut, Launcher: &recordLauncher{}, Scripter: &recordScriptRunner{}}
+	begin := time.Now()
+	if _, err := runCommand(t, app, "restore", "--into-empty", "--db", emptyPath, archivePath); err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+	if elapsed := time.Since(begin); elapsed > createdItemWait*3/2 {
+		t.Fatalf("expected one shared wait, took %s", elapsed)
+	}
+	if got := strings.Count(errOut.String(), "not restored: timed out"); got != 2 {
+		t.Fatalf("expected two timeout warnings, got:\n%s", errOut.String())
+	}
+}
+
+func TestRestoreRequiresEmptyLibrary(t *testing.T) {
+	dbPath := writeTestDB(t)
+	archive := `{"version": 1, "todos": [{"title": "One", "status": "incomplete"}]}`
+	for _, args := range [][]string{
+		{"restore", "--db", dbPath, "-"},
+		{"restore", "--into-empty", "--db", dbPath, "-"},
+	} {
+		launcher := &recordLauncher{}
+		app := &App{In: strings.NewReader(archive), Out: &bytes.Buffer{}, Err: &bytes.Buffer{}, Launcher: launcher, Scripter: &recordScriptRunner{}}
+		root := NewRoot(app)
+		root.SetArgs(args)
+		root.SetOut(app.Out)
+		root.SetErr(app.Err)
+		if err := root.Execute(); err == nil {
+			t.Fatalf("expected %v to fail", args)
+		}
+		if len(launcher.args) != 0 {
+			t.Fatalf("expected no open invocation")
+		}
+	}
+}
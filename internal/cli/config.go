@@ -0,0 +1,194 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ossianhempel/things3-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// NewConfigCommand builds the config command and its subcommands.
+func NewConfigCommand(app *App) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config <get|set|list> [ARGS...]",
+		Short: "Read and edit the config file",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			printHelp(app.Out, formatHelpText(configHelp, isTTY(app.Out)))
+			return ErrHelpPrinted
+		},
+	}
+
+	cmd.AddCommand(newConfigGetCommand(app))
+	cmd.AddCommand(newConfigSetCommand(app))
+	cmd.AddCommand(newConfigListCommand(app))
+	return cmd
+}
+
+func newConfigGetCommand(app *App) *cobra.Command {
+	return &cobra.Command{
+		Use:   "get KEY",
+		Short: "Print a config value",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, _, err := loadConfigFile()
+			if err != nil {
+				return err
+			}
+			value, err := cfg.Get(args[0])
+			if err != nil {
+				return fmt.Errorf("Error: %v", err)
+			}
+			fmt.Fprintln(app.Out, value)
+			return nil
+		},
+	}
+}
+
+func newConfigSetCommand(app *App) *cobra.Command {
+	return &cobra.Command{
+		Use:   "set KEY [VALUE]",
+		Short: "Set a config value (omit VALUE to clear it)",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, path, err := loadConfigFile()
+			if err != nil {
+				return err
+			}
+			value := ""
+			if len(args) == 2 {
+				value = args[1]
+			}
+			if err := cfg.Set(args[0], value); err != nil {
+				return fmt.Errorf("Error: %v", err)
+			}
+			if app.DryRun {
+				fmt.Fprintf(app.Out, "Would write %s\n", path)
+				return nil
+			}
+			if err := config.Save(path, cfg); err != nil {
+				return fmt.Errorf("Error: %v", err)
+			}
+			return nil
+		},
+	}
+}
+
+func newConfigListCommand(app *App) *cobra.Command {
+	var showToken bool
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List config values",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, _, err := loadConfigFile()
+			if err != nil {
+				return err
+			}
+			for _, entry := range cfg.Entries() {
+				value := entry[1]
+				if !showToken && strings.HasSuffix(entry[0], "auth-token") {
+					value = maskToken(value)
+				}
+				fmt.Fprintf(app.Out, "%s=%s\n", entry[0], value)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&showToken, "show-token", false, "Print auth tokens unmasked")
+	return cmd
+}
+
+func loadConfigFile() (config.Config, string, error) {
+	path, err := config.Path()
+	if err != nil {
+		return config.Config{}, "", fmt.Errorf("Error: %v", err)
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		return config.Config{}, "", fmt.Errorf("Error: %v", err)
+	}
+	return cfg, path, nil
+}
+
+// applyConfig resolves config.toml and the selected profile into app and
+// fills in flags the user did not set. Precedence for the database is
+// --db, then the --profile database, then THINGSDB, then the config file.
+func applyConfig(app *App, cmd *cobra.Command) error {
+	for c := cmd; c != nil; c = c.Parent() {
+		if c.Name() == "config" && c.Parent() != nil && c.Parent().Parent() == nil {
+			// Let config subcommands repair a broken file.
+			return nil
+		}
+	}
+	path, err := config.Path()
+	if err != nil {
+		return nil
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		return fmt.Errorf("Error: config: %v", err)
+	}
+	profile := strings.TrimSpace(app.Profile)
+	if profile == "" {
+		profile = strings.TrimSpace(os.Getenv("THINGS_PROFILE"))
+	}
+	settings, err := cfg.Resolve(profile)
+	if err != nil {
+		return fmt.Errorf("Error: %v", err)
+	}
+	app.Config = settings
+
+	flags := cmd.Flags()
+	if settings.Foreground != nil && !flags.Changed("foreground") {
+		app.Foreground = *settings.Foreground
+	}
+	if settings.DB != "" && !flags.Changed("database") {
+		if profile != "" || strings.TrimSpace(os.Getenv("THINGSDB")) == "" {
+			if err := setFlagDefault(cmd, "db", settings.DB); err != nil {
+				return err
+			}
+		}
+	}
+	// format and limit only apply to task list commands; other commands
+	// with flags of the same name accept different values.
+	if settings.Format != "" && !flags.Changed("json") && isTaskListFlag(cmd, "format") {
+		if err := setFlagDefault(cmd, "format", settings.Format); err != nil {
+			return err
+		}
+	}
+	if settings.Limit > 0 && isTaskListFlag(cmd, "limit") {
+		if err := setFlagDefault(cmd, "limit", fmt.Sprint(settings.Limit)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isTaskListFlag(cmd *cobra.Command, name string) bool {
+	flag := cmd.Flags().Lookup(name)
+	return flag != nil && len(flag.Annotations[configDefaultAnnotation]) > 0
+}
+
+// setFlagDefault assigns value to an unset flag without marking it changed,
+// so commands can still tell config defaults from explicit flags.
+func setFlagDefault(cmd *cobra.Command, name, value string) error {
+	flag := cmd.Flags().Lookup(name)
+	if flag == nil || flag.Changed {
+		return nil
+	}
+	if err := flag.Value.Set(value); err != nil {
+		return fmt.Errorf("Error: config: invalid %s %q: %v", name, value, err)
+	}
+	return nil
+}
+
+func maskToken(token string) string {
+	if len(token) <= 4 {
+		return strings.Repeat("*", len(token))
+	}
+	return strings.Repeat("*", len(token)-4) + token[len(token)-4:]
+}
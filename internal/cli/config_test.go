@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestConfigSetGetList(t *testing.T) {
+	setConfigHome(t)
+	app := &App{}
+	for _, args := range [][]string{
+		{"config", "set", "limit", "5"},
+		{"config", "set", "auth-token", "secret-token"},
+		{"config", "set", "profiles.fixture.format", "json"},
+	} {
+		if _, err := runCommand(t, app, args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	out, err := runCommand(t, app, "config", "get", "profiles.fixture.format")
+	if err != nil || out != "json\n" {
+		t.Fatalf("unexpected get output %q (%v)", out, err)
+	}
+	out, err = runCommand(t, app, "config", "list")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out, "limit=5\n") || !strings.Contains(out, "auth-token=********oken\n") || strings.Contains(out, "secret") {
+		t.Fatalf("unexpected list output:\n%s", out)
+	}
+	if _, err := runCommand(t, app, "config", "set", "limit", "lots"); err == nil {
+		t.Fatalf("expected error for invalid limit")
+	}
+	if _, err := runCommand(t, app, "config", "set", "profile", "missing"); err == nil {
+		t.Fatalf("expected error for unknown profile")
+	}
+}
+
+func TestConfigProfileAppliesDefaults(t *testing.T) {
+	setConfigHome(t)
+	t.Setenv("THINGSDB", "")
+	t.Setenv("THINGS_AUTH_TOKEN", "")
+	t.Setenv("THINGS_PROFILE", "")
+	dbPath := writeTestDB(t)
+	app := &App{}
+	for _, args := range [][]string{
+		{"config", "set", "profiles.fixture.db", dbPath},
+		{"config", "set", "profiles.fixture.format", "json"},
+		{"config", "set", "profiles.fixture.auth-token", "profile-token"},
+		{"config", "set", "profiles.fixture.foreground", "true"},
+	} {
+		if _, err := runCommand(t, app, args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	app = &App{}
+	out, err := runCommand(t, app, "--profile", "fixture", "inbox")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var tasks []map[string]any
+	if err := json.Unmarshal([]byte(out), &tasks); err != nil {
+		t.Fatalf("expected JSON output from profile format, got %q", out)
+	}
+	if len(tasks) != 1 || tasks[0]["uuid"] != "INBOX1" {
+		t.Fatalf("unexpected tasks: %v", tasks)
+	}
+	if !app.Foreground {
+		t.Fatalf("expected foreground from profile")
+	}
+	if token, err := resolveAuthToken(app, ""); err != nil || token != "profile-token" {
+		t.Fatalf("expected profile token, got %q (%v)", token, err)
+	}
+
+	out, err = runCommand(t, &App{}, "--profile", "fixture", "inbox", "--format", "csv")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(out, "UUID,") {
+		t.Fatalf("expected explicit --format to win, got %q", out)
+	}
+
+	if _, err := runCommand(t, &App{}, "--profile", "nope", "inbox"); err == nil {
+		t.Fatalf("expected unknown profile error")
+	}
+}
+
+func TestConfigDefaultsOnlyApplyToTaskLists(t *testing.T) {
+	setConfigHome(t)
+	t.Setenv("THINGSDB", "")
+	t.Setenv("THINGS_PROFILE", "")
+	dbPath := writeTestDB(t)
+	for _, args := range [][]string{
+		{"config", "set", "db", dbPath},
+		{"config", "set", "format", "jsonl"},
+		{"config", "set", "limit", "1"},
+	} {
+		if _, err := runCommand(t, &App{}, args...); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	out, err := runCommand(t, &App{}, "tasks", "--status", "any")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(out), "\n"); len(lines) != 1 || !strings.HasPrefix(lines[0], "{") {
+		t.Fatalf("expected one JSONL task from config defaults, got %q", out)
+	}
+
+	if _, err := runCommand(t, &App{}, "stats"); err != nil {
+		t.Fatalf("expected stats to ignore the config format, got %v", err)
+	}
+
+	seedActions(t,
+		ActionEntry{Type: ActionTrash, Items: []ActionItem{{UUID: "A", Title: "Alpha"}}},
+		ActionEntry{Type: ActionTrash, Items: []ActionItem{{UUID: "B", Title: "Beta"}}},
+	)
+	out, err = runCommand(t, &App{}, "history", "--no-header")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(out), "\n"); len(lines) != 2 {
+		t.Fatalf("expected history to ignore the config limit, got %q", out)
+	}
+}
@@ -35,7 +35,7 @@ func NewCreatedTodayCommand(app *App) *cobra.Command {
 			if err != nil {
 				return err
 			}
-			forcePost := opts.Query != "" || opts.Sort != "" || opts.Offset > 0
+			forcePost := opts.Sort != "" || opts.Offset > 0
 			tasks, err := fetchTasks(store, func(filter db.TaskFilter) ([]db.Task, error) {
 				return store.TasksCreatedBetween(start, now, filter)
 			}, opts, forcePost, []int{db.TaskTypeTodo})
@@ -81,7 +81,7 @@ func NewLogTodayCommand(app *App) *cobra.Command {
 			if err != nil {
 				return err
 			}
-			forcePost := opts.Query != "" || opts.Sort != "" || opts.Offset > 0
+			forcePost := opts.Sort != "" || opts.Offset > 0
 			tasks, err := fetchTasks(store, func(filter db.TaskFilter) ([]db.Task, error) {
 				return store.TasksCompletedBetween(start, end, filter)
 			}, opts, forcePost, []int{db.TaskTypeTodo})
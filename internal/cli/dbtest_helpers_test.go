@@ -1,62 +1,38 @@
 package cli
 
 import (
+	"bytes"
 	"database/sql"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
 
-func writeTestDB(t *testing.T) string {
+// runCommand executes args against a fresh root command and returns stdout.
+// In and Err default to empty stdin and a discarded buffer when unset.
+func runCommand(t testing.TB, app *App, args ...string) (string, error) {
 	t.Helper()
-	path := filepath.Join(t.TempDir(), "Things.sqlite3")
-	conn, err := sql.Open("sqlite", path)
-	if err != nil {
-		t.Fatalf("open db: %v", err)
-	}
-	defer conn.Close()
-
-	statements := []string{
-		`CREATE TABLE TMArea (uuid TEXT PRIMARY KEY, title TEXT, visible INTEGER, "index" INTEGER);`,
-		`CREATE TABLE TMTask (
-			uuid TEXT PRIMARY KEY,
-			type INTEGER,
-			status INTEGER,
-			trashed INTEGER,
-			title TEXT,
-			notes TEXT,
-			area TEXT,
-			project TEXT,
-			heading TEXT,
-			start INTEGER,
-			startDate INTEGER,
-			deadline INTEGER,
-			deadlineSuppressionDate INTEGER,
-			creationDate REAL,
-			userModificationDate REAL,
-			stopDate REAL,
-			"index" INTEGER,
-			rt1_recurrenceRule BLOB,
-			todayIndex INTEGER
-		);`,
-		`CREATE TABLE TMTag (uuid TEXT PRIMARY KEY, title TEXT, shortcut TEXT, parent TEXT);`,
-		`CREATE TABLE TMTaskTag (tasks TEXT NOT NULL, tags TEXT NOT NULL);`,
-		`CREATE TABLE TMChecklistItem (
-			uuid TEXT PRIMARY KEY,
-			userModificationDate REAL,
-			creationDate REAL,
-			title TEXT,
-			status INTEGER,
-			stopDate REAL,
-			"index" INTEGER,
-			task TEXT
-		);`,
+	out := &bytes.Buffer{}
+	if app.In == nil {
+		app.In = strings.NewReader("")
 	}
-	for _, stmt := range statements {
-		if _, err := conn.Exec(stmt); err != nil {
-			t.Fatalf("create schema: %v", err)
-		}
+	if app.Err == nil {
+		app.Err = &bytes.Buffer{}
 	}
+	app.Out = out
+	root := NewRoot(app)
+	root.SetArgs(args)
+	root.SetOut(app.Out)
+	root.SetErr(app.Err)
+	err := root.Execute()
+	return out.String(), err
+}
+
+func writeTestDB(t testing.TB) string {
+	t.Helper()
+	path, conn := createTestDB(t)
+	defer conn.Close()
 
 	if _, err := conn.Exec(`INSERT INTO TMArea (uuid, title, visible, "index") VALUES ('A1', 'Home', 1, 1);`); err != nil {
 		t.Fatalf("insert area: %v", err)
@@ -126,6 +102,66 @@ func writeTestDB(t *testing.T) string {
 	return path
 }
 
+// createTestDB creates an empty Things schema in a temporary directory.
+func createTestDB(t testing.TB) (string, *sql.DB) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "Things.sqlite3")
+	conn, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+
+	statements := []string{
+		`CREATE TABLE TMArea (uuid TEXT PRIMARY KEY, title TEXT, visible INTEGER, "index" INTEGER);`,
+		`CREATE TABLE TMTask (
+			uuid TEXT PRIMARY KEY,
+			type INTEGER,
+			status INTEGER,
+			trashed INTEGER,
+			title TEXT,
+			notes TEXT,
+			area TEXT,
+			project TEXT,
+			heading TEXT,
+			start INTEGER,
+			startDate INTEGER,
+			startBucket INTEGER,
+			deadline INTEGER,
+			deadlineSuppressionDate INTEGER,
+			creationDate REAL,
+			userModificationDate REAL,
+			stopDate REAL,
+			"index" INTEGER,
+			rt1_repeatingTemplate TEXT,
+			rt1_recurrenceRule BLOB,
+			rt1_instanceCreationStartDate INTEGER,
+			rt1_instanceCreationPaused INTEGER,
+			rt1_instanceCreationCount INTEGER,
+			rt1_afterCompletionReferenceDate INTEGER,
+			rt1_nextInstanceStartDate INTEGER,
+			todayIndex INTEGER
+		);`,
+		`CREATE TABLE TMTag (uuid TEXT PRIMARY KEY, title TEXT, shortcut TEXT, parent TEXT);`,
+		`CREATE TABLE TMTaskTag (tasks TEXT NOT NULL, tags TEXT NOT NULL);`,
+		`CREATE TABLE TMChecklistItem (
+			uuid TEXT PRIMARY KEY,
+			userModificationDate REAL,
+			creationDate REAL,
+			title TEXT,
+			status INTEGER,
+			stopDate REAL,
+			"index" INTEGER,
+			task TEXT
+		);`,
+	}
+	for _, stmt := range statements {
+		if _, err := conn.Exec(stmt); err != nil {
+			t.Fatalf("create schema: %v", err)
+		}
+	}
+	return path, conn
+}
+
 func thingsDate(t time.Time) int {
 	date := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
 	return date.Year()<<16 | int(date.Month())<<12 | date.Day()<<7
@@ -85,10 +85,7 @@ func NewDeleteCommand(app *App) *cobra.Command {
 
 			entry := ActionEntry{
 				Type:  ActionTrash,
-				Items: make([]ActionItem, 0, len(tasks)),
-			}
-			for _, task := range tasks {
-				entry.Items = append(entry.Items, taskToActionItem(task))
+				Items: actionItemsFromTasks(store, tasks),
 			}
 			if err := appendAction(entry); err != nil {
 				fmt.Fprintf(app.Err, "Warning: failed to write action log: %v\n", err)
@@ -0,0 +1,228 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ossianhempel/things3-cli/internal/backup"
+	"github.com/ossianhempel/things3-cli/internal/db"
+	"github.com/ossianhempel/things3-cli/internal/diff"
+	"github.com/spf13/cobra"
+)
+
+type diffReport struct {
+	From     string        `json:"from"`
+	To       string        `json:"to"`
+	Added    int           `json:"added"`
+	Removed  int           `json:"removed"`
+	Modified int           `json:"modified"`
+	Changes  []diff.Change `json:"changes"`
+}
+
+// NewDiffCommand builds the diff command.
+func NewDiffCommand(app *App) *cobra.Command {
+	var dbPath string
+	var from string
+	var to string
+	var format string
+	var asJSON bool
+	var noHeader bool
+
+	cmd := &cobra.Command{
+		Use:   "diff --from SOURCE [--to SOURCE] [OPTIONS...]",
+		Short: "Compare two databases or backup snapshots",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format = strings.ToLower(strings.TrimSpace(format))
+			if asJSON {
+				if format != "" && format != "json" {
+					return fmt.Errorf("Error: --json cannot be used with --format %s", format)
+				}
+				format = "json"
+			}
+			if format == "" {
+				format = "table"
+			}
+			switch format {
+			case "table", "json", "diff":
+			default:
+				return fmt.Errorf("Error: invalid format %q", format)
+			}
+			if strings.TrimSpace(from) == "" {
+				return fmt.Errorf("Error: --from is required")
+			}
+
+			before, err := loadDiffSnapshot(from, dbPath)
+			if err != nil {
+				return err
+			}
+			after, err := loadDiffSnapshot(to, dbPath)
+			if err != nil {
+				return err
+			}
+
+			report := diffReport{From: from, To: to, Changes: diff.Compare(before, after)}
+			for _, change := range report.Changes {
+				switch change.Change {
+				case diff.Added:
+					report.Added++
+				case diff.Removed:
+					report.Removed++
+				case diff.Modified:
+					report.Modified++
+				}
+			}
+			return printDiffReport(app.Out, report, format, noHeader)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVarP(&dbPath, "db", "d", "", "Path to the database used for \"current\" (overrides THINGSDB)")
+	flags.StringVar(&dbPath, "database", "", "Alias for --db")
+	flags.StringVar(&from, "from", "", "Older side: a backup archive, a database file, or \"current\" (./current for a file)")
+	flags.StringVar(&to, "to", "current", "Newer side: a backup archive, a database file, or \"current\" (./current for a file)")
+	flags.StringVar(&format, "format", "", "Output format: table, json, diff")
+	flags.BoolVarP(&asJSON, "json", "j", false, "Output JSON")
+	flags.BoolVar(&noHeader, "no-header", false, "Suppress header row")
+
+	return cmd
+}
+
+var sqliteHeader = []byte("SQLite format 3\x00")
+
+// loadDiffSnapshot reads "current" (the default database), a SQLite
+// database file, or a backup archive written by things backup. A file named
+// current is read as ./current.
+func loadDiffSnapshot(source, dbPath string) (diff.Snapshot, error) {
+	if source == "current" {
+		return readDiffDatabase(dbPath)
+	}
+	isDB, err := isSQLiteFile(source)
+	if err != nil {
+		return diff.Snapshot{}, fmt.Errorf("Error: read %s: %v", source, err)
+	}
+	if isDB {
+		return readDiffDatabase(source)
+	}
+	data, err := os.ReadFile(source)
+	if err != nil {
+		return diff.Snapshot{}, fmt.Errorf("Error: read %s: %v", source, err)
+	}
+	archive, err := backup.Load(data)
+	if err != nil {
+		return diff.Snapshot{}, fmt.Errorf("%v (%s)", err, source)
+	}
+	return diff.FromArchive(archive), nil
+}
+
+// isSQLiteFile reports whether path starts with the SQLite header, reading
+// only the header rather than the whole database.
+func isSQLiteFile(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	header := make([]byte, len(sqliteHeader))
+	if _, err := io.ReadFull(f, header); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return false, nil
+		}
+		return false, err
+	}
+	return bytes.Equal(header, sqliteHeader), nil
+}
+
+func readDiffDatabase(path string) (diff.Snapshot, error) {
+	store, _, err := db.OpenDefault(path)
+	if err != nil {
+		return diff.Snapshot{}, formatDBError(err)
+	}
+	defer store.Close()
+	// Read through the backup archive so databases and snapshots compare
+	// the same items.
+	archive, err := backup.Read(store, time.Now())
+	if err != nil {
+		return diff.Snapshot{}, formatDBError(err)
+	}
+	return diff.FromArchive(archive), nil
+}
+
+func printDiffReport(out io.Writer, report diffReport, format string, noHeader bool) error {
+	switch format {
+	case "json":
+		if report.Changes == nil {
+			report.Changes = []diff.Change{}
+		}
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	case "diff":
+		printUnifiedDiff(out, report)
+		return nil
+	}
+
+	if len(report.Changes) == 0 {
+		fmt.Fprintln(out, "No changes.")
+		return nil
+	}
+	w := tabwriter.NewWriter(out, 0, 2, 2, ' ', 0)
+	if !noHeader {
+		fmt.Fprintln(w, "CHANGE\tTYPE\tTITLE\tFIELD\tFROM\tTO")
+	}
+	for _, change := range report.Changes {
+		if change.Change != diff.Modified {
+			fmt.Fprintf(w, "%s\t%s\t%s\t\t\t\n", change.Change, change.Type, diffCell(change.Title))
+			continue
+		}
+		for _, field := range change.Fields {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", change.Change, change.Type, diffCell(change.Title), field.Field, diffCell(field.From), diffCell(field.To))
+		}
+	}
+	w.Flush()
+	if !noHeader {
+		fmt.Fprintf(out, "\n%d added, %d removed, %d modified\n", report.Added, report.Removed, report.Modified)
+	}
+	return nil
+}
+
+// diffCell keeps multi-line values on one table row and shortens long ones.
+func diffCell(value string) string {
+	value = strings.ReplaceAll(value, "\n", `\n`)
+	if runes := []rune(value); len(runes) > 40 {
+		value = string(runes[:39]) + "…"
+	}
+	return value
+}
+
+// printUnifiedDiff prints one hunk per changed item, with "-" lines for old
+// field values and "+" lines for new ones.
+func printUnifiedDiff(out io.Writer, report diffReport) {
+	fmt.Fprintf(out, "--- %s\n+++ %s\n", report.From, report.To)
+	for _, change := range report.Changes {
+		fmt.Fprintf(out, "@@ %s %s %q (%s) @@\n", change.Change, change.Type, change.Title, change.UUID)
+		for _, field := range change.Fields {
+			if change.Change != diff.Added {
+				printDiffLines(out, "-", field.Field, field.From)
+			}
+			if change.Change != diff.Removed {
+				printDiffLines(out, "+", field.Field, field.To)
+			}
+		}
+	}
+}
+
+func printDiffLines(out io.Writer, prefix, name, value string) {
+	lines := strings.Split(value, "\n")
+	fmt.Fprintf(out, "%s%s: %s\n", prefix, name, lines[0])
+	for _, line := range lines[1:] {
+		fmt.Fprintf(out, "%s  %s\n", prefix, line)
+	}
+}
@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDiffSnapshotAgainstDatabase(t *testing.T) {
+	dbPath := writeTestDB(t)
+	archivePath := filepath.Join(t.TempDir(), "backup.json")
+	app := &App{In: strings.NewReader(""), Out: &bytes.Buffer{}, Err: &bytes.Buffer{}}
+	root := NewRoot(app)
+	root.SetArgs([]string{"backup", "--db", dbPath, "-o", archivePath})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("backup failed: %v", err)
+	}
+
+	conn, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	for _, stmt := range []string{
+		`UPDATE TMTask SET notes = 'new notes' WHERE uuid = 'T1'`,
+		`UPDATE TMTask SET trashed = 1 WHERE uuid = 'INBOX1'`,
+	} {
+		if _, err := conn.Exec(stmt); err != nil {
+			t.Fatalf("update db: %v", err)
+		}
+	}
+	if _, err := conn.Exec(`INSERT INTO TMTask (uuid, type, status, trashed, title, start, creationDate) VALUES ('NEW1', 0, 0, 0, 'Fresh', 0, ?)`, float64(time.Now().Unix())); err != nil {
+		t.Fatalf("insert task: %v", err)
+	}
+	conn.Close()
+
+	runDiff := func(args ...string) string {
+		out := &bytes.Buffer{}
+		app := &App{In: strings.NewReader(""), Out: out, Err: &bytes.Buffer{}}
+		root := NewRoot(app)
+		root.SetArgs(append([]string{"diff", "--from", archivePath, "--db", dbPath}, args...))
+		if err := root.Execute(); err != nil {
+			t.Fatalf("diff failed: %v", err)
+		}
+		return out.String()
+	}
+
+	table := runDiff()
+	for _, want := range []string{"CHANGE", "added     to-do  Fresh", "removed   to-do  Inbox Task", "notes", "new notes", "1 added, 1 removed, 1 modified"} {
+		if !strings.Contains(table, want) {
+			t.Fatalf("expected %q in table output:\n%s", want, table)
+		}
+	}
+
+	var report struct {
+		Added    int `json:"added"`
+		Removed  int `json:"removed"`
+		Modified int `json:"modified"`
+		Changes  []struct {
+			Change string `json:"change"`
+			UUID   string `json:"uuid"`
+			Fields []struct {
+				Field string `json:"field"`
+				From  string `json:"from"`
+				To    string `json:"to"`
+			} `json:"fields"`
+		} `json:"changes"`
+	}
+	if err := json.Unmarshal([]byte(runDiff("--json")), &report); err != nil {
+		t.Fatalf("decode json: %v", err)
+	}
+	if report.Added != 1 || report.Removed != 1 || report.Modified != 1 || len(report.Changes) != 3 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	unified := runDiff("--format", "diff")
+	for _, want := range []string{"+++ current", `@@ modified to-do "Task One" (T1) @@`, "+notes: new notes", "+title: Fresh", "-title: Inbox Task"} {
+		if !strings.Contains(unified, want) {
+			t.Fatalf("expected %q in diff output:\n%s", want, unified)
+		}
+	}
+
+	if got := runDiff("--to", archivePath); got != "No changes.\n" {
+		t.Fatalf("unexpected output for identical snapshots: %q", got)
+	}
+}
+
+func TestDiffReadsFileNamedCurrent(t *testing.T) {
+	dbPath := writeTestDB(t)
+	data, err := os.ReadFile(dbPath)
+	if err != nil {
+		t.Fatalf("read db: %v", err)
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "current"), data, 0o644); err != nil {
+		t.Fatalf("write db copy: %v", err)
+	}
+	t.Chdir(dir)
+
+	out, err := runCommand(t, &App{}, "diff", "--from", "./current", "--db", dbPath)
+	if err != nil {
+		t.Fatalf("diff failed: %v", err)
+	}
+	if out != "No changes.\n" {
+		t.Fatalf("unexpected output: %q", out)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "short"), []byte("SQL"), 0o644); err != nil {
+		t.Fatalf("write short file: %v", err)
+	}
+	if _, err := runCommand(t, &App{}, "diff", "--from", "short", "--db", dbPath); err == nil {
+		t.Fatalf("expected error for a file that is neither a database nor an archive")
+	}
+}
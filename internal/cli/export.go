@@ -0,0 +1,20 @@
+package cli
+
+import "github.com/spf13/cobra"
+
+// NewExportCommand builds the export command and its format subcommands.
+func NewExportCommand(app *App) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export <ics|markdown|taskpaper> [OPTIONS...]",
+		Short: "Export tasks to other formats",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			printHelp(app.Out, formatHelpText(exportHelp, isTTY(app.Out)))
+			return ErrHelpPrinted
+		},
+	}
+
+	cmd.AddCommand(newExportICSCommand(app))
+	cmd.AddCommand(newExportMarkdownCommand(app))
+	cmd.AddCommand(newExportTaskPaperCommand(app))
+	return cmd
+}
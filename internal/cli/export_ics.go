@@ -0,0 +1,275 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ossianhempel/things3-cli/internal/db"
+	"github.com/ossianhempel/things3-cli/internal/repeat"
+	"github.com/spf13/cobra"
+)
+
+func newExportICSCommand(app *App) *cobra.Command {
+	var dbPath string
+	var output string
+	var kind string
+	var noRepeating bool
+	opts := TaskQueryOptions{
+		Status: "incomplete",
+	}
+
+	cmd := &cobra.Command{
+		Use:   "ics [OPTIONS...]",
+		Short: "Export scheduled and deadline tasks as iCalendar",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kind = strings.ToLower(strings.TrimSpace(kind))
+			switch kind {
+			case "auto", "todo", "event":
+			default:
+				return fmt.Errorf("Error: invalid --type %q (use auto, todo, or event)", kind)
+			}
+
+			store, _, err := db.OpenDefault(dbPath)
+			if err != nil {
+				return formatDBError(err)
+			}
+			defer store.Close()
+
+			opts.HasURLSet = cmd.Flags().Changed("has-url")
+			opts.IncludeRepeating = !noRepeating
+			tasks, err := fetchTasks(store, store.Tasks, opts, false, []int{db.TaskTypeTodo, db.TaskTypeProject})
+			if err != nil {
+				return formatDBError(err)
+			}
+
+			rules := map[string]db.RecurrenceRule{}
+			var repeatingIDs []string
+			for _, task := range tasks {
+				if task.Repeating {
+					repeatingIDs = append(repeatingIDs, task.UUID)
+				}
+			}
+			if len(repeatingIDs) > 0 {
+				rules, err = store.RecurrenceRules(repeatingIDs)
+				if err != nil {
+					return formatDBError(err)
+				}
+			}
+
+			components := buildICSComponents(app, tasks, rules, kind, time.Now())
+
+			out := app.Out
+			if output != "" && output != "-" {
+				file, err := os.Create(output)
+				if err != nil {
+					return fmt.Errorf("Error: %v", err)
+				}
+				defer file.Close()
+				out = file
+			}
+			return writeICS(out, components)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVarP(&dbPath, "db", "d", "", "Path to Things database (overrides THINGSDB)")
+	flags.StringVar(&dbPath, "database", "", "Alias for --db")
+	flags.StringVarP(&output, "output", "o", "", "Write to FILE instead of stdout")
+	flags.StringVar(&kind, "type", "auto", "Component type: auto, todo, or event")
+	flags.BoolVar(&noRepeating, "no-repeating", false, "Skip repeating templates (no RRULEs)")
+	addTaskQueryFlags(cmd, &opts, true, true)
+
+	return cmd
+}
+
+// icsComponent is a VTODO or VEVENT with its properties in output order.
+type icsComponent struct {
+	Name  string
+	Lines []string
+}
+
+func buildICSComponents(app *App, tasks []db.Task, rules map[string]db.RecurrenceRule, kind string, now time.Time) []icsComponent {
+	components := make([]icsComponent, 0, len(tasks))
+	for _, task := range tasks {
+		start := task.StartDate
+		due := task.Deadline
+		if isThingsSentinelDate(due) {
+			due = ""
+		}
+
+		var rrule string
+		if task.Repeating {
+			rule, ok := rules[task.UUID]
+			if !ok {
+				continue
+			}
+			spec, err := repeat.Parse(rule.Rule)
+			if err != nil {
+				if app != nil && app.Debug {
+					fmt.Fprintf(app.Err, "Skipping %s: %v\n", task.UUID, err)
+				}
+				continue
+			}
+			rrule = spec.RRule()
+			start = rule.NextStartDate
+			if start == "" && !spec.Anchor.IsZero() {
+				start = spec.Anchor.Format("2006-01-02")
+			}
+			due = ""
+		}
+		if start == "" && due == "" {
+			continue
+		}
+
+		name := "VEVENT"
+		switch kind {
+		case "todo":
+			name = "VTODO"
+		case "auto":
+			if due != "" && rrule == "" {
+				name = "VTODO"
+			}
+		}
+
+		lines := []string{
+			"UID:" + task.UUID,
+			"DTSTAMP:" + icsStamp(task, now),
+			"SUMMARY:" + icsEscape(task.Title),
+		}
+		if name == "VTODO" {
+			if start != "" {
+				lines = append(lines, "DTSTART;VALUE=DATE:"+icsDate(start))
+			}
+			if due != "" {
+				lines = append(lines, "DUE;VALUE=DATE:"+icsDate(due))
+			}
+			lines = append(lines, "STATUS:"+icsTodoStatus(task.Status))
+		} else {
+			day := start
+			if day == "" {
+				day = due
+			}
+			lines = append(lines, "DTSTART;VALUE=DATE:"+icsDate(day))
+			if next, err := time.Parse("2006-01-02", day); err == nil {
+				lines = append(lines, "DTEND;VALUE=DATE:"+next.AddDate(0, 0, 1).Format("20060102"))
+			}
+			lines = append(lines, "TRANSP:TRANSPARENT")
+		}
+		if rrule != "" {
+			lines = append(lines, "RRULE:"+rrule)
+		}
+		if categories := icsCategories(task); categories != "" {
+			lines = append(lines, "CATEGORIES:"+categories)
+		}
+		if task.Notes != "" {
+			lines = append(lines, "DESCRIPTION:"+icsEscape(task.Notes))
+		}
+		lines = append(lines, "URL:things:///show?id="+task.UUID)
+		components = append(components, icsComponent{Name: name, Lines: lines})
+	}
+	return components
+}
+
+func writeICS(out io.Writer, components []icsComponent) error {
+	var b strings.Builder
+	writeLine := func(line string) {
+		b.WriteString(icsFold(line))
+		b.WriteString("\r\n")
+	}
+	writeLine("BEGIN:VCALENDAR")
+	writeLine("VERSION:2.0")
+	writeLine("PRODID:-//things3-cli//Things Export//EN")
+	writeLine("CALSCALE:GREGORIAN")
+	writeLine("X-WR-CALNAME:Things")
+	for _, component := range components {
+		writeLine("BEGIN:" + component.Name)
+		for _, line := range component.Lines {
+			writeLine(line)
+		}
+		writeLine("END:" + component.Name)
+	}
+	writeLine("END:VCALENDAR")
+	_, err := io.WriteString(out, b.String())
+	return err
+}
+
+func icsCategories(task db.Task) string {
+	var parts []string
+	if task.ProjectTitle != "" {
+		parts = append(parts, icsEscape(task.ProjectTitle))
+	}
+	if task.AreaTitle != "" {
+		parts = append(parts, icsEscape(task.AreaTitle))
+	}
+	return strings.Join(parts, ",")
+}
+
+func icsTodoStatus(status int) string {
+	switch status {
+	case db.StatusCompleted:
+		return "COMPLETED"
+	case db.StatusCanceled:
+		return "CANCELLED"
+	default:
+		return "NEEDS-ACTION"
+	}
+}
+
+func icsStamp(task db.Task, now time.Time) string {
+	if task.Modified != "" {
+		if modified, err := time.ParseInLocation("2006-01-02 15:04:05", task.Modified, time.Local); err == nil {
+			return modified.UTC().Format("20060102T150405Z")
+		}
+	}
+	return now.UTC().Format("20060102T150405Z")
+}
+
+func icsDate(value string) string {
+	return strings.ReplaceAll(value, "-", "")
+}
+
+// isThingsSentinelDate reports dates Things uses as placeholders (year 4001)
+// on repeating templates.
+func isThingsSentinelDate(value string) bool {
+	return len(value) >= 4 && value[:4] >= "4000"
+}
+
+func icsEscape(value string) string {
+	replacer := strings.NewReplacer(
+		"\\", "\\\\",
+		";", "\\;",
+		",", "\\,",
+		"\r\n", "\\n",
+		"\n", "\\n",
+		"\r", "\\n",
+	)
+	return replacer.Replace(value)
+}
+
+// icsFold splits content lines longer than 75 octets without breaking UTF-8
+// sequences, as required by RFC 5545.
+func icsFold(line string) string {
+	const limit = 75
+	if len(line) <= limit {
+		return line
+	}
+	var b strings.Builder
+	width := limit
+	for len(line) > width {
+		cut := width
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		width = limit - 1
+	}
+	b.WriteString(line)
+	return b.String()
+}
@@ -0,0 +1,155 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ossianhempel/things3-cli/internal/db"
+	"github.com/ossianhempel/things3-cli/internal/repeat"
+)
+
+func TestExportICSCommand(t *testing.T) {
+	dbPath := writeTestDB(t)
+	out := &bytes.Buffer{}
+	app := &App{
+		In:  strings.NewReader(""),
+		Out: out,
+		Err: &bytes.Buffer{},
+	}
+
+	root := NewRoot(app)
+	root.SetArgs([]string{"export", "ics", "--db", dbPath})
+	root.SetOut(app.Out)
+	root.SetErr(app.Err)
+	if err := root.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	output := out.String()
+	if !strings.HasPrefix(output, "BEGIN:VCALENDAR\r\n") || !strings.HasSuffix(output, "END:VCALENDAR\r\n") {
+		t.Fatalf("expected calendar wrapper, got %q", output)
+	}
+	components := splitICSComponents(output)
+	if got := components["DL1"]; !strings.HasPrefix(got, "BEGIN:VTODO") || !strings.Contains(got, "DUE;VALUE=DATE:") {
+		t.Fatalf("expected deadline task as VTODO, got %q", got)
+	}
+	if got := components["TODAY1"]; !strings.HasPrefix(got, "BEGIN:VEVENT") || !strings.Contains(got, "DTEND;VALUE=DATE:") {
+		t.Fatalf("expected scheduled task as VEVENT, got %q", got)
+	}
+	if _, ok := components["UP1"]; !ok {
+		t.Fatalf("expected upcoming task in export")
+	}
+	for _, id := range []string{"INBOX1", "ANY1", "COMP1", "TRASH1"} {
+		if _, ok := components[id]; ok {
+			t.Fatalf("unexpected %s in export", id)
+		}
+	}
+}
+
+func TestExportICSCommandTypeAndOutput(t *testing.T) {
+	dbPath := writeTestDB(t)
+	path := filepath.Join(t.TempDir(), "things.ics")
+	app := &App{
+		In:  strings.NewReader(""),
+		Out: &bytes.Buffer{},
+		Err: &bytes.Buffer{},
+	}
+
+	root := NewRoot(app)
+	root.SetArgs([]string{"export", "ics", "--db", dbPath, "--type", "todo", "-o", path})
+	root.SetOut(app.Out)
+	root.SetErr(app.Err)
+	if err := root.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	if strings.Contains(string(data), "BEGIN:VEVENT") {
+		t.Fatalf("expected only VTODO entries, got %q", data)
+	}
+	if got := splitICSComponents(string(data))["TODAY1"]; !strings.Contains(got, "STATUS:NEEDS-ACTION") {
+		t.Fatalf("expected TODAY1 as VTODO, got %q", got)
+	}
+
+	root = NewRoot(app)
+	root.SetArgs([]string{"export", "ics", "--db", dbPath, "--type", "journal"})
+	root.SetOut(app.Out)
+	root.SetErr(app.Err)
+	if err := root.Execute(); err == nil {
+		t.Fatalf("expected error for invalid --type")
+	}
+}
+
+func TestBuildICSComponentsRepeating(t *testing.T) {
+	anchor := time.Date(2026, 1, 5, 0, 0, 0, 0, time.Local)
+	update, err := repeat.BuildUpdate(repeat.Spec{Mode: repeat.ModeSchedule, Unit: repeat.UnitWeek, Every: 1, Anchor: anchor})
+	if err != nil {
+		t.Fatalf("BuildUpdate failed: %v", err)
+	}
+	tasks := []db.Task{{
+		UUID:         "R1",
+		Title:        "Weekly review; notes, too",
+		Notes:        "line one\nline two",
+		Repeating:    true,
+		Deadline:     "4001-01-01",
+		ProjectTitle: "Admin",
+		AreaTitle:    "Work",
+	}}
+	rules := map[string]db.RecurrenceRule{"R1": {UUID: "R1", Rule: update.RecurrenceRule, NextStartDate: "2026-01-12"}}
+
+	components := buildICSComponents(nil, tasks, rules, "auto", anchor)
+	if len(components) != 1 {
+		t.Fatalf("expected 1 component, got %d", len(components))
+	}
+	got := strings.Join(components[0].Lines, "\n")
+	for _, want := range []string{
+		"SUMMARY:Weekly review\\; notes\\, too",
+		"DTSTART;VALUE=DATE:20260112",
+		"RRULE:FREQ=WEEKLY;BYDAY=MO",
+		"CATEGORIES:Admin,Work",
+		"DESCRIPTION:line one\\nline two",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in %q", want, got)
+		}
+	}
+	if components[0].Name != "VEVENT" || strings.Contains(got, "DUE") {
+		t.Fatalf("expected repeating template as VEVENT without DUE, got %s %q", components[0].Name, got)
+	}
+}
+
+func TestICSFold(t *testing.T) {
+	line := "DESCRIPTION:" + strings.Repeat("é", 60)
+	folded := icsFold(line)
+	for i, part := range strings.Split(folded, "\r\n") {
+		if len(part) > 75 {
+			t.Fatalf("line %d exceeds 75 octets: %d", i, len(part))
+		}
+		if i > 0 && !strings.HasPrefix(part, " ") {
+			t.Fatalf("continuation line %d missing leading space", i)
+		}
+	}
+	if unfolded := strings.ReplaceAll(folded, "\r\n ", ""); unfolded != line {
+		t.Fatalf("unfolded line mismatch")
+	}
+}
+
+// splitICSComponents maps UIDs to their BEGIN..END block.
+func splitICSComponents(output string) map[string]string {
+	components := map[string]string{}
+	for _, block := range strings.Split(output, "BEGIN:V")[1:] {
+		block = "BEGIN:V" + block
+		for _, line := range strings.Split(block, "\r\n") {
+			if uid, ok := strings.CutPrefix(line, "UID:"); ok {
+				components[uid] = block
+			}
+		}
+	}
+	return components
+}
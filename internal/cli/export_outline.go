@@ -0,0 +1,260 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ossianhempel/things3-cli/internal/db"
+	"github.com/spf13/cobra"
+)
+
+// outlineWriter renders an area/project/heading/todo tree in one text format.
+type outlineWriter func(out *bufio.Writer, items []db.TreeItem, details map[string]db.Task)
+
+func newExportMarkdownCommand(app *App) *cobra.Command {
+	return newExportOutlineCommand(app, "markdown", "Export areas, projects, and todos as Markdown", writeMarkdownOutline)
+}
+
+func newExportTaskPaperCommand(app *App) *cobra.Command {
+	return newExportOutlineCommand(app, "taskpaper", "Export areas, projects, and todos as TaskPaper", writeTaskPaperOutline)
+}
+
+func newExportOutlineCommand(app *App, name, short string, write outlineWriter) *cobra.Command {
+	var dbPath string
+	var output string
+	var area string
+	var project string
+	var exact bool
+	var includeCompleted bool
+
+	cmd := &cobra.Command{
+		Use:   name + " [OPTIONS...]",
+		Short: short,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if area != "" && project != "" {
+				return fmt.Errorf("Error: --area and --project cannot be combined")
+			}
+
+			store, _, err := db.OpenDefault(dbPath)
+			if err != nil {
+				return formatDBError(err)
+			}
+			defer store.Close()
+
+			filter := db.TaskFilter{ExcludeTrashedContext: true}
+			if !includeCompleted {
+				status := db.StatusIncomplete
+				filter.Status = &status
+			}
+			items, err := loadOutlineTree(store, filter, area, project, exact)
+			if err != nil {
+				return err
+			}
+
+			detailFilter := filter
+			detailFilter.Types = []int{db.TaskTypeTodo, db.TaskTypeProject}
+			detailFilter.IncludeChecklist = true
+			tasks, err := store.Tasks(detailFilter)
+			if err != nil {
+				return formatDBError(err)
+			}
+			details := make(map[string]db.Task, len(tasks))
+			for _, task := range tasks {
+				details[task.UUID] = task
+			}
+
+			out := app.Out
+			if output != "" && output != "-" {
+				file, err := os.Create(output)
+				if err != nil {
+					return fmt.Errorf("Error: %v", err)
+				}
+				defer file.Close()
+				out = file
+			}
+			w := bufio.NewWriter(out)
+			write(w, items, details)
+			return w.Flush()
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVarP(&dbPath, "db", "d", "", "Path to Things database (overrides THINGSDB)")
+	flags.StringVar(&dbPath, "database", "", "Alias for --db")
+	flags.StringVarP(&output, "output", "o", "", "Write to FILE instead of stdout")
+	flags.StringVar(&area, "area", "", "Only export this area (title or ID)")
+	flags.StringVar(&project, "project", "", "Only export this project (title or ID)")
+	flags.BoolVar(&exact, "exact", false, "Match area and project names exactly instead of fuzzily")
+	flags.BoolVar(&includeCompleted, "include-completed", false, "Include completed and canceled items")
+
+	return cmd
+}
+
+// loadOutlineTree returns projects without an area followed by every area,
+// or just the requested area or project.
+func loadOutlineTree(store *db.Store, filter db.TaskFilter, area, project string, exact bool) ([]db.TreeItem, error) {
+	if project != "" {
+		projectID, err := resolveNamed(store.ResolveProjectID, store.ResolveProjectIDFuzzy, exact, project)
+		if err != nil {
+			return nil, fmt.Errorf("Error: %s", err)
+		}
+		filter.ProjectID = projectID
+		items, err := store.ProjectsTree(filter, false)
+		if err != nil {
+			return nil, formatDBError(err)
+		}
+		return items, nil
+	}
+
+	// AreasTree applies AreaID to todos as well, and todos inside projects
+	// rarely carry an area, so pick the area out of the full tree instead.
+	areas, err := store.AreasTree(filter, false)
+	if err != nil {
+		return nil, formatDBError(err)
+	}
+	if area != "" {
+		areaID, err := resolveNamed(store.ResolveAreaID, store.ResolveAreaIDFuzzy, exact, area)
+		if err != nil {
+			return nil, fmt.Errorf("Error: %s", err)
+		}
+		for _, item := range areas {
+			if item.UUID == areaID {
+				return []db.TreeItem{item}, nil
+			}
+		}
+		return nil, nil
+	}
+
+	loose, err := store.ProjectsWithoutAreaTree(filter, false)
+	if err != nil {
+		return nil, formatDBError(err)
+	}
+	return append(loose, areas...), nil
+}
+
+func writeMarkdownOutline(out *bufio.Writer, items []db.TreeItem, details map[string]db.Task) {
+	for i, item := range items {
+		switch item.Type {
+		case "area":
+			fmt.Fprintf(out, "# %s\n\n", item.Title)
+			writeMarkdownOutline(out, todosFirst(item.Items), details)
+		case "project":
+			task := details[item.UUID]
+			fmt.Fprintf(out, "## %s%s\n\n", item.Title, outlineTags(task))
+			if notes := strings.TrimSpace(task.Notes); notes != "" {
+				fmt.Fprintf(out, "%s\n\n", notes)
+			}
+			writeMarkdownOutline(out, todosFirst(item.Items), details)
+		case "heading":
+			fmt.Fprintf(out, "### %s\n\n", item.Title)
+			writeMarkdownOutline(out, item.Items, details)
+		default:
+			task := details[item.UUID]
+			fmt.Fprintf(out, "- %s %s%s\n", markdownCheckbox(item.Status), item.Title, outlineTags(task))
+			writeOutlineNotes(out, task.Notes, "  ")
+			for _, check := range task.Checklist {
+				fmt.Fprintf(out, "  - %s %s\n", markdownCheckbox(&check.Status), check.Title)
+			}
+			if i == len(items)-1 || items[i+1].Type != item.Type {
+				fmt.Fprintln(out)
+			}
+		}
+	}
+}
+
+func writeTaskPaperOutline(out *bufio.Writer, items []db.TreeItem, details map[string]db.Task) {
+	writeTaskPaperItems(out, items, details, "")
+}
+
+func writeTaskPaperItems(out *bufio.Writer, items []db.TreeItem, details map[string]db.Task, indent string) {
+	for _, item := range items {
+		switch item.Type {
+		case "area", "heading":
+			fmt.Fprintf(out, "%s%s:\n", indent, item.Title)
+			writeTaskPaperItems(out, todosFirst(item.Items), details, indent+"\t")
+		case "project":
+			task := details[item.UUID]
+			fmt.Fprintf(out, "%s%s:%s\n", indent, item.Title, outlineTags(task))
+			writeOutlineNotes(out, task.Notes, indent+"\t")
+			writeTaskPaperItems(out, todosFirst(item.Items), details, indent+"\t")
+		default:
+			task := details[item.UUID]
+			fmt.Fprintf(out, "%s- %s%s\n", indent, item.Title, outlineTags(task))
+			writeOutlineNotes(out, task.Notes, indent+"\t")
+			for _, check := range task.Checklist {
+				done := ""
+				if check.Status == db.StatusCompleted {
+					done = " @done"
+				}
+				fmt.Fprintf(out, "%s\t- %s%s\n", indent, check.Title, done)
+			}
+		}
+	}
+}
+
+// outlineTags renders tags and dates as TaskPaper-style @tags, with a
+// leading space when there are any.
+func outlineTags(task db.Task) string {
+	var parts []string
+	for _, tag := range task.Tags {
+		parts = append(parts, "@"+strings.Join(strings.Fields(tag), "_"))
+	}
+	if task.StartDate != "" && task.Start != "Inbox" {
+		parts = append(parts, "@defer("+task.StartDate+")")
+	}
+	if task.Deadline != "" && !isThingsSentinelDate(task.Deadline) {
+		parts = append(parts, "@due("+task.Deadline+")")
+	}
+	stopped := task.StopDate
+	if len(stopped) > 10 {
+		stopped = stopped[:10]
+	}
+	switch task.Status {
+	case db.StatusCompleted:
+		parts = append(parts, "@done("+stopped+")")
+	case db.StatusCanceled:
+		parts = append(parts, "@canceled("+stopped+")")
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " " + strings.Join(parts, " ")
+}
+
+func writeOutlineNotes(out io.Writer, notes, indent string) {
+	notes = strings.TrimSpace(notes)
+	if notes == "" {
+		return
+	}
+	for _, line := range strings.Split(notes, "\n") {
+		fmt.Fprintln(out, strings.TrimRight(indent+line, " \t\r"))
+	}
+}
+
+func markdownCheckbox(status *int) string {
+	if status != nil && *status != db.StatusIncomplete {
+		return "[x]"
+	}
+	return "[ ]"
+}
+
+// todosFirst moves loose todos ahead of projects and headings so they are
+// not read as belonging to the section printed just before them.
+func todosFirst(items []db.TreeItem) []db.TreeItem {
+	ordered := make([]db.TreeItem, 0, len(items))
+	for _, item := range items {
+		if item.Type == "to-do" {
+			ordered = append(ordered, item)
+		}
+	}
+	for _, item := range items {
+		if item.Type != "to-do" {
+			ordered = append(ordered, item)
+		}
+	}
+	return ordered
+}
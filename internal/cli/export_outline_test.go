@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExportMarkdownCommand(t *testing.T) {
+	dbPath := writeTestDB(t)
+	out, err := runCommand(t, &App{}, "export", "markdown", "--db", dbPath)
+	if err != nil {
+		t.Fatalf("export markdown failed: %v", err)
+	}
+	want := "# Home\n\n## Project One\n\n### Heading\n\n- [ ] Task One @urgent\n  Some notes\n  - [ ] Check Item\n\n"
+	if out != want {
+		t.Fatalf("unexpected markdown:\n%s", out)
+	}
+}
+
+func TestExportTaskPaperCommandScopedToProject(t *testing.T) {
+	dbPath := writeTestDB(t)
+	out, err := runCommand(t, &App{}, "export", "taskpaper", "--db", dbPath, "--project", "proj one")
+	if err != nil {
+		t.Fatalf("export taskpaper failed: %v", err)
+	}
+	want := "Project One:\n\tHeading:\n\t\t- Task One @urgent\n\t\t\tSome notes\n\t\t\t- Check Item\n"
+	if out != want {
+		t.Fatalf("unexpected taskpaper:\n%q", out)
+	}
+
+	if _, err := runCommand(t, &App{}, "export", "taskpaper", "--db", dbPath, "--project", "P1", "--area", "Home"); err == nil {
+		t.Fatalf("expected --area with --project to fail")
+	}
+}
+
+func TestExportOutlineIncludeCompleted(t *testing.T) {
+	dbPath := writeTestDB(t)
+	conn, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	stop := time.Date(2026, 9, 14, 10, 0, 0, 0, time.Local)
+	if _, err := conn.Exec(`UPDATE TMTask SET status = 3, stopDate = ?, deadline = ? WHERE uuid = 'T1'`, float64(stop.Unix()), thingsDate(stop)); err != nil {
+		t.Fatalf("complete task: %v", err)
+	}
+	conn.Close()
+
+	out, err := runCommand(t, &App{}, "export", "markdown", "--db", dbPath, "--area", "home")
+	if err != nil {
+		t.Fatalf("export markdown failed: %v", err)
+	}
+	if strings.Contains(out, "Task One") {
+		t.Fatalf("expected completed todo to be skipped:\n%s", out)
+	}
+
+	out, err = runCommand(t, &App{}, "export", "markdown", "--db", dbPath, "--area", "home", "--include-completed")
+	if err != nil {
+		t.Fatalf("export markdown failed: %v", err)
+	}
+	if !strings.Contains(out, "- [x] Task One @urgent @due(2026-09-14) @done(2026-09-14)\n") {
+		t.Fatalf("expected completed todo with dates:\n%s", out)
+	}
+}
@@ -15,13 +15,29 @@ COMMANDS
   add            - add new todo
   update         - update exiting todo
   delete         - delete an existing todo
-  undo           - undo the last bulk action
+  undo           - undo logged actions
+  redo           - redo the last undone action
+  history        - list logged actions
   add-area       - add new area
   add-project    - add new project
   update-area    - update exiting area
   delete-area    - delete an area
   update-project - update exiting project
   delete-project - delete an existing project
+  import-json    - create or update items from a Things JSON payload
+  import         - create projects and todos from outlines and other apps
+  template       - create projects from YAML/JSON templates
+  serve          - serve a local HTTP/JSON API for the Things database
+  mcp            - run a Model Context Protocol server over stdio
+  tui            - browse and update tasks in an interactive terminal UI
+  review         - walk through active projects for a weekly review
+  stats          - summarize completed, created, and canceled todos
+  export         - export tasks as iCalendar, Markdown, or TaskPaper
+  backup         - write the whole library to a JSON archive
+  restore        - rebuild the library from a backup archive
+  diff           - compare two databases or backup snapshots
+  config         - read and edit the config file
+  query          - save and run named task queries (things @NAME)
   show           - show an area, project, tag, or todo from the Things database
   search         - search tasks in the Things database
   inbox          - list inbox tasks from the Things database
@@ -58,6 +74,10 @@ GLOBAL OPTIONS
   --dry-run
     Print the Things URL without opening it.
 
+  --profile=NAME
+    Use the named profile from config.toml. Defaults to THINGS_PROFILE, then
+    the profile set in the config file.
+
 AUTHOR
   Ossian Hempel
 
@@ -82,8 +102,9 @@ SYNOPSIS
   things auth
 
 DESCRIPTION
-  Prints whether {{BT}}THINGS_AUTH_TOKEN{{BT}} is set. If missing, prints setup
-  steps for the Things URL scheme authorization token.
+  Prints whether {{BT}}THINGS_AUTH_TOKEN{{BT}} or the {{BT}}auth-token{{BT}}
+  config setting is set. If missing, prints setup steps for the Things URL
+  scheme authorization token.
 
 NOTES
   Token setup:
@@ -115,7 +136,10 @@ OPTIONS
     Filter by status: incomplete, completed, canceled, any. Default: incomplete.
 
   --area=AREA
-    Filter by area title or ID.
+    Filter by area title or ID. Matched fuzzily unless {{BT}}--exact{{BT}} is set.
+
+  --exact
+    Match the area name exactly.
 
   --include-trashed
     Include trashed projects.
@@ -226,11 +250,17 @@ OPTIONS
   --tag=TAG
     Filter by tag title or ID.
 
+  --exact
+    Match project, area, and tag names exactly. By default names are matched
+    fuzzily (prefix, words, small typos) and an ambiguous name lists the
+    candidates.
+
   --search=TEXT
     Case-insensitive substring match on title or notes.
 
   --query=QUERY
-    Rich query with boolean ops, fields, and regex (e.g. title:/regex/ AND tag:work).
+    Rich query with boolean ops, fields, regex, and date comparisons
+    (e.g. tag:work AND deadline < +7d AND status:incomplete).
 
   --limit=N
     Limit number of results (0 = no limit). Default: 200.
@@ -316,11 +346,17 @@ OPTIONS
   --tag=TAG
     Filter by tag title or ID.
 
+  --exact
+    Match project, area, and tag names exactly. By default names are matched
+    fuzzily (prefix, words, small typos) and an ambiguous name lists the
+    candidates.
+
   --search=TEXT
     Case-insensitive substring match on title or notes.
 
   --query=QUERY
-    Rich query with boolean ops, fields, and regex (e.g. title:/regex/ AND tag:work).
+    Rich query with boolean ops, fields, regex, and date comparisons
+    (e.g. tag:work AND deadline < +7d AND status:incomplete).
 
   --limit=N
     Limit number of results (0 = no limit). Default: 200.
@@ -406,11 +442,17 @@ OPTIONS
   --tag=TAG
     Filter by tag title or ID.
 
+  --exact
+    Match project, area, and tag names exactly. By default names are matched
+    fuzzily (prefix, words, small typos) and an ambiguous name lists the
+    candidates.
+
   --search=TEXT
     Case-insensitive substring match on title or notes.
 
   --query=QUERY
-    Rich query with boolean ops, fields, and regex (e.g. title:/regex/ AND tag:work).
+    Rich query with boolean ops, fields, regex, and date comparisons
+    (e.g. tag:work AND deadline < +7d AND status:incomplete).
 
   --limit=N
     Limit number of results (0 = no limit). Default: 200.
@@ -496,11 +538,17 @@ OPTIONS
   --tag=TAG
     Filter by tag title or ID.
 
+  --exact
+    Match project, area, and tag names exactly. By default names are matched
+    fuzzily (prefix, words, small typos) and an ambiguous name lists the
+    candidates.
+
   --search=TEXT
     Case-insensitive substring match on title or notes.
 
   --query=QUERY
-    Rich query with boolean ops, fields, and regex (e.g. title:/regex/ AND tag:work).
+    Rich query with boolean ops, fields, regex, and date comparisons
+    (e.g. tag:work AND deadline < +7d AND status:incomplete).
 
   --limit=N
     Limit number of results (0 = no limit). Default: 200.
@@ -567,8 +615,14 @@ SYNOPSIS
   things repeating [OPTIONS...]
 
 DESCRIPTION
-  Lists repeating tasks using the local Things database (read-only). By default
-  only incomplete, non-trashed tasks are shown.
+  Lists repeating todos and projects using the local Things database
+  (read-only). By default only incomplete, non-trashed templates are shown.
+
+  The stored recurrence rule is decoded into the {{BT}}repeat_rule{{BT}} field
+  (e.g. "every 2 weeks on Monday (after completion)"), along with
+  {{BT}}repeat_next{{BT}} (next scheduled instance) and {{BT}}repeat_until{{BT}}
+  (end date). These fields can be selected in any list command with
+  {{BT}}--select{{BT}}.
 
 OPTIONS
   --db=PATH
@@ -586,11 +640,17 @@ OPTIONS
   --tag=TAG
     Filter by tag title or ID.
 
+  --exact
+    Match project, area, and tag names exactly. By default names are matched
+    fuzzily (prefix, words, small typos) and an ambiguous name lists the
+    candidates.
+
   --search=TEXT
     Case-insensitive substring match on title or notes.
 
   --query=QUERY
-    Rich query with boolean ops, fields, and regex (e.g. title:/regex/ AND tag:work).
+    Rich query with boolean ops, fields, regex, and date comparisons
+    (e.g. tag:work AND deadline < +7d AND status:incomplete).
 
   --limit=N
     Limit number of results (0 = no limit). Default: 200.
@@ -675,11 +735,17 @@ OPTIONS
   --tag=TAG
     Filter by tag title or ID.
 
+  --exact
+    Match project, area, and tag names exactly. By default names are matched
+    fuzzily (prefix, words, small typos) and an ambiguous name lists the
+    candidates.
+
   --search=TEXT
     Case-insensitive substring match on title or notes.
 
   --query=QUERY
-    Rich query with boolean ops, fields, and regex (e.g. title:/regex/ AND tag:work).
+    Rich query with boolean ops, fields, regex, and date comparisons
+    (e.g. tag:work AND deadline < +7d AND status:incomplete).
 
   --limit=N
     Limit number of results (0 = no limit). Default: 200.
@@ -765,11 +831,17 @@ OPTIONS
   --tag=TAG
     Filter by tag title or ID.
 
+  --exact
+    Match project, area, and tag names exactly. By default names are matched
+    fuzzily (prefix, words, small typos) and an ambiguous name lists the
+    candidates.
+
   --search=TEXT
     Case-insensitive substring match on title or notes.
 
   --query=QUERY
-    Rich query with boolean ops, fields, and regex (e.g. title:/regex/ AND tag:work).
+    Rich query with boolean ops, fields, regex, and date comparisons
+    (e.g. tag:work AND deadline < +7d AND status:incomplete).
 
   --limit=N
     Limit number of results (0 = no limit). Default: 200.
@@ -856,11 +928,17 @@ OPTIONS
   --tag=TAG
     Filter by tag title or ID.
 
+  --exact
+    Match project, area, and tag names exactly. By default names are matched
+    fuzzily (prefix, words, small typos) and an ambiguous name lists the
+    candidates.
+
   --search=TEXT
     Case-insensitive substring match on title or notes.
 
   --query=QUERY
-    Rich query with boolean ops, fields, and regex (e.g. title:/regex/ AND tag:work).
+    Rich query with boolean ops, fields, regex, and date comparisons
+    (e.g. tag:work AND deadline < +7d AND status:incomplete).
 
   --limit=N
     Limit number of results (0 = no limit). Default: 200.
@@ -946,11 +1024,17 @@ OPTIONS
   --tag=TAG
     Filter by tag title or ID.
 
+  --exact
+    Match project, area, and tag names exactly. By default names are matched
+    fuzzily (prefix, words, small typos) and an ambiguous name lists the
+    candidates.
+
   --search=TEXT
     Case-insensitive substring match on title or notes.
 
   --query=QUERY
-    Rich query with boolean ops, fields, and regex (e.g. title:/regex/ AND tag:work).
+    Rich query with boolean ops, fields, regex, and date comparisons
+    (e.g. tag:work AND deadline < +7d AND status:incomplete).
 
   --limit=N
     Limit number of results (0 = no limit). Default: 200.
@@ -1036,11 +1120,17 @@ OPTIONS
   --tag=TAG
     Filter by tag title or ID.
 
+  --exact
+    Match project, area, and tag names exactly. By default names are matched
+    fuzzily (prefix, words, small typos) and an ambiguous name lists the
+    candidates.
+
   --search=TEXT
     Case-insensitive substring match on title or notes.
 
   --query=QUERY
-    Rich query with boolean ops, fields, and regex (e.g. title:/regex/ AND tag:work).
+    Rich query with boolean ops, fields, regex, and date comparisons
+    (e.g. tag:work AND deadline < +7d AND status:incomplete).
 
   --limit=N
     Limit number of results (0 = no limit). Default: 200.
@@ -1126,11 +1216,17 @@ OPTIONS
   --tag=TAG
     Filter by tag title or ID.
 
+  --exact
+    Match project, area, and tag names exactly. By default names are matched
+    fuzzily (prefix, words, small typos) and an ambiguous name lists the
+    candidates.
+
   --search=TEXT
     Case-insensitive substring match on title or notes.
 
   --query=QUERY
-    Rich query with boolean ops, fields, and regex (e.g. title:/regex/ AND tag:work).
+    Rich query with boolean ops, fields, regex, and date comparisons
+    (e.g. tag:work AND deadline < +7d AND status:incomplete).
 
   --limit=N
     Limit number of results (0 = no limit). Default: 200.
@@ -1216,11 +1312,17 @@ OPTIONS
   --tag=TAG
     Filter by tag title or ID.
 
+  --exact
+    Match project, area, and tag names exactly. By default names are matched
+    fuzzily (prefix, words, small typos) and an ambiguous name lists the
+    candidates.
+
   --search=TEXT
     Case-insensitive substring match on title or notes.
 
   --query=QUERY
-    Rich query with boolean ops, fields, and regex (e.g. title:/regex/ AND tag:work).
+    Rich query with boolean ops, fields, regex, and date comparisons
+    (e.g. tag:work AND deadline < +7d AND status:incomplete).
 
   --limit=N
     Limit number of results (0 = no limit). Default: 200.
@@ -1306,11 +1408,17 @@ OPTIONS
   --tag=TAG
     Filter by tag title or ID.
 
+  --exact
+    Match project, area, and tag names exactly. By default names are matched
+    fuzzily (prefix, words, small typos) and an ambiguous name lists the
+    candidates.
+
   --search=TEXT
     Case-insensitive substring match on title or notes.
 
   --query=QUERY
-    Rich query with boolean ops, fields, and regex (e.g. title:/regex/ AND tag:work).
+    Rich query with boolean ops, fields, regex, and date comparisons
+    (e.g. tag:work AND deadline < +7d AND status:incomplete).
 
   --limit=N
     Limit number of results (0 = no limit). Default: 200.
@@ -1396,11 +1504,17 @@ OPTIONS
   --tag=TAG
     Filter by tag title or ID.
 
+  --exact
+    Match project, area, and tag names exactly. By default names are matched
+    fuzzily (prefix, words, small typos) and an ambiguous name lists the
+    candidates.
+
   --search=TEXT
     Case-insensitive substring match on title or notes.
 
   --query=QUERY
-    Rich query with boolean ops, fields, and regex (e.g. title:/regex/ AND tag:work).
+    Rich query with boolean ops, fields, regex, and date comparisons
+    (e.g. tag:work AND deadline < +7d AND status:incomplete).
 
   --limit=N
     Limit number of results (0 = no limit). Default: 200.
@@ -1513,10 +1627,13 @@ DESCRIPTION
   explicit title (no {{BT}}--titles{{BT}}, {{BT}}--use-clipboard{{BT}}, or
   quick entry).
 
+  When the Things database is readable, created todos are recorded so
+  {{BT}}things undo{{BT}} can move them to Trash.
+
 OPTIONS
   --db=PATH
     Path to the Things database. Overrides the THINGSDB environment variable.
-    Used for repeat operations.
+    Used for repeat operations and to record created todos for undo.
 
   --canceled, --cancelled
     Whether or not the todo should be set to canceled. Default: false. Takes
@@ -1557,6 +1674,8 @@ OPTIONS
 
   --list=LIST
     The title of a project or area to add to. Ignored if list-id is present.
+    A partial or misspelled title is resolved to the matching project or area
+    ID from the database; use --exact to send the title as given.
 
   --list-id=ID
     The ID of a project or area to add to. Takes precedence over list.
@@ -1591,6 +1710,11 @@ OPTIONS
   --repeat-until=DATE
     Stop repeating after the given date (YYYY-MM-DD). Optional.
 
+  --repeat-on=DAYS
+    Comma separated repeat days. Weekly rules take weekdays (mon,wed,fri);
+    monthly rules take days of the month (15, last-day) or nth weekdays
+    (2nd-tue, last-fri). Defaults to the anchor date's day.
+
   --repeat-deadline=DAYS
     Add repeating deadlines; each copy appears in Today DAYS earlier.
 
@@ -1678,7 +1802,14 @@ DESCRIPTION
   remaining lines are set as the todo's notes. Notes set this way take
   precedence over the {{BT}}--notes={{BT}} option.
 
+  When the Things database is readable, the created project is recorded so
+  {{BT}}things undo{{BT}} can move it to Trash.
+
 OPTIONS
+  --db=PATH
+    Path to the Things database. Overrides the THINGSDB environment variable.
+    Used for repeat operations and to record the created project for undo.
+
   --area-id=AREAID
     The ID of an area to add to. Takes precedence over area. Optional.
 
@@ -1737,6 +1868,11 @@ OPTIONS
   --repeat-until=DATE
     Stop repeating after the given date (YYYY-MM-DD). Optional.
 
+  --repeat-on=DAYS
+    Comma separated repeat days. Weekly rules take weekdays (mon,wed,fri);
+    monthly rules take days of the month (15, last-day) or nth weekdays
+    (2nd-tue, last-fri). Defaults to the anchor date's day.
+
   --repeat-deadline=DAYS
     Add repeating deadlines; each copy appears in Today DAYS earlier.
 
@@ -1804,7 +1940,23 @@ SYNOPSIS
 DESCRIPTION
   Searches tasks in the local Things database. The QUERY argument performs a
   case-insensitive substring search on title or notes. Use {{BT}}--query{{BT}}
-  for rich queries with boolean ops, fields, and regex.
+  for rich queries with boolean ops, fields, regex, and date comparisons:
+
+    deadline < 2026-11-01 AND status:completed
+    created >= -7d AND has:checklist
+    start:someday OR (start <= next-monday AND NOT evening:true)
+
+  Comparisons ({{BT}}<{{BT}}, {{BT}}<={{BT}}, {{BT}}>{{BT}}, {{BT}}>={{BT}}, {{BT}}={{BT}}) work on deadline, start,
+  created, modified, and stop, with YYYY-MM-DD or relative dates (today,
+  tomorrow, yesterday, -7d, +2w, +1m, next-monday, last-fri). Other predicates: status:, type:
+  (todo/project/heading), start: (inbox/anytime/someday), has: (checklist,
+  deadline, notes, tags, url), evening:, repeating:, and url:.
+
+  With {{BT}}--fts{{BT}}, QUERY is matched against a full-text index of titles,
+  notes, and checklist items. Every word must match (as a prefix), results
+  are ranked by relevance, and a SNIPPET column highlights the matches. The
+  index is kept in the user cache directory and refreshed from items modified
+  since the last search. Other filters still apply.
 
   Query is required.
 
@@ -1814,6 +1966,12 @@ OPTIONS
   --db=PATH
     Path to the Things database. Overrides the THINGSDB environment variable.
 
+  --fts
+    Ranked full-text search over titles, notes, and checklist items.
+
+  --reindex
+    Rebuild the full-text index before searching (with --fts).
+
   --status=STATUS
     Filter by status: incomplete, completed, canceled, any. Default: incomplete.
 
@@ -1826,8 +1984,14 @@ OPTIONS
   --tag=TAG
     Filter by tag title or ID.
 
+  --exact
+    Match project, area, and tag names exactly. By default names are matched
+    fuzzily (prefix, words, small typos) and an ambiguous name lists the
+    candidates.
+
   --query=QUERY
-    Rich query with boolean ops, fields, and regex (e.g. title:/regex/ AND tag:work).
+    Rich query with boolean ops, fields, regex, and date comparisons
+    (e.g. tag:work AND deadline < +7d AND status:incomplete).
 
   --limit=N
     Limit number of results (0 = no limit). Default: 200.
@@ -1887,6 +2051,8 @@ NOTES
 EXAMPLES
   things search "Work"
 
+  things search --fts "invoice march"
+
   echo "Home" | things search -
 `
 
@@ -2022,7 +2188,9 @@ OPTIONS
 
   --list=LIST
     The title of a project or area to move the todo into. Ignored if
-    {{BT}}--list-id={{BT}} is present.
+    {{BT}}--list-id={{BT}} is present. A partial or misspelled title is
+    resolved to the matching project or area ID; {{BT}}--exact{{BT}} sends
+    the title as given.
 
   --list-id=LISTID
     The ID of a project or area to move the todo into. Takes precedence
@@ -2057,6 +2225,11 @@ OPTIONS
   --repeat-until=DATE
     Stop repeating after the given date (YYYY-MM-DD). Optional.
 
+  --repeat-on=DAYS
+    Comma separated repeat days. Weekly rules take weekdays (mon,wed,fri);
+    monthly rules take days of the month (15, last-day) or nth weekdays
+    (2nd-tue, last-fri). Defaults to the anchor date's day.
+
   --repeat-deadline=DAYS
     Add repeating deadlines; each copy appears in Today DAYS earlier.
 
@@ -2135,23 +2308,109 @@ EXAMPLES
 const undoHelp = `Usage: things undo [OPTIONS...]
 
 NAME
-  things undo - undo the last bulk action
+  things undo - undo logged actions
 
 SYNOPSIS
   things undo [OPTIONS...]
 
 DESCRIPTION
-  Replays the last bulk update or trash action recorded by things3-cli.
-  Undoing updates requires a Things URL scheme token. Undoing trash recreates
-  tasks as new items.
+  Reverts actions recorded by things3-cli (see {{BT}}things history{{BT}}). By
+  default the most recent action that has not been undone is reverted.
+
+  Undoing updates requires a Things URL scheme token and restores notes,
+  tags, dates, placement, and checklists as recorded. Undoing trash moves the
+  original items out of Trash and back to their list, project, or area using
+  AppleScript; headings are restored when a token is available. Undoing
+  {{BT}}add{{BT}} or {{BT}}add-project{{BT}} moves the created items to Trash.
+
+  Undone entries stay in the history so they can be reapplied with
+  {{BT}}things redo{{BT}}.
 
 OPTIONS
   --auth-token=TOKEN
     The Things URL scheme authorization token. If not provided, uses
     THINGS_AUTH_TOKEN.
 
+  --db=PATH
+    Path to the Things database, used to record the current state for redo
+    and to compare checklists.
+
+  --id=ID
+    Undo the action with this history ID.
+
+  --steps=N
+    Undo the last N actions that have not been undone. Default: 1.
+
   --yes
     Confirm undo for multiple tasks.
+
+EXAMPLES
+  things undo
+
+  things undo --steps 3 --yes
+
+  things undo --id 12
+`
+
+const redoHelp = `Usage: things redo [OPTIONS...]
+
+NAME
+  things redo - redo the last undone action
+
+SYNOPSIS
+  things redo [OPTIONS...]
+
+DESCRIPTION
+  Reapplies an action that was reverted with {{BT}}things undo{{BT}}. By
+  default the most recently undone action is redone. Updates are reapplied
+  from the task state recorded at undo time and require a Things URL scheme
+  token; trashes move the items to Trash again; creates restore the items
+  from Trash.
+
+  Any new logged action clears the undone actions from history, so they can
+  no longer be redone.
+
+OPTIONS
+  --auth-token=TOKEN
+    The Things URL scheme authorization token. If not provided, uses
+    THINGS_AUTH_TOKEN.
+
+  --db=PATH
+    Path to the Things database, used to compare checklists.
+
+  --id=ID
+    Redo the action with this history ID.
+
+  --yes
+    Confirm redo for multiple tasks.
+`
+
+const historyHelp = `Usage: things history [OPTIONS...]
+
+NAME
+  things history - list logged actions
+
+SYNOPSIS
+  things history [OPTIONS...]
+
+DESCRIPTION
+  Lists actions recorded by things3-cli, newest first, with their ID,
+  timestamp, type, item count, and whether they have been undone. IDs can be
+  passed to {{BT}}things undo --id{{BT}} and {{BT}}things redo --id{{BT}}.
+
+  The log is kept in the user config directory as actions.jsonl. Once it
+  reaches 1 MiB it is rotated to actions.1.jsonl, replacing any older
+  rotation.
+
+OPTIONS
+  --limit=N
+    Limit number of entries. Default: 20. Use 0 for all.
+
+  --json
+    Output JSON.
+
+  --no-header
+    Suppress header row.
 `
 
 const updateAreaHelp = `Usage: things update-area [OPTIONS...] [--] [-|TITLE]
@@ -2367,6 +2626,37 @@ OPTIONS
     Title of a todo to add to the project. Can be specified more than once
     to add multiple todos. Optional.
 
+  --repeat=UNIT
+    Set a repeating schedule. Units: day, week, month, year. The project's
+    headings and todos stay part of the template.
+
+  --repeat-mode=MODE
+    Repeat mode: after-completion (default) or schedule.
+
+  --repeat-every=N
+    Repeat every N units. Default: 1.
+
+  --repeat-start=DATE
+    Anchor date for the repeat rule (YYYY-MM-DD). Defaults to today.
+
+  --repeat-until=DATE
+    Stop repeating after the given date (YYYY-MM-DD). Optional.
+
+  --repeat-on=DAYS
+    Comma separated repeat days. Weekly rules take weekdays (mon,wed,fri);
+    monthly rules take days of the month (15, last-day) or nth weekdays
+    (2nd-tue, last-fri). Defaults to the anchor date's day.
+
+  --repeat-deadline=DAYS
+    Add repeating deadlines; each copy appears in Today DAYS earlier.
+
+  --repeat-clear
+    Remove the repeating schedule for the project.
+
+  --db=PATH
+    Path to the Things database used for repeat updates. Overrides the
+    THINGSDB environment variable.
+
 EXAMPLES
   things update-project --id=8TN1bbz946oBsRBGiQ2XBN "The new project title"
 
@@ -2387,6 +2677,772 @@ EXAMPLES
   things update --id=8TN1bbz946oBsRBGiQ2XBN --reveal
     "Ship this project"
 
+  things update-project --id=8TN1bbz946oBsRBGiQ2XBN --repeat=month
+    --repeat-on=1 --repeat-mode=schedule
+
 SEE ALSO
   Authorization: https://culturedcode.com/things/support/articles/2803573/#overview-authorization
 `
+
+const importJSONHelp = `Usage: things import-json [OPTIONS...] [--] [-|FILE]
+
+NAME
+  things import-json - create or update items from a Things JSON payload
+
+SYNOPSIS
+  things import-json [OPTIONS...] [--] [-|FILE]
+
+DESCRIPTION
+  Sends a payload to the Things {{BT}}json{{BT}} URL command, which can create
+  projects with headings, todos with checklist items, and update existing
+  items in one batch.
+
+  The payload is read from FILE, or from STDIN when FILE is {{BT}}-{{BT}} or
+  omitted. It is an array of objects (or a single object) in the format
+  documented by Things:
+
+    [{"type": "project", "attributes": {"title": "Trip", "items": [
+      {"type": "heading", "attributes": {"title": "Before"}},
+      {"type": "to-do", "attributes": {"title": "Pack", "checklist-items": [
+        {"type": "checklist-item", "attributes": {"title": "Passport"}}]}}]}}]
+
+  Supported item types are {{BT}}to-do{{BT}} and {{BT}}project{{BT}} at the top
+  level, {{BT}}to-do{{BT}} and {{BT}}heading{{BT}} inside project items, and
+  {{BT}}checklist-item{{BT}} inside to-do checklist items. The payload is
+  validated before anything is sent to Things.
+
+  Use {{BT}}--dry-run{{BT}} to print the URL without opening it.
+
+AUTHORIZATION
+  Payloads containing {{BT}}"operation": "update"{{BT}} require a Things URL
+  scheme token. Run {{BT}}things auth{{BT}} for setup, set
+  {{BT}}THINGS_AUTH_TOKEN{{BT}}, or pass {{BT}}--auth-token{{BT}}.
+
+OPTIONS
+  --auth-token=TOKEN
+    The Things URL scheme authorization token. Only required when the
+    payload contains updates. If not provided, uses THINGS_AUTH_TOKEN.
+
+  --reveal
+    Whether or not to navigate to and show the first created item. Default:
+    false. Optional.
+
+EXAMPLES
+  things import-json plan.json
+
+  cat plan.json | things import-json --reveal -
+
+  echo '[{"type":"to-do","operation":"update","id":"ABC123","attributes":{"completed":true}}]' |
+    things import-json -
+
+SEE ALSO
+  https://culturedcode.com/things/support/articles/2803573/#json
+`
+
+const importHelp = `Usage: things import <taskpaper|markdown|todoist|omnifocus> [OPTIONS...] [--] [-|FILE...]
+
+NAME
+  things import - create projects and todos from outlines and other apps
+
+SYNOPSIS
+  things import taskpaper [OPTIONS...] [--] [-|FILE]
+  things import markdown [OPTIONS...] [--] [-|FILE]
+  things import todoist [OPTIONS...] [--] FILE...
+  things import omnifocus [OPTIONS...] [--] [-|FILE]
+
+DESCRIPTION
+  Parses an outline or export and creates its projects, headings, todos, and
+  checklist items in one batch through the Things {{BT}}json{{BT}} URL command.
+  The input is read from FILE, or from STDIN when FILE is {{BT}}-{{BT}} or
+  omitted.
+
+  TaskPaper: lines ending in ":" are projects at the top level and headings
+  inside a project, "- " lines are todos, "- " lines indented under a todo
+  are its checklist items, and other indented lines are notes.
+
+  Markdown: headings nest by level, "Title:" lines start a section one level
+  below the enclosing heading, list items (with optional [ ] or [x]) are
+  todos or, when nested, checklist items, text indented under a list item
+  is its notes, and paragraphs under a heading are project notes.
+
+  In both formats a top-level section is an area rather than a project when
+  an area with that title exists in the database, or when it nests sections
+  two levels deep (area, project, heading). In Markdown, a "#" heading that
+  holds "##" headings is also an area, as {{BT}}things export markdown{{BT}}
+  writes areas and their projects. Areas are not created; projects and todos are filed into existing ones.
+
+  Tags are written as @tag. @due(DATE) sets the deadline, @defer(DATE) or
+  @start(DATE) sets when, and @done or @canceled mark items closed. Values of
+  other tags are dropped.
+
+  Todoist: each CSV export (one per project) becomes a project named after
+  the file. Sections become headings, labels become tags, priorities 1-3
+  become the tags p1-p3, DATE becomes the deadline (or when, if the task
+  also has a DEADLINE), notes are appended to the task above them, and
+  subtasks become checklist items. Recurring dates such as "every 2 weeks",
+  "every mon, fri", "every 15th", or "every! 3 days" become repeating rules.
+
+  OmniFocus: accepts the CSV written by File > Export or TaskPaper copied
+  from OmniFocus. Folders become areas, projects become projects, actions
+  become todos, action groups become todos with checklist items, contexts,
+  tags, and @flagged become tags, on-hold projects go to Someday, and repeat
+  rules become repeating rules.
+
+  For todoist and omnifocus, repeating rules are applied once Things has
+  created the items, as {{BT}}things add --repeat{{BT}} does. Anything that
+  cannot be mapped (times of day, assignees, durations, sequential projects,
+  unsupported recurrences, tags missing from Things) is listed on STDERR
+  under "Not imported".
+
+  Use {{BT}}--dry-run{{BT}} to print the planned tree, repeats, and the URL
+  without opening it.
+
+OPTIONS
+  --db=PATH
+    Path to the Things database used to recognize existing areas, check
+    tags, and apply repeating rules. Overrides the THINGSDB environment
+    variable. Optional.
+
+  --project=TITLE
+    todoist only: title of the created project. Defaults to the CSV file
+    name. Requires a single FILE. Optional.
+
+  --reveal
+    Whether or not to navigate to and show the first created item. Default:
+    false. Optional.
+
+EXAMPLES
+  things import taskpaper plan.taskpaper
+
+  things --dry-run import markdown snapshot.md
+
+  pbpaste | things import taskpaper -
+
+  things import todoist Groceries.csv Work.csv
+
+  things --dry-run import omnifocus OmniFocus.csv
+`
+
+const templateHelp = `Usage: things template <apply|list|show> [OPTIONS...] [ARGS...]
+
+NAME
+  things template - create projects from YAML/JSON templates
+
+SYNOPSIS
+  things template apply [OPTIONS...] <NAME|FILE>
+  things template list [--json] [--no-header]
+  things template show <NAME|FILE>
+
+DESCRIPTION
+  Templates describe a project (with headings, todos, checklist items, and
+  tags) and/or loose todos. {{BT}}apply{{BT}} renders the template and creates
+  everything in one batch through the Things JSON URL command.
+
+  Templates are looked up by file path first, then by name in the templates
+  directory ({{BT}}things3-cli/templates{{BT}} under the user config directory,
+  e.g. ~/Library/Application Support/things3-cli/templates on macOS). Files
+  may be YAML ({{BT}}.yaml{{BT}}, {{BT}}.yml{{BT}}) or JSON ({{BT}}.json{{BT}}).
+
+  Any string may reference variables as {{BT}}{{ name }}{{BT}}. Values come from
+  {{BT}}--var{{BT}} flags, falling back to the template's {{BT}}vars{{BT}} section.
+  Applying a template with undefined variables fails.
+
+  The {{BT}}when{{BT}} and {{BT}}deadline{{BT}} fields accept relative dates such as
+  {{BT}}+3d{{BT}}, {{BT}}-1d{{BT}}, {{BT}}+2w{{BT}}, {{BT}}+1m{{BT}}, or {{BT}}+1y{{BT}}, resolved against
+  today (or {{BT}}--base{{BT}}). Other values are passed to Things unchanged.
+
+TEMPLATE FORMAT
+  name: release
+  description: Sprint release checklist
+  vars:
+    owner: qa
+  project:
+    title: "Release {{ version }}"
+    area: Work
+    deadline: "{{ date }}"
+    tags: [release]
+    todos:
+      - title: Write release notes
+    headings:
+      - title: QA
+        todos:
+          - title: "Smoke test {{ version }}"
+            when: +3d
+            checklist: [iOS, macOS]
+  todos:
+    - title: "Announce {{ version }}"
+      list: Inbox
+
+OPTIONS (apply)
+  --var=NAME=VALUE
+    Set a template variable. Repeatable.
+
+  --base=YYYY-MM-DD
+    Base date for relative dates. Default: today.
+
+  --reveal
+    Whether or not to navigate to and show the first created item.
+
+OPTIONS (list)
+  --json
+    Output JSON.
+
+  --no-header
+    Suppress the header row.
+
+EXAMPLES
+  things template apply release --var version=1.4 --var date=2026-11-01
+
+  things template apply ./release.yaml --var version=1.4 --dry-run
+
+  things template list
+
+  things template show release
+`
+
+const configHelp = `Usage: things config <get|set|list> [ARGS...]
+
+NAME
+  things config - read and edit the config file
+
+SYNOPSIS
+  things config get KEY
+  things config set KEY [VALUE]
+  things config list [--show-token]
+
+DESCRIPTION
+  Settings are stored in {{BT}}config.toml{{BT}} under the things3-cli directory
+  in the user config directory (e.g.
+  ~/Library/Application Support/things3-cli/config.toml on macOS). The file
+  is read at startup; flags and environment variables still take precedence.
+
+  {{BT}}set{{BT}} without a VALUE clears the key. {{BT}}list{{BT}} masks auth
+  tokens unless {{BT}}--show-token{{BT}} is given.
+
+KEYS
+  db            Default database path (used when THINGSDB is unset).
+  auth-token    Things URL scheme token (used when THINGS_AUTH_TOKEN is
+                unset).
+  format        Default output format for task list commands: table, json,
+                jsonl, or csv.
+  limit         Default {{BT}}--limit{{BT}} for task list commands.
+  foreground    Open Things in the foreground (true or false).
+  profile       Profile to use when {{BT}}--profile{{BT}} is not given.
+
+  Each setting can also be set per profile as
+  {{BT}}profiles.NAME.SETTING{{BT}}. A selected profile overrides the
+  top-level settings, and a database from {{BT}}--profile{{BT}} or
+  THINGS_PROFILE takes precedence over THINGSDB.
+
+CONFIG FORMAT
+  db = "~/Things/main.sqlite"
+  format = "table"
+  limit = 50
+
+  [profiles.fixture]
+  db = "~/src/things3-cli/integration/fixtures/main.sqlite"
+  format = "json"
+
+EXAMPLES
+  things config set profiles.fixture.db ./integration/fixtures/main.sqlite
+
+  things --profile fixture today
+
+  things config get limit
+
+  things config list
+`
+
+const queryHelp = `Usage: things query <save|run|list|edit|delete> [OPTIONS...] [NAME]
+
+NAME
+  things query - save and run named task queries
+
+SYNOPSIS
+  things query save NAME [QUERY OPTIONS...] [OUTPUT OPTIONS...] [--force]
+  things query run NAME [OUTPUT OPTIONS...]
+  things @NAME [OUTPUT OPTIONS...]
+  things query list [--json] [--no-header]
+  things query edit NAME [QUERY OPTIONS...] [OUTPUT OPTIONS...]
+  things query delete NAME
+
+DESCRIPTION
+  Saved queries store the filters of {{BT}}things tasks{{BT}} (status,
+  project, area, tag, search, rich {{BT}}--query{{BT}}, dates, sort, limit)
+  together with an output selection ({{BT}}--format{{BT}}, {{BT}}--select{{BT}},
+  {{BT}}--json{{BT}}, {{BT}}--no-header{{BT}}). They are kept in
+  {{BT}}queries.json{{BT}} in the things3-cli config directory.
+
+  {{BT}}run{{BT}} and the {{BT}}@NAME{{BT}} shortcut execute a query against the
+  database. Output flags given at run time override the saved ones, and
+  {{BT}}--limit{{BT}} overrides the saved limit.
+
+  {{BT}}edit{{BT}} changes only the options passed and keeps the rest.
+
+  Names may contain letters, digits, {{BT}}-{{BT}}, and {{BT}}_{{BT}}.
+
+OPTIONS (run)
+  --db=PATH
+    Path to the Things database. Overrides the THINGSDB environment variable.
+
+  --limit=N
+    Limit number of results. Default: the saved limit, else 200.
+
+  --format=FORMAT, --select=FIELDS, --json, --no-header
+    Override the saved output selection.
+
+OPTIONS (save)
+  --force
+    Overwrite an existing query.
+
+  All filter and output options of {{BT}}things tasks{{BT}} are accepted.
+
+EXAMPLES
+  things query save work --query 'tag:work AND NOT project:/archive/i' --sort deadline
+
+  things @work
+
+  things query edit work --select uuid,title,deadline
+
+  things query list
+`
+
+const serveHelp = `Usage: things serve [OPTIONS...]
+
+NAME
+  things serve - serve a local HTTP/JSON API for the Things database
+
+SYNOPSIS
+  things serve [OPTIONS...]
+
+DESCRIPTION
+  Starts an HTTP server that keeps one read-only connection to the Things
+  database open and answers JSON requests. Write endpoints go through the
+  Things URL scheme and AppleScript, exactly like the CLI commands, and
+  respect {{BT}}--dry-run{{BT}} and {{BT}}--foreground{{BT}}.
+
+  Keep the server bound to a loopback address. Requests whose Host or Origin
+  header is not a loopback address are rejected. Write endpoints also
+  require {{BT}}Content-Type: application/json{{BT}} and the per-server token in
+  the {{BT}}X-Things-Token{{BT}} header; the token is printed at startup.
+
+ENDPOINTS
+  GET    /tasks              List todos (same filters as {{BT}}things tasks{{BT}})
+  GET    /today              List Today todos
+  GET    /search?q=TEXT      Search titles and notes
+  GET    /tasks/{id}         Show a single todo
+  GET    /projects           List projects (status, area)
+  GET    /projects/{id}      Show a project with its todos
+  GET    /areas              List areas
+  GET    /tags               List tags
+  POST   /tasks              Add a todo
+  PATCH  /tasks/{id}         Update a todo (requires auth token)
+  POST   /tasks/{id}/complete  Complete a todo (requires auth token)
+  DELETE /tasks/{id}         Move a todo to the Trash
+
+  Task list endpoints accept the task filter flags as query parameters:
+  status, project, area, tag, search, query, limit, offset,
+  include-trashed, all, recursive, created-before, created-after,
+  modified-before, modified-after, due-before, start-before, has-url, sort.
+
+  Write endpoints accept a JSON body with title, notes, append_notes, when,
+  deadline, tags, add_tags, checklist, list, list_id, heading, completed,
+  and canceled. They respond with 202 and the URL or script that was run.
+  Errors are returned as {{BT}}{"error": "..."}{{BT}}.
+
+AUTHORIZATION
+  Write endpoints require the server token in the {{BT}}X-Things-Token{{BT}}
+  header. Update endpoints also read the Things token from THINGS_AUTH_TOKEN.
+
+OPTIONS
+  --addr=HOST:PORT
+    Address to listen on. Default: 127.0.0.1:7878.
+
+  --token=TOKEN
+    Token write requests must send in {{BT}}X-Things-Token{{BT}}. Default: a
+    random token generated at startup.
+
+  --db=PATH
+    Path to the Things database. Overrides the THINGSDB environment variable.
+
+EXAMPLES
+  things serve --addr 127.0.0.1:7878
+
+  curl 'http://127.0.0.1:7878/tasks?project=Work&sort=deadline'
+
+  curl -X POST -H 'Content-Type: application/json' -H "X-Things-Token: $TOKEN" \
+    -d '{"title":"Call Sam","when":"today"}' http://127.0.0.1:7878/tasks
+`
+
+const mcpHelp = `Usage: things mcp [OPTIONS...]
+
+NAME
+  things mcp - run a Model Context Protocol server over stdio
+
+SYNOPSIS
+  things mcp [OPTIONS...]
+
+DESCRIPTION
+  Speaks MCP (JSON-RPC 2.0, one message per line) on STDIN/STDOUT so that
+  assistants can read and manage Things. Diagnostics go to STDERR.
+
+  The database is opened on the first read and kept open for the session.
+
+TOOLS
+  list_today     List incomplete todos in Today (optional rich query).
+  search_tasks   Search todos with the rich query syntax used by
+                 {{BT}}--query{{BT}} (fields, regex, AND/OR/NOT).
+  show_item      Show a todo, project, heading, area, or tag by ID.
+  add_todo       Create a todo.
+  update_todo    Update a todo (requires THINGS_AUTH_TOKEN).
+  complete_todo  Complete a todo (requires THINGS_AUTH_TOKEN).
+
+  Write tools accept {{BT}}"dry_run": true{{BT}} to return the generated
+  {{BT}}things:///{{BT}} URL without opening it. Running with the global
+  {{BT}}--dry-run{{BT}} flag makes every write a dry run.
+
+OPTIONS
+  --db=PATH
+    Path to the Things database. Overrides the THINGSDB environment variable.
+
+EXAMPLES
+  MCP client configuration:
+
+    {"mcpServers": {"things": {"command": "things", "args": ["mcp"]}}}
+`
+
+const tuiHelp = `Usage: things tui [OPTIONS...]
+
+NAME
+  things tui - browse and update tasks in an interactive terminal UI
+
+SYNOPSIS
+  things tui [OPTIONS...]
+
+DESCRIPTION
+  Opens a full-screen, keyboard-driven view of the Things database. The
+  sidebar lists Inbox, Today, Upcoming, Anytime, Someday, and Logbook,
+  followed by projects grouped by area. The middle pane lists the todos in
+  the selected list and the right pane shows the selected todo's details,
+  notes, and checklist.
+
+  Complete, move, schedule, and tag go through the {{BT}}things:///update{{BT}}
+  URL scheme (requires an auth token, see {{BT}}things auth{{BT}}), and the view
+  reloads from the database once Things has saved the change. Actions are
+  logged so {{BT}}things undo{{BT}} can revert them. With the global
+  {{BT}}--dry-run{{BT}} flag the URL is shown in the status line instead.
+
+KEYS
+  j, k, arrows  Move within the focused pane
+  h, l, tab     Switch between the sidebar and the todo list
+  /             Filter with a rich query (same syntax as {{BT}}--query{{BT}});
+                esc returns to the selected list
+  c             Complete the selected todo
+  m             Move the selected todo to a project or area (fuzzy title)
+  s             Schedule the selected todo (today, tomorrow, evening,
+                someday, anytime, or a date)
+  t             Add comma-separated tags to the selected todo
+  r             Reload from the database
+  q, ctrl+c     Quit
+
+OPTIONS
+  --db=PATH
+    Path to the Things database. Overrides the THINGSDB environment variable.
+`
+
+const reviewHelp = `Usage: things review [OPTIONS...]
+
+NAME
+  things review - walk through active projects for a weekly review
+
+SYNOPSIS
+  things review [OPTIONS...]
+
+DESCRIPTION
+  Shows each incomplete project that is not in Someday, one at a time, with
+  its deadline, last activity, open todos, and whether it is stalled. A
+  project is stalled when it has no next action (no open todo in Anytime or
+  Today) or when neither it nor its todos changed in {{BT}}--stale-days{{BT}}.
+
+  For each project, answer:
+    s  skip
+    r  mark reviewed
+    d  move the project to Someday
+    c  complete the project
+
+  Someday and complete go through the {{BT}}things:///update-project{{BT}} URL
+  scheme and need an auth token. Answer q (or close STDIN) to stop; progress
+  is saved to review.json in the config directory and the next
+  {{BT}}things review{{BT}} resumes with the first unanswered project. The file
+  is removed when the review is complete.
+
+OPTIONS
+  --db=PATH
+    Path to the Things database. Overrides the THINGSDB environment variable.
+
+  --auth-token=TOKEN
+    The Things URL scheme authorization token. See {{BT}}things auth{{BT}}.
+
+  --stale-days=N
+    Days without changes before a project counts as stalled. Default: 14.
+
+  --restart
+    Discard saved progress and start a new review.
+
+  --list
+    Print one row per project (open todos, deadline, last activity, stalled
+    reasons, and this review's answer) without prompting.
+
+  --no-header
+    Suppress the header row with {{BT}}--list{{BT}}.
+
+EXAMPLES
+  things review
+  things review --stale-days 7
+  things review --list
+`
+
+const statsHelp = `Usage: things stats [OPTIONS...]
+
+NAME
+  things stats - summarize completed, created, and canceled todos
+
+SYNOPSIS
+  things stats [OPTIONS...]
+
+DESCRIPTION
+  Counts todos created, completed, and canceled between {{BT}}--since{{BT}} and
+  {{BT}}--until{{BT}}, grouped by ISO week, project, area, or tag, along with the
+  median time from creation to completion. Created todos are counted by
+  creation date; completed and canceled todos by the day they were closed.
+
+  Weekly reports include weeks with no activity. With {{BT}}--group-by=tag{{BT}}
+  a todo counts once for each of its tags.
+
+OPTIONS
+  --db=PATH
+    Path to the Things database. Overrides the THINGSDB environment variable.
+
+  --since=DATE
+    Start of the range (YYYY-MM-DD or relative, e.g. -30d). Default: -4w.
+
+  --until=DATE
+    Last day of the range, inclusive. Default: today.
+
+  --group-by=week|project|area|tag
+    How to group the counts. Default: week.
+
+  --format=table|json|csv|chart
+    Output format. {{BT}}chart{{BT}} draws one sparkline per metric.
+
+  --json
+    Shortcut for {{BT}}--format=json{{BT}}.
+
+  --no-header
+    Suppress the header row for table and CSV output.
+
+EXAMPLES
+  things stats
+  things stats --since 2026-09-01 --group-by project
+  things stats --since -12w --format chart
+`
+
+const exportHelp = `Usage: things export <ics|markdown|taskpaper> [OPTIONS...]
+
+NAME
+  things export - export tasks as iCalendar, Markdown, or TaskPaper
+
+SYNOPSIS
+  things export ics [OPTIONS...]
+  things export markdown [OPTIONS...]
+  things export taskpaper [OPTIONS...]
+
+DESCRIPTION
+  Writes todos and projects that have a start date or a deadline as an
+  iCalendar (RFC 5545) feed, suitable for subscribing from a calendar app.
+
+  By default ({{BT}}--type=auto{{BT}}) items with a deadline become VTODO entries
+  with a DUE date, scheduled items become all-day VEVENT entries, and
+  repeating templates become VEVENT entries with an RRULE derived from the
+  stored recurrence rule. After-completion rules are approximated by their
+  fixed interval.
+
+  Each entry uses the Things ID as its UID, the project and area as
+  CATEGORIES, the notes as DESCRIPTION, and a things:///show URL.
+
+  {{BT}}markdown{{BT}} and {{BT}}taskpaper{{BT}} write the area, project, and heading
+  outline with its todos: projects without an area first, then each area.
+  Notes follow their item, checklist items are nested list entries, and
+  tags and dates are appended as @tag, @defer(DATE), @due(DATE), and
+  @done(DATE). Markdown uses # areas, ## projects, ### headings, and task
+  list checkboxes; TaskPaper uses tab-indented "Title:" projects and "- "
+  items.
+
+OPTIONS (ics)
+  --type=auto|todo|event
+    Component type to emit. Default: auto.
+
+  --output=FILE, -o FILE
+    Write to FILE instead of STDOUT.
+
+  --no-repeating
+    Skip repeating templates.
+
+  --db=PATH
+    Path to the Things database. Overrides the THINGSDB environment variable.
+
+  --status, --filter-project, --filter-area, --filter-tag, --search, --query,
+  --due-before, --start-before, --limit, ...
+    Task filters, as for {{BT}}things tasks{{BT}}. Default status: incomplete.
+
+OPTIONS (markdown, taskpaper)
+  --area=AREA
+    Only export this area (title or ID).
+
+  --project=PROJECT
+    Only export this project (title or ID). Cannot be combined with --area.
+
+  --exact
+    Match --area and --project exactly instead of fuzzily.
+
+  --include-completed
+    Include completed and canceled projects and todos.
+
+  --output=FILE, -o FILE
+    Write to FILE instead of STDOUT.
+
+  --db=PATH
+    Path to the Things database. Overrides the THINGSDB environment variable.
+
+EXAMPLES
+  things export ics > things.ics
+
+  things export ics --filter-area Work --type todo -o work.ics
+
+  things export ics --no-repeating --due-before 2026-12-31
+
+  things export markdown -o snapshot.md
+
+  things export taskpaper --area Work --include-completed
+`
+
+const backupHelp = `Usage: things backup [OPTIONS...]
+
+NAME
+  things backup - write the whole library to a JSON archive
+
+SYNOPSIS
+  things backup [OPTIONS...]
+
+DESCRIPTION
+  Reads the Things database and writes one versioned JSON archive with
+  every tag (with its parent tag), area, project, heading, todo, and
+  checklist item outside the Trash, in library order, including completed
+  and canceled items. Repeating projects and todos keep their recurrence
+  rule, stored as Things saves it along with a description and an RRULE.
+  Todos created from a repeating template are left out; Things creates them
+  again from the template.
+
+  Restore the archive with {{BT}}things restore --into-empty{{BT}}.
+
+OPTIONS
+  --output=FILE, -o FILE
+    Write to FILE instead of STDOUT.
+
+  --db=PATH
+    Path to the Things database. Overrides the THINGSDB environment variable.
+
+EXAMPLES
+  things backup -o things-backup.json
+
+  things backup | gzip > things-$(date +%F).json.gz
+`
+
+const restoreHelp = `Usage: things restore [OPTIONS...] --into-empty [--] [-|ARCHIVE]
+
+NAME
+  things restore - rebuild the library from a backup archive
+
+SYNOPSIS
+  things restore [OPTIONS...] --into-empty [--] [-|ARCHIVE]
+
+DESCRIPTION
+  Rebuilds the library written by {{BT}}things backup{{BT}}. Tags (with their
+  parents) and areas are created with AppleScript, then projects, headings,
+  todos, and checklist items are created in library order through the
+  Things {{BT}}json{{BT}} URL command, in batches of at most 250 items ten
+  seconds apart. Completed and canceled items keep their state and dates.
+  Once the items exist, the archived recurrence rules are written back to
+  the database unchanged, anchored on the archived next date (or today when
+  the archive has none) so no repeat is scheduled in the past.
+
+  The archive is read from ARCHIVE, or from STDIN when ARCHIVE is {{BT}}-{{BT}}
+  or omitted.
+
+  Restoring only makes sense into a library without areas, projects, or
+  todos (tags that already exist are kept). {{BT}}--into-empty{{BT}} is
+  required, and restore refuses to run when the database is not empty.
+
+  Use {{BT}}--dry-run{{BT}} to print the scripts and URLs without running them.
+
+OPTIONS
+  --into-empty
+    Confirm that the library is empty and should be rebuilt. Required.
+
+  --db=PATH
+    Path to the Things database used to check that the library is empty and
+    to write recurrence rules. Overrides the THINGSDB environment variable.
+
+EXAMPLES
+  things restore --into-empty things-backup.json
+
+  things --dry-run restore --into-empty things-backup.json
+`
+
+const diffHelp = `Usage: things diff --from SOURCE [--to SOURCE] [OPTIONS...]
+
+NAME
+  things diff - compare two databases or backup snapshots
+
+SYNOPSIS
+  things diff --from SOURCE [--to SOURCE] [OPTIONS...]
+
+DESCRIPTION
+  Reports the areas, projects, and todos added, removed, or modified
+  between two points in time. Each SOURCE is a backup archive written by
+  {{BT}}things backup{{BT}}, a Things database file ({{BT}}main.sqlite{{BT}}), or
+  {{BT}}current{{BT}} for the database in use (write {{BT}}./current{{BT}} for a file
+  with that name). Items are matched by UUID and compared field by field:
+  title, notes, status, start, start date, deadline, completion date, tags,
+  area, project, heading, checklist, and repeat rule.
+
+  Items in the Trash and todos created from a repeating template are left
+  out on both sides, as in a backup.
+
+OPTIONS
+  --from=SOURCE
+    The older side. Required.
+
+  --to=SOURCE
+    The newer side. Defaults to {{BT}}current{{BT}}.
+
+  --format=FORMAT
+    Output format: table (default), json, or diff (unified-diff style).
+
+  --json, -j
+    Shorthand for --format json.
+
+  --no-header
+    Omit the table header and the summary line.
+
+  --db=PATH
+    Path to the database used for {{BT}}current{{BT}}. Overrides the THINGSDB
+    environment variable.
+
+EXAMPLES
+  things diff --from things-backup.json
+
+  things diff --from old/main.sqlite --to new/main.sqlite --format diff
+
+  things diff --from monday.json --to tuesday.json --json
+`
@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// NewHistoryCommand builds the history subcommand.
+func NewHistoryCommand(app *App) *cobra.Command {
+	var limit int
+	var asJSON bool
+	var noHeader bool
+
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "List logged actions",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			entries, err := readActions()
+			if err != nil {
+				return fmt.Errorf("Error: %s", err)
+			}
+			// Newest first.
+			for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+				entries[i], entries[j] = entries[j], entries[i]
+			}
+			if limit > 0 && len(entries) > limit {
+				entries = entries[:limit]
+			}
+			return printActionHistory(app.Out, entries, asJSON, noHeader)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.IntVar(&limit, "limit", 20, "Limit number of entries (0 = all)")
+	flags.BoolVar(&asJSON, "json", false, "Output JSON")
+	flags.BoolVar(&noHeader, "no-header", false, "Suppress header row")
+
+	return cmd
+}
+
+func printActionHistory(out io.Writer, entries []ActionEntry, asJSON bool, noHeader bool) error {
+	if asJSON {
+		if entries == nil {
+			entries = []ActionEntry{}
+		}
+		enc := json.NewEncoder(out)
+		return enc.Encode(entries)
+	}
+	w := tabwriter.NewWriter(out, 0, 2, 2, ' ', 0)
+	if !noHeader {
+		fmt.Fprintln(w, "ID\tTIME\tTYPE\tITEMS\tSTATUS\tTITLES")
+	}
+	for _, entry := range entries {
+		status := "done"
+		if entry.UndoneAt != "" {
+			status = "undone"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%s\t%s\n", entry.ID, entry.Timestamp, entry.Type, len(entry.Items), status, actionTitles(entry.Items))
+	}
+	return w.Flush()
+}
+
+func actionTitles(items []ActionItem) string {
+	if len(items) == 0 {
+		return ""
+	}
+	if len(items) == 1 {
+		return items[0].Title
+	}
+	return fmt.Sprintf("%s (+%d)", items[0].Title, len(items)-1)
+}
@@ -0,0 +1,385 @@
+package cli
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ossianhempel/things3-cli/internal/db"
+)
+
+func seedActions(t *testing.T, entries ...ActionEntry) {
+	t.Helper()
+	for _, entry := range entries {
+		if err := appendAction(entry); err != nil {
+			t.Fatalf("appendAction failed: %v", err)
+		}
+	}
+}
+
+func TestHistoryCommandListsNewestFirst(t *testing.T) {
+	setConfigHome(t)
+	seedActions(t,
+		ActionEntry{Type: ActionUpdate, Items: []ActionItem{{UUID: "A", Title: "Alpha"}}},
+		ActionEntry{Type: ActionTrash, Items: []ActionItem{{UUID: "B", Title: "Beta"}, {UUID: "C", Title: "Gamma"}}},
+	)
+
+	out, err := runCommand(t, &App{Launcher: &recordLauncher{}, Scripter: &recordScriptRunner{}}, "history")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "ID") {
+		t.Fatalf("unexpected output:\n%s", out)
+	}
+	if !strings.HasPrefix(lines[1], "2 ") || !strings.Contains(lines[1], "trash") || !strings.Contains(lines[1], "Beta (+1)") {
+		t.Fatalf("unexpected first row: %q", lines[1])
+	}
+
+	out, err = runCommand(t, &App{Launcher: &recordLauncher{}, Scripter: &recordScriptRunner{}}, "history", "--json", "--limit", "1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var entries []ActionEntry
+	if err := json.Unmarshal([]byte(out), &entries); err != nil {
+		t.Fatalf("decode output: %v", err)
+	}
+	if len(entries) != 1 || entries[0].ID != 2 {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+}
+
+func TestUndoCommandTargetsEntries(t *testing.T) {
+	setConfigHome(t)
+	t.Setenv("THINGS_AUTH_TOKEN", "token")
+	seedActions(t,
+		ActionEntry{Type: ActionUpdate, Items: []ActionItem{{UUID: "A", Title: "Alpha", Status: db.StatusCompleted}}},
+		ActionEntry{Type: ActionUpdate, Items: []ActionItem{{UUID: "B", Title: "Beta", Status: db.StatusCompleted}}},
+		ActionEntry{Type: ActionUpdate, Items: []ActionItem{{UUID: "C", Title: "Gamma", Status: db.StatusCompleted}}},
+	)
+
+	launcher := &recordLauncher{}
+	if _, err := runCommand(t, &App{Launcher: launcher, Scripter: &recordScriptRunner{}}, "undo", "--id", "1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := requireUpdateID(t, launcher); got != "A" {
+		t.Fatalf("expected undo of A, got %q", got)
+	}
+	if _, err := runCommand(t, &App{Launcher: launcher, Scripter: &recordScriptRunner{}}, "undo", "--id", "1"); err == nil {
+		t.Fatalf("expected error undoing an undone action")
+	}
+
+	out, err := runCommand(t, &App{Launcher: launcher, Scripter: &recordScriptRunner{}}, "undo", "--steps", "3", "--dry-run")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out, "Would undo #3") || !strings.Contains(out, "Would undo #2") || strings.Contains(out, "#1") {
+		t.Fatalf("unexpected dry-run output:\n%s", out)
+	}
+
+	if _, err := runCommand(t, &App{Launcher: launcher, Scripter: &recordScriptRunner{}}, "undo", "--steps", "3"); err == nil {
+		t.Fatalf("expected confirmation error for multiple tasks")
+	}
+	if _, err := runCommand(t, &App{Launcher: launcher, Scripter: &recordScriptRunner{}}, "undo", "--steps", "3", "--yes"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := requireUpdateID(t, launcher); got != "B" {
+		t.Fatalf("expected last undo of B, got %q", got)
+	}
+	for _, entry := range mustReadActions(t) {
+		if entry.UndoneAt == "" {
+			t.Fatalf("expected action %d to be marked undone", entry.ID)
+		}
+	}
+	if _, err := runCommand(t, &App{Launcher: launcher, Scripter: &recordScriptRunner{}}, "undo"); err == nil {
+		t.Fatalf("expected error with nothing left to undo")
+	}
+}
+
+func TestRedoCommandReappliesSnapshot(t *testing.T) {
+	setConfigHome(t)
+	t.Setenv("THINGS_AUTH_TOKEN", "token")
+	dbPath := writeTestDB(t)
+	seedActions(t, ActionEntry{Type: ActionUpdate, Items: []ActionItem{{UUID: "ANY1", Title: "Old title", Status: db.StatusCompleted}}})
+
+	launcher := &recordLauncher{}
+	if _, err := runCommand(t, &App{Launcher: launcher, Scripter: &recordScriptRunner{}}, "undo", "--db", dbPath); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	entries := mustReadActions(t)
+	if len(entries[0].Redo) != 1 || entries[0].Redo[0].Title == "Old title" {
+		t.Fatalf("expected redo snapshot from database, got %+v", entries[0].Redo)
+	}
+	want := entries[0].Redo[0].Title
+
+	launcher = &recordLauncher{}
+	if _, err := runCommand(t, &App{Launcher: launcher, Scripter: &recordScriptRunner{}}, "redo"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	parsed, err := url.Parse(requireOpenURL(t, launcher))
+	if err != nil {
+		t.Fatalf("parse url: %v", err)
+	}
+	if parsed.Query().Get("id") != "ANY1" || parsed.Query().Get("title") != want {
+		t.Fatalf("unexpected redo url: %s", parsed)
+	}
+	if entry := mustReadActions(t)[0]; entry.UndoneAt != "" || len(entry.Redo) != 0 {
+		t.Fatalf("expected redo to clear undo state, got %+v", entry)
+	}
+	if _, err := runCommand(t, &App{Launcher: launcher, Scripter: &recordScriptRunner{}}, "redo"); err == nil {
+		t.Fatalf("expected error with nothing to redo")
+	}
+}
+
+func requireUpdateID(t *testing.T, launcher *recordLauncher) string {
+	t.Helper()
+	parsed, err := url.Parse(requireOpenURL(t, launcher))
+	if err != nil {
+		t.Fatalf("parse url: %v", err)
+	}
+	return parsed.Query().Get("id")
+}
+
+func TestAddLogsCreatedItemsForUndo(t *testing.T) {
+	setConfigHome(t)
+	dbPath := writeTestDB(t)
+	conn, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	now := float64(time.Now().Unix())
+	for _, row := range []struct {
+		uuid    string
+		created float64
+	}{{"NEW1", now}, {"OLD1", now - 3600}} {
+		if _, err := conn.Exec(`INSERT INTO TMTask (uuid, type, status, trashed, title, start, creationDate) VALUES (?, 0, 0, 0, 'Fresh todo', 0, ?)`, row.uuid, row.created); err != nil {
+			t.Fatalf("insert task: %v", err)
+		}
+	}
+	conn.Close()
+
+	if _, err := runCommand(t, &App{Launcher: &recordLauncher{}, Scripter: &recordScriptRunner{}}, "add", "--db", dbPath, "Fresh todo"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	entries := mustReadActions(t)
+	if len(entries) != 1 || entries[0].Type != ActionCreate || len(entries[0].Items) != 1 || entries[0].Items[0].UUID != "NEW1" {
+		t.Fatalf("unexpected action log: %+v", entries)
+	}
+
+	runner := &recordScriptRunner{}
+	app := &App{
+		In:       strings.NewReader(""),
+		Out:      &bytes.Buffer{},
+		Err:      &bytes.Buffer{},
+		Launcher: &recordLauncher{},
+		Scripter: runner,
+	}
+	root := NewRoot(app)
+	root.SetArgs([]string{"undo"})
+	root.SetOut(app.Out)
+	root.SetErr(app.Err)
+	if err := root.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if script := requireScript(t, runner); !strings.Contains(script, `"NEW1"`) || !strings.Contains(script, "delete to do id") {
+		t.Fatalf("unexpected script:\n%s", script)
+	}
+}
+
+func TestAddWarnsWhenCreatedItemMissing(t *testing.T) {
+	setConfigHome(t)
+	dbPath := writeTestDB(t)
+	prev := createdItemTimeout
+	createdItemTimeout = 0
+	t.Cleanup(func() { createdItemTimeout = prev })
+
+	errOut := &bytes.Buffer{}
+	app := &App{
+		In:       strings.NewReader(""),
+		Out:      &bytes.Buffer{},
+		Err:      errOut,
+		Launcher: &recordLauncher{},
+	}
+	root := NewRoot(app)
+	root.SetArgs([]string{"add", "--db", dbPath, "Never written"})
+	root.SetOut(app.Out)
+	root.SetErr(app.Err)
+	if err := root.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(errOut.String(), "could not find every created item") {
+		t.Fatalf("expected warning, got %q", errOut.String())
+	}
+	if entries := mustReadActions(t); len(entries) != 0 {
+		t.Fatalf("expected nothing logged, got %+v", entries)
+	}
+}
+
+func TestAddSkipsAmbiguousCreatedItems(t *testing.T) {
+	setConfigHome(t)
+	dbPath := writeTestDB(t)
+	conn, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	now := float64(time.Now().Unix())
+	for _, uuid := range []string{"NEW1", "NEW2"} {
+		if _, err := conn.Exec(`INSERT INTO TMTask (uuid, type, status, trashed, title, start, creationDate) VALUES (?, 0, 0, 0, 'Shared title', 0, ?)`, uuid, now); err != nil {
+			t.Fatalf("insert task: %v", err)
+		}
+	}
+	conn.Close()
+
+	errOut := &bytes.Buffer{}
+	app := &App{
+		In:       strings.NewReader(""),
+		Out:      &bytes.Buffer{},
+		Err:      errOut,
+		Launcher: &recordLauncher{},
+	}
+	root := NewRoot(app)
+	root.SetArgs([]string{"add", "--db", dbPath, "Shared title"})
+	root.SetOut(app.Out)
+	root.SetErr(app.Err)
+	if err := root.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(errOut.String(), "share a created title") {
+		t.Fatalf("expected warning, got %q", errOut.String())
+	}
+	if entries := mustReadActions(t); len(entries) != 0 {
+		t.Fatalf("expected nothing logged, got %+v", entries)
+	}
+}
+
+func TestUndoTrashRestoresOriginals(t *testing.T) {
+	setConfigHome(t)
+	t.Setenv("THINGS_AUTH_TOKEN", "token")
+	dbPath := writeTestDB(t)
+
+	runner := &recordScriptRunner{}
+	launcher := &recordLauncher{}
+	run := func(args ...string) {
+		t.Helper()
+		app := &App{
+			In:       strings.NewReader(""),
+			Out:      &bytes.Buffer{},
+			Err:      &bytes.Buffer{},
+			Launcher: launcher,
+			Scripter: runner,
+		}
+		root := NewRoot(app)
+		root.SetArgs(args)
+		root.SetOut(app.Out)
+		root.SetErr(app.Err)
+		if err := root.Execute(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	run("delete", "--db", dbPath, "--search", "Task One", "--yes")
+	entries := mustReadActions(t)
+	if len(entries) != 1 || len(entries[0].Items) != 1 {
+		t.Fatalf("unexpected action log: %+v", entries)
+	}
+	item := entries[0].Items[0]
+	if item.HeadingID != "H1" || len(item.Checklist) != 1 || item.Checklist[0].UUID != "C1" {
+		t.Fatalf("expected heading and checklist snapshot, got %+v", item)
+	}
+
+	run("undo")
+	script := requireScript(t, runner)
+	for _, want := range []string{`to do id "T1"`, `project id "P1"`} {
+		if !strings.Contains(script, want) {
+			t.Fatalf("expected %q in restore script:\n%s", want, script)
+		}
+	}
+	parsed, err := url.Parse(requireOpenURL(t, launcher))
+	if err != nil {
+		t.Fatalf("parse url: %v", err)
+	}
+	if parsed.Query().Get("id") != "T1" || parsed.Query().Get("heading") == "" {
+		t.Fatalf("expected heading restore url, got %s", parsed)
+	}
+
+	runner.script = ""
+	run("redo")
+	if script := requireScript(t, runner); !strings.Contains(script, "delete to do id") || !strings.Contains(script, `"T1"`) {
+		t.Fatalf("expected redo to trash again:\n%s", script)
+	}
+}
+
+func TestRedoFollowsUndoOrder(t *testing.T) {
+	setConfigHome(t)
+	t.Setenv("THINGS_AUTH_TOKEN", "token")
+	seedActions(t,
+		ActionEntry{Type: ActionTrash, Items: []ActionItem{{UUID: "A", Title: "Alpha"}}},
+		ActionEntry{Type: ActionTrash, Items: []ActionItem{{UUID: "B", Title: "Beta"}}},
+		ActionEntry{Type: ActionTrash, Items: []ActionItem{{UUID: "C", Title: "Gamma"}}},
+	)
+	if _, err := runCommand(t, &App{Launcher: &recordLauncher{}, Scripter: &recordScriptRunner{}}, "undo", "--steps", "3", "--yes"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Undo ran C, B, A within one second; redo must replay A, B, C.
+	for _, want := range []string{"Alpha", "Beta", "Gamma"} {
+		out, err := runCommand(t, &App{Launcher: &recordLauncher{}, Scripter: &recordScriptRunner{}}, "redo", "--dry-run")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected redo of %s, got:\n%s", want, out)
+		}
+		if _, err := runCommand(t, &App{Launcher: &recordLauncher{}, Scripter: &recordScriptRunner{}}, "redo"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
+
+func TestNewActionClearsRedo(t *testing.T) {
+	setConfigHome(t)
+	seedActions(t,
+		ActionEntry{Type: ActionTrash, Items: []ActionItem{{UUID: "A", Title: "Alpha"}}},
+		ActionEntry{Type: ActionTrash, Items: []ActionItem{{UUID: "B", Title: "Beta"}}},
+	)
+	if _, err := runCommand(t, &App{Launcher: &recordLauncher{}, Scripter: &recordScriptRunner{}}, "undo"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	seedActions(t, ActionEntry{Type: ActionTrash, Items: []ActionItem{{UUID: "C", Title: "Gamma"}}})
+
+	entries := mustReadActions(t)
+	if len(entries) != 2 || entries[0].ID != 1 || entries[1].ID != 3 {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+	if _, err := runCommand(t, &App{Launcher: &recordLauncher{}, Scripter: &recordScriptRunner{}}, "redo"); err == nil {
+		t.Fatalf("expected error with nothing to redo")
+	}
+}
+
+func TestUndoTrashKeepsEntryWhenRestoreFails(t *testing.T) {
+	setConfigHome(t)
+	seedActions(t, ActionEntry{Type: ActionTrash, Items: []ActionItem{{UUID: "GONE", Title: "Gone"}}})
+
+	app := &App{
+		In:       strings.NewReader(""),
+		Out:      &bytes.Buffer{},
+		Err:      &bytes.Buffer{},
+		Launcher: &recordLauncher{},
+		Scripter: &recordScriptRunner{err: errors.New("Could not restore GONE: not found")},
+	}
+	root := NewRoot(app)
+	root.SetArgs([]string{"undo"})
+	root.SetOut(app.Out)
+	root.SetErr(app.Err)
+	if err := root.Execute(); err == nil {
+		t.Fatalf("expected restore failure to be reported")
+	}
+	if entry := mustReadActions(t)[0]; entry.UndoneAt != "" {
+		t.Fatalf("expected action to stay pending after a failed restore, got %+v", entry)
+	}
+}
@@ -0,0 +1,125 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ossianhempel/things3-cli/internal/db"
+	"github.com/ossianhempel/things3-cli/internal/outline"
+	"github.com/ossianhempel/things3-cli/internal/things"
+	"github.com/spf13/cobra"
+)
+
+// NewImportCommand builds the import command and its format subcommands.
+func NewImportCommand(app *App) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import <taskpaper|markdown|todoist|omnifocus> [OPTIONS...] [--] [-|FILE...]",
+		Short: "Create projects and todos from outlines and other apps' exports",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			printHelp(app.Out, formatHelpText(importHelp, isTTY(app.Out)))
+			return ErrHelpPrinted
+		},
+	}
+
+	cmd.AddCommand(newImportOutlineCommand(app, "taskpaper", "Create projects and todos from a TaskPaper outline", outline.ParseTaskPaper))
+	cmd.AddCommand(newImportOutlineCommand(app, "markdown", "Create projects and todos from a Markdown outline", outline.ParseMarkdown))
+	cmd.AddCommand(newImportTodoistCommand(app))
+	cmd.AddCommand(newImportOmniFocusCommand(app))
+	return cmd
+}
+
+func newImportOutlineCommand(app *App, name, short string, parse func([]byte) ([]*outline.Entry, error)) *cobra.Command {
+	var dbPath string
+	var reveal bool
+
+	cmd := &cobra.Command{
+		Use:   name + " [OPTIONS...] [--] [-|FILE]",
+		Short: short,
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			data, err := readJSONPayload(app.In, args)
+			if err != nil {
+				return err
+			}
+			entries, err := parse(data)
+			if err != nil {
+				return err
+			}
+			items := outline.Build(entries, existingAreas(app, dbPath))
+			if len(items) == 0 {
+				return fmt.Errorf("Error: no projects or todos found in the %s outline", name)
+			}
+
+			url, err := things.BuildJSONURL(things.JSONOptions{Reveal: reveal}, items)
+			if err != nil {
+				return err
+			}
+			if app.DryRun {
+				printJSONItemTree(app.Out, items, "")
+			}
+			return openURL(app, url)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVarP(&dbPath, "db", "d", "", "Path to Things database used to recognize existing areas")
+	flags.StringVar(&dbPath, "database", "", "Alias for --db")
+	flags.BoolVar(&reveal, "reveal", false, "Reveal the first created item")
+
+	return cmd
+}
+
+// existingAreas returns a case-insensitive lookup of area titles in the
+// database, or nil when the database cannot be read; imports then rely on
+// the outline's structure alone.
+func existingAreas(app *App, dbPath string) func(string) bool {
+	store, _, err := db.OpenDefault(dbPath)
+	if err != nil {
+		if app.Debug {
+			fmt.Fprintf(app.Err, "Skipping area lookup: %v\n", err)
+		}
+		return nil
+	}
+	defer store.Close()
+	areas, err := store.Areas()
+	if err != nil {
+		return nil
+	}
+	titles := make(map[string]bool, len(areas))
+	for _, area := range areas {
+		titles[strings.ToLower(area.Title)] = true
+	}
+	return func(title string) bool { return titles[strings.ToLower(title)] }
+}
+
+// printJSONItemTree prints the items a JSON batch will create, one per line,
+// indented like printTree.
+func printJSONItemTree(out io.Writer, items []things.JSONItem, indent string) {
+	for _, item := range items {
+		attrs := item.Attributes
+		line := fmt.Sprintf("%s- %s (%s)", indent, attrs.Title, item.Type)
+		for _, field := range [][2]string{
+			{"area", attrs.Area},
+			{"list", attrs.List},
+			{"when", attrs.When},
+			{"deadline", attrs.Deadline},
+			{"tags", strings.Join(attrs.Tags, ",")},
+		} {
+			if field[1] != "" {
+				line += fmt.Sprintf(" %s=%s", field[0], field[1])
+			}
+		}
+		if attrs.Completed {
+			line += " completed"
+		} else if attrs.Canceled {
+			line += " canceled"
+		}
+		if attrs.Notes != "" {
+			line += " +notes"
+		}
+		fmt.Fprintln(out, line)
+		printJSONItemTree(out, attrs.Items, indent+"  ")
+		printJSONItemTree(out, attrs.ChecklistItems, indent+"  ")
+	}
+}
@@ -0,0 +1,197 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ossianhempel/things3-cli/internal/db"
+	"github.com/ossianhempel/things3-cli/internal/importer"
+	"github.com/ossianhempel/things3-cli/internal/things"
+	"github.com/spf13/cobra"
+)
+
+func newImportTodoistCommand(app *App) *cobra.Command {
+	var dbPath string
+	var project string
+	var reveal bool
+
+	cmd := &cobra.Command{
+		Use:   "todoist [OPTIONS...] [--] FILE...",
+		Short: "Create projects from Todoist CSV exports",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if project != "" && len(args) > 1 {
+				return fmt.Errorf("Error: --project requires a single CSV file")
+			}
+			imp := &importer.Import{}
+			now := time.Now()
+			for _, path := range args {
+				data, err := readJSONPayload(app.In, []string{path})
+				if err != nil {
+					return err
+				}
+				name := project
+				if name == "" {
+					name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+				}
+				if name == "" || name == "-" {
+					return fmt.Errorf("Error: --project is required when reading from stdin")
+				}
+				if err := imp.AddTodoist(data, name, now); err != nil {
+					return fmt.Errorf("%v (%s)", err, path)
+				}
+			}
+			return runAppImport(app, dbPath, reveal, imp)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVarP(&dbPath, "db", "d", "", "Path to Things database used to recognize areas and apply repeats")
+	flags.StringVar(&dbPath, "database", "", "Alias for --db")
+	flags.StringVar(&project, "project", "", "Project title (defaults to the file name)")
+	flags.BoolVar(&reveal, "reveal", false, "Reveal the first created item")
+
+	return cmd
+}
+
+func newImportOmniFocusCommand(app *App) *cobra.Command {
+	var dbPath string
+	var reveal bool
+
+	cmd := &cobra.Command{
+		Use:   "omnifocus [OPTIONS...] [--] [-|FILE]",
+		Short: "Create areas, projects, and todos from an OmniFocus export",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			data, err := readJSONPayload(app.In, args)
+			if err != nil {
+				return err
+			}
+			imp := &importer.Import{}
+			if err := imp.AddOmniFocus(data, time.Now()); err != nil {
+				return err
+			}
+			return runAppImport(app, dbPath, reveal, imp)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVarP(&dbPath, "db", "d", "", "Path to Things database used to recognize areas and apply repeats")
+	flags.StringVar(&dbPath, "database", "", "Alias for --db")
+	flags.BoolVar(&reveal, "reveal", false, "Reveal the first created item")
+
+	return cmd
+}
+
+// runAppImport creates the imported items with one JSON batch, then applies
+// repeating rules to the created items and prints what could not be mapped.
+func runAppImport(app *App, dbPath string, reveal bool, imp *importer.Import) error {
+	items := imp.Items(existingAreas(app, dbPath))
+	if len(items) == 0 {
+		return fmt.Errorf("Error: no projects or todos found in the export")
+	}
+	url, err := things.BuildJSONURL(things.JSONOptions{Reveal: reveal}, items)
+	if err != nil {
+		return err
+	}
+	missingImportTags(app, dbPath, imp, items)
+
+	if app.DryRun {
+		printJSONItemTree(app.Out, items, "")
+		for _, rule := range imp.Repeats {
+			fmt.Fprintf(app.Out, "repeat %s: %s\n", rule.Title, rule.Spec.Describe())
+		}
+		if err := openURL(app, url); err != nil {
+			return err
+		}
+		if len(imp.Repeats) > 0 {
+			fmt.Fprintln(app.Err, "Note: repeats are skipped in --dry-run mode.")
+		}
+		printImportReport(app.Err, imp.Unmapped)
+		return nil
+	}
+
+	ensureThingsLaunched(app)
+	started := time.Now().Add(-2 * time.Second)
+	if err := openURL(app, url); err != nil {
+		return err
+	}
+	if len(imp.Repeats) > 0 {
+		applyImportRepeats(dbPath, imp, started)
+	}
+	printImportReport(app.Err, imp.Unmapped)
+	return nil
+}
+
+func applyImportRepeats(dbPath string, imp *importer.Import, started time.Time) {
+	store, _, err := db.OpenDefaultWritable(dbPath)
+	if err != nil {
+		for _, rule := range imp.Repeats {
+			imp.Unmapped = append(imp.Unmapped, importer.Issue{Item: rule.Title, Field: "repeat", Value: rule.Spec.Describe(), Reason: formatDBError(err).Error()})
+		}
+		return
+	}
+	defer store.Close()
+
+	deadline := time.Now().Add(createdItemWait)
+	for _, rule := range imp.Repeats {
+		taskType := db.TaskTypeTodo
+		if rule.Project {
+			taskType = db.TaskTypeProject
+		}
+		err := applyCreatedRepeat(store, rule.Title, taskType, rule.Spec, started, deadline)
+		if err != nil {
+			imp.Unmapped = append(imp.Unmapped, importer.Issue{Item: rule.Title, Field: "repeat", Value: rule.Spec.Describe(), Reason: err.Error()})
+		}
+	}
+}
+
+// missingImportTags reports tags that do not exist in the database; Things
+// drops unknown tags from JSON batches.
+func missingImportTags(app *App, dbPath string, imp *importer.Import, items []things.JSONItem) {
+	store, _, err := db.OpenDefault(dbPath)
+	if err != nil {
+		return
+	}
+	defer store.Close()
+	tags, err := store.Tags()
+	if err != nil {
+		return
+	}
+	known := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		known[strings.ToLower(tag.Title)] = true
+	}
+
+	var walk func(items []things.JSONItem)
+	walk = func(items []things.JSONItem) {
+		for _, item := range items {
+			for _, tag := range item.Attributes.Tags {
+				if known[strings.ToLower(tag)] {
+					continue
+				}
+				known[strings.ToLower(tag)] = true
+				imp.Unmapped = append(imp.Unmapped, importer.Issue{Item: item.Attributes.Title, Field: "tag", Value: tag, Reason: "tag does not exist in Things; create it first"})
+			}
+			walk(item.Attributes.Items)
+		}
+	}
+	walk(items)
+}
+
+func printImportReport(out io.Writer, issues []importer.Issue) {
+	if len(issues) == 0 {
+		return
+	}
+	fmt.Fprintf(out, "Not imported (%d):\n", len(issues))
+	w := tabwriter.NewWriter(out, 0, 2, 2, ' ', 0)
+	fmt.Fprintln(w, "ITEM\tFIELD\tVALUE\tNOTE")
+	for _, issue := range issues {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", issue.Item, issue.Field, issue.Value, issue.Reason)
+	}
+	w.Flush()
+}
@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestImportJSONCommandFromStdin(t *testing.T) {
+	launcher := &recordLauncher{}
+	app := &App{
+		In:       strings.NewReader(`[{"type":"to-do","attributes":{"title":"Buy milk"}}]`),
+		Out:      &bytes.Buffer{},
+		Err:      &bytes.Buffer{},
+		Launcher: launcher,
+	}
+
+	root := NewRoot(app)
+	root.SetArgs([]string{"import-json", "--reveal", "-"})
+	root.SetOut(app.Out)
+	root.SetErr(app.Err)
+
+	if err := root.Execute(); err != nil {
+		t.Fatalf("execute failed: %v", err)
+	}
+
+	url := requireOpenURL(t, launcher)
+	if !strings.HasPrefix(url, "things:///json?data=") {
+		t.Fatalf("unexpected url: %q", url)
+	}
+	if !strings.Contains(url, "Buy%20milk") {
+		t.Fatalf("expected title in url, got %q", url)
+	}
+	if !strings.Contains(url, "reveal=true") {
+		t.Fatalf("expected reveal in url, got %q", url)
+	}
+}
+
+func TestImportJSONCommandFromFileDryRun(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.json")
+	if err := os.WriteFile(path, []byte(`{"type":"project","attributes":{"title":"Trip"}}`), 0o600); err != nil {
+		t.Fatalf("write payload: %v", err)
+	}
+
+	launcher := &recordLauncher{}
+	out := &bytes.Buffer{}
+	app := &App{
+		In:       strings.NewReader(""),
+		Out:      out,
+		Err:      &bytes.Buffer{},
+		Launcher: launcher,
+	}
+
+	root := NewRoot(app)
+	root.SetArgs([]string{"--dry-run", "import-json", path})
+	root.SetOut(app.Out)
+	root.SetErr(app.Err)
+
+	if err := root.Execute(); err != nil {
+		t.Fatalf("execute failed: %v", err)
+	}
+	if len(launcher.args) != 0 {
+		t.Fatalf("expected no open invocation in dry-run")
+	}
+	if !strings.Contains(out.String(), "things:///json?data=") {
+		t.Fatalf("expected url in output, got %q", out.String())
+	}
+}
+
+func TestImportJSONCommandUpdateRequiresToken(t *testing.T) {
+	t.Setenv("THINGS_AUTH_TOKEN", "")
+	launcher := &recordLauncher{}
+	app := &App{
+		In:       strings.NewReader(`[{"type":"to-do","operation":"update","id":"T1","attributes":{"completed":true}}]`),
+		Out:      &bytes.Buffer{},
+		Err:      &bytes.Buffer{},
+		Launcher: launcher,
+	}
+
+	root := NewRoot(app)
+	root.SetArgs([]string{"import-json"})
+	root.SetOut(app.Out)
+	root.SetErr(app.Err)
+
+	if err := root.Execute(); err == nil {
+		t.Fatalf("expected error")
+	}
+	if len(launcher.args) != 0 {
+		t.Fatalf("expected no open invocation")
+	}
+
+	launcher = &recordLauncher{}
+	app.In = strings.NewReader(`[{"type":"to-do","operation":"update","id":"T1","attributes":{"completed":true}}]`)
+	app.Launcher = launcher
+	root = NewRoot(app)
+	root.SetArgs([]string{"import-json", "--auth-token=tok"})
+	root.SetOut(app.Out)
+	root.SetErr(app.Err)
+
+	if err := root.Execute(); err != nil {
+		t.Fatalf("execute failed: %v", err)
+	}
+	url := requireOpenURL(t, launcher)
+	if !strings.Contains(url, "auth-token=tok") {
+		t.Fatalf("expected auth token in url, got %q", url)
+	}
+}
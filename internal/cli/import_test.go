@@ -0,0 +1,194 @@
+package cli
+
+import (
+	"bytes"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestImportTaskPaperDryRunPrintsTree(t *testing.T) {
+	dbPath := writeTestDB(t)
+	path := filepath.Join(t.TempDir(), "plan.taskpaper")
+	doc := "Home:\n\t- Water plants @errand\nTrip: @due(2026-12-01)\n\tPacking:\n\t\t- Passport\n\t\t\tCheck expiry\n\t\t\t- Renew\n"
+	if err := os.WriteFile(path, []byte(doc), 0o600); err != nil {
+		t.Fatalf("write outline: %v", err)
+	}
+
+	launcher := &recordLauncher{}
+	out := &bytes.Buffer{}
+	app := &App{In: strings.NewReader(""), Out: out, Err: &bytes.Buffer{}, Launcher: launcher}
+	root := NewRoot(app)
+	root.SetArgs([]string{"--dry-run", "import", "taskpaper", "--db", dbPath, path})
+	root.SetOut(app.Out)
+	root.SetErr(app.Err)
+	if err := root.Execute(); err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	if len(launcher.args) != 0 {
+		t.Fatalf("expected no open invocation in dry-run")
+	}
+
+	want := strings.Join([]string{
+		"- Water plants (to-do) list=Home tags=errand",
+		"- Trip (project) deadline=2026-12-01",
+		"  - Packing (heading)",
+		"  - Passport (to-do) +notes",
+		"    - Renew (checklist-item)",
+		"things:///json?data=",
+	}, "\n")
+	if !strings.HasPrefix(out.String(), want) {
+		t.Fatalf("unexpected dry-run output:\n%s", out.String())
+	}
+}
+
+func TestImportMarkdownRoundTripsExport(t *testing.T) {
+	dbPath := writeTestDB(t)
+	exported, err := runCommand(t, &App{}, "export", "markdown", "--db", dbPath)
+	if err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+
+	launcher := &recordLauncher{}
+	app := &App{In: strings.NewReader(exported), Out: &bytes.Buffer{}, Err: &bytes.Buffer{}, Launcher: launcher}
+	root := NewRoot(app)
+	root.SetArgs([]string{"import", "markdown", "--db", filepath.Join(t.TempDir(), "missing.sqlite"), "-"})
+	root.SetOut(app.Out)
+	root.SetErr(app.Err)
+	if err := root.Execute(); err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	url := requireOpenURL(t, launcher)
+	for _, want := range []string{"%22type%22%3A%22project%22", "%22area%22%3A%22Home%22", "%22title%22%3A%22Heading%22", "%22tags%22%3A%5B%22urgent%22%5D", "Check%20Item"} {
+		if !strings.Contains(url, want) {
+			t.Fatalf("expected %s in url: %s", want, url)
+		}
+	}
+}
+
+func TestImportMarkdownRoundTripsProjectWithoutHeadings(t *testing.T) {
+	dbPath := writeTestDB(t)
+	conn, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if _, err := conn.Exec(`UPDATE TMTask SET trashed = 1 WHERE uuid = 'H1'`); err != nil {
+		t.Fatalf("trash heading: %v", err)
+	}
+	conn.Close()
+	exported, err := runCommand(t, &App{}, "export", "markdown", "--db", dbPath, "--area", "Home")
+	if err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	if strings.Contains(exported, "###") {
+		t.Fatalf("expected an export without headings:\n%s", exported)
+	}
+
+	launcher := &recordLauncher{}
+	app := &App{In: strings.NewReader(exported), Out: &bytes.Buffer{}, Err: &bytes.Buffer{}, Launcher: launcher}
+	root := NewRoot(app)
+	root.SetArgs([]string{"import", "markdown", "--db", filepath.Join(t.TempDir(), "missing.sqlite"), "-"})
+	root.SetOut(app.Out)
+	root.SetErr(app.Err)
+	if err := root.Execute(); err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	url := requireOpenURL(t, launcher)
+	if !strings.Contains(url, "%22title%22%3A%22Project%20One%22") || !strings.Contains(url, "%22area%22%3A%22Home%22") {
+		t.Fatalf("expected Project One filed into Home: %s", url)
+	}
+	if strings.Contains(url, "%22title%22%3A%22Home%22") || strings.Contains(url, "heading") {
+		t.Fatalf("expected Home to stay an area: %s", url)
+	}
+}
+
+func TestImportTodoistDryRunPrintsRepeatsAndReport(t *testing.T) {
+	dbPath := writeTestDB(t)
+	path := filepath.Join(t.TempDir(), "Chores.csv")
+	csv := "TYPE,CONTENT,DESCRIPTION,PRIORITY,INDENT,AUTHOR,RESPONSIBLE,DATE,DATE_LANG,TIMEZONE\n" +
+		"task,Take out bins @urgent @outside,,2,1,Me,,every mon at 7pm,en,UTC\n" +
+		"section,Weekend,,,,,,,,\n" +
+		"task,Mow lawn,,4,1,Me,Sam,,en,UTC\n"
+	if err := os.WriteFile(path, []byte(csv), 0o600); err != nil {
+		t.Fatalf("write csv: %v", err)
+	}
+
+	launcher := &recordLauncher{}
+	out := &bytes.Buffer{}
+	errOut := &bytes.Buffer{}
+	app := &App{In: strings.NewReader(""), Out: out, Err: errOut, Launcher: launcher}
+	root := NewRoot(app)
+	root.SetArgs([]string{"--dry-run", "import", "todoist", "--db", dbPath, path})
+	root.SetOut(app.Out)
+	root.SetErr(app.Err)
+	if err := root.Execute(); err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	if len(launcher.args) != 0 {
+		t.Fatalf("expected no open invocation in dry-run")
+	}
+
+	want := strings.Join([]string{
+		"- Chores (project)",
+		"  - Take out bins (to-do) tags=urgent,outside,p2",
+		"  - Weekend (heading)",
+		"  - Mow lawn (to-do)",
+		"repeat Take out bins: ",
+	}, "\n")
+	if !strings.HasPrefix(out.String(), want) || !strings.Contains(out.String(), "things:///json?data=") {
+		t.Fatalf("unexpected dry-run output:\n%s", out.String())
+	}
+	for _, want := range []string{"Note: repeats are skipped in --dry-run mode.", "Not imported (4):", "\"at 7pm\"", "RESPONSIBLE", "outside", "p2"} {
+		if !strings.Contains(errOut.String(), want) {
+			t.Fatalf("expected %q in report:\n%s", want, errOut.String())
+		}
+	}
+}
+
+func TestImportOmniFocusAppliesRepeatRules(t *testing.T) {
+	dbPath := writeTestDB(t)
+	conn, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	// Stands in for the todo Things creates from the JSON batch.
+	if _, err := conn.Exec(`INSERT INTO TMTask (uuid, type, status, trashed, title, start, creationDate) VALUES ('WATER1', 0, 0, 0, 'Water plants', 1, ?)`, float64(time.Now().Unix())); err != nil {
+		t.Fatalf("insert task: %v", err)
+	}
+	conn.Close()
+
+	doc := "- Water plants @repeat-method(fixed) @repeat-rule(FREQ=WEEKLY;BYDAY=SA)\n"
+	launcher := &recordLauncher{}
+	errOut := &bytes.Buffer{}
+	app := &App{In: strings.NewReader(doc), Out: &bytes.Buffer{}, Err: errOut, Launcher: launcher}
+	root := NewRoot(app)
+	root.SetArgs([]string{"import", "omnifocus", "--db", dbPath})
+	root.SetOut(app.Out)
+	root.SetErr(app.Err)
+	if err := root.Execute(); err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	url := requireOpenURL(t, launcher)
+	if !strings.Contains(url, "Water%20plants") {
+		t.Fatalf("unexpected url: %s", url)
+	}
+	if errOut.Len() != 0 {
+		t.Fatalf("unexpected report:\n%s", errOut.String())
+	}
+
+	conn, err = sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer conn.Close()
+	var rule []byte
+	if err := conn.QueryRow(`SELECT rt1_recurrenceRule FROM TMTask WHERE uuid = 'WATER1'`).Scan(&rule); err != nil {
+		t.Fatalf("read rule: %v", err)
+	}
+	if len(rule) == 0 {
+		t.Fatalf("expected repeat rule to be applied")
+	}
+}
@@ -56,7 +56,10 @@ func NewRepeatingCommand(app *App) *cobra.Command {
 			if err != nil {
 				return err
 			}
-			tasks, err := fetchTasks(store, store.Tasks, opts, false, []int{db.TaskTypeTodo})
+			if len(outputOpts.Select) == 0 && (outputOpts.Format == "table" || outputOpts.Format == "csv") {
+				outputOpts.Select = defaultRepeatingTableFields
+			}
+			tasks, err := fetchTasks(store, store.Tasks, opts, false, []int{db.TaskTypeTodo, db.TaskTypeProject})
 			if err != nil {
 				return formatDBError(err)
 			}
@@ -72,6 +75,17 @@ func NewRepeatingCommand(app *App) *cobra.Command {
 	return cmd
 }
 
+var defaultRepeatingTableFields = []string{
+	"uuid",
+	"type",
+	"title",
+	"project",
+	"area",
+	"repeat_rule",
+	"repeat_next",
+	"repeat_until",
+}
+
 func NewDeadlinesCommand(app *App) *cobra.Command {
 	return newTaskListCommand(app, "deadlines", "List tasks with deadlines from the Things database", "incomplete", func(store *db.Store, filter db.TaskFilter) ([]db.Task, error) {
 		return store.DeadlinesTasks(filter)
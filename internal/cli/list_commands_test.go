@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ossianhempel/things3-cli/internal/db"
+	"github.com/ossianhempel/things3-cli/internal/repeat"
+)
+
+func TestRepeatingCommandDescribesRule(t *testing.T) {
+	dbPath := writeTestDB(t)
+	end := time.Date(2026, 6, 30, 0, 0, 0, 0, time.Local)
+	update, err := repeat.BuildUpdate(repeat.Spec{
+		Mode:    repeat.ModeAfterCompletion,
+		Unit:    repeat.UnitWeek,
+		Every:   2,
+		Anchor:  time.Date(2026, 1, 5, 0, 0, 0, 0, time.Local),
+		EndDate: &end,
+	})
+	if err != nil {
+		t.Fatalf("BuildUpdate failed: %v", err)
+	}
+	conn, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	next := thingsDate(time.Date(2026, 1, 19, 0, 0, 0, 0, time.Local))
+	if _, err := conn.Exec(`UPDATE TMTask SET rt1_recurrenceRule = ?, rt1_nextInstanceStartDate = ? WHERE uuid = 'ANY1'`, update.RecurrenceRule, next); err != nil {
+		t.Fatalf("set rule: %v", err)
+	}
+	conn.Close()
+
+	out := &bytes.Buffer{}
+	app := &App{
+		In:  strings.NewReader(""),
+		Out: out,
+		Err: &bytes.Buffer{},
+	}
+	root := NewRoot(app)
+	root.SetArgs([]string{"repeating", "--db", dbPath})
+	root.SetOut(app.Out)
+	root.SetErr(app.Err)
+	if err := root.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	table := out.String()
+	for _, want := range []string{"REPEAT_RULE", "every 2 weeks on Monday until 2026-06-30 (after completion)", "2026-01-19"} {
+		if !strings.Contains(table, want) {
+			t.Fatalf("expected %q in output:\n%s", want, table)
+		}
+	}
+
+	out.Reset()
+	root = NewRoot(app)
+	root.SetArgs([]string{"repeating", "--db", dbPath, "--select", "uuid,repeat_rule,repeat_next,repeat_until", "--json"})
+	root.SetOut(app.Out)
+	root.SetErr(app.Err)
+	if err := root.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var records []map[string]any
+	if err := json.Unmarshal(out.Bytes(), &records); err != nil {
+		t.Fatalf("decode output: %v", err)
+	}
+	if len(records) != 1 || records[0]["uuid"] != "ANY1" || records[0]["repeat_next"] != "2026-01-19" || records[0]["repeat_until"] != "2026-06-30" {
+		t.Fatalf("unexpected records: %v", records)
+	}
+}
+
+func TestAnnotateRepeatRulesSkipsPlainTasks(t *testing.T) {
+	tasks := []db.Task{{UUID: "T1", Title: "Plain"}}
+	if err := annotateRepeatRules(nil, tasks); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tasks[0].RepeatRule != "" {
+		t.Fatalf("unexpected repeat rule %q", tasks[0].RepeatRule)
+	}
+}
+
+func TestTasksCommandFuzzyProjectFilter(t *testing.T) {
+	dbPath := writeTestDB(t)
+	out := &bytes.Buffer{}
+	app := &App{
+		In:  strings.NewReader(""),
+		Out: out,
+		Err: &bytes.Buffer{},
+	}
+	root := NewRoot(app)
+	root.SetArgs([]string{"tasks", "--db", dbPath, "--project", "proj on", "--select", "uuid", "--no-header"})
+	root.SetOut(app.Out)
+	root.SetErr(app.Err)
+	if err := root.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := strings.TrimSpace(out.String()); got != "T1" {
+		t.Fatalf("expected T1, got %q", got)
+	}
+
+	root = NewRoot(app)
+	root.SetArgs([]string{"tasks", "--db", dbPath, "--project", "proj on", "--exact"})
+	root.SetOut(app.Out)
+	root.SetErr(app.Err)
+	if err := root.Execute(); err == nil || !strings.Contains(err.Error(), "project not found: proj on") {
+		t.Fatalf("expected --exact to reject a partial title, got %v", err)
+	}
+}
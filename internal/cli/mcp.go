@@ -0,0 +1,509 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ossianhempel/things3-cli/internal/db"
+	"github.com/ossianhempel/things3-cli/internal/things"
+	"github.com/spf13/cobra"
+)
+
+const mcpDefaultProtocolVersion = "2024-11-05"
+
+var mcpProtocolVersions = []string{"2024-11-05", "2025-03-26", "2025-06-18"}
+
+// JSON-RPC error codes used by the MCP server.
+const (
+	mcpParseError     = -32700
+	mcpInvalidRequest = -32600
+	mcpMethodNotFound = -32601
+	mcpInvalidParams  = -32602
+)
+
+// NewMCPCommand builds the mcp command.
+func NewMCPCommand(app *App) *cobra.Command {
+	var dbPath string
+
+	cmd := &cobra.Command{
+		Use:   "mcp [OPTIONS...]",
+		Short: "Run a Model Context Protocol server over stdio",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			server := &mcpServer{app: app, dbPath: dbPath}
+			defer server.close()
+			return server.serve(app.In, app.Out)
+		},
+	}
+
+	cmd.Flags().StringVarP(&dbPath, "db", "d", "", "Path to Things database (overrides THINGSDB)")
+	cmd.Flags().StringVar(&dbPath, "database", "", "Alias for --db")
+
+	return cmd
+}
+
+type mcpRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type mcpResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *mcpError       `json:"error,omitempty"`
+}
+
+type mcpError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type mcpTool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	InputSchema map[string]any `json:"inputSchema"`
+}
+
+type mcpContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type mcpToolResult struct {
+	Content []mcpContent `json:"content"`
+	IsError bool         `json:"isError,omitempty"`
+}
+
+// mcpWriteArgs are the arguments accepted by the write tools.
+type mcpWriteArgs struct {
+	todoRequest
+	ID     string `json:"id"`
+	DryRun bool   `json:"dry_run"`
+}
+
+type mcpServer struct {
+	app    *App
+	dbPath string
+	store  *db.Store
+}
+
+func (s *mcpServer) serve(in io.Reader, out io.Writer) error {
+	reader := bufio.NewReader(in)
+	enc := json.NewEncoder(out)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			if resp := s.handle(line); resp != nil {
+				if encErr := enc.Encode(resp); encErr != nil {
+					return encErr
+				}
+			}
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+func (s *mcpServer) handle(line []byte) *mcpResponse {
+	var req mcpRequest
+	if err := json.Unmarshal(line, &req); err != nil {
+		return mcpErrorResponse(json.RawMessage("null"), mcpParseError, "parse error")
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return mcpErrorResponse(idOrNull(req.ID), mcpInvalidRequest, "invalid request")
+	}
+	// Notifications carry no id and never get a response.
+	if len(req.ID) == 0 {
+		return nil
+	}
+
+	switch req.Method {
+	case "initialize":
+		return mcpResult(req.ID, s.initialize(req.Params))
+	case "ping":
+		return mcpResult(req.ID, map[string]any{})
+	case "tools/list":
+		return mcpResult(req.ID, map[string]any{"tools": mcpTools()})
+	case "tools/call":
+		var params struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return mcpErrorResponse(req.ID, mcpInvalidParams, "invalid params")
+		}
+		result, known := s.callTool(params.Name, params.Arguments)
+		if !known {
+			return mcpErrorResponse(req.ID, mcpInvalidParams, fmt.Sprintf("unknown tool %q", params.Name))
+		}
+		return mcpResult(req.ID, result)
+	default:
+		return mcpErrorResponse(req.ID, mcpMethodNotFound, fmt.Sprintf("method not found: %s", req.Method))
+	}
+}
+
+func (s *mcpServer) initialize(params json.RawMessage) map[string]any {
+	var init struct {
+		ProtocolVersion string `json:"protocolVersion"`
+	}
+	_ = json.Unmarshal(params, &init)
+	version := mcpDefaultProtocolVersion
+	for _, supported := range mcpProtocolVersions {
+		if init.ProtocolVersion == supported {
+			version = supported
+		}
+	}
+	return map[string]any{
+		"protocolVersion": version,
+		"capabilities":    map[string]any{"tools": map[string]any{}},
+		"serverInfo":      map[string]any{"name": "things3-cli", "version": Version},
+	}
+}
+
+func (s *mcpServer) callTool(name string, raw json.RawMessage) (mcpToolResult, bool) {
+	var value any
+	var err error
+	switch name {
+	case "list_today":
+		value, err = s.listToday(raw)
+	case "search_tasks":
+		value, err = s.searchTasks(raw)
+	case "show_item":
+		value, err = s.showItem(raw)
+	case "add_todo":
+		value, err = s.addTodo(raw)
+	case "update_todo":
+		value, err = s.updateTodo(raw, false)
+	case "complete_todo":
+		value, err = s.updateTodo(raw, true)
+	default:
+		return mcpToolResult{}, false
+	}
+	if err != nil {
+		return mcpToolResult{
+			Content: []mcpContent{{Type: "text", Text: strings.TrimPrefix(err.Error(), "Error: ")}},
+			IsError: true,
+		}, true
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return mcpToolResult{Content: []mcpContent{{Type: "text", Text: err.Error()}}, IsError: true}, true
+	}
+	return mcpToolResult{Content: []mcpContent{{Type: "text", Text: string(data)}}}, true
+}
+
+func (s *mcpServer) openStore() (*db.Store, error) {
+	if s.store != nil {
+		return s.store, nil
+	}
+	store, _, err := db.OpenDefault(s.dbPath)
+	if err != nil {
+		return nil, formatDBError(err)
+	}
+	s.store = store
+	return store, nil
+}
+
+func (s *mcpServer) close() {
+	if s.store != nil {
+		s.store.Close()
+		s.store = nil
+	}
+}
+
+func (s *mcpServer) listToday(raw json.RawMessage) (any, error) {
+	var args struct {
+		Query string `json:"query"`
+		Limit int    `json:"limit"`
+	}
+	if err := decodeMCPArgs(raw, &args); err != nil {
+		return nil, err
+	}
+	store, err := s.openStore()
+	if err != nil {
+		return nil, err
+	}
+	opts := TaskQueryOptions{Status: "incomplete", Query: args.Query, Limit: mcpLimit(args.Limit)}
+	tasks, err := fetchTasks(store, store.TodayTasks, opts, opts.Query != "", []int{db.TaskTypeTodo})
+	if err != nil {
+		return nil, err
+	}
+	return nonNilTasks(tasks), nil
+}
+
+func (s *mcpServer) searchTasks(raw json.RawMessage) (any, error) {
+	var args struct {
+		Query   string `json:"query"`
+		Status  string `json:"status"`
+		Project string `json:"project"`
+		Area    string `json:"area"`
+		Tag     string `json:"tag"`
+		Limit   int    `json:"limit"`
+	}
+	if err := decodeMCPArgs(raw, &args); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(args.Query) == "" {
+		return nil, errors.New("Error: query required")
+	}
+	if _, err := parseRichQuery(args.Query); err != nil {
+		return nil, err
+	}
+	store, err := s.openStore()
+	if err != nil {
+		return nil, err
+	}
+	status := args.Status
+	if status == "" {
+		status = "incomplete"
+	}
+	opts := TaskQueryOptions{
+		Status:  status,
+		Project: args.Project,
+		Area:    args.Area,
+		Tag:     args.Tag,
+		Query:   args.Query,
+		Limit:   mcpLimit(args.Limit),
+	}
+	tasks, err := fetchTasks(store, store.Tasks, opts, false, []int{db.TaskTypeTodo})
+	if err != nil {
+		return nil, err
+	}
+	return nonNilTasks(tasks), nil
+}
+
+func (s *mcpServer) showItem(raw json.RawMessage) (any, error) {
+	var args struct {
+		ID string `json:"id"`
+	}
+	if err := decodeMCPArgs(raw, &args); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(args.ID) == "" {
+		return nil, errors.New("Error: id required")
+	}
+	store, err := s.openStore()
+	if err != nil {
+		return nil, err
+	}
+	item, err := store.ItemByID(args.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("Error: item not found")
+		}
+		return nil, err
+	}
+	if item.Type != "to-do" {
+		return item, nil
+	}
+	task, err := store.TaskByID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+func (s *mcpServer) addTodo(raw json.RawMessage) (any, error) {
+	var args mcpWriteArgs
+	if err := decodeMCPArgs(raw, &args); err != nil {
+		return nil, err
+	}
+	title := strings.TrimSpace(args.Title)
+	if title == "" {
+		return nil, errors.New("Error: title required")
+	}
+	if err := validateWhenInput(args.When); err != nil {
+		return nil, err
+	}
+	return s.dispatchURL(things.BuildAddURL(args.addOptions(), title), args.DryRun)
+}
+
+func (s *mcpServer) updateTodo(raw json.RawMessage, complete bool) (any, error) {
+	var args mcpWriteArgs
+	if err := decodeMCPArgs(raw, &args); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(args.ID) == "" {
+		return nil, errors.New("Error: id required")
+	}
+	if err := validateWhenInput(args.When); err != nil {
+		return nil, err
+	}
+	opts := args.updateOptions(args.ID)
+	title := strings.TrimSpace(args.Title)
+	if complete {
+		opts = things.UpdateOptions{ID: args.ID, Completed: true}
+		title = ""
+	}
+	// Dry runs send nothing, so they preview the URL with a stand-in token
+	// rather than requiring (and echoing) the real one.
+	opts.AuthToken = mcpDryRunToken
+	if !args.DryRun && !s.app.DryRun {
+		token, err := resolveAuthToken(s.app, "")
+		if err != nil {
+			return nil, err
+		}
+		opts.AuthToken = token
+	}
+	url, err := things.BuildUpdateURL(opts, title)
+	if err != nil {
+		return nil, err
+	}
+	return s.dispatchURL(url, args.DryRun)
+}
+
+// mcpDryRunToken stands in for the auth token in dry-run update URLs.
+const mcpDryRunToken = "AUTH_TOKEN"
+
+// dispatchURL opens url unless this is a dry run. openURL is not used for dry
+// runs because it prints to stdout, which carries the protocol stream.
+func (s *mcpServer) dispatchURL(url string, dryRun bool) (any, error) {
+	if dryRun || s.app.DryRun {
+		return map[string]any{"url": url, "dry_run": true}, nil
+	}
+	if err := openURL(s.app, url); err != nil {
+		return nil, err
+	}
+	return map[string]any{"url": url, "dry_run": false}, nil
+}
+
+func decodeMCPArgs(raw json.RawMessage, v any) error {
+	if len(bytes.TrimSpace(raw)) == 0 || string(raw) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("Error: invalid arguments: %v", err)
+	}
+	return nil
+}
+
+func mcpLimit(limit int) int {
+	if limit <= 0 {
+		return 50
+	}
+	return limit
+}
+
+func mcpResult(id json.RawMessage, result any) *mcpResponse {
+	return &mcpResponse{JSONRPC: "2.0", ID: id, Result: result}
+}
+
+func mcpErrorResponse(id json.RawMessage, code int, message string) *mcpResponse {
+	return &mcpResponse{JSONRPC: "2.0", ID: id, Error: &mcpError{Code: code, Message: message}}
+}
+
+func idOrNull(id json.RawMessage) json.RawMessage {
+	if len(id) == 0 {
+		return json.RawMessage("null")
+	}
+	return id
+}
+
+func mcpTools() []mcpTool {
+	str := func(description string) map[string]any {
+		return map[string]any{"type": "string", "description": description}
+	}
+	strList := func(description string) map[string]any {
+		return map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "description": description}
+	}
+	integer := func(description string) map[string]any {
+		return map[string]any{"type": "integer", "description": description}
+	}
+	boolean := func(description string) map[string]any {
+		return map[string]any{"type": "boolean", "description": description}
+	}
+	schema := func(props map[string]any, required ...string) map[string]any {
+		out := map[string]any{"type": "object", "properties": props}
+		if len(required) > 0 {
+			out["required"] = required
+		}
+		return out
+	}
+	dryRun := boolean("Return the things:/// URL without opening it")
+
+	return []mcpTool{
+		{
+			Name:        "list_today",
+			Description: "List incomplete todos in the Things Today list.",
+			InputSchema: schema(map[string]any{
+				"query": str("Optional rich query to filter results (e.g. tag:work AND NOT project:Home)"),
+				"limit": integer("Maximum number of results (default 50)"),
+			}),
+		},
+		{
+			Name:        "search_tasks",
+			Description: "Search todos with the rich query syntax: field:value (title, notes, tag, project, area, heading, id, url, repeating), /regex/, AND, OR, NOT, and parentheses.",
+			InputSchema: schema(map[string]any{
+				"query":   str("Rich query, e.g. title:/invoice/ AND tag:finance"),
+				"status":  str("incomplete (default), completed, canceled, or any"),
+				"project": str("Restrict to a project title or ID"),
+				"area":    str("Restrict to an area title or ID"),
+				"tag":     str("Restrict to a tag title or ID"),
+				"limit":   integer("Maximum number of results (default 50)"),
+			}, "query"),
+		},
+		{
+			Name:        "show_item",
+			Description: "Show a todo, project, heading, area, or tag by ID.",
+			InputSchema: schema(map[string]any{
+				"id": str("Things item ID"),
+			}, "id"),
+		},
+		{
+			Name:        "add_todo",
+			Description: "Create a todo in Things.",
+			InputSchema: schema(map[string]any{
+				"title":     str("Todo title"),
+				"notes":     str("Notes"),
+				"when":      str("today, tomorrow, evening, someday, or a date"),
+				"deadline":  str("Deadline date (YYYY-MM-DD)"),
+				"tags":      strList("Tag titles"),
+				"checklist": strList("Checklist item titles"),
+				"list":      str("Project or area title to add to"),
+				"list_id":   str("Project or area ID to add to"),
+				"heading":   str("Heading title within the project"),
+				"dry_run":   dryRun,
+			}, "title"),
+		},
+		{
+			Name:        "update_todo",
+			Description: "Update an existing todo in Things (requires THINGS_AUTH_TOKEN unless dry_run is set).",
+			InputSchema: schema(map[string]any{
+				"id":           str("Todo ID"),
+				"title":        str("New title"),
+				"notes":        str("Replace notes"),
+				"append_notes": str("Append to notes"),
+				"when":         str("today, tomorrow, evening, someday, or a date"),
+				"deadline":     str("Deadline date (YYYY-MM-DD)"),
+				"tags":         strList("Replace tags"),
+				"add_tags":     strList("Add tags"),
+				"list":         str("Move to project or area title"),
+				"list_id":      str("Move to project or area ID"),
+				"heading":      str("Move under heading title"),
+				"canceled":     boolean("Cancel the todo"),
+				"dry_run":      dryRun,
+			}, "id"),
+		},
+		{
+			Name:        "complete_todo",
+			Description: "Mark a todo as completed in Things (requires THINGS_AUTH_TOKEN).",
+			InputSchema: schema(map[string]any{
+				"id":      str("Todo ID"),
+				"dry_run": dryRun,
+			}, "id"),
+		},
+	}
+}
@@ -0,0 +1,170 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func runMCP(t *testing.T, app *App, args []string, requests ...string) []mcpResponse {
+	t.Helper()
+	app.In = strings.NewReader(strings.Join(requests, "\n") + "\n")
+	out := &bytes.Buffer{}
+	app.Out = out
+
+	root := NewRoot(app)
+	root.SetArgs(append([]string{"mcp"}, args...))
+	root.SetOut(app.Out)
+	root.SetErr(app.Err)
+	if err := root.Execute(); err != nil {
+		t.Fatalf("execute failed: %v", err)
+	}
+
+	var responses []mcpResponse
+	scanner := bufio.NewScanner(out)
+	for scanner.Scan() {
+		var resp mcpResponse
+		if err := json.Unmarshal(scanner.Bytes(), &resp); err != nil {
+			t.Fatalf("decode response %q: %v", scanner.Text(), err)
+		}
+		responses = append(responses, resp)
+	}
+	return responses
+}
+
+func mcpToolText(t *testing.T, resp mcpResponse) (string, bool) {
+	t.Helper()
+	if resp.Error != nil {
+		t.Fatalf("unexpected error response: %+v", resp.Error)
+	}
+	data, _ := json.Marshal(resp.Result)
+	var result mcpToolResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		t.Fatalf("decode tool result: %v", err)
+	}
+	if len(result.Content) != 1 {
+		t.Fatalf("expected one content block, got %+v", result)
+	}
+	return result.Content[0].Text, result.IsError
+}
+
+func TestMCPInitializeAndListTools(t *testing.T) {
+	app := &App{Err: &bytes.Buffer{}}
+	responses := runMCP(t, app, nil,
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{}}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"bogus"}`,
+		`not json`,
+	)
+	if len(responses) != 4 {
+		t.Fatalf("expected 4 responses, got %d", len(responses))
+	}
+
+	init, _ := json.Marshal(responses[0].Result)
+	if !strings.Contains(string(init), `"protocolVersion":"2025-03-26"`) || !strings.Contains(string(init), `"tools"`) {
+		t.Fatalf("unexpected initialize result: %s", init)
+	}
+
+	tools, _ := json.Marshal(responses[1].Result)
+	for _, name := range []string{"list_today", "search_tasks", "add_todo", "update_todo", "complete_todo", "show_item"} {
+		if !strings.Contains(string(tools), `"name":"`+name+`"`) {
+			t.Fatalf("expected tool %s in %s", name, tools)
+		}
+	}
+
+	if responses[2].Error == nil || responses[2].Error.Code != mcpMethodNotFound {
+		t.Fatalf("expected method not found, got %+v", responses[2])
+	}
+	if responses[3].Error == nil || responses[3].Error.Code != mcpParseError {
+		t.Fatalf("expected parse error, got %+v", responses[3])
+	}
+}
+
+func TestMCPReadTools(t *testing.T) {
+	dbPath := writeTestDB(t)
+	app := &App{Err: &bytes.Buffer{}}
+	responses := runMCP(t, app, []string{"--db", dbPath},
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"list_today","arguments":{}}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"search_tasks","arguments":{"query":"tag:urgent OR title:/^Deadline/"}}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"show_item","arguments":{"id":"P1"}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"search_tasks","arguments":{"query":"title:("}}}`,
+	)
+	if len(responses) != 4 {
+		t.Fatalf("expected 4 responses, got %d", len(responses))
+	}
+
+	text, _ := mcpToolText(t, responses[0])
+	if !strings.Contains(text, "Today Task") {
+		t.Fatalf("expected Today Task in %s", text)
+	}
+	text, _ = mcpToolText(t, responses[1])
+	if !strings.Contains(text, "Task One") || !strings.Contains(text, "Deadline Task") || strings.Contains(text, "Inbox Task") {
+		t.Fatalf("unexpected search result: %s", text)
+	}
+	text, _ = mcpToolText(t, responses[2])
+	if !strings.Contains(text, `"type":"project"`) || !strings.Contains(text, "Project One") {
+		t.Fatalf("unexpected show result: %s", text)
+	}
+	if _, isError := mcpToolText(t, responses[3]); !isError {
+		t.Fatalf("expected tool error for invalid query")
+	}
+}
+
+func TestMCPUpdateDryRunSkipsAuthToken(t *testing.T) {
+	t.Setenv("THINGS_AUTH_TOKEN", "")
+	setConfigHome(t)
+	launcher := &recordLauncher{}
+	app := &App{Err: &bytes.Buffer{}, Launcher: launcher}
+	responses := runMCP(t, app, nil,
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"update_todo","arguments":{"id":"T1","title":"Renamed","dry_run":true}}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"update_todo","arguments":{"id":"T1","title":"Renamed"}}}`,
+	)
+	if len(responses) != 2 {
+		t.Fatalf("expected 2 responses, got %d", len(responses))
+	}
+	text, isError := mcpToolText(t, responses[0])
+	if isError || !strings.Contains(text, "things:///update?") || !strings.Contains(text, "auth-token=AUTH_TOKEN") {
+		t.Fatalf("unexpected dry-run result: %s", text)
+	}
+	if _, isError := mcpToolText(t, responses[1]); !isError {
+		t.Fatalf("expected tool error without an auth token")
+	}
+	if len(launcher.args) != 0 {
+		t.Fatalf("expected nothing opened, got %v", launcher.args)
+	}
+}
+
+func TestMCPWriteToolsDryRun(t *testing.T) {
+	t.Setenv("THINGS_AUTH_TOKEN", "tok")
+	launcher := &recordLauncher{}
+	app := &App{Err: &bytes.Buffer{}, Launcher: launcher}
+	responses := runMCP(t, app, nil,
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"add_todo","arguments":{"title":"Buy milk","tags":["errand"],"dry_run":true}}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"complete_todo","arguments":{"id":"T1","dry_run":true}}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"update_todo","arguments":{"id":"T1","when":"tomorrow"}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"nope","arguments":{}}}`,
+	)
+	if len(responses) != 4 {
+		t.Fatalf("expected 4 responses, got %d", len(responses))
+	}
+
+	text, _ := mcpToolText(t, responses[0])
+	if !strings.Contains(text, "things:///add?") || !strings.Contains(text, "Buy%20milk") || !strings.Contains(text, `"dry_run":true`) {
+		t.Fatalf("unexpected add result: %s", text)
+	}
+	text, _ = mcpToolText(t, responses[1])
+	if !strings.Contains(text, "completed=true") {
+		t.Fatalf("unexpected complete result: %s", text)
+	}
+
+	url := requireOpenURL(t, launcher)
+	if !strings.Contains(url, "things:///update?") || !strings.Contains(url, "when=tomorrow") {
+		t.Fatalf("unexpected update url: %q", url)
+	}
+	if responses[3].Error == nil || responses[3].Error.Code != mcpInvalidParams {
+		t.Fatalf("expected invalid params for unknown tool, got %+v", responses[3])
+	}
+}
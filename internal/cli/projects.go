@@ -18,6 +18,7 @@ func NewProjectsCommand(app *App) *cobra.Command {
 	var noHeader bool
 	var recursive bool
 	var onlyProjects bool
+	var exact bool
 
 	cmd := &cobra.Command{
 		Use:   "projects",
@@ -44,7 +45,7 @@ func NewProjectsCommand(app *App) *cobra.Command {
 
 			areaID := ""
 			if area != "" {
-				areaID, err = store.ResolveAreaID(area)
+				areaID, err = resolveNamed(store.ResolveAreaID, store.ResolveAreaIDFuzzy, exact, area)
 				if err != nil {
 					return fmt.Errorf("Error: %s", err)
 				}
@@ -81,6 +82,7 @@ func NewProjectsCommand(app *App) *cobra.Command {
 	cmd.Flags().StringVar(&status, "status", "incomplete", "Filter by status: incomplete, completed, canceled, any")
 	cmd.Flags().StringVarP(&area, "filter-area", "a", "", "Filter by area title or ID")
 	cmd.Flags().StringVar(&area, "area", "", "Alias for --filter-area")
+	cmd.Flags().BoolVar(&exact, "exact", false, "Match the area name exactly instead of fuzzily")
 	cmd.Flags().BoolVar(&includeTrashed, "include-trashed", false, "Include trashed projects")
 	cmd.Flags().BoolVar(&all, "all", false, "Include completed, canceled, and trashed projects")
 	cmd.Flags().BoolVarP(&asJSON, "json", "j", false, "Output JSON")
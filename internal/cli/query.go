@@ -0,0 +1,319 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/ossianhempel/things3-cli/internal/db"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// NewQueryCommand builds the query command and its subcommands.
+func NewQueryCommand(app *App) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "query <save|run|list|edit|delete> [ARGS...]",
+		Short: "Manage saved task queries",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			printHelp(app.Out, formatHelpText(queryHelp, isTTY(app.Out)))
+			return ErrHelpPrinted
+		},
+	}
+
+	cmd.AddCommand(newQuerySaveCommand(app))
+	cmd.AddCommand(newQueryRunCommand(app, "run NAME", ""))
+	cmd.AddCommand(newQueryListCommand(app))
+	cmd.AddCommand(newQueryEditCommand(app))
+	cmd.AddCommand(newQueryDeleteCommand(app))
+	return cmd
+}
+
+// addSavedQueryShortcuts registers a hidden @NAME command for each saved
+// query so `things @work` runs it.
+func addSavedQueryShortcuts(app *App, root *cobra.Command) {
+	queries, err := loadSavedQueries()
+	if err != nil {
+		return
+	}
+	for _, name := range sortedQueryNames(queries) {
+		shortcut := newQueryRunCommand(app, "@"+name, name)
+		shortcut.Hidden = true
+		root.AddCommand(shortcut)
+	}
+}
+
+func bindSavedQueryFlags(cmd *cobra.Command, q *SavedQuery) {
+	addTaskQueryFlags(cmd, &q.Options, true, true)
+	addTaskOutputFlags(cmd, &q.Format, &q.Select, &q.JSON, &q.NoHeader)
+}
+
+func newQuerySaveCommand(app *App) *cobra.Command {
+	q := SavedQuery{Options: TaskQueryOptions{Status: "incomplete"}}
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "save NAME [OPTIONS...]",
+		Short: "Save a task query under a name",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			if err := validateSavedQueryName(name); err != nil {
+				return err
+			}
+			queries, err := loadSavedQueries()
+			if err != nil {
+				return fmt.Errorf("Error: %v", err)
+			}
+			if _, ok := queries[name]; ok && !force {
+				return fmt.Errorf("Error: query %q already exists (use --force or query edit)", name)
+			}
+			flags := cmd.Flags()
+			q.Options.HasURLSet = flags.Changed("has-url")
+			// Keep config defaults out of the saved query.
+			if !flags.Changed("format") {
+				q.Format = ""
+			}
+			q.LimitSet = flags.Changed("limit")
+			if !q.LimitSet {
+				q.Options.Limit = 0
+			}
+			if err := validateSavedQuery(q); err != nil {
+				return err
+			}
+			queries[name] = q
+			if app.DryRun {
+				fmt.Fprintf(app.Out, "Would save @%s: %s\n", name, describeSavedQuery(q))
+				return nil
+			}
+			if err := writeSavedQueries(queries); err != nil {
+				return fmt.Errorf("Error: %v", err)
+			}
+			return nil
+		},
+	}
+
+	bindSavedQueryFlags(cmd, &q)
+	cmd.Flags().BoolVar(&force, "force", false, "Overwrite an existing query")
+	return cmd
+}
+
+func newQueryEditCommand(app *App) *cobra.Command {
+	var scratch SavedQuery
+
+	cmd := &cobra.Command{
+		Use:   "edit NAME [OPTIONS...]",
+		Short: "Change options of a saved query",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			queries, err := loadSavedQueries()
+			if err != nil {
+				return fmt.Errorf("Error: %v", err)
+			}
+			q, ok := queries[name]
+			if !ok {
+				return fmt.Errorf("Error: no saved query %q", name)
+			}
+			if err := applyChangedQueryFlags(cmd, &q); err != nil {
+				return err
+			}
+			if err := validateSavedQuery(q); err != nil {
+				return err
+			}
+			queries[name] = q
+			if app.DryRun {
+				fmt.Fprintf(app.Out, "Would save @%s: %s\n", name, describeSavedQuery(q))
+				return nil
+			}
+			if err := writeSavedQueries(queries); err != nil {
+				return fmt.Errorf("Error: %v", err)
+			}
+			return nil
+		},
+	}
+
+	bindSavedQueryFlags(cmd, &scratch)
+	return cmd
+}
+
+// applyChangedQueryFlags copies the flags set on cmd onto q, leaving the
+// other saved options untouched.
+func applyChangedQueryFlags(cmd *cobra.Command, q *SavedQuery) error {
+	var target SavedQuery
+	holder := &cobra.Command{}
+	bindSavedQueryFlags(holder, &target)
+	// Binding resets target to flag defaults; restore the saved values.
+	target = *q
+	var setErr error
+	changed := false
+	cmd.Flags().Visit(func(flag *pflag.Flag) {
+		if setErr != nil {
+			return
+		}
+		// Global flags such as --dry-run are not part of the query.
+		if dest := holder.Flags().Lookup(flag.Name); dest != nil {
+			changed = true
+			setErr = dest.Value.Set(flag.Value.String())
+		}
+	})
+	if setErr != nil {
+		return fmt.Errorf("Error: %v", setErr)
+	}
+	if !changed {
+		return fmt.Errorf("Error: no changes (pass query or output flags to edit)")
+	}
+	if cmd.Flags().Changed("has-url") {
+		target.Options.HasURLSet = true
+	}
+	if cmd.Flags().Changed("limit") {
+		target.LimitSet = true
+	}
+	*q = target
+	return nil
+}
+
+// validateSavedQuery checks the output options, sort, and rich query so bad
+// queries fail when saved rather than when run.
+func validateSavedQuery(q SavedQuery) error {
+	if _, err := resolveTaskOutputOptions(q.Format, q.JSON, q.Select, q.NoHeader); err != nil {
+		return err
+	}
+	if _, err := parseRichQuery(q.Options.Query); err != nil {
+		return err
+	}
+	if _, _, err := parseSortSpec(q.Options.Sort); err != nil {
+		return err
+	}
+	return nil
+}
+
+func newQueryRunCommand(app *App, use string, fixedName string) *cobra.Command {
+	var dbPath string
+	var limit int
+	var format string
+	var selectRaw string
+	var asJSON bool
+	var noHeader bool
+
+	cmd := &cobra.Command{
+		Use:   use,
+		Short: "Run a saved task query",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if fixedName != "" {
+				return cobra.NoArgs(cmd, args)
+			}
+			return cobra.ExactArgs(1)(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := fixedName
+			if name == "" {
+				name = args[0]
+			}
+			queries, err := loadSavedQueries()
+			if err != nil {
+				return fmt.Errorf("Error: %v", err)
+			}
+			q, ok := queries[name]
+			if !ok {
+				return fmt.Errorf("Error: no saved query %q", name)
+			}
+
+			flags := cmd.Flags()
+			if !flags.Changed("format") && !flags.Changed("json") && (q.Format != "" || q.JSON) {
+				format, asJSON = q.Format, q.JSON
+			}
+			if !flags.Changed("select") && q.Select != "" {
+				selectRaw = q.Select
+			}
+			if !flags.Changed("no-header") && q.NoHeader {
+				noHeader = true
+			}
+			opts := q.Options
+			if flags.Changed("limit") || !q.LimitSet && opts.Limit == 0 {
+				opts.Limit = limit
+			}
+
+			outputOpts, err := resolveTaskOutputOptions(format, asJSON, selectRaw, noHeader)
+			if err != nil {
+				return err
+			}
+			store, _, err := db.OpenDefault(dbPath)
+			if err != nil {
+				return formatDBError(err)
+			}
+			defer store.Close()
+
+			tasks, err := fetchTasks(store, store.Tasks, opts, false, []int{db.TaskTypeTodo})
+			if err != nil {
+				return formatDBError(err)
+			}
+			return printTasks(app.Out, tasks, outputOpts)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVarP(&dbPath, "db", "d", "", "Path to Things database (overrides THINGSDB)")
+	flags.StringVar(&dbPath, "database", "", "Alias for --db")
+	flags.IntVar(&limit, "limit", 200, "Limit number of results (0 = no limit); overrides the saved limit")
+	addTaskOutputFlags(cmd, &format, &selectRaw, &asJSON, &noHeader)
+	return cmd
+}
+
+func newQueryListCommand(app *App) *cobra.Command {
+	var asJSON bool
+	var noHeader bool
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List saved queries",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			queries, err := loadSavedQueries()
+			if err != nil {
+				return fmt.Errorf("Error: %v", err)
+			}
+			if asJSON {
+				return json.NewEncoder(app.Out).Encode(queries)
+			}
+			w := tabwriter.NewWriter(app.Out, 0, 2, 2, ' ', 0)
+			if !noHeader {
+				fmt.Fprintln(w, "NAME\tOPTIONS")
+			}
+			for _, name := range sortedQueryNames(queries) {
+				fmt.Fprintf(w, "%s\t%s\n", name, describeSavedQuery(queries[name]))
+			}
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Output JSON")
+	cmd.Flags().BoolVar(&noHeader, "no-header", false, "Suppress header row")
+	return cmd
+}
+
+func newQueryDeleteCommand(app *App) *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete NAME",
+		Short: "Delete a saved query",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			queries, err := loadSavedQueries()
+			if err != nil {
+				return fmt.Errorf("Error: %v", err)
+			}
+			if _, ok := queries[args[0]]; !ok {
+				return fmt.Errorf("Error: no saved query %q", args[0])
+			}
+			delete(queries, args[0])
+			if app.DryRun {
+				fmt.Fprintf(app.Out, "Would delete @%s\n", args[0])
+				return nil
+			}
+			if err := writeSavedQueries(queries); err != nil {
+				return fmt.Errorf("Error: %v", err)
+			}
+			return nil
+		},
+	}
+}
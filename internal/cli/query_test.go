@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestQuerySaveRunAndShortcut(t *testing.T) {
+	setConfigHome(t)
+	dbPath := writeTestDB(t)
+
+	if _, err := runCommand(t, &App{}, "query", "save", "urgent", "--query", "tag:urgent", "--select", "uuid,title", "--format", "csv"); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	if _, err := runCommand(t, &App{}, "query", "save", "urgent", "--query", "tag:other"); err == nil {
+		t.Fatalf("expected error saving over an existing query")
+	}
+
+	out, err := runCommand(t, &App{}, "query", "run", "urgent", "--db", dbPath)
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if out != "UUID,TITLE\nT1,Task One\n" {
+		t.Fatalf("unexpected run output %q", out)
+	}
+
+	out, err = runCommand(t, &App{}, "@urgent", "--db", dbPath, "--json")
+	if err != nil {
+		t.Fatalf("shortcut failed: %v", err)
+	}
+	var records []map[string]any
+	if err := json.Unmarshal([]byte(out), &records); err != nil {
+		t.Fatalf("decode output %q: %v", out, err)
+	}
+	if len(records) != 1 || records[0]["uuid"] != "T1" {
+		t.Fatalf("unexpected records: %v", records)
+	}
+
+	if _, err := runCommand(t, &App{}, "query", "run", "missing", "--db", dbPath); err == nil {
+		t.Fatalf("expected error for unknown query")
+	}
+}
+
+func TestQueryEditListDelete(t *testing.T) {
+	setConfigHome(t)
+	if _, err := runCommand(t, &App{}, "query", "save", "work", "--query", "tag:work", "--sort", "deadline", "--limit", "10"); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	if _, err := runCommand(t, &App{}, "query", "edit", "work", "--filter-project", "Project One", "--json"); err != nil {
+		t.Fatalf("edit failed: %v", err)
+	}
+	queries, err := loadSavedQueries()
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	q := queries["work"]
+	if q.Options.Query != "tag:work" || q.Options.Sort != "deadline" || q.Options.Limit != 10 || q.Options.Project != "Project One" || !q.JSON {
+		t.Fatalf("unexpected edited query: %+v", q)
+	}
+	if _, err := runCommand(t, &App{}, "query", "edit", "work"); err == nil {
+		t.Fatalf("expected error for edit without changes")
+	}
+	if _, err := runCommand(t, &App{}, "query", "edit", "work", "--dry-run"); err == nil {
+		t.Fatalf("expected error for edit with only global flags")
+	}
+	if _, err := runCommand(t, &App{}, "query", "edit", "work", "--query", "tag:("); err == nil {
+		t.Fatalf("expected error for invalid rich query")
+	}
+
+	out, err := runCommand(t, &App{}, "query", "list")
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if !strings.Contains(out, `work  --query="tag:work" --filter-project="Project One" --sort="deadline" --limit=10 --json`) {
+		t.Fatalf("unexpected list output:\n%s", out)
+	}
+
+	if _, err := runCommand(t, &App{}, "query", "delete", "work"); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	if _, err := runCommand(t, &App{}, "query", "delete", "work"); err == nil {
+		t.Fatalf("expected error deleting a missing query")
+	}
+	if _, err := runCommand(t, &App{}, "query", "save", "bad name"); err == nil {
+		t.Fatalf("expected error for invalid name")
+	}
+}
+
+func TestQuerySaveKeepsExplicitNoLimit(t *testing.T) {
+	setConfigHome(t)
+	if _, err := runCommand(t, &App{}, "query", "save", "all", "--query", "tag:work", "--limit", "0"); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	queries, err := loadSavedQueries()
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if q := queries["all"]; !q.LimitSet || q.Options.Limit != 0 {
+		t.Fatalf("expected explicit no limit, got %+v", q)
+	}
+	out, err := runCommand(t, &App{}, "query", "list")
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if !strings.Contains(out, `all   --query="tag:work" --limit=0`) {
+		t.Fatalf("unexpected list output:\n%s", out)
+	}
+}
@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/ossianhempel/things3-cli/internal/db"
+	"github.com/spf13/cobra"
+)
+
+// NewRedoCommand builds the redo subcommand.
+func NewRedoCommand(app *App) *cobra.Command {
+	var authToken string
+	var dbPath string
+	var id int
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "redo",
+		Short: "Redo the last undone action",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			entries, err := readActions()
+			if err != nil {
+				return fmt.Errorf("Error: %s", err)
+			}
+			var entry ActionEntry
+			if cmd.Flags().Changed("id") {
+				entry, err = findAction(entries, id)
+				if err != nil {
+					return fmt.Errorf("Error: %s", err)
+				}
+				if entry.UndoneAt == "" {
+					return fmt.Errorf("Error: action %d has not been undone", id)
+				}
+			} else {
+				var ok bool
+				entry, ok = lastUndone(entries)
+				if !ok {
+					return fmt.Errorf("Error: no actions to redo")
+				}
+			}
+
+			items := entry.Items
+			switch entry.Type {
+			case ActionUpdate:
+				if len(entry.Redo) == 0 {
+					return fmt.Errorf("Error: action %d has no recorded state to redo", entry.ID)
+				}
+				items = entry.Redo
+			case ActionCreate:
+				if len(entry.Redo) > 0 {
+					items = entry.Redo
+				}
+			case ActionTrash:
+			default:
+				return fmt.Errorf("Error: unsupported action type %q", entry.Type)
+			}
+
+			if app.DryRun {
+				fmt.Fprintf(app.Out, "Would redo #%d %s for %d tasks\n", entry.ID, entry.Type, len(items))
+				return previewActionItems(app.Out, items)
+			}
+			if len(items) > 1 && !yes {
+				return fmt.Errorf("Error: %d tasks matched (rerun with --yes to apply)", len(items))
+			}
+
+			switch entry.Type {
+			case ActionUpdate:
+				token, err := resolveAuthToken(app, authToken)
+				if err != nil {
+					return err
+				}
+				var store *db.Store
+				if opened, _, err := db.OpenDefault(dbPath); err == nil {
+					store = opened
+					defer store.Close()
+				}
+				if err := applyActionItems(app, store, token, items); err != nil {
+					return err
+				}
+			case ActionTrash:
+				if err := trashActionItems(app, items); err != nil {
+					return err
+				}
+			case ActionCreate:
+				if err := restoreActionItems(app, authToken, items); err != nil {
+					return err
+				}
+			}
+
+			entry.UndoneAt = ""
+			entry.UndoSeq = 0
+			entry.Redo = nil
+			if err := saveAction(entry); err != nil {
+				fmt.Fprintf(app.Err, "Warning: failed to update action log: %v\n", err)
+			}
+			return nil
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&authToken, "auth-token", "", "Things URL scheme authorization token")
+	flags.StringVarP(&dbPath, "db", "d", "", "Path to Things database (overrides THINGSDB)")
+	flags.StringVar(&dbPath, "database", "", "Alias for --db")
+	flags.IntVar(&id, "id", 0, "Redo the action with this history ID")
+	flags.BoolVar(&yes, "yes", false, "Confirm redo for multiple tasks")
+
+	return cmd
+}
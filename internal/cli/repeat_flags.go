@@ -15,6 +15,7 @@ type RepeatOptions struct {
 	Every          int
 	Start          string
 	Until          string
+	On             string
 	DeadlineOffset int
 	Clear          bool
 }
@@ -33,6 +34,7 @@ func addRepeatFlags(cmd *cobra.Command, opts *RepeatOptions, allowClear bool) {
 	flags.IntVar(&opts.Every, "repeat-every", 1, "Repeat interval (every N units)")
 	flags.StringVar(&opts.Start, "repeat-start", "", "Repeat anchor date (YYYY-MM-DD)")
 	flags.StringVar(&opts.Until, "repeat-until", "", "Repeat until date (YYYY-MM-DD)")
+	flags.StringVar(&opts.On, "repeat-on", "", "Repeat days: weekdays (mon,wed,fri) or monthly days (15, 2nd-tue, last-day)")
 	flags.IntVar(&opts.DeadlineOffset, "repeat-deadline", 0, "Add repeating deadlines (days earlier)")
 	if allowClear {
 		flags.BoolVar(&opts.Clear, "repeat-clear", false, "Remove repeating schedule")
@@ -46,6 +48,7 @@ func parseRepeatSpec(cmd *cobra.Command, opts RepeatOptions) (RepeatSpec, error)
 			cmd.Flags().Changed("repeat-every") ||
 			cmd.Flags().Changed("repeat-start") ||
 			cmd.Flags().Changed("repeat-until") ||
+			cmd.Flags().Changed("repeat-on") ||
 			cmd.Flags().Changed("repeat-deadline") {
 			return RepeatSpec{}, fmt.Errorf("Error: --repeat-clear cannot be combined with other repeat flags")
 		}
@@ -57,6 +60,7 @@ func parseRepeatSpec(cmd *cobra.Command, opts RepeatOptions) (RepeatSpec, error)
 		cmd.Flags().Changed("repeat-every") ||
 		cmd.Flags().Changed("repeat-start") ||
 		cmd.Flags().Changed("repeat-until") ||
+		cmd.Flags().Changed("repeat-on") ||
 		cmd.Flags().Changed("repeat-deadline")
 
 	if !changed {
@@ -74,6 +78,10 @@ func parseRepeatSpec(cmd *cobra.Command, opts RepeatOptions) (RepeatSpec, error)
 	if err != nil {
 		return RepeatSpec{}, fmt.Errorf("Error: %v", err)
 	}
+	offsets, err := repeat.ParseOffsets(opts.On, unit)
+	if err != nil {
+		return RepeatSpec{}, fmt.Errorf("Error: --repeat-on: %v", err)
+	}
 	anchor := time.Now()
 	if opts.Start != "" {
 		parsed, _, err := parseDateOrTime(opts.Start)
@@ -104,6 +112,7 @@ func parseRepeatSpec(cmd *cobra.Command, opts RepeatOptions) (RepeatSpec, error)
 		Anchor:         anchor,
 		EndDate:        until,
 		DeadlineOffset: deadlineOffset,
+		Offsets:        offsets,
 	}
 
 	return RepeatSpec{Enabled: true, Spec: spec}, nil
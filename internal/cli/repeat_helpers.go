@@ -24,16 +24,22 @@ func extractTitle(rawInput string, titlesRaw string) string {
 	return rawInput
 }
 
-func waitForCreatedItem(store *db.Store, title string, taskType int, started time.Time) (string, error) {
+// createdItemWait bounds how long commands wait for Things to write the
+// items they created through a URL.
+var createdItemWait = 90 * time.Second
+
+// waitForCreatedItem polls for the item titled title created since started.
+// Commands that wait for several items pass one shared deadline, so a batch
+// Things rejected fails once instead of once per item.
+func waitForCreatedItem(store *db.Store, title string, taskType int, started, deadline time.Time) (string, error) {
 	if store == nil {
 		return "", fmt.Errorf("database not initialized")
 	}
 	if strings.TrimSpace(title) == "" {
 		return "", fmt.Errorf("title required to locate created item")
 	}
-	deadline := time.Now().Add(90 * time.Second)
 	since := float64(started.Unix())
-	for time.Now().Before(deadline) {
+	for {
 		matches, err := store.TasksByTitleSince(title, taskType, since)
 		if err != nil {
 			return "", err
@@ -44,9 +50,11 @@ func waitForCreatedItem(store *db.Store, title string, taskType int, started tim
 		if len(matches) > 1 {
 			return "", fmt.Errorf("multiple items created with title %q; use --id", title)
 		}
+		if !time.Now().Before(deadline) {
+			return "", fmt.Errorf("timed out waiting for the created item")
+		}
 		time.Sleep(200 * time.Millisecond)
 	}
-	return "", fmt.Errorf("timed out waiting for the created item")
 }
 
 func resolveRepeatTarget(store *db.Store, id string, expectedType int) (string, bool, error) {
@@ -86,3 +94,17 @@ func applyRepeatSpec(store *db.Store, id string, spec RepeatSpec) error {
 	}
 	return store.ApplyRepeatRule(id, update)
 }
+
+// applyCreatedRepeat waits until deadline for an item created by a URL
+// command and writes spec as its repeating rule.
+func applyCreatedRepeat(store *db.Store, title string, taskType int, spec repeat.Spec, started, deadline time.Time) error {
+	id, err := waitForCreatedItem(store, title, taskType, started, deadline)
+	if err != nil {
+		return err
+	}
+	update, err := repeat.BuildUpdate(spec)
+	if err != nil {
+		return err
+	}
+	return store.ApplyRepeatRule(id, update)
+}
@@ -0,0 +1,178 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ossianhempel/things3-cli/internal/backup"
+	"github.com/ossianhempel/things3-cli/internal/db"
+	"github.com/ossianhempel/things3-cli/internal/repeat"
+	"github.com/ossianhempel/things3-cli/internal/things"
+	"github.com/spf13/cobra"
+)
+
+// restoreBatchPause is the wait between json batches; Things accepts at most
+// 250 items every ten seconds.
+var restoreBatchPause = 10 * time.Second
+
+// NewRestoreCommand builds the restore command.
+func NewRestoreCommand(app *App) *cobra.Command {
+	var dbPath string
+	var intoEmpty bool
+
+	cmd := &cobra.Command{
+		Use:   "restore [OPTIONS...] --into-empty [--] [-|ARCHIVE]",
+		Short: "Rebuild the library from a backup archive",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !intoEmpty {
+				return fmt.Errorf("Error: restore only rebuilds into an empty library; pass --into-empty to confirm")
+			}
+			data, err := readJSONPayload(app.In, args)
+			if err != nil {
+				return err
+			}
+			archive, err := backup.Load(data)
+			if err != nil {
+				return err
+			}
+
+			store, _, err := db.OpenDefault(dbPath)
+			if err != nil && !app.DryRun {
+				return formatDBError(err)
+			}
+			existingTags := map[string]bool{}
+			if store != nil {
+				err := checkEmptyLibrary(store, existingTags)
+				store.Close()
+				if err != nil {
+					return err
+				}
+			}
+
+			var tags []things.AddTagOptions
+			for _, tag := range archive.Tags {
+				if !existingTags[tag.Title] {
+					tags = append(tags, things.AddTagOptions{Title: tag.Title, Parent: tag.Parent, Shortcut: tag.Shortcut})
+				}
+			}
+			if len(tags) > 0 {
+				script, err := things.BuildAddTagsScript(tags)
+				if err != nil {
+					return err
+				}
+				if err := runScript(app, script); err != nil {
+					return err
+				}
+			}
+			for _, area := range archive.Areas {
+				script, err := things.BuildAddAreaScript(things.AddAreaOptions{}, area.Title)
+				if err != nil {
+					return err
+				}
+				if err := runScript(app, script); err != nil {
+					return err
+				}
+			}
+
+			if !app.DryRun {
+				ensureThingsLaunched(app)
+			}
+			started := time.Now().Add(-2 * time.Second)
+			batches := backup.Batches(archive.Items(time.Now()), backup.BatchLimit)
+			for i, batch := range batches {
+				if i > 0 && !app.DryRun {
+					time.Sleep(restoreBatchPause)
+				}
+				url, err := things.BuildJSONURL(things.JSONOptions{}, batch)
+				if err != nil {
+					return err
+				}
+				if err := openURL(app, url); err != nil {
+					return err
+				}
+			}
+
+			repeats := archive.Repeats()
+			if app.DryRun {
+				if len(repeats) > 0 {
+					fmt.Fprintln(app.Err, "Note: repeats are skipped in --dry-run mode.")
+				}
+				return nil
+			}
+			if len(repeats) > 0 {
+				applyRestoreRepeats(app, dbPath, repeats, started)
+			}
+			areas, projects, todos := archive.Counts()
+			fmt.Fprintf(app.Out, "Restored %d areas, %d projects, %d todos, and %d tags\n", areas, projects, todos, len(tags))
+			return nil
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVarP(&dbPath, "db", "d", "", "Path to Things database (overrides THINGSDB)")
+	flags.StringVar(&dbPath, "database", "", "Alias for --db")
+	flags.BoolVar(&intoEmpty, "into-empty", false, "Confirm the library is empty and rebuild it from the archive")
+
+	return cmd
+}
+
+// checkEmptyLibrary fails when the library has areas, projects, or todos
+// outside the Trash, and records the tags that already exist.
+func checkEmptyLibrary(store *db.Store, existingTags map[string]bool) error {
+	areas, err := store.Areas()
+	if err != nil {
+		return formatDBError(err)
+	}
+	tasks, err := store.Tasks(db.TaskFilter{IncludeRepeating: true, Types: []int{db.TaskTypeTodo, db.TaskTypeProject}})
+	if err != nil {
+		return formatDBError(err)
+	}
+	if len(areas) > 0 || len(tasks) > 0 {
+		return fmt.Errorf("Error: the Things library is not empty (%d areas, %d projects and todos); restore requires an empty library", len(areas), len(tasks))
+	}
+	tags, err := store.Tags()
+	if err != nil {
+		return formatDBError(err)
+	}
+	for _, tag := range tags {
+		existingTags[tag.Title] = true
+	}
+	return nil
+}
+
+// applyRestoreRepeats waits for each repeating item to appear in the
+// database and writes its archived recurrence rule, anchored on the archived
+// next date or today. Failures are reported and skipped.
+func applyRestoreRepeats(app *App, dbPath string, targets []backup.RepeatTarget, started time.Time) {
+	store, _, err := db.OpenDefaultWritable(dbPath)
+	if err != nil {
+		fmt.Fprintf(app.Err, "Warning: repeating rules not restored: %v\n", formatDBError(err))
+		return
+	}
+	defer store.Close()
+
+	today := time.Now()
+	deadline := time.Now().Add(createdItemWait)
+	for _, target := range targets {
+		taskType := db.TaskTypeTodo
+		if target.Project {
+			taskType = db.TaskTypeProject
+		}
+		var next time.Time
+		if target.Next != "" {
+			next, _ = time.ParseInLocation("2006-01-02", target.Next, time.Local)
+		}
+		update, err := repeat.RestoreUpdate(target.Rule, next, today)
+		if err == nil {
+			var id string
+			id, err = waitForCreatedItem(store, target.Title, taskType, started, deadline)
+			if err == nil {
+				err = store.ApplyRepeatRule(id, update)
+			}
+		}
+		if err != nil {
+			fmt.Fprintf(app.Err, "Warning: repeating rule for %q not restored: %v\n", target.Title, err)
+		}
+	}
+}
@@ -0,0 +1,394 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ossianhempel/things3-cli/internal/db"
+	"github.com/ossianhempel/things3-cli/internal/things"
+	"github.com/spf13/cobra"
+)
+
+// Review outcomes recorded per project.
+const (
+	reviewSkipped   = "skipped"
+	reviewReviewed  = "reviewed"
+	reviewSomeday   = "someday"
+	reviewCompleted = "completed"
+)
+
+// reviewOpenTodoLimit caps the open todos printed for each project.
+const reviewOpenTodoLimit = 10
+
+// reviewProgress is the state of an unfinished review, kept in the config
+// directory so `things review` can pick up where it stopped.
+type reviewProgress struct {
+	Started  time.Time         `json:"started"`
+	Database string            `json:"database"`
+	Outcomes map[string]string `json:"outcomes"`
+}
+
+// reviewProject is an active project with what a review needs to know.
+type reviewProject struct {
+	Project      db.Project
+	Deadline     string
+	Open         []db.Task
+	NextAction   bool
+	LastActivity time.Time
+	Stale        bool
+}
+
+func (p reviewProject) stalled() bool {
+	return !p.NextAction || p.Stale
+}
+
+func (p reviewProject) stalledReasons(staleDays int) []string {
+	var reasons []string
+	if !p.NextAction {
+		reasons = append(reasons, "no next action")
+	}
+	if p.Stale {
+		reasons = append(reasons, fmt.Sprintf("nothing modified in %d days", staleDays))
+	}
+	return reasons
+}
+
+// NewReviewCommand builds the review command.
+func NewReviewCommand(app *App) *cobra.Command {
+	var dbPath string
+	var authToken string
+	var staleDays int
+	var restart bool
+	var list bool
+	var noHeader bool
+
+	cmd := &cobra.Command{
+		Use:   "review [OPTIONS...]",
+		Short: "Walk through active projects for a weekly review",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if staleDays < 1 {
+				return fmt.Errorf("Error: --stale-days must be at least 1")
+			}
+			store, _, err := db.OpenDefault(dbPath)
+			if err != nil {
+				return formatDBError(err)
+			}
+			defer store.Close()
+
+			projects, err := loadReviewProjects(store, staleDays, time.Now())
+			if err != nil {
+				return formatDBError(err)
+			}
+
+			if restart {
+				if err := clearReviewProgress(); err != nil {
+					return fmt.Errorf("Error: %v", err)
+				}
+			}
+			progress, err := loadReviewProgress()
+			if err != nil {
+				return fmt.Errorf("Error: %v", err)
+			}
+			if progress == nil || progress.Database != store.Path() {
+				progress = &reviewProgress{Started: time.Now(), Database: store.Path(), Outcomes: map[string]string{}}
+			}
+
+			if list {
+				return printReviewList(app.Out, projects, progress, staleDays, noHeader)
+			}
+			return runReview(app, projects, progress, staleDays, authToken)
+		},
+	}
+
+	cmd.Flags().StringVarP(&dbPath, "db", "d", "", "Path to Things database (overrides THINGSDB)")
+	cmd.Flags().StringVar(&dbPath, "database", "", "Alias for --db")
+	cmd.Flags().StringVar(&authToken, "auth-token", "", "Things URL scheme authorization token")
+	cmd.Flags().IntVar(&staleDays, "stale-days", 14, "Days without changes before a project counts as stalled")
+	cmd.Flags().BoolVar(&restart, "restart", false, "Discard saved progress and start a new review")
+	cmd.Flags().BoolVar(&list, "list", false, "Print the review summary without prompting")
+	cmd.Flags().BoolVar(&noHeader, "no-header", false, "Suppress header row")
+
+	return cmd
+}
+
+// loadReviewProjects returns incomplete projects that are not in Someday,
+// with their open todos and activity.
+func loadReviewProjects(store *db.Store, staleDays int, now time.Time) ([]reviewProject, error) {
+	status := db.StatusIncomplete
+	projects, err := store.Projects(db.ProjectFilter{Status: &status})
+	if err != nil {
+		return nil, err
+	}
+	cutoff := now.AddDate(0, 0, -staleDays)
+	result := make([]reviewProject, 0, len(projects))
+	for _, project := range projects {
+		task, err := store.TaskByID(project.UUID)
+		if err != nil {
+			return nil, err
+		}
+		if task.Start == "Someday" && task.StartDate == "" {
+			continue
+		}
+		tasks, err := store.Tasks(db.TaskFilter{
+			ProjectID:             project.UUID,
+			Types:                 []int{db.TaskTypeTodo},
+			ExcludeTrashedContext: true,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		item := reviewProject{Project: project, Deadline: task.Deadline, LastActivity: taskActivity(*task)}
+		for _, todo := range tasks {
+			if activity := taskActivity(todo); activity.After(item.LastActivity) {
+				item.LastActivity = activity
+			}
+			if todo.Status != db.StatusIncomplete {
+				continue
+			}
+			item.Open = append(item.Open, todo)
+			if todo.Start == "Anytime" {
+				item.NextAction = true
+			}
+		}
+		item.Stale = item.LastActivity.Before(cutoff)
+		result = append(result, item)
+	}
+	return result, nil
+}
+
+// taskActivity is the latest of a task's modification and creation times.
+func taskActivity(task db.Task) time.Time {
+	var latest time.Time
+	for _, value := range []string{task.Modified, task.Created} {
+		parsed, err := time.ParseInLocation("2006-01-02 15:04:05", value, time.Local)
+		if err == nil && parsed.After(latest) {
+			latest = parsed
+		}
+	}
+	return latest
+}
+
+func runReview(app *App, projects []reviewProject, progress *reviewProgress, staleDays int, authToken string) error {
+	var remaining []reviewProject
+	for _, project := range projects {
+		if _, done := progress.Outcomes[project.Project.UUID]; !done {
+			remaining = append(remaining, project)
+		}
+	}
+	if len(remaining) == 0 {
+		fmt.Fprintln(app.Out, "No projects left to review.")
+		return finishReview()
+	}
+	done := len(projects) - len(remaining)
+	if done > 0 {
+		fmt.Fprintf(app.Out, "Resuming review started %s: %d of %d projects done.\n", progress.Started.Local().Format("2006-01-02 15:04"), done, len(projects))
+	}
+
+	reader := bufio.NewReader(app.In)
+	for i, project := range remaining {
+		fmt.Fprintln(app.Out)
+		printReviewProject(app.Out, project, done+i+1, len(projects), staleDays)
+		outcome, err := promptReviewOutcome(app, reader)
+		if err != nil {
+			return err
+		}
+		if outcome == "" {
+			fmt.Fprintln(app.Out, "Review paused; run `things review` to resume.")
+			if err := saveReviewProgress(progress); err != nil {
+				return fmt.Errorf("Error: %v", err)
+			}
+			return nil
+		}
+		if err := applyReviewOutcome(app, project, outcome, authToken); err != nil {
+			return err
+		}
+		progress.Outcomes[project.Project.UUID] = outcome
+		if err := saveReviewProgress(progress); err != nil {
+			return fmt.Errorf("Error: %v", err)
+		}
+	}
+
+	counts := map[string]int{}
+	for _, outcome := range progress.Outcomes {
+		counts[outcome]++
+	}
+	fmt.Fprintf(app.Out, "\nReview complete: %d reviewed, %d skipped, %d moved to Someday, %d completed.\n",
+		counts[reviewReviewed], counts[reviewSkipped], counts[reviewSomeday], counts[reviewCompleted])
+	return finishReview()
+}
+
+func finishReview() error {
+	if err := clearReviewProgress(); err != nil {
+		return fmt.Errorf("Error: %v", err)
+	}
+	return nil
+}
+
+// promptReviewOutcome asks what to do with a project. It returns "" when the
+// user quits or input ends.
+func promptReviewOutcome(app *App, reader *bufio.Reader) (string, error) {
+	for {
+		fmt.Fprint(app.Err, "[s]kip, [r]eviewed, some[d]ay, [c]omplete, [q]uit: ")
+		line, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return "", err
+		}
+		switch strings.ToLower(strings.TrimSpace(line)) {
+		case "s", "skip":
+			return reviewSkipped, nil
+		case "r", "reviewed":
+			return reviewReviewed, nil
+		case "d", "someday":
+			return reviewSomeday, nil
+		case "c", "complete":
+			return reviewCompleted, nil
+		case "q", "quit":
+			return "", nil
+		}
+		if err == io.EOF {
+			fmt.Fprintln(app.Err)
+			return "", nil
+		}
+	}
+}
+
+func applyReviewOutcome(app *App, project reviewProject, outcome, authToken string) error {
+	opts := things.UpdateProjectOptions{ID: project.Project.UUID}
+	switch outcome {
+	case reviewSomeday:
+		opts.When = "someday"
+	case reviewCompleted:
+		opts.Completed = true
+	default:
+		return nil
+	}
+	token, err := resolveAuthToken(app, authToken)
+	if err != nil {
+		return err
+	}
+	opts.AuthToken = token
+	url, err := things.BuildUpdateProjectURL(opts, "")
+	if err != nil {
+		return err
+	}
+	return openURL(app, url)
+}
+
+func printReviewProject(out io.Writer, project reviewProject, index, total, staleDays int) {
+	title := project.Project.Title
+	if project.Project.AreaTitle != "" {
+		title += " (" + project.Project.AreaTitle + ")"
+	}
+	fmt.Fprintf(out, "[%d/%d] %s\n", index, total, title)
+	if project.Deadline != "" {
+		fmt.Fprintf(out, "  Deadline: %s\n", project.Deadline)
+	}
+	if !project.LastActivity.IsZero() {
+		fmt.Fprintf(out, "  Last activity: %s\n", project.LastActivity.Format("2006-01-02"))
+	}
+	if project.stalled() {
+		fmt.Fprintf(out, "  Stalled: %s\n", strings.Join(project.stalledReasons(staleDays), "; "))
+	}
+	fmt.Fprintf(out, "  Open todos (%d):\n", len(project.Open))
+	for i, todo := range project.Open {
+		if i == reviewOpenTodoLimit {
+			fmt.Fprintf(out, "    … and %d more\n", len(project.Open)-i)
+			break
+		}
+		line := "    - " + todo.Title
+		if todo.Deadline != "" {
+			line += " (due " + todo.Deadline + ")"
+		}
+		fmt.Fprintln(out, line)
+	}
+}
+
+func printReviewList(out io.Writer, projects []reviewProject, progress *reviewProgress, staleDays int, noHeader bool) error {
+	w := tabwriter.NewWriter(out, 0, 2, 2, ' ', 0)
+	if !noHeader {
+		fmt.Fprintln(w, "UUID\tTITLE\tAREA\tOPEN\tDEADLINE\tLAST_ACTIVITY\tSTALLED\tREVIEW")
+	}
+	for _, project := range projects {
+		activity := ""
+		if !project.LastActivity.IsZero() {
+			activity = project.LastActivity.Format("2006-01-02")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\t%s\t%s\n",
+			project.Project.UUID,
+			project.Project.Title,
+			project.Project.AreaTitle,
+			len(project.Open),
+			project.Deadline,
+			activity,
+			strings.Join(project.stalledReasons(staleDays), "; "),
+			progress.Outcomes[project.Project.UUID],
+		)
+	}
+	return w.Flush()
+}
+
+func reviewProgressPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "things3-cli", "review.json"), nil
+}
+
+func loadReviewProgress() (*reviewProgress, error) {
+	path, err := reviewProgressPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var progress reviewProgress
+	if err := json.Unmarshal(data, &progress); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if progress.Outcomes == nil {
+		progress.Outcomes = map[string]string{}
+	}
+	return &progress, nil
+}
+
+func saveReviewProgress(progress *reviewProgress) error {
+	path, err := reviewProgressPath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(progress, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+func clearReviewProgress() error {
+	path, err := reviewProgressPath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
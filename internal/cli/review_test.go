@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeReviewTestDB(t *testing.T) string {
+	t.Helper()
+	dbPath := writeTestDB(t)
+	conn, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer conn.Close()
+	old := float64(time.Now().AddDate(0, -2, 0).Unix())
+	statements := []struct {
+		query string
+		args  []any
+	}{
+		{`UPDATE TMTask SET "index" = 1 WHERE uuid = 'P1'`, nil},
+		{`INSERT INTO TMTask (uuid, type, status, trashed, title, start, creationDate, "index") VALUES ('P2', 1, 0, 0, 'Stalled Project', 1, ?, 2)`, []any{old}},
+		{`INSERT INTO TMTask (uuid, type, status, trashed, title, project, start, creationDate) VALUES ('P2T1', 0, 0, 0, 'Waiting on reply', 'P2', 2, ?)`, []any{old}},
+		{`INSERT INTO TMTask (uuid, type, status, trashed, title, start, "index") VALUES ('P3', 1, 0, 0, 'Someday Project', 2, 3)`, nil},
+	}
+	for _, stmt := range statements {
+		if _, err := conn.Exec(stmt.query, stmt.args...); err != nil {
+			t.Fatalf("seed review data: %v", err)
+		}
+	}
+	return dbPath
+}
+
+func TestReviewCommandResumesAndCompletes(t *testing.T) {
+	configDir := setConfigHome(t)
+	t.Setenv("THINGS_AUTH_TOKEN", "token")
+	dbPath := writeReviewTestDB(t)
+	launcher := &recordLauncher{}
+
+	out, err := runCommand(t, &App{In: strings.NewReader("r\nq\n"), Launcher: launcher}, "review", "--db", dbPath)
+	if err != nil {
+		t.Fatalf("review failed: %v", err)
+	}
+	for _, want := range []string{"[1/2] Project One (Home)", "- Task One", "[2/2] Stalled Project", "Stalled: no next action; nothing modified in 14 days", "Review paused"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "Someday Project") {
+		t.Fatalf("expected Someday projects to be skipped:\n%s", out)
+	}
+	progressPath := filepath.Join(configDir, "things3-cli", "review.json")
+	data, err := os.ReadFile(progressPath)
+	if err != nil || !strings.Contains(string(data), `"P1": "reviewed"`) {
+		t.Fatalf("expected saved progress, got %s (%v)", data, err)
+	}
+
+	out, err = runCommand(t, &App{Launcher: launcher}, "review", "--db", dbPath, "--list", "--no-header")
+	if err != nil {
+		t.Fatalf("review --list failed: %v", err)
+	}
+	if !strings.Contains(out, "P1") || !strings.Contains(out, "reviewed") || !strings.Contains(out, "no next action") {
+		t.Fatalf("unexpected review list:\n%s", out)
+	}
+
+	out, err = runCommand(t, &App{In: strings.NewReader("x\nc\n"), Launcher: launcher}, "review", "--db", dbPath)
+	if err != nil {
+		t.Fatalf("review failed: %v", err)
+	}
+	if !strings.Contains(out, "Resuming review") || strings.Contains(out, "Project One") {
+		t.Fatalf("expected resume at the second project:\n%s", out)
+	}
+	url := requireOpenURL(t, launcher)
+	if !strings.HasPrefix(url, "things:///update-project?") || !strings.Contains(url, "id=P2") || !strings.Contains(url, "completed=true") {
+		t.Fatalf("unexpected complete URL: %s", url)
+	}
+	if !strings.Contains(out, "Review complete: 1 reviewed, 0 skipped, 0 moved to Someday, 1 completed.") {
+		t.Fatalf("unexpected summary:\n%s", out)
+	}
+	if _, err := os.Stat(progressPath); !os.IsNotExist(err) {
+		t.Fatalf("expected progress to be cleared, got %v", err)
+	}
+}
+
+func TestReviewCommandRestart(t *testing.T) {
+	setConfigHome(t)
+	dbPath := writeReviewTestDB(t)
+
+	if _, err := runCommand(t, &App{In: strings.NewReader("s\n"), Launcher: &recordLauncher{}}, "review", "--db", dbPath); err != nil {
+		t.Fatalf("review failed: %v", err)
+	}
+	out, err := runCommand(t, &App{In: strings.NewReader("q\n"), Launcher: &recordLauncher{}}, "review", "--db", dbPath, "--restart")
+	if err != nil {
+		t.Fatalf("review failed: %v", err)
+	}
+	if strings.Contains(out, "Resuming") || !strings.Contains(out, "[1/2] Project One") {
+		t.Fatalf("expected --restart to start over:\n%s", out)
+	}
+}
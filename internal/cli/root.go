@@ -20,7 +20,7 @@ func NewRoot(app *App) *cobra.Command {
 				printVersion(app.Out)
 				return ErrVersionPrinted
 			}
-			return nil
+			return applyConfig(app, cmd)
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			printHelp(app.Out, formatHelpText(rootHelp, isTTY(app.Out)))
@@ -32,6 +32,7 @@ func NewRoot(app *App) *cobra.Command {
 	cmd.PersistentFlags().BoolVar(&app.Foreground, "foreground", false, "Open Things in the foreground")
 	cmd.PersistentFlags().BoolVar(&app.DryRun, "dry-run", false, "Print the Things URL without opening it")
 	cmd.PersistentFlags().BoolVarP(&versionFlag, "version", "V", false, "Print version information")
+	cmd.PersistentFlags().StringVar(&app.Profile, "profile", "", "Config profile to use")
 
 	cmd.AddCommand(NewAddCommand(app))
 	cmd.AddCommand(NewAddAreaCommand(app))
@@ -62,8 +63,25 @@ func NewRoot(app *App) *cobra.Command {
 	cmd.AddCommand(NewDeleteProjectCommand(app))
 	cmd.AddCommand(NewUpdateProjectCommand(app))
 	cmd.AddCommand(NewUndoCommand(app))
+	cmd.AddCommand(NewRedoCommand(app))
+	cmd.AddCommand(NewHistoryCommand(app))
 	cmd.AddCommand(NewShowCommand(app))
 	cmd.AddCommand(NewSearchCommand(app))
+	cmd.AddCommand(NewImportJSONCommand(app))
+	cmd.AddCommand(NewImportCommand(app))
+	cmd.AddCommand(NewTemplateCommand(app))
+	cmd.AddCommand(NewServeCommand(app))
+	cmd.AddCommand(NewMCPCommand(app))
+	cmd.AddCommand(NewTUICommand(app))
+	cmd.AddCommand(NewReviewCommand(app))
+	cmd.AddCommand(NewStatsCommand(app))
+	cmd.AddCommand(NewExportCommand(app))
+	cmd.AddCommand(NewBackupCommand(app))
+	cmd.AddCommand(NewRestoreCommand(app))
+	cmd.AddCommand(NewDiffCommand(app))
+	cmd.AddCommand(NewConfigCommand(app))
+	cmd.AddCommand(NewQueryCommand(app))
+	addSavedQueryShortcuts(app, cmd)
 
 	cmd.SetHelpCommand(&cobra.Command{
 		Use:   "help [command]",
@@ -130,6 +148,14 @@ func NewRoot(app *App) *cobra.Command {
 				printHelp(app.Out, formatHelpText(deleteHelp, isTTY(app.Out)))
 			case "undo":
 				printHelp(app.Out, formatHelpText(undoHelp, isTTY(app.Out)))
+			case "config":
+				printHelp(app.Out, formatHelpText(configHelp, isTTY(app.Out)))
+			case "query":
+				printHelp(app.Out, formatHelpText(queryHelp, isTTY(app.Out)))
+			case "redo":
+				printHelp(app.Out, formatHelpText(redoHelp, isTTY(app.Out)))
+			case "history":
+				printHelp(app.Out, formatHelpText(historyHelp, isTTY(app.Out)))
 			case "update-area":
 				printHelp(app.Out, formatHelpText(updateAreaHelp, isTTY(app.Out)))
 			case "delete-area":
@@ -138,6 +164,30 @@ func NewRoot(app *App) *cobra.Command {
 				printHelp(app.Out, formatHelpText(updateProjectHelp, isTTY(app.Out)))
 			case "delete-project":
 				printHelp(app.Out, formatHelpText(deleteProjectHelp, isTTY(app.Out)))
+			case "import-json":
+				printHelp(app.Out, formatHelpText(importJSONHelp, isTTY(app.Out)))
+			case "import":
+				printHelp(app.Out, formatHelpText(importHelp, isTTY(app.Out)))
+			case "template":
+				printHelp(app.Out, formatHelpText(templateHelp, isTTY(app.Out)))
+			case "serve":
+				printHelp(app.Out, formatHelpText(serveHelp, isTTY(app.Out)))
+			case "mcp":
+				printHelp(app.Out, formatHelpText(mcpHelp, isTTY(app.Out)))
+			case "tui":
+				printHelp(app.Out, formatHelpText(tuiHelp, isTTY(app.Out)))
+			case "review":
+				printHelp(app.Out, formatHelpText(reviewHelp, isTTY(app.Out)))
+			case "stats":
+				printHelp(app.Out, formatHelpText(statsHelp, isTTY(app.Out)))
+			case "export":
+				printHelp(app.Out, formatHelpText(exportHelp, isTTY(app.Out)))
+			case "backup":
+				printHelp(app.Out, formatHelpText(backupHelp, isTTY(app.Out)))
+			case "restore":
+				printHelp(app.Out, formatHelpText(restoreHelp, isTTY(app.Out)))
+			case "diff":
+				printHelp(app.Out, formatHelpText(diffHelp, isTTY(app.Out)))
 			case "help":
 				printHelp(app.Out, formatHelpText(rootHelp, isTTY(app.Out)))
 			default:
@@ -149,6 +199,10 @@ func NewRoot(app *App) *cobra.Command {
 
 	cmd.SetHelpFunc(func(cmd *cobra.Command, args []string) {
 		name := cmd.Name()
+		if cmd.HasParent() && cmd.Parent() != cmd.Root() {
+			// Nested subcommands share their parent's help page.
+			name = cmd.Parent().Name()
+		}
 		switch name {
 		case "things":
 			printHelp(app.Out, formatHelpText(rootHelp, isTTY(app.Out)))
@@ -210,6 +264,14 @@ func NewRoot(app *App) *cobra.Command {
 			printHelp(app.Out, formatHelpText(deleteHelp, isTTY(app.Out)))
 		case "undo":
 			printHelp(app.Out, formatHelpText(undoHelp, isTTY(app.Out)))
+		case "config":
+			printHelp(app.Out, formatHelpText(configHelp, isTTY(app.Out)))
+		case "query":
+			printHelp(app.Out, formatHelpText(queryHelp, isTTY(app.Out)))
+		case "redo":
+			printHelp(app.Out, formatHelpText(redoHelp, isTTY(app.Out)))
+		case "history":
+			printHelp(app.Out, formatHelpText(historyHelp, isTTY(app.Out)))
 		case "update-area":
 			printHelp(app.Out, formatHelpText(updateAreaHelp, isTTY(app.Out)))
 		case "delete-area":
@@ -218,6 +280,30 @@ func NewRoot(app *App) *cobra.Command {
 			printHelp(app.Out, formatHelpText(updateProjectHelp, isTTY(app.Out)))
 		case "delete-project":
 			printHelp(app.Out, formatHelpText(deleteProjectHelp, isTTY(app.Out)))
+		case "import-json":
+			printHelp(app.Out, formatHelpText(importJSONHelp, isTTY(app.Out)))
+		case "import":
+			printHelp(app.Out, formatHelpText(importHelp, isTTY(app.Out)))
+		case "template":
+			printHelp(app.Out, formatHelpText(templateHelp, isTTY(app.Out)))
+		case "serve":
+			printHelp(app.Out, formatHelpText(serveHelp, isTTY(app.Out)))
+		case "mcp":
+			printHelp(app.Out, formatHelpText(mcpHelp, isTTY(app.Out)))
+		case "tui":
+			printHelp(app.Out, formatHelpText(tuiHelp, isTTY(app.Out)))
+		case "review":
+			printHelp(app.Out, formatHelpText(reviewHelp, isTTY(app.Out)))
+		case "stats":
+			printHelp(app.Out, formatHelpText(statsHelp, isTTY(app.Out)))
+		case "export":
+			printHelp(app.Out, formatHelpText(exportHelp, isTTY(app.Out)))
+		case "backup":
+			printHelp(app.Out, formatHelpText(backupHelp, isTTY(app.Out)))
+		case "restore":
+			printHelp(app.Out, formatHelpText(restoreHelp, isTTY(app.Out)))
+		case "diff":
+			printHelp(app.Out, formatHelpText(diffHelp, isTTY(app.Out)))
 		default:
 			printHelp(app.Out, formatHelpText(rootHelp, isTTY(app.Out)))
 		}
@@ -0,0 +1,138 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SavedQuery is a named task query with its output selection.
+type SavedQuery struct {
+	Options  TaskQueryOptions `json:"options"`
+	Format   string           `json:"format,omitempty"`
+	Select   string           `json:"select,omitempty"`
+	JSON     bool             `json:"json,omitempty"`
+	NoHeader bool             `json:"no_header,omitempty"`
+	// LimitSet records an explicit --limit, so a saved 0 means no limit
+	// rather than the run default.
+	LimitSet bool `json:"limit_set,omitempty"`
+}
+
+var savedQueryNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+func validateSavedQueryName(name string) error {
+	if !savedQueryNamePattern.MatchString(name) {
+		return fmt.Errorf("Error: invalid query name %q (use letters, digits, - and _)", name)
+	}
+	return nil
+}
+
+func savedQueriesPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "things3-cli", "queries.json"), nil
+}
+
+func loadSavedQueries() (map[string]SavedQuery, error) {
+	path, err := savedQueriesPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return map[string]SavedQuery{}, nil
+		}
+		return nil, err
+	}
+	queries := map[string]SavedQuery{}
+	if err := json.Unmarshal(data, &queries); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return queries, nil
+}
+
+func writeSavedQueries(queries map[string]SavedQuery) error {
+	path, err := savedQueriesPath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(queries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+func sortedQueryNames(queries map[string]SavedQuery) []string {
+	names := make([]string, 0, len(queries))
+	for name := range queries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// savedQueryArgs renders a saved query as the equivalent command-line flags.
+func savedQueryArgs(q SavedQuery) []string {
+	var args []string
+	addString := func(flag, value string) {
+		if value != "" {
+			args = append(args, "--"+flag+"="+strconv.Quote(value))
+		}
+	}
+	addBool := func(flag string, value bool) {
+		if value {
+			args = append(args, "--"+flag)
+		}
+	}
+	opts := q.Options
+	if opts.Status != "" && opts.Status != "incomplete" {
+		addString("status", opts.Status)
+	}
+	addString("query", opts.Query)
+	addString("search", opts.Search)
+	addString("filter-project", opts.Project)
+	addString("filter-area", opts.Area)
+	addString("filter-tag", opts.Tag)
+	addString("created-before", opts.CreatedBefore)
+	addString("created-after", opts.CreatedAfter)
+	addString("modified-before", opts.ModifiedBefore)
+	addString("modified-after", opts.ModifiedAfter)
+	addString("due-before", opts.DueBefore)
+	addString("start-before", opts.StartBefore)
+	if opts.HasURLSet {
+		args = append(args, "--has-url="+strconv.FormatBool(opts.HasURL))
+	}
+	addBool("include-trashed", opts.IncludeTrashed)
+	addBool("all", opts.All)
+	addBool("recursive", opts.IncludeChecklist)
+	addBool("exact", opts.Exact)
+	addString("sort", opts.Sort)
+	if opts.Limit != 0 || q.LimitSet {
+		args = append(args, "--limit="+strconv.Itoa(opts.Limit))
+	}
+	if opts.Offset != 0 {
+		args = append(args, "--offset="+strconv.Itoa(opts.Offset))
+	}
+	addString("format", q.Format)
+	addString("select", q.Select)
+	addBool("json", q.JSON)
+	addBool("no-header", q.NoHeader)
+	return args
+}
+
+func describeSavedQuery(q SavedQuery) string {
+	return strings.Join(savedQueryArgs(q), " ")
+}
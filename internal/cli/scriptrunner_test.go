@@ -3,12 +3,15 @@ package cli
 import "testing"
 
 type recordScriptRunner struct {
-	script string
+	script  string
+	scripts []string
+	err     error
 }
 
 func (r *recordScriptRunner) Run(script string) error {
 	r.script = script
-	return nil
+	r.scripts = append(r.scripts, script)
+	return r.err
 }
 
 func requireScript(t *testing.T, runner *recordScriptRunner) string {
@@ -1,7 +1,9 @@
 package cli
 
 import (
+	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/ossianhempel/things3-cli/internal/db"
@@ -19,6 +21,8 @@ func NewSearchCommand(app *App) *cobra.Command {
 	var selectRaw string
 	var asJSON bool
 	var noHeader bool
+	var fts bool
+	var reindex bool
 
 	cmd := &cobra.Command{
 		Use:   "search [--] [-|QUERY]",
@@ -32,10 +36,13 @@ func NewSearchCommand(app *App) *cobra.Command {
 			if query == "" && strings.TrimSpace(opts.Query) == "" {
 				return fmt.Errorf("Error: query required")
 			}
-			if query != "" && strings.TrimSpace(opts.Query) != "" {
+			if fts && query == "" {
+				return fmt.Errorf("Error: --fts requires a QUERY argument")
+			}
+			if !fts && query != "" && strings.TrimSpace(opts.Query) != "" {
 				return fmt.Errorf("Error: use either QUERY argument or --query")
 			}
-			if query != "" {
+			if query != "" && !fts {
 				opts.Search = query
 			}
 
@@ -50,6 +57,20 @@ func NewSearchCommand(app *App) *cobra.Command {
 			if err != nil {
 				return err
 			}
+			if fts {
+				if len(outputOpts.Select) == 0 && outputOpts.Format != "json" && outputOpts.Format != "jsonl" {
+					outputOpts.Select = []string{"uuid", "title", "project", "area", "snippet"}
+				}
+				marks := [2]string{"[", "]"}
+				if isTTY(app.Out) && outputOpts.Format == "table" {
+					marks = [2]string{"\x1b[1m", "\x1b[0m"}
+				}
+				tasks, err := searchIndexedTasks(app, store, query, marks, reindex, opts)
+				if err != nil {
+					return formatDBError(err)
+				}
+				return printTasks(app.Out, tasks, outputOpts)
+			}
 			tasks, err := fetchTasks(store, store.Tasks, opts, false, []int{db.TaskTypeTodo})
 			if err != nil {
 				return formatDBError(err)
@@ -62,6 +83,75 @@ func NewSearchCommand(app *App) *cobra.Command {
 	cmd.Flags().StringVar(&dbPath, "database", "", "Alias for --db")
 	addTaskQueryFlags(cmd, &opts, false, true)
 	addTaskOutputFlags(cmd, &format, &selectRaw, &asJSON, &noHeader)
+	cmd.Flags().BoolVar(&fts, "fts", false, "Ranked full-text search over titles, notes, and checklist items")
+	cmd.Flags().BoolVar(&reindex, "reindex", false, "Rebuild the full-text index before searching (with --fts)")
 
 	return cmd
 }
+
+// searchIndexedTasks syncs the full-text index, then loads the matching tasks
+// with the regular filters applied and returns them in rank order.
+func searchIndexedTasks(app *App, store *db.Store, query string, marks [2]string, reindex bool, opts TaskQueryOptions) ([]db.Task, error) {
+	path, err := db.SearchIndexPath(store.Path())
+	if err != nil {
+		return nil, err
+	}
+	index, err := db.OpenSearchIndex(path)
+	if err != nil {
+		return nil, err
+	}
+	defer index.Close()
+	if reindex {
+		if err := index.Reset(); err != nil {
+			return nil, err
+		}
+	}
+	stats, err := index.Sync(store)
+	if err != nil {
+		return nil, err
+	}
+	if app.Debug {
+		fmt.Fprintf(app.Err, "Search index %s: %d indexed, %d removed\n", index.Path(), stats.Indexed, stats.Removed)
+	}
+
+	hits, err := index.Search(query, marks[0], marks[1])
+	if err != nil {
+		return nil, err
+	}
+	if len(hits) == 0 {
+		return []db.Task{}, nil
+	}
+	ids := make([]string, len(hits))
+	rank := make(map[string]int, len(hits))
+	snippets := make(map[string]string, len(hits))
+	for i, hit := range hits {
+		ids[i] = hit.UUID
+		rank[hit.UUID] = i
+		snippets[hit.UUID] = hit.Snippet
+	}
+	idsJSON, err := json.Marshal(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	limit, offset := opts.Limit, opts.Offset
+	opts.Limit, opts.Offset = 0, 0
+	matchIDs := querySQL{Where: "t.uuid IN (SELECT value FROM json_each(?))", Args: []any{string(idsJSON)}}
+	tasks, err := fetchTasks(store, func(filter db.TaskFilter) ([]db.Task, error) {
+		compiled := joinQuerySQL(querySQL{Where: filter.Where, Args: filter.WhereArgs}, matchIDs)
+		filter.Where, filter.WhereArgs = compiled.Where, compiled.Args
+		return store.Tasks(filter)
+	}, opts, false, []int{db.TaskTypeTodo})
+	if err != nil {
+		return nil, err
+	}
+	for i := range tasks {
+		tasks[i].Snippet = snippets[tasks[i].UUID]
+	}
+	if strings.TrimSpace(opts.Sort) == "" {
+		sort.SliceStable(tasks, func(i, j int) bool {
+			return rank[tasks[i].UUID] < rank[tasks[j].UUID]
+		})
+	}
+	return applyOffsetLimit(tasks, limit, offset), nil
+}
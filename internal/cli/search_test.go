@@ -2,8 +2,13 @@ package cli
 
 import (
 	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/ossianhempel/things3-cli/internal/db"
 )
 
 func TestSearchCommandEmpty(t *testing.T) {
@@ -46,3 +51,53 @@ func TestSearchCommandWithQuery(t *testing.T) {
 		t.Fatalf("unexpected output: %q", output)
 	}
 }
+
+func TestSearchCommandFTS(t *testing.T) {
+	setConfigHome(t)
+	dbPath := writeTestDB(t)
+	run := func(args ...string) string {
+		t.Helper()
+		out := &bytes.Buffer{}
+		app := &App{In: strings.NewReader(""), Out: out, Err: &bytes.Buffer{}}
+		root := NewRoot(app)
+		root.SetArgs(append([]string{"search", "--db", dbPath, "--fts"}, args...))
+		root.SetOut(app.Out)
+		root.SetErr(app.Err)
+		if err := root.Execute(); err != nil {
+			t.Fatalf("execute failed: %v", err)
+		}
+		return out.String()
+	}
+
+	output := run("check")
+	if !strings.Contains(output, "SNIPPET") || !strings.Contains(output, "T1") || !strings.Contains(output, "[Check] Item") {
+		t.Fatalf("expected checklist match with snippet, got %q", output)
+	}
+
+	var tasks []db.Task
+	if err := json.Unmarshal([]byte(run("task", "--json", "--status", "any")), &tasks); err != nil {
+		t.Fatalf("decode output: %v", err)
+	}
+	if len(tasks) < 2 || tasks[0].Snippet == "" {
+		t.Fatalf("expected ranked tasks with snippets, got %+v", tasks)
+	}
+	for _, task := range tasks {
+		if task.UUID == "TRASH1" {
+			t.Fatalf("trashed task should be filtered out")
+		}
+	}
+	if output := run("task", "--status", "completed", "--select", "uuid", "--no-header"); strings.TrimSpace(output) != "COMP1" {
+		t.Fatalf("expected status filter to apply to FTS results, got %q", output)
+	}
+
+	path, err := db.SearchIndexPath(dbPath)
+	if err != nil {
+		t.Fatalf("index path: %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("expected index under the cache dir: %v", err)
+	}
+	if rel, err := filepath.Rel(os.Getenv("XDG_CACHE_HOME"), path); err != nil || strings.HasPrefix(rel, "..") {
+		t.Fatalf("index %s outside cache dir", path)
+	}
+}
@@ -0,0 +1,506 @@
+package cli
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ossianhempel/things3-cli/internal/db"
+	"github.com/ossianhempel/things3-cli/internal/things"
+	"github.com/spf13/cobra"
+)
+
+// NewServeCommand builds the serve command.
+func NewServeCommand(app *App) *cobra.Command {
+	var dbPath string
+	var addr string
+	var token string
+
+	cmd := &cobra.Command{
+		Use:   "serve [OPTIONS...]",
+		Short: "Serve a local HTTP/JSON API for the Things database",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, path, err := db.OpenDefault(dbPath)
+			if err != nil {
+				return formatDBError(err)
+			}
+			defer store.Close()
+
+			if token == "" {
+				token, err = newAPIToken()
+				if err != nil {
+					return fmt.Errorf("Error: %v", err)
+				}
+			}
+
+			server := &http.Server{
+				Addr:              addr,
+				Handler:           newAPIHandler(app, store, token),
+				ReadHeaderTimeout: 10 * time.Second,
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			errCh := make(chan error, 1)
+			go func() {
+				errCh <- server.ListenAndServe()
+			}()
+			fmt.Fprintf(app.Err, "Serving %s on http://%s\n", path, addr)
+			fmt.Fprintf(app.Err, "Write requests need the header %s: %s\n", apiTokenHeader, token)
+
+			select {
+			case err := <-errCh:
+				if errors.Is(err, http.ErrServerClosed) {
+					return nil
+				}
+				return fmt.Errorf("Error: %v", err)
+			case <-ctx.Done():
+				shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				return server.Shutdown(shutdownCtx)
+			}
+		},
+	}
+
+	cmd.Flags().StringVarP(&dbPath, "db", "d", "", "Path to Things database (overrides THINGSDB)")
+	cmd.Flags().StringVar(&dbPath, "database", "", "Alias for --db")
+	cmd.Flags().StringVar(&addr, "addr", "127.0.0.1:7878", "Address to listen on")
+	cmd.Flags().StringVar(&token, "token", "", "Token write requests must send (default: random per server)")
+
+	return cmd
+}
+
+// apiTokenHeader carries the per-server token on write requests.
+const apiTokenHeader = "X-Things-Token"
+
+type apiServer struct {
+	app   *App
+	store *db.Store
+	token string
+}
+
+type apiURLResponse struct {
+	URL    string `json:"url,omitempty"`
+	Script string `json:"script,omitempty"`
+}
+
+type apiProjectResponse struct {
+	Project *db.Item  `json:"project"`
+	Tasks   []db.Task `json:"tasks"`
+}
+
+func newAPIHandler(app *App, store *db.Store, token string) http.Handler {
+	s := &apiServer{app: app, store: store, token: token}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /tasks", s.listTasks(store.Tasks, false))
+	mux.HandleFunc("GET /today", s.listTasks(store.TodayTasks, true))
+	mux.HandleFunc("GET /search", s.search)
+	mux.HandleFunc("GET /projects", s.projects)
+	mux.HandleFunc("GET /projects/{id}", s.project)
+	mux.HandleFunc("GET /areas", s.areas)
+	mux.HandleFunc("GET /tags", s.tags)
+	mux.HandleFunc("GET /tasks/{id}", s.task)
+	mux.HandleFunc("POST /tasks", s.guardWrite(s.addTask))
+	mux.HandleFunc("PATCH /tasks/{id}", s.guardWrite(s.updateTask))
+	mux.HandleFunc("POST /tasks/{id}/complete", s.guardWrite(s.completeTask))
+	mux.HandleFunc("DELETE /tasks/{id}", s.guardWrite(s.trashTask))
+	return guardLoopback(mux)
+}
+
+// guardLoopback rejects requests whose Host or Origin is not a loopback
+// address, so web pages cannot reach the API through DNS rebinding.
+func guardLoopback(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isLoopbackHost(r.Host) {
+			writeAPIError(w, http.StatusForbidden, errors.New("Error: host not allowed"))
+			return
+		}
+		if origin := r.Header.Get("Origin"); origin != "" {
+			parsed, err := url.Parse(origin)
+			if err != nil || !isLoopbackHost(parsed.Host) {
+				writeAPIError(w, http.StatusForbidden, errors.New("Error: origin not allowed"))
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func isLoopbackHost(hostport string) bool {
+	host := hostport
+	if h, _, err := net.SplitHostPort(hostport); err == nil {
+		host = h
+	}
+	host = strings.Trim(host, "[]")
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// guardWrite requires a JSON content type, which browsers cannot send
+// cross-origin without a preflight, and the per-server token.
+func (s *apiServer) guardWrite(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err != nil || mediaType != "application/json" {
+			writeAPIError(w, http.StatusUnsupportedMediaType, errors.New("Error: Content-Type must be application/json"))
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get(apiTokenHeader)), []byte(s.token)) != 1 {
+			writeAPIError(w, http.StatusUnauthorized, fmt.Errorf("Error: missing or invalid %s header", apiTokenHeader))
+			return
+		}
+		next(w, r)
+	}
+}
+
+func newAPIToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func (s *apiServer) listTasks(runner func(db.TaskFilter) ([]db.Task, error), forcePost bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		opts, err := taskQueryOptionsFromValues(r.URL.Query())
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, err)
+			return
+		}
+		post := forcePost && (opts.Query != "" || opts.Sort != "" || opts.Offset > 0)
+		tasks, err := fetchTasks(s.store, runner, opts, post, []int{db.TaskTypeTodo})
+		if err != nil {
+			writeAPIError(w, apiErrorStatus(err), err)
+			return
+		}
+		writeJSON(w, http.StatusOK, nonNilTasks(tasks))
+	}
+}
+
+func (s *apiServer) search(w http.ResponseWriter, r *http.Request) {
+	values := r.URL.Query()
+	q := strings.TrimSpace(values.Get("q"))
+	if q == "" && strings.TrimSpace(values.Get("query")) == "" {
+		writeAPIError(w, http.StatusBadRequest, errors.New("Error: query required (use ?q=)"))
+		return
+	}
+	opts, err := taskQueryOptionsFromValues(values)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+	if q != "" {
+		opts.Search = q
+	}
+	tasks, err := fetchTasks(s.store, s.store.Tasks, opts, false, []int{db.TaskTypeTodo})
+	if err != nil {
+		writeAPIError(w, apiErrorStatus(err), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, nonNilTasks(tasks))
+}
+
+func (s *apiServer) task(w http.ResponseWriter, r *http.Request) {
+	task, err := s.store.TaskByID(r.PathValue("id"))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeAPIError(w, http.StatusNotFound, errors.New("Error: task not found"))
+			return
+		}
+		writeAPIError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, task)
+}
+
+func (s *apiServer) projects(w http.ResponseWriter, r *http.Request) {
+	values := r.URL.Query()
+	status, err := db.ParseStatus(stringParam(values, "incomplete", "status"))
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, fmt.Errorf("Error: %s", err))
+		return
+	}
+	filter := db.ProjectFilter{Status: status}
+	if area := stringParam(values, "", "area", "filter-area"); area != "" {
+		exact, err := boolParam(values, "exact")
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, err)
+			return
+		}
+		filter.AreaID, err = resolveNamed(s.store.ResolveAreaID, s.store.ResolveAreaIDFuzzy, exact, area)
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, fmt.Errorf("Error: %s", err))
+			return
+		}
+	}
+	projects, err := s.store.Projects(filter)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if projects == nil {
+		projects = []db.Project{}
+	}
+	writeJSON(w, http.StatusOK, projects)
+}
+
+func (s *apiServer) project(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	item, err := s.store.ItemByID(id)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		writeAPIError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if item == nil || item.Type != "project" {
+		writeAPIError(w, http.StatusNotFound, errors.New("Error: project not found"))
+		return
+	}
+	opts, err := taskQueryOptionsFromValues(r.URL.Query())
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+	opts.Project = item.UUID
+	tasks, err := fetchTasks(s.store, s.store.Tasks, opts, false, []int{db.TaskTypeTodo})
+	if err != nil {
+		writeAPIError(w, apiErrorStatus(err), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, apiProjectResponse{Project: item, Tasks: nonNilTasks(tasks)})
+}
+
+func (s *apiServer) areas(w http.ResponseWriter, r *http.Request) {
+	areas, err := s.store.Areas()
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if areas == nil {
+		areas = []db.Area{}
+	}
+	writeJSON(w, http.StatusOK, areas)
+}
+
+func (s *apiServer) tags(w http.ResponseWriter, r *http.Request) {
+	tags, err := s.store.Tags()
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if tags == nil {
+		tags = []db.Tag{}
+	}
+	writeJSON(w, http.StatusOK, tags)
+}
+
+func (s *apiServer) addTask(w http.ResponseWriter, r *http.Request) {
+	var req todoRequest
+	if err := decodeAPIRequest(r, &req); err != nil {
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		writeAPIError(w, http.StatusBadRequest, errors.New("Error: title required"))
+		return
+	}
+	if err := validateWhenInput(req.When); err != nil {
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+	url := things.BuildAddURL(req.addOptions(), title)
+	if err := openURL(s.app, url); err != nil {
+		writeAPIError(w, http.StatusBadGateway, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, apiURLResponse{URL: url})
+}
+
+func (s *apiServer) updateTask(w http.ResponseWriter, r *http.Request) {
+	var req todoRequest
+	if err := decodeAPIRequest(r, &req); err != nil {
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := validateWhenInput(req.When); err != nil {
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+	s.openUpdate(w, req.updateOptions(r.PathValue("id")), strings.TrimSpace(req.Title))
+}
+
+func (s *apiServer) completeTask(w http.ResponseWriter, r *http.Request) {
+	s.openUpdate(w, things.UpdateOptions{ID: r.PathValue("id"), Completed: true}, "")
+}
+
+func (s *apiServer) openUpdate(w http.ResponseWriter, opts things.UpdateOptions, title string) {
+	token, err := resolveAuthToken(s.app, "")
+	if err != nil {
+		writeAPIError(w, http.StatusUnauthorized, err)
+		return
+	}
+	opts.AuthToken = token
+	url, err := things.BuildUpdateURL(opts, title)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := openURL(s.app, url); err != nil {
+		writeAPIError(w, http.StatusBadGateway, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, apiURLResponse{URL: url})
+}
+
+func (s *apiServer) trashTask(w http.ResponseWriter, r *http.Request) {
+	script, err := things.BuildTrashScript([]string{r.PathValue("id")})
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := runScript(s.app, script); err != nil {
+		writeAPIError(w, http.StatusBadGateway, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, apiURLResponse{Script: script})
+}
+
+// taskQueryOptionsFromValues maps query parameters onto TaskQueryOptions using
+// the same names as the task list flags.
+func taskQueryOptionsFromValues(values url.Values) (TaskQueryOptions, error) {
+	opts := TaskQueryOptions{
+		Status:         stringParam(values, "incomplete", "status"),
+		Project:        stringParam(values, "", "project", "filter-project"),
+		Area:           stringParam(values, "", "area", "filter-area"),
+		Tag:            stringParam(values, "", "tag", "filter-tag"),
+		Search:         stringParam(values, "", "search"),
+		Query:          stringParam(values, "", "query"),
+		CreatedBefore:  stringParam(values, "", "created-before"),
+		CreatedAfter:   stringParam(values, "", "created-after"),
+		ModifiedBefore: stringParam(values, "", "modified-before"),
+		ModifiedAfter:  stringParam(values, "", "modified-after"),
+		DueBefore:      stringParam(values, "", "due-before"),
+		StartBefore:    stringParam(values, "", "start-before"),
+		Sort:           stringParam(values, "", "sort"),
+	}
+
+	var err error
+	if opts.Limit, err = intParam(values, "limit", 200); err != nil {
+		return opts, err
+	}
+	if opts.Offset, err = intParam(values, "offset", 0); err != nil {
+		return opts, err
+	}
+	if opts.IncludeTrashed, err = boolParam(values, "include-trashed"); err != nil {
+		return opts, err
+	}
+	if opts.All, err = boolParam(values, "all"); err != nil {
+		return opts, err
+	}
+	if opts.IncludeChecklist, err = boolParam(values, "recursive"); err != nil {
+		return opts, err
+	}
+	if opts.Exact, err = boolParam(values, "exact"); err != nil {
+		return opts, err
+	}
+	if values.Has("has-url") {
+		opts.HasURLSet = true
+		if opts.HasURL, err = boolParam(values, "has-url"); err != nil {
+			return opts, err
+		}
+	}
+	return opts, nil
+}
+
+func stringParam(values url.Values, fallback string, names ...string) string {
+	for _, name := range names {
+		if value := strings.TrimSpace(values.Get(name)); value != "" {
+			return value
+		}
+	}
+	return fallback
+}
+
+func intParam(values url.Values, name string, fallback int) (int, error) {
+	raw := strings.TrimSpace(values.Get(name))
+	if raw == "" {
+		return fallback, nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("Error: invalid %s %q", name, raw)
+	}
+	return value, nil
+}
+
+func boolParam(values url.Values, name string) (bool, error) {
+	if !values.Has(name) {
+		return false, nil
+	}
+	raw := strings.TrimSpace(values.Get(name))
+	if raw == "" {
+		return true, nil
+	}
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("Error: invalid %s %q", name, raw)
+	}
+	return value, nil
+}
+
+func decodeAPIRequest(r *http.Request, v any) error {
+	dec := json.NewDecoder(http.MaxBytesReader(nil, r.Body, 1<<20))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("Error: invalid request body: %v", err)
+	}
+	return nil
+}
+
+// apiErrorStatus treats user-facing "Error: ..." messages as bad requests and
+// everything else (database failures) as server errors.
+func apiErrorStatus(err error) int {
+	if strings.HasPrefix(err.Error(), "Error: ") {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+func writeAPIError(w http.ResponseWriter, status int, err error) {
+	message := strings.TrimPrefix(err.Error(), "Error: ")
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func nonNilTasks(tasks []db.Task) []db.Task {
+	if tasks == nil {
+		return []db.Task{}
+	}
+	return tasks
+}
@@ -0,0 +1,228 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ossianhempel/things3-cli/internal/db"
+)
+
+func newTestAPIServer(t *testing.T) (*httptest.Server, *recordLauncher, *recordScriptRunner) {
+	t.Helper()
+	store, err := db.Open(writeTestDB(t))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	launcher := &recordLauncher{}
+	runner := &recordScriptRunner{}
+	app := &App{
+		In:       strings.NewReader(""),
+		Out:      &bytes.Buffer{},
+		Err:      &bytes.Buffer{},
+		Launcher: launcher,
+		Scripter: runner,
+	}
+	server := httptest.NewServer(newAPIHandler(app, store, testAPIToken))
+	t.Cleanup(server.Close)
+	return server, launcher, runner
+}
+
+const testAPIToken = "secret"
+
+// writeAPI sends a write request with a JSON content type and the server
+// token.
+func writeAPI(t *testing.T, method, url, body string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(apiTokenHeader, testAPIToken)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, url, err)
+	}
+	resp.Body.Close()
+	return resp
+}
+
+func getJSON(t *testing.T, url string, wantStatus int, v any) {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("get %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != wantStatus {
+		t.Fatalf("get %s: expected status %d, got %d", url, wantStatus, resp.StatusCode)
+	}
+	if v != nil {
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			t.Fatalf("decode %s: %v", url, err)
+		}
+	}
+}
+
+func taskTitles(tasks []db.Task) []string {
+	titles := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		titles = append(titles, task.Title)
+	}
+	return titles
+}
+
+func TestServeTaskEndpoints(t *testing.T) {
+	server, _, _ := newTestAPIServer(t)
+
+	var tasks []db.Task
+	getJSON(t, server.URL+"/tasks?project=Project%20One", http.StatusOK, &tasks)
+	if len(tasks) != 1 || tasks[0].UUID != "T1" {
+		t.Fatalf("unexpected project tasks: %v", taskTitles(tasks))
+	}
+
+	tasks = nil
+	getJSON(t, server.URL+"/today", http.StatusOK, &tasks)
+	if !strings.Contains(strings.Join(taskTitles(tasks), ","), "Today Task") {
+		t.Fatalf("expected Today Task, got %v", taskTitles(tasks))
+	}
+
+	tasks = nil
+	getJSON(t, server.URL+"/search?q=notes", http.StatusOK, &tasks)
+	if len(tasks) != 1 || tasks[0].Title != "Task One" {
+		t.Fatalf("unexpected search results: %v", taskTitles(tasks))
+	}
+
+	tasks = nil
+	getJSON(t, server.URL+"/tasks?status=completed&limit=5", http.StatusOK, &tasks)
+	if len(tasks) != 1 || tasks[0].UUID != "COMP1" {
+		t.Fatalf("unexpected completed tasks: %v", taskTitles(tasks))
+	}
+
+	getJSON(t, server.URL+"/tasks?limit=abc", http.StatusBadRequest, nil)
+	getJSON(t, server.URL+"/tasks?project=Missing", http.StatusBadRequest, nil)
+	getJSON(t, server.URL+"/search", http.StatusBadRequest, nil)
+}
+
+func TestServeProjectAreaTagEndpoints(t *testing.T) {
+	server, _, _ := newTestAPIServer(t)
+
+	var project apiProjectResponse
+	getJSON(t, server.URL+"/projects/P1", http.StatusOK, &project)
+	if project.Project == nil || project.Project.Title != "Project One" || len(project.Tasks) != 1 {
+		t.Fatalf("unexpected project response: %+v", project)
+	}
+	getJSON(t, server.URL+"/projects/T1", http.StatusNotFound, nil)
+
+	var areas []db.Area
+	getJSON(t, server.URL+"/areas", http.StatusOK, &areas)
+	if len(areas) != 1 || areas[0].Title != "Home" {
+		t.Fatalf("unexpected areas: %+v", areas)
+	}
+
+	var tags []db.Tag
+	getJSON(t, server.URL+"/tags", http.StatusOK, &tags)
+	if len(tags) != 1 || tags[0].Title != "urgent" {
+		t.Fatalf("unexpected tags: %+v", tags)
+	}
+}
+
+func TestServeWriteEndpoints(t *testing.T) {
+	t.Setenv("THINGS_AUTH_TOKEN", "tok")
+	server, launcher, runner := newTestAPIServer(t)
+
+	resp := writeAPI(t, http.MethodPost, server.URL+"/tasks", `{"title":"From API","tags":["a","b"],"when":"today"}`)
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", resp.StatusCode)
+	}
+	url := requireOpenURL(t, launcher)
+	if !strings.HasPrefix(url, "things:///add?") || !strings.Contains(url, "title=From%20API") || !strings.Contains(url, "tags=a%2Cb") {
+		t.Fatalf("unexpected add url: %q", url)
+	}
+
+	writeAPI(t, http.MethodPost, server.URL+"/tasks/T1/complete", "")
+	url = requireOpenURL(t, launcher)
+	if !strings.Contains(url, "id=T1") || !strings.Contains(url, "completed=true") || !strings.Contains(url, "auth-token=tok") {
+		t.Fatalf("unexpected complete url: %q", url)
+	}
+
+	writeAPI(t, http.MethodDelete, server.URL+"/tasks/T1", "")
+	if !strings.Contains(requireScript(t, runner), `"T1"`) {
+		t.Fatalf("unexpected trash script: %q", runner.script)
+	}
+
+	resp = writeAPI(t, http.MethodPost, server.URL+"/tasks", `{"notes":"no title"}`)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for missing title, got %d", resp.StatusCode)
+	}
+}
+
+func TestServeUpdateRequiresToken(t *testing.T) {
+	t.Setenv("THINGS_AUTH_TOKEN", "")
+	server, launcher, _ := newTestAPIServer(t)
+
+	resp := writeAPI(t, http.MethodPatch, server.URL+"/tasks/T1", `{"when":"tomorrow"}`)
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", resp.StatusCode)
+	}
+	if len(launcher.args) != 0 {
+		t.Fatalf("expected no open invocation")
+	}
+}
+
+func TestServeRejectsCrossSiteRequests(t *testing.T) {
+	t.Setenv("THINGS_AUTH_TOKEN", "tok")
+	server, launcher, _ := newTestAPIServer(t)
+
+	tests := []struct {
+		name   string
+		header map[string]string
+		host   string
+		want   int
+	}{
+		{"form post", map[string]string{"Content-Type": "application/x-www-form-urlencoded", apiTokenHeader: testAPIToken}, "", http.StatusUnsupportedMediaType},
+		{"missing token", map[string]string{"Content-Type": "application/json"}, "", http.StatusUnauthorized},
+		{"wrong token", map[string]string{"Content-Type": "application/json", apiTokenHeader: "guess"}, "", http.StatusUnauthorized},
+		{"foreign origin", map[string]string{"Content-Type": "application/json", apiTokenHeader: testAPIToken, "Origin": "https://example.com"}, "", http.StatusForbidden},
+		{"rebound host", map[string]string{"Content-Type": "application/json", apiTokenHeader: testAPIToken}, "attacker.example:7878", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodPost, server.URL+"/tasks/T1/complete", nil)
+			for key, value := range tt.header {
+				req.Header.Set(key, value)
+			}
+			if tt.host != "" {
+				req.Host = tt.host
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("post: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.want {
+				t.Fatalf("expected %d, got %d", tt.want, resp.StatusCode)
+			}
+		})
+	}
+	if len(launcher.args) != 0 {
+		t.Fatalf("expected no open invocation, got %v", launcher.args)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/tasks", nil)
+	req.Host = "attacker.example"
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected 403 for rebound host on reads, got %d", resp.StatusCode)
+	}
+}
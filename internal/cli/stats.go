@@ -0,0 +1,414 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ossianhempel/things3-cli/internal/db"
+	"github.com/spf13/cobra"
+)
+
+// statsRow holds the counts for one group in a stats report.
+type statsRow struct {
+	Group       string   `json:"group"`
+	Completed   int      `json:"completed"`
+	Created     int      `json:"created"`
+	Canceled    int      `json:"canceled"`
+	MedianHours *float64 `json:"median_completion_hours"`
+
+	leadTimes []float64
+}
+
+type statsReport struct {
+	Since   string     `json:"since"`
+	Until   string     `json:"until"`
+	GroupBy string     `json:"group_by"`
+	Groups  []statsRow `json:"groups"`
+	Total   statsRow   `json:"total"`
+}
+
+// statsContext resolves the project and area of todos filed under headings
+// or inside projects, which the task rows do not carry themselves.
+type statsContext struct {
+	projectTitles   map[string]string
+	projectAreas    map[string]string
+	headingProjects map[string]string
+}
+
+// NewStatsCommand builds the stats command.
+func NewStatsCommand(app *App) *cobra.Command {
+	var dbPath string
+	var since string
+	var until string
+	var groupBy string
+	var format string
+	var asJSON bool
+	var noHeader bool
+
+	cmd := &cobra.Command{
+		Use:   "stats [OPTIONS...]",
+		Short: "Summarize completed, created, and canceled todos",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			groupBy = strings.ToLower(strings.TrimSpace(groupBy))
+			switch groupBy {
+			case "week", "project", "area", "tag":
+			default:
+				return fmt.Errorf("Error: invalid --group-by %q (allowed: week, project, area, tag)", groupBy)
+			}
+			format = strings.ToLower(strings.TrimSpace(format))
+			if asJSON {
+				if format != "" && format != "json" {
+					return fmt.Errorf("Error: --json cannot be used with --format %s", format)
+				}
+				format = "json"
+			}
+			if format == "" {
+				format = "table"
+			}
+			switch format {
+			case "table", "json", "csv", "chart":
+			default:
+				return fmt.Errorf("Error: invalid format %q", format)
+			}
+
+			now := time.Now()
+			start, err := parseStatsDate("--since", since, now)
+			if err != nil {
+				return err
+			}
+			last, err := parseStatsDate("--until", until, now)
+			if err != nil {
+				return err
+			}
+			end := last.AddDate(0, 0, 1)
+			if !start.Before(end) {
+				return fmt.Errorf("Error: --since must be before --until")
+			}
+
+			store, _, err := db.OpenDefault(dbPath)
+			if err != nil {
+				return formatDBError(err)
+			}
+			defer store.Close()
+
+			filter := db.TaskFilter{Types: []int{db.TaskTypeTodo}, ExcludeTrashedContext: true}
+			finished, err := store.TasksCompletedBetween(start, end, filter)
+			if err != nil {
+				return formatDBError(err)
+			}
+			created, err := store.TasksCreatedBetween(start, end, filter)
+			if err != nil {
+				return formatDBError(err)
+			}
+			ctx, err := loadStatsContext(store)
+			if err != nil {
+				return formatDBError(err)
+			}
+
+			report := buildStatsReport(created, finished, groupBy, start, end, ctx)
+			return printStatsReport(app.Out, report, format, noHeader)
+		},
+	}
+
+	cmd.Flags().StringVarP(&dbPath, "db", "d", "", "Path to Things database (overrides THINGSDB)")
+	cmd.Flags().StringVar(&dbPath, "database", "", "Alias for --db")
+	cmd.Flags().StringVar(&since, "since", "-4w", "Start date (YYYY-MM-DD or relative, e.g. -30d)")
+	cmd.Flags().StringVar(&until, "until", "today", "End date, inclusive")
+	cmd.Flags().StringVar(&groupBy, "group-by", "week", "Group by week, project, area, or tag")
+	cmd.Flags().StringVar(&format, "format", "", "Output format: table, json, csv, chart")
+	cmd.Flags().BoolVarP(&asJSON, "json", "j", false, "Output JSON")
+	cmd.Flags().BoolVar(&noHeader, "no-header", false, "Suppress header row")
+
+	return cmd
+}
+
+func parseStatsDate(flag, value string, now time.Time) (time.Time, error) {
+	resolved, ok := resolveQueryDate(value, now)
+	if !ok {
+		return time.Time{}, fmt.Errorf("Error: invalid %s date %q", flag, value)
+	}
+	day, err := time.ParseInLocation("2006-01-02", resolved, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Error: invalid %s date %q", flag, value)
+	}
+	return day, nil
+}
+
+func loadStatsContext(store *db.Store) (statsContext, error) {
+	ctx := statsContext{
+		projectTitles:   map[string]string{},
+		projectAreas:    map[string]string{},
+		headingProjects: map[string]string{},
+	}
+	projects, err := store.Projects(db.ProjectFilter{IncludeTrashed: true})
+	if err != nil {
+		return ctx, err
+	}
+	for _, project := range projects {
+		ctx.projectTitles[project.UUID] = project.Title
+		ctx.projectAreas[project.UUID] = project.AreaTitle
+	}
+	headings, err := store.Tasks(db.TaskFilter{Types: []int{db.TaskTypeHeading}, IncludeTrashed: true})
+	if err != nil {
+		return ctx, err
+	}
+	for _, heading := range headings {
+		ctx.headingProjects[heading.UUID] = heading.ProjectID
+	}
+	return ctx, nil
+}
+
+// buildStatsReport counts created todos by creation date and completed or
+// canceled todos by stop date. Weekly reports include empty weeks so charts
+// keep their time axis; other groupings are ordered by completed count.
+func buildStatsReport(created, finished []db.Task, groupBy string, start, end time.Time, ctx statsContext) statsReport {
+	report := statsReport{
+		Since:   start.Format("2006-01-02"),
+		Until:   end.Add(-time.Second).Format("2006-01-02"),
+		GroupBy: groupBy,
+	}
+	rows := map[string]*statsRow{}
+	var order []string
+	row := func(group string) *statsRow {
+		if r, ok := rows[group]; ok {
+			return r
+		}
+		rows[group] = &statsRow{Group: group}
+		order = append(order, group)
+		return rows[group]
+	}
+	if groupBy == "week" {
+		for week := statsWeekStart(start); week.Before(end); week = week.AddDate(0, 0, 7) {
+			row(statsWeekLabel(week))
+		}
+	}
+
+	for _, task := range created {
+		at, ok := parseStatsTimestamp(task.Created)
+		if !ok {
+			continue
+		}
+		report.Total.Created++
+		for _, group := range ctx.groups(task, groupBy, at) {
+			row(group).Created++
+		}
+	}
+	for _, task := range finished {
+		at, ok := parseStatsTimestamp(task.StopDate)
+		if !ok {
+			continue
+		}
+		lead := -1.0
+		if task.Status == db.StatusCompleted {
+			report.Total.Completed++
+			if createdAt, ok := parseStatsTimestamp(task.Created); ok && !at.Before(createdAt) {
+				lead = at.Sub(createdAt).Hours()
+				report.Total.leadTimes = append(report.Total.leadTimes, lead)
+			}
+		} else {
+			report.Total.Canceled++
+		}
+		for _, group := range ctx.groups(task, groupBy, at) {
+			r := row(group)
+			if task.Status != db.StatusCompleted {
+				r.Canceled++
+				continue
+			}
+			r.Completed++
+			if lead >= 0 {
+				r.leadTimes = append(r.leadTimes, lead)
+			}
+		}
+	}
+
+	if groupBy != "week" {
+		sort.SliceStable(order, func(i, j int) bool {
+			left, right := rows[order[i]], rows[order[j]]
+			if left.Completed != right.Completed {
+				return left.Completed > right.Completed
+			}
+			return strings.ToLower(left.Group) < strings.ToLower(right.Group)
+		})
+	}
+	report.Groups = make([]statsRow, 0, len(order))
+	for _, group := range order {
+		r := rows[group]
+		r.MedianHours = medianHours(r.leadTimes)
+		report.Groups = append(report.Groups, *r)
+	}
+	report.Total.Group = "TOTAL"
+	report.Total.MedianHours = medianHours(report.Total.leadTimes)
+	return report
+}
+
+func (ctx statsContext) groups(task db.Task, groupBy string, at time.Time) []string {
+	projectID := task.ProjectID
+	if projectID == "" {
+		projectID = ctx.headingProjects[task.HeadingID]
+	}
+	switch groupBy {
+	case "week":
+		return []string{statsWeekLabel(statsWeekStart(at))}
+	case "project":
+		title := task.ProjectTitle
+		if title == "" {
+			title = ctx.projectTitles[projectID]
+		}
+		if title == "" {
+			return []string{"(no project)"}
+		}
+		return []string{title}
+	case "area":
+		title := task.AreaTitle
+		if title == "" {
+			title = ctx.projectAreas[projectID]
+		}
+		if title == "" {
+			return []string{"(no area)"}
+		}
+		return []string{title}
+	default:
+		if len(task.Tags) == 0 {
+			return []string{"(no tag)"}
+		}
+		return task.Tags
+	}
+}
+
+func parseStatsTimestamp(value string) (time.Time, bool) {
+	parsed, err := time.ParseInLocation("2006-01-02 15:04:05", value, time.Local)
+	return parsed, err == nil
+}
+
+// statsWeekStart returns the Monday that starts t's week.
+func statsWeekStart(t time.Time) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+func statsWeekLabel(weekStart time.Time) string {
+	year, week := weekStart.ISOWeek()
+	return fmt.Sprintf("%d-W%02d", year, week)
+}
+
+func medianHours(values []float64) *float64 {
+	if len(values) == 0 {
+		return nil
+	}
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	median := sorted[mid]
+	if len(sorted)%2 == 0 {
+		median = (sorted[mid-1] + sorted[mid]) / 2
+	}
+	median = math.Round(median*10) / 10
+	return &median
+}
+
+// formatLeadTime renders hours as hours under a day and as days otherwise.
+func formatLeadTime(hours *float64) string {
+	if hours == nil {
+		return ""
+	}
+	if *hours < 24 {
+		return strconv.FormatFloat(*hours, 'f', 1, 64) + "h"
+	}
+	return strconv.FormatFloat(*hours/24, 'f', 1, 64) + "d"
+}
+
+func printStatsReport(out io.Writer, report statsReport, format string, noHeader bool) error {
+	groupHeader := strings.ToUpper(report.GroupBy)
+	switch format {
+	case "json":
+		if report.Groups == nil {
+			report.Groups = []statsRow{}
+		}
+		return json.NewEncoder(out).Encode(report)
+	case "csv":
+		w := csv.NewWriter(out)
+		if !noHeader {
+			_ = w.Write([]string{groupHeader, "COMPLETED", "CREATED", "CANCELED", "MEDIAN_HOURS"})
+		}
+		for _, row := range report.Groups {
+			median := ""
+			if row.MedianHours != nil {
+				median = strconv.FormatFloat(*row.MedianHours, 'f', -1, 64)
+			}
+			_ = w.Write([]string{row.Group, strconv.Itoa(row.Completed), strconv.Itoa(row.Created), strconv.Itoa(row.Canceled), median})
+		}
+		w.Flush()
+		return w.Error()
+	case "chart":
+		return printStatsChart(out, report)
+	default:
+		w := tabwriter.NewWriter(out, 0, 2, 2, ' ', 0)
+		if !noHeader {
+			fmt.Fprintf(w, "%s\tCOMPLETED\tCREATED\tCANCELED\tMEDIAN_TIME\n", groupHeader)
+		}
+		for _, row := range append(report.Groups, report.Total) {
+			fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%s\n", row.Group, row.Completed, row.Created, row.Canceled, formatLeadTime(row.MedianHours))
+		}
+		return w.Flush()
+	}
+}
+
+// printStatsChart draws one sparkline per metric across the report's groups.
+func printStatsChart(out io.Writer, report statsReport) error {
+	if len(report.Groups) == 0 {
+		_, err := fmt.Fprintf(out, "No todos created or finished between %s and %s.\n", report.Since, report.Until)
+		return err
+	}
+	metrics := []struct {
+		label string
+		value func(statsRow) int
+		total int
+	}{
+		{"completed", func(r statsRow) int { return r.Completed }, report.Total.Completed},
+		{"created", func(r statsRow) int { return r.Created }, report.Total.Created},
+		{"canceled", func(r statsRow) int { return r.Canceled }, report.Total.Canceled},
+	}
+	first, last := report.Groups[0].Group, report.Groups[len(report.Groups)-1].Group
+	fmt.Fprintf(out, "%-10s %s … %s (%d %ss)\n", "", first, last, len(report.Groups), report.GroupBy)
+	for _, metric := range metrics {
+		values := make([]int, len(report.Groups))
+		for i, row := range report.Groups {
+			values[i] = metric.value(row)
+		}
+		fmt.Fprintf(out, "%-10s %s  %d\n", metric.label, sparkline(values), metric.total)
+	}
+	if median := formatLeadTime(report.Total.MedianHours); median != "" {
+		fmt.Fprintf(out, "%-10s %s\n", "median", median)
+	}
+	return nil
+}
+
+var sparkLevels = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline scales values to block characters; zero is always the lowest
+// block and any non-zero value is at least one step above it.
+func sparkline(values []int) string {
+	peak := 0
+	for _, value := range values {
+		peak = max(peak, value)
+	}
+	var b strings.Builder
+	for _, value := range values {
+		level := 0
+		if value > 0 && peak > 0 {
+			level = 1 + value*(len(sparkLevels)-2)/peak
+		}
+		b.WriteRune(sparkLevels[level])
+	}
+	return b.String()
+}
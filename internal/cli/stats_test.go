@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ossianhempel/things3-cli/internal/db"
+)
+
+func TestStatsCommandWeeklyTotals(t *testing.T) {
+	out, err := runCommand(t, &App{}, "stats", "--db", writeTestDB(t), "--since", "-1w", "--json")
+	if err != nil {
+		t.Fatalf("stats failed: %v", err)
+	}
+	var report struct {
+		GroupBy string     `json:"group_by"`
+		Groups  []statsRow `json:"groups"`
+		Total   statsRow   `json:"total"`
+	}
+	if err := json.Unmarshal([]byte(out), &report); err != nil {
+		t.Fatalf("decode stats: %v\n%s", err, out)
+	}
+	if report.GroupBy != "week" || len(report.Groups) < 2 {
+		t.Fatalf("expected at least two weekly groups, got %+v", report)
+	}
+	total := report.Total
+	if total.Completed != 1 || total.Canceled != 1 || total.Created != 9 {
+		t.Fatalf("unexpected totals: %+v", total)
+	}
+	if total.MedianHours == nil || *total.MedianHours != 0 {
+		t.Fatalf("expected zero median lead time, got %v", total.MedianHours)
+	}
+	current := report.Groups[len(report.Groups)-1]
+	if current.Group != statsWeekLabel(statsWeekStart(time.Now())) || current.Created != 9 {
+		t.Fatalf("expected this week's group last, got %+v", current)
+	}
+}
+
+func TestStatsCommandGroupByAreaTable(t *testing.T) {
+	out, err := runCommand(t, &App{}, "stats", "--db", writeTestDB(t), "--group-by", "area")
+	if err != nil {
+		t.Fatalf("stats failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[0], "AREA") {
+		t.Fatalf("unexpected table:\n%s", out)
+	}
+	if fields := strings.Fields(lines[1]); strings.Join(fields, " ") != "(no area) 1 8 1 0.0h" {
+		t.Fatalf("unexpected first row: %q", lines[1])
+	}
+	if fields := strings.Fields(lines[2]); strings.Join(fields, " ") != "Home 0 1 0" {
+		t.Fatalf("unexpected area row: %q", lines[2])
+	}
+	if !strings.HasPrefix(lines[3], "TOTAL") {
+		t.Fatalf("expected total row, got %q", lines[3])
+	}
+
+	if _, err := runCommand(t, &App{}, "stats", "--db", writeTestDB(t), "--group-by", "month"); err == nil || !strings.Contains(err.Error(), "invalid --group-by") {
+		t.Fatalf("expected group-by error, got %v", err)
+	}
+}
+
+func TestBuildStatsReport(t *testing.T) {
+	start := time.Date(2026, 9, 1, 0, 0, 0, 0, time.Local)
+	end := time.Date(2026, 9, 15, 0, 0, 0, 0, time.Local)
+	ctx := statsContext{
+		projectTitles:   map[string]string{"P1": "Launch"},
+		projectAreas:    map[string]string{"P1": "Work"},
+		headingProjects: map[string]string{"H1": "P1"},
+	}
+	finished := []db.Task{
+		{UUID: "A", Status: db.StatusCompleted, HeadingID: "H1", Tags: []string{"deep", "work"}, Created: "2026-09-01 09:00:00", StopDate: "2026-09-01 15:00:00"},
+		{UUID: "B", Status: db.StatusCompleted, ProjectID: "P1", ProjectTitle: "Launch", Tags: []string{"deep"}, Created: "2026-08-20 09:00:00", StopDate: "2026-09-03 09:00:00"},
+		{UUID: "C", Status: db.StatusCanceled, Created: "2026-09-02 09:00:00", StopDate: "2026-09-10 09:00:00"},
+	}
+	created := []db.Task{finished[0], finished[2]}
+
+	report := buildStatsReport(created, finished, "week", start, end, ctx)
+	var weeks []string
+	for _, row := range report.Groups {
+		weeks = append(weeks, row.Group)
+	}
+	if got := strings.Join(weeks, ","); got != "2026-W36,2026-W37,2026-W38" {
+		t.Fatalf("unexpected weeks: %s", got)
+	}
+	if report.Groups[0].Completed != 2 || report.Groups[1].Canceled != 1 || report.Groups[2].Completed != 0 {
+		t.Fatalf("unexpected weekly counts: %+v", report.Groups)
+	}
+	if median := formatLeadTime(report.Total.MedianHours); median != "7.1d" {
+		t.Fatalf("unexpected median %s", median)
+	}
+
+	report = buildStatsReport(created, finished, "area", start, end, ctx)
+	if report.Groups[0].Group != "Work" || report.Groups[0].Completed != 2 {
+		t.Fatalf("expected heading todo to resolve its area, got %+v", report.Groups)
+	}
+
+	report = buildStatsReport(created, finished, "tag", start, end, ctx)
+	var tags []string
+	for _, row := range report.Groups {
+		tags = append(tags, row.Group)
+	}
+	if got := strings.Join(tags, ","); got != "deep,work,(no tag)" {
+		t.Fatalf("unexpected tag groups: %s", got)
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	if got := sparkline([]int{0, 1, 4, 8}); got != "▁▂▅█" {
+		t.Fatalf("unexpected sparkline %q", got)
+	}
+	if got := sparkline([]int{0, 0}); got != "▁▁" {
+		t.Fatalf("unexpected empty sparkline %q", got)
+	}
+}
@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -9,28 +10,66 @@ import (
 )
 
 type TaskQueryOptions struct {
-	Status           string
-	IncludeTrashed   bool
-	All              bool
-	Project          string
-	Area             string
-	Tag              string
-	Search           string
-	Query            string
-	Limit            int
-	Offset           int
-	IncludeChecklist bool
-	CreatedBefore    string
-	CreatedAfter     string
-	ModifiedBefore   string
-	ModifiedAfter    string
-	DueBefore        string
-	StartBefore      string
-	IncludeRepeating bool
-	RepeatingOnly    bool
-	HasURL           bool
-	HasURLSet        bool
-	Sort             string
+	Status           string `json:"status,omitempty"`
+	IncludeTrashed   bool   `json:"include_trashed,omitempty"`
+	All              bool   `json:"all,omitempty"`
+	Project          string `json:"project,omitempty"`
+	Area             string `json:"area,omitempty"`
+	Tag              string `json:"tag,omitempty"`
+	Search           string `json:"search,omitempty"`
+	Query            string `json:"query,omitempty"`
+	Limit            int    `json:"limit,omitempty"`
+	Offset           int    `json:"offset,omitempty"`
+	IncludeChecklist bool   `json:"include_checklist,omitempty"`
+	CreatedBefore    string `json:"created_before,omitempty"`
+	CreatedAfter     string `json:"created_after,omitempty"`
+	ModifiedBefore   string `json:"modified_before,omitempty"`
+	ModifiedAfter    string `json:"modified_after,omitempty"`
+	DueBefore        string `json:"due_before,omitempty"`
+	StartBefore      string `json:"start_before,omitempty"`
+	IncludeRepeating bool   `json:"include_repeating,omitempty"`
+	RepeatingOnly    bool   `json:"repeating_only,omitempty"`
+	HasURL           bool   `json:"has_url,omitempty"`
+	HasURLSet        bool   `json:"has_url_set,omitempty"`
+	Sort             string `json:"sort,omitempty"`
+	Exact            bool   `json:"exact,omitempty"`
+}
+
+// resolveNamed resolves a project, area, or tag name with the strict resolver
+// when exact is set and the fuzzy one otherwise.
+func resolveNamed(strict, fuzzy func(string) (string, error), exact bool, input string) (string, error) {
+	if exact {
+		return strict(input)
+	}
+	return fuzzy(input)
+}
+
+// resolveListOption turns a fuzzy --list title into a --list-id so the URL
+// scheme, which only matches exact titles, finds the intended project or area.
+// Exact titles, explicit IDs, and an unreadable database pass through
+// unchanged; an ambiguous title is an error.
+func resolveListOption(dbPath string, exact bool, list, listID *string) error {
+	if exact || strings.TrimSpace(*list) == "" || *listID != "" {
+		return nil
+	}
+	store, _, err := db.OpenDefault(dbPath)
+	if err != nil {
+		return nil
+	}
+	defer store.Close()
+	candidate, matched, err := store.ResolveListFuzzy(*list)
+	if err != nil {
+		var ambiguous *db.AmbiguousError
+		if errors.As(err, &ambiguous) {
+			return fmt.Errorf("Error: %s", err)
+		}
+		return nil
+	}
+	if !matched {
+		*listID = candidate.UUID
+		*list = ""
+	}
+	return nil
 }
 
 type TaskSortField struct {
@@ -51,7 +90,7 @@ func buildTaskFilter(store *db.Store, opts TaskQueryOptions) (db.TaskFilter, []T
 
 	projectID := ""
 	if opts.Project != "" {
-		projectID, err = store.ResolveProjectID(opts.Project)
+		projectID, err = resolveNamed(store.ResolveProjectID, store.ResolveProjectIDFuzzy, opts.Exact, opts.Project)
 		if err != nil {
 			return db.TaskFilter{}, nil, fmt.Errorf("Error: %s", err)
 		}
@@ -59,7 +98,7 @@ func buildTaskFilter(store *db.Store, opts TaskQueryOptions) (db.TaskFilter, []T
 
 	areaID := ""
 	if opts.Area != "" {
-		areaID, err = store.ResolveAreaID(opts.Area)
+		areaID, err = resolveNamed(store.ResolveAreaID, store.ResolveAreaIDFuzzy, opts.Exact, opts.Area)
 		if err != nil {
 			return db.TaskFilter{}, nil, fmt.Errorf("Error: %s", err)
 		}
@@ -67,7 +106,7 @@ func buildTaskFilter(store *db.Store, opts TaskQueryOptions) (db.TaskFilter, []T
 
 	tagID := ""
 	if opts.Tag != "" {
-		tagID, err = store.ResolveTagID(opts.Tag)
+		tagID, err = resolveNamed(store.ResolveTagID, store.ResolveTagIDFuzzy, opts.Exact, opts.Tag)
 		if err != nil {
 			return db.TaskFilter{}, nil, fmt.Errorf("Error: %s", err)
 		}
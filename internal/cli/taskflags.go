@@ -2,6 +2,10 @@ package cli
 
 import "github.com/spf13/cobra"
 
+// configDefaultAnnotation marks the task list flags that take their default
+// from the config file's format and limit settings.
+const configDefaultAnnotation = "things3-cli/config-default"
+
 func addTaskQueryFlags(cmd *cobra.Command, opts *TaskQueryOptions, includeSearch bool, includeQuery bool) {
 	flags := cmd.Flags()
 	flags.StringVar(&opts.Status, "status", opts.Status, "Filter by status: incomplete, completed, canceled, any")
@@ -12,13 +16,15 @@ func addTaskQueryFlags(cmd *cobra.Command, opts *TaskQueryOptions, includeSearch
 	flags.StringVarP(&opts.Tag, "filter-tag", "t", "", "Filter by tag title or ID")
 	flags.StringVar(&opts.Tag, "filtertag", "", "Alias for --filter-tag")
 	flags.StringVar(&opts.Tag, "tag", "", "Alias for --filter-tag")
+	flags.BoolVar(&opts.Exact, "exact", false, "Match project, area, and tag names exactly instead of fuzzily")
 	if includeSearch {
 		flags.StringVar(&opts.Search, "search", "", "Search title or notes (case-insensitive substring)")
 	}
 	if includeQuery {
-		flags.StringVar(&opts.Query, "query", "", "Rich query (boolean, fields, regex; e.g. title:/regex/ AND tag:reading)")
+		flags.StringVar(&opts.Query, "query", "", "Rich query (boolean, fields, regex, dates; e.g. tag:reading AND deadline < +7d)")
 	}
 	flags.IntVar(&opts.Limit, "limit", opts.Limit, "Limit number of results (0 = no limit)")
+	_ = flags.SetAnnotation("limit", configDefaultAnnotation, []string{"true"})
 	flags.IntVar(&opts.Offset, "offset", 0, "Offset results for pagination")
 	flags.BoolVar(&opts.IncludeTrashed, "include-trashed", false, "Include trashed tasks")
 	flags.BoolVar(&opts.All, "all", false, "Include completed, canceled, and trashed tasks")
@@ -36,6 +42,7 @@ func addTaskQueryFlags(cmd *cobra.Command, opts *TaskQueryOptions, includeSearch
 func addTaskOutputFlags(cmd *cobra.Command, format *string, selectRaw *string, asJSON *bool, noHeader *bool) {
 	flags := cmd.Flags()
 	flags.StringVar(format, "format", "", "Output format: table, json, jsonl, csv")
+	_ = flags.SetAnnotation("format", configDefaultAnnotation, []string{"true"})
 	flags.StringVar(selectRaw, "select", "", "Select fields (comma-separated)")
 	flags.BoolVarP(asJSON, "json", "j", false, "Output JSON")
 	flags.BoolVar(noHeader, "no-header", false, "Suppress header row")
@@ -111,7 +111,11 @@ var taskFieldHeaders = map[string]string{
 	"notes":        "NOTES",
 	"start":        "START",
 	"start_date":   "START_DATE",
+	"evening":      "EVENING",
 	"repeating":    "REPEATING",
+	"repeat_rule":  "REPEAT_RULE",
+	"repeat_next":  "REPEAT_NEXT",
+	"repeat_until": "REPEAT_UNTIL",
 	"deadline":     "DEADLINE",
 	"stop_date":    "STOP_DATE",
 	"created":      "CREATED",
@@ -120,6 +124,7 @@ var taskFieldHeaders = map[string]string{
 	"today_index":  "TODAY_INDEX",
 	"tags":         "TAGS",
 	"type":         "TYPE",
+	"snippet":      "SNIPPET",
 }
 
 var defaultTaskTableFields = []string{
@@ -162,8 +167,16 @@ func taskFieldValue(task db.Task, field string) any {
 		return task.Start
 	case "start_date":
 		return task.StartDate
+	case "evening":
+		return task.Evening
 	case "repeating":
 		return task.Repeating
+	case "repeat_rule":
+		return task.RepeatRule
+	case "repeat_next":
+		return task.RepeatNext
+	case "repeat_until":
+		return task.RepeatUntil
 	case "deadline":
 		return task.Deadline
 	case "stop_date":
@@ -183,6 +196,8 @@ func taskFieldValue(task db.Task, field string) any {
 		return task.Tags
 	case "type":
 		return task.Type
+	case "snippet":
+		return task.Snippet
 	default:
 		return ""
 	}
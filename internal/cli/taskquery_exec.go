@@ -1,6 +1,9 @@
 package cli
 
-import "github.com/ossianhempel/things3-cli/internal/db"
+import (
+	"github.com/ossianhempel/things3-cli/internal/db"
+	"github.com/ossianhempel/things3-cli/internal/repeat"
+)
 
 func fetchTasks(store *db.Store, runner func(db.TaskFilter) ([]db.Task, error), opts TaskQueryOptions, forcePost bool, types []int) ([]db.Task, error) {
 	filter, sortSpec, err := buildTaskFilter(store, opts)
@@ -15,8 +18,11 @@ func fetchTasks(store *db.Store, runner func(db.TaskFilter) ([]db.Task, error),
 	if err != nil {
 		return nil, err
 	}
+	compiled, residual := compileQuery(queryExpr)
+	filter.Where = compiled.Where
+	filter.WhereArgs = compiled.Args
 
-	postProcess := forcePost || queryExpr != nil
+	postProcess := forcePost || residual != nil
 	if postProcess {
 		filter.Limit = 0
 		filter.Offset = 0
@@ -27,8 +33,8 @@ func fetchTasks(store *db.Store, runner func(db.TaskFilter) ([]db.Task, error),
 		return nil, err
 	}
 
-	if queryExpr != nil {
-		tasks = filterTasksByQuery(tasks, queryExpr)
+	if residual != nil {
+		tasks = filterTasksByQuery(tasks, residual)
 	}
 
 	if postProcess && len(sortSpec) > 0 {
@@ -39,9 +45,47 @@ func fetchTasks(store *db.Store, runner func(db.TaskFilter) ([]db.Task, error),
 		tasks = applyOffsetLimit(tasks, opts.Limit, opts.Offset)
 	}
 
+	if err := annotateRepeatRules(store, tasks); err != nil {
+		return nil, err
+	}
+
 	return tasks, nil
 }
 
+// annotateRepeatRules fills the repeat_* fields of repeating tasks from their
+// stored recurrence rules. Rules that fail to decode are left blank.
+func annotateRepeatRules(store *db.Store, tasks []db.Task) error {
+	var ids []string
+	for _, task := range tasks {
+		if task.Repeating {
+			ids = append(ids, task.UUID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	rules, err := store.RecurrenceRules(ids)
+	if err != nil {
+		return err
+	}
+	for i := range tasks {
+		rule, ok := rules[tasks[i].UUID]
+		if !ok {
+			continue
+		}
+		tasks[i].RepeatNext = rule.NextStartDate
+		spec, err := repeat.Parse(rule.Rule)
+		if err != nil {
+			continue
+		}
+		tasks[i].RepeatRule = spec.Describe()
+		if spec.EndDate != nil {
+			tasks[i].RepeatUntil = spec.EndDate.Format("2006-01-02")
+		}
+	}
+	return nil
+}
+
 func applyOffsetLimit(tasks []db.Task, limit int, offset int) []db.Task {
 	if offset < 0 {
 		offset = 0
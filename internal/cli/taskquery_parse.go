@@ -5,6 +5,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/ossianhempel/things3-cli/internal/db"
 )
@@ -70,6 +71,21 @@ func (q queryPredicate) Match(task db.Task) bool {
 		return matchURLPredicate(q.Matcher, task.Notes)
 	case "repeating":
 		return matchBoolPredicate(q.Matcher, task.Repeating)
+	case "evening":
+		return matchBoolPredicate(q.Matcher, task.Evening)
+	case "status":
+		return matchStatusPredicate(q.Matcher, task.Status)
+	case "type":
+		return matchTypePredicate(q.Matcher, task.Type)
+	case "start":
+		if q.Matcher.Regex != nil {
+			return q.Matcher.Regex.MatchString(task.Start)
+		}
+		return strings.EqualFold(task.Start, q.Matcher.Value)
+	case "has":
+		return matchHasPredicate(q.Matcher, task)
+	case "deadline", "created", "modified", "stop":
+		return q.Matcher.Match(queryDateField(task, field))
 	default:
 		if q.Field != "" {
 			return false
@@ -130,12 +146,208 @@ func matchBoolPredicate(m matcher, value bool) bool {
 	return strings.Contains(text, valueText)
 }
 
+func matchStatusPredicate(m matcher, status int) bool {
+	if m.Regex != nil {
+		return m.Regex.MatchString(db.StatusLabel(status))
+	}
+	want, err := db.ParseStatus(m.Value)
+	if err != nil {
+		return false
+	}
+	return want == nil || *want == status
+}
+
+func matchTypePredicate(m matcher, taskType string) bool {
+	if m.Regex != nil {
+		return m.Regex.MatchString(taskType)
+	}
+	return normalizeQueryType(m.Value) == taskType
+}
+
+func normalizeQueryType(value string) string {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "todo", "to-do":
+		return "to-do"
+	case "project":
+		return "project"
+	case "heading":
+		return "heading"
+	default:
+		return ""
+	}
+}
+
+func matchHasPredicate(m matcher, task db.Task) bool {
+	if m.Regex != nil {
+		for _, name := range queryHasValues {
+			if m.Regex.MatchString(name) && taskHas(task, name) {
+				return true
+			}
+		}
+		return false
+	}
+	return taskHas(task, strings.TrimSpace(m.Value))
+}
+
+var queryHasValues = []string{"checklist", "deadline", "notes", "tags", "url"}
+
+func taskHas(task db.Task, name string) bool {
+	switch name {
+	case "checklist":
+		return task.HasChecklist || len(task.Checklist) > 0
+	case "deadline":
+		return task.Deadline != "" && !isThingsSentinelDate(task.Deadline)
+	case "notes":
+		return strings.TrimSpace(task.Notes) != ""
+	case "tags":
+		return len(task.Tags) > 0
+	case "url":
+		return notesHasURL(task.Notes)
+	default:
+		return false
+	}
+}
+
+// queryCompare compares a date field against a resolved YYYY-MM-DD date.
+// Timestamps are compared by their local calendar day.
+type queryCompare struct {
+	Field string
+	Op    string
+	Date  string
+}
+
+func (q queryCompare) Match(task db.Task) bool {
+	value := queryDateField(task, q.Field)
+	if value == "" || (q.Field == "deadline" && isThingsSentinelDate(value)) {
+		return false
+	}
+	if len(value) > len("2006-01-02") {
+		value = value[:len("2006-01-02")]
+	}
+	switch q.Op {
+	case "<":
+		return value < q.Date
+	case "<=":
+		return value <= q.Date
+	case ">":
+		return value > q.Date
+	case ">=":
+		return value >= q.Date
+	default:
+		return value == q.Date
+	}
+}
+
+func isQueryDateField(field string) bool {
+	switch field {
+	case "deadline", "start", "created", "modified", "stop":
+		return true
+	default:
+		return false
+	}
+}
+
+func queryDateField(task db.Task, field string) string {
+	switch field {
+	case "deadline":
+		return task.Deadline
+	case "start":
+		return task.StartDate
+	case "created":
+		return task.Created
+	case "modified":
+		return task.Modified
+	case "stop":
+		return task.StopDate
+	default:
+		return ""
+	}
+}
+
+// resolveQueryDate turns an absolute or relative date into YYYY-MM-DD.
+// Relative forms: today, tomorrow, yesterday, +3d/-2w/+1m/-1y, and
+// next-<weekday>/last-<weekday>.
+func resolveQueryDate(value string, now time.Time) (string, bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch value {
+	case "today":
+		return dateString(today), true
+	case "tomorrow":
+		return dateString(today.AddDate(0, 0, 1)), true
+	case "yesterday":
+		return dateString(today.AddDate(0, 0, -1)), true
+	}
+	if rest, ok := strings.CutPrefix(value, "next-"); ok {
+		weekday, ok := parseQueryWeekday(rest)
+		if !ok {
+			return "", false
+		}
+		days := (int(weekday) - int(today.Weekday()) + 7) % 7
+		if days == 0 {
+			days = 7
+		}
+		return dateString(today.AddDate(0, 0, days)), true
+	}
+	if rest, ok := strings.CutPrefix(value, "last-"); ok {
+		weekday, ok := parseQueryWeekday(rest)
+		if !ok {
+			return "", false
+		}
+		days := (int(today.Weekday()) - int(weekday) + 7) % 7
+		if days == 0 {
+			days = 7
+		}
+		return dateString(today.AddDate(0, 0, -days)), true
+	}
+	if len(value) >= 3 && (value[0] == '+' || value[0] == '-') {
+		n, err := strconv.Atoi(value[1 : len(value)-1])
+		if err != nil {
+			return "", false
+		}
+		if value[0] == '-' {
+			n = -n
+		}
+		switch value[len(value)-1] {
+		case 'd':
+			return dateString(today.AddDate(0, 0, n)), true
+		case 'w':
+			return dateString(today.AddDate(0, 0, 7*n)), true
+		case 'm':
+			return dateString(today.AddDate(0, n, 0)), true
+		case 'y':
+			return dateString(today.AddDate(n, 0, 0)), true
+		}
+		return "", false
+	}
+	parsed, err := time.ParseInLocation("2006-01-02", value, now.Location())
+	if err != nil {
+		return "", false
+	}
+	return dateString(parsed), true
+}
+
+func parseQueryWeekday(value string) (time.Weekday, bool) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		name := strings.ToLower(day.String())
+		if value == name || value == name[:3] {
+			return day, true
+		}
+	}
+	return 0, false
+}
+
 func notesHasURL(notes string) bool {
 	notes = strings.ToLower(notes)
 	return strings.Contains(notes, "http://") || strings.Contains(notes, "https://")
 }
 
 func parseRichQuery(input string) (queryExpr, error) {
+	return parseRichQueryAt(input, time.Now())
+}
+
+// parseRichQueryAt parses a rich query, resolving relative dates against now.
+func parseRichQueryAt(input string, now time.Time) (queryExpr, error) {
 	input = strings.TrimSpace(input)
 	if input == "" {
 		return nil, nil
@@ -145,7 +357,7 @@ func parseRichQuery(input string) (queryExpr, error) {
 	if err != nil {
 		return nil, err
 	}
-	parser := queryParser{tokens: tokens}
+	parser := queryParser{tokens: tokens, now: now}
 	expr, err := parser.parseExpression()
 	if err != nil {
 		return nil, err
@@ -182,6 +394,7 @@ const (
 	tokenLParen
 	tokenRParen
 	tokenColon
+	tokenCompare
 )
 
 type token struct {
@@ -193,6 +406,10 @@ type token struct {
 type queryLexer struct {
 	input []rune
 	pos   int
+	// prev and prevPrev are the last two tokens, used to read comparison
+	// operators only after a date field.
+	prev     token
+	prevPrev token
 }
 
 func newQueryLexer(input string) *queryLexer {
@@ -210,16 +427,39 @@ func (l *queryLexer) tokens() ([]token, error) {
 		if tok.typ == tokenEOF {
 			break
 		}
+		l.prevPrev, l.prev = l.prev, tok
 	}
 	return tokens, nil
 }
 
+// expectsCompare reports whether the next token follows "FIELD" or "FIELD:"
+// for a date field, the only place <, > and = are operators. Elsewhere they
+// are part of the word, as in notes:key=value.
+func (l *queryLexer) expectsCompare() bool {
+	field := l.prev
+	if field.typ == tokenColon {
+		field = l.prevPrev
+	} else if l.prevPrev.typ == tokenColon {
+		// The word is a value, as in tag:deadline.
+		return false
+	}
+	return field.typ == tokenIdent && isQueryDateField(strings.ToLower(field.value))
+}
+
 func (l *queryLexer) nextToken() (token, error) {
 	l.skipWhitespace()
 	if l.pos >= len(l.input) {
 		return token{typ: tokenEOF}, nil
 	}
 	ch := l.input[l.pos]
+	if isCompareChar(ch) && l.expectsCompare() {
+		l.pos++
+		if ch != '=' && l.pos < len(l.input) && l.input[l.pos] == '=' {
+			l.pos++
+			return token{typ: tokenCompare, value: string(ch) + "="}, nil
+		}
+		return token{typ: tokenCompare, value: string(ch)}, nil
+	}
 	switch ch {
 	case '(':
 		l.pos++
@@ -255,6 +495,10 @@ func (l *queryLexer) nextToken() (token, error) {
 		if isDelimiter(ch) {
 			break
 		}
+		// "deadline<today" ends the field name at the operator.
+		if isCompareChar(ch) && isQueryDateField(strings.ToLower(string(l.input[start:l.pos]))) {
+			break
+		}
 		l.pos++
 	}
 	if start == l.pos {
@@ -362,9 +606,14 @@ func isDelimiter(ch rune) bool {
 	}
 }
 
+func isCompareChar(ch rune) bool {
+	return ch == '<' || ch == '>' || ch == '='
+}
+
 type queryParser struct {
 	tokens []token
 	pos    int
+	now    time.Time
 }
 
 func (p *queryParser) parseExpression() (queryExpr, error) {
@@ -437,9 +686,12 @@ func (p *queryParser) parsePrimary() (queryExpr, error) {
 func (p *queryParser) parsePredicate() (queryExpr, error) {
 	field := ""
 	valueToken := p.next()
-	if valueToken.typ == tokenIdent && p.peek().typ == tokenColon {
-		field = valueToken.value
-		p.next()
+	if valueToken.typ == tokenIdent && (p.peek().typ == tokenColon || p.peek().typ == tokenCompare) {
+		field = strings.ToLower(valueToken.value)
+		p.match(tokenColon)
+		if isQueryDateField(field) {
+			return p.parseDatePredicate(field)
+		}
 		valueToken = p.next()
 	}
 
@@ -453,9 +705,97 @@ func (p *queryParser) parsePredicate() (queryExpr, error) {
 	if err != nil {
 		return nil, err
 	}
+	if err := validateQueryPredicate(field, matcher); err != nil {
+		return nil, err
+	}
 	return queryPredicate{Field: field, Matcher: matcher}, nil
 }
 
+// parseDatePredicate handles "deadline < DATE", "deadline:<DATE" and
+// "deadline:DATE". start also accepts inbox, anytime, or someday.
+func (p *queryParser) parseDatePredicate(field string) (queryExpr, error) {
+	op := ""
+	if p.peek().typ == tokenCompare {
+		op = p.next().value
+	}
+	valueToken := p.next()
+	switch valueToken.typ {
+	case tokenIdent, tokenString:
+		if date, ok := resolveQueryDate(valueToken.value, p.now); ok {
+			if op == "" {
+				op = "="
+			}
+			return queryCompare{Field: field, Op: op, Date: date}, nil
+		}
+		if op == "" && field == "start" {
+			matcher, err := buildMatcher(valueToken)
+			if err != nil {
+				return nil, err
+			}
+			if err := validateQueryPredicate(field, matcher); err != nil {
+				return nil, err
+			}
+			return queryPredicate{Field: field, Matcher: matcher}, nil
+		}
+		return nil, fmt.Errorf("Error: invalid date %q for %s (use YYYY-MM-DD, today, tomorrow, yesterday, -7d, +2w, or next-monday)", valueToken.value, field)
+	case tokenRegex:
+		if op != "" {
+			return nil, fmt.Errorf("Error: cannot compare %s with a regex", field)
+		}
+		matcher, err := buildMatcher(valueToken)
+		if err != nil {
+			return nil, err
+		}
+		return queryPredicate{Field: field, Matcher: matcher}, nil
+	default:
+		return nil, fmt.Errorf("Error: expected value after %q", field)
+	}
+}
+
+// validateQueryPredicate rejects values that can never match an enumerated
+// field, so typos fail loudly instead of returning nothing.
+func validateQueryPredicate(field string, m matcher) error {
+	if m.Regex != nil {
+		return nil
+	}
+	value := strings.TrimSpace(m.Value)
+	switch field {
+	case "status":
+		if _, err := db.ParseStatus(value); err != nil {
+			return fmt.Errorf("Error: invalid status %q (use incomplete, completed, canceled, or any)", value)
+		}
+	case "type":
+		if normalizeQueryType(value) == "" {
+			return fmt.Errorf("Error: invalid type %q (use todo, project, or heading)", value)
+		}
+	case "start":
+		switch value {
+		case "inbox", "anytime", "someday":
+		default:
+			return fmt.Errorf("Error: invalid start %q (use inbox, anytime, someday, or a date)", value)
+		}
+	case "has":
+		if taskHasValue(value) {
+			return nil
+		}
+		return fmt.Errorf("Error: invalid has %q (use %s)", value, strings.Join(queryHasValues, ", "))
+	case "evening":
+		if value != "true" && value != "false" {
+			return fmt.Errorf("Error: invalid evening %q (use true or false)", value)
+		}
+	}
+	return nil
+}
+
+func taskHasValue(value string) bool {
+	for _, name := range queryHasValues {
+		if name == value {
+			return true
+		}
+	}
+	return false
+}
+
 func buildMatcher(tok token) (matcher, error) {
 	switch tok.typ {
 	case tokenRegex:
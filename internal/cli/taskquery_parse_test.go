@@ -1,7 +1,11 @@
 package cli
 
 import (
+	"bytes"
+	"database/sql"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/ossianhempel/things3-cli/internal/db"
 )
@@ -69,3 +73,196 @@ func TestParseRichQueryRepeatingPredicate(t *testing.T) {
 		t.Fatalf("unexpected matches: %+v", filtered)
 	}
 }
+
+func TestParseRichQueryDateComparisons(t *testing.T) {
+	now := time.Date(2026, 10, 14, 15, 30, 0, 0, time.Local) // Wednesday
+	tasks := []db.Task{
+		{Title: "soon", Deadline: "2026-10-20", Status: db.StatusIncomplete},
+		{Title: "later", Deadline: "2026-11-05", Status: db.StatusIncomplete},
+		{Title: "done", Deadline: "2026-10-01", Status: db.StatusCompleted, StopDate: "2026-10-13 09:00:00"},
+		{Title: "template", Deadline: "4001-01-01", Repeating: true},
+		{Title: "none"},
+	}
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"deadline < 2026-11-01", []string{"soon", "done"}},
+		{"deadline:<2026-11-01 AND status:completed", []string{"done"}},
+		{"deadline >= 2026-11-05", []string{"later"}},
+		{"deadline=2026-10-20", []string{"soon"}},
+		{"deadline:2026-10-20", []string{"soon"}},
+		{"deadline <= next-tuesday", []string{"soon", "done"}},
+		{"stop > -7d", []string{"done"}},
+		{"stop = yesterday", []string{"done"}},
+		{"deadline > +1m", []string{}},
+		{"deadline:/^2026-11/", []string{"later"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			expr, err := parseRichQueryAt(tt.query, now)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got := []string{}
+			for _, task := range filterTasksByQuery(tasks, expr) {
+				got = append(got, task.Title)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRichQueryKeepsOperatorsInWords(t *testing.T) {
+	tasks := []db.Task{
+		{Title: "a=b", Notes: "key=value"},
+		{Title: "x->y <3"},
+		{Title: "deadline planning", Tags: []string{"deadline"}},
+		{Title: "plain"},
+	}
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"notes:key=value", "a=b"},
+		{"title:a=b", "a=b"},
+		{"x->y", "x->y <3"},
+		{"<3", "x->y <3"},
+		{"tag:deadline <3", ""},
+		{"tag:deadline planning", "deadline planning"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			expr, err := parseRichQuery(tt.query)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got := []string{}
+			for _, task := range filterTasksByQuery(tasks, expr) {
+				got = append(got, task.Title)
+			}
+			if strings.Join(got, ",") != tt.want {
+				t.Fatalf("got %v, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveQueryDate(t *testing.T) {
+	now := time.Date(2026, 10, 14, 23, 59, 0, 0, time.Local) // Wednesday
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"today", "2026-10-14"},
+		{"Tomorrow", "2026-10-15"},
+		{"yesterday", "2026-10-13"},
+		{"-7d", "2026-10-07"},
+		{"+2w", "2026-10-28"},
+		{"+1m", "2026-11-14"},
+		{"-1y", "2025-10-14"},
+		{"next-monday", "2026-10-19"},
+		{"next-wed", "2026-10-21"},
+		{"last-wednesday", "2026-10-07"},
+		{"last-fri", "2026-10-09"},
+		{"2026-02-03", "2026-02-03"},
+	}
+	for _, tt := range tests {
+		got, ok := resolveQueryDate(tt.input, now)
+		if !ok || got != tt.want {
+			t.Fatalf("resolveQueryDate(%q) = %q, %v; want %q", tt.input, got, ok, tt.want)
+		}
+	}
+	for _, input := range []string{"soon", "next-week", "+3x", "7d", "2026-13-01"} {
+		if got, ok := resolveQueryDate(input, now); ok {
+			t.Fatalf("expected %q to be rejected, got %q", input, got)
+		}
+	}
+}
+
+func TestParseRichQueryEnumPredicates(t *testing.T) {
+	tasks := []db.Task{
+		{Title: "inbox todo", Type: "to-do", Start: "Inbox", Status: db.StatusIncomplete},
+		{Title: "evening todo", Type: "to-do", Start: "Anytime", StartDate: "2026-10-14", Evening: true, HasChecklist: true},
+		{Title: "project", Type: "project", Start: "Someday", Status: db.StatusCanceled, Deadline: "2026-12-01"},
+	}
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"type:todo", "inbox todo,evening todo"},
+		{"NOT type:project", "inbox todo,evening todo"},
+		{"start:inbox OR start:someday", "inbox todo,project"},
+		{"start:2026-10-14", "evening todo"},
+		{"status:cancelled", "project"},
+		{"status:any", "inbox todo,evening todo,project"},
+		{"has:checklist", "evening todo"},
+		{"has:deadline", "project"},
+		{"evening:true", "evening todo"},
+		{"evening:false AND type:to-do", "inbox todo"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			expr, err := parseRichQuery(tt.query)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got := []string{}
+			for _, task := range filterTasksByQuery(tasks, expr) {
+				got = append(got, task.Title)
+			}
+			if strings.Join(got, ",") != tt.want {
+				t.Fatalf("got %v, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRichQueryRejectsInvalidPredicates(t *testing.T) {
+	for _, query := range []string{
+		"deadline < soon",
+		"deadline < /2026/",
+		"status:finished",
+		"type:task",
+		"start:later",
+		"has:wings",
+		"evening:maybe",
+		"created <",
+	} {
+		if _, err := parseRichQuery(query); err == nil {
+			t.Fatalf("expected error for %q", query)
+		}
+	}
+}
+
+func TestTasksQueryHasChecklistAndEvening(t *testing.T) {
+	dbPath := writeTestDB(t)
+	conn, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if _, err := conn.Exec(`UPDATE TMTask SET startBucket = 1 WHERE uuid = 'TODAY1'`); err != nil {
+		t.Fatalf("set evening: %v", err)
+	}
+	conn.Close()
+
+	for query, want := range map[string]string{
+		"has:checklist": "T1",
+		"evening:true":  "TODAY1",
+	} {
+		out := &bytes.Buffer{}
+		app := &App{In: strings.NewReader(""), Out: out, Err: &bytes.Buffer{}}
+		root := NewRoot(app)
+		root.SetArgs([]string{"tasks", "--db", dbPath, "--query", query, "--select", "uuid", "--no-header"})
+		root.SetOut(app.Out)
+		root.SetErr(app.Err)
+		if err := root.Execute(); err != nil {
+			t.Fatalf("unexpected error for %q: %v", query, err)
+		}
+		if got := strings.TrimSpace(out.String()); got != want {
+			t.Fatalf("query %q: got %q, want %q", query, got, want)
+		}
+	}
+}
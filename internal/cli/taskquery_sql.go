@@ -0,0 +1,286 @@
+package cli
+
+import (
+	"strings"
+	"time"
+
+	"github.com/ossianhempel/things3-cli/internal/db"
+)
+
+// querySQL is a WHERE fragment over the task alias t (and its p, a, h joins)
+// with positional parameters.
+type querySQL struct {
+	Where string
+	Args  []any
+}
+
+// compileQuery splits a rich query into a SQL condition and the part that must
+// still be matched in memory. Top-level AND terms are pushed down one by one;
+// OR and NOT subtrees are pushed down whole or not at all. Regexes never
+// compile, nor do substring matches SQLite cannot lowercase (non-ASCII).
+func compileQuery(expr queryExpr) (querySQL, queryExpr) {
+	if expr == nil {
+		return querySQL{}, nil
+	}
+	if and, ok := expr.(queryAnd); ok {
+		left, leftRest := compileQuery(and.Left)
+		right, rightRest := compileQuery(and.Right)
+		compiled := joinQuerySQL(left, right)
+		switch {
+		case leftRest == nil:
+			return compiled, rightRest
+		case rightRest == nil:
+			return compiled, leftRest
+		default:
+			return compiled, queryAnd{Left: leftRest, Right: rightRest}
+		}
+	}
+	where, args, ok := compileQueryExpr(expr)
+	if !ok {
+		return querySQL{}, expr
+	}
+	return querySQL{Where: where, Args: args}, nil
+}
+
+func joinQuerySQL(left, right querySQL) querySQL {
+	if left.Where == "" {
+		return right
+	}
+	if right.Where == "" {
+		return left
+	}
+	return querySQL{
+		Where: "(" + left.Where + ") AND (" + right.Where + ")",
+		Args:  append(append([]any{}, left.Args...), right.Args...),
+	}
+}
+
+// compileQueryExpr compiles a whole subtree. Every condition it emits is
+// non-NULL so NOT keeps the in-memory semantics.
+func compileQueryExpr(expr queryExpr) (string, []any, bool) {
+	switch q := expr.(type) {
+	case queryAnd:
+		return compileQueryBinary(q.Left, q.Right, "AND")
+	case queryOr:
+		return compileQueryBinary(q.Left, q.Right, "OR")
+	case queryNot:
+		where, args, ok := compileQueryExpr(q.Inner)
+		if !ok {
+			return "", nil, false
+		}
+		return "NOT (" + where + ")", args, true
+	case queryPredicate:
+		return compileQueryPredicate(q)
+	case queryCompare:
+		return compileQueryCompare(q)
+	default:
+		return "", nil, false
+	}
+}
+
+func compileQueryBinary(leftExpr, rightExpr queryExpr, op string) (string, []any, bool) {
+	left, leftArgs, ok := compileQueryExpr(leftExpr)
+	if !ok {
+		return "", nil, false
+	}
+	right, rightArgs, ok := compileQueryExpr(rightExpr)
+	if !ok {
+		return "", nil, false
+	}
+	return "(" + left + ") " + op + " (" + right + ")", append(leftArgs, rightArgs...), true
+}
+
+const (
+	sqlTaskHasTag = "EXISTS (SELECT 1 FROM TMTaskTag tt JOIN TMTag tag ON tag.uuid = tt.tags WHERE tt.tasks = t.uuid)"
+	sqlTaskTagHas = "EXISTS (SELECT 1 FROM TMTaskTag tt JOIN TMTag tag ON tag.uuid = tt.tags WHERE tt.tasks = t.uuid AND instr(lower(IFNULL(tag.title, '')), ?) > 0)"
+	sqlTaskHasURL = "(IFNULL(t.notes, '') LIKE '%http://%' OR IFNULL(t.notes, '') LIKE '%https://%')"
+)
+
+func compileQueryPredicate(q queryPredicate) (string, []any, bool) {
+	m := q.Matcher
+	if m.Regex != nil {
+		return "", nil, false
+	}
+	value := strings.TrimSpace(m.Value)
+	switch strings.ToLower(q.Field) {
+	case "title":
+		return compileContains("t.title", m.Value)
+	case "notes":
+		return compileContains("t.notes", m.Value)
+	case "tag", "tags":
+		if !isASCII(m.Value) {
+			return "", nil, false
+		}
+		return sqlTaskTagHas, []any{m.Value}, true
+	case "project":
+		return compileContains("p.title", m.Value)
+	case "area":
+		return compileContains("a.title", m.Value)
+	case "heading":
+		return compileContains("h.title", m.Value)
+	case "id", "uuid":
+		return compileContains("t.uuid", m.Value)
+	case "url":
+		switch value {
+		case "true":
+			return sqlTaskHasURL, nil, true
+		case "false":
+			return "NOT " + sqlTaskHasURL, nil, true
+		}
+		return compileContains("t.notes", m.Value)
+	case "repeating":
+		return compileBoolPredicate(value, "t.rt1_recurrenceRule IS NOT NULL")
+	case "evening":
+		return compileBoolPredicate(value, "IFNULL(t.startBucket, 0) = 1")
+	case "status":
+		status, err := db.ParseStatus(value)
+		if err != nil {
+			return "0", nil, true
+		}
+		if status == nil {
+			return "1", nil, true
+		}
+		return "t.status = ?", []any{*status}, true
+	case "type":
+		switch normalizeQueryType(value) {
+		case "to-do":
+			return "t.type = ?", []any{db.TaskTypeTodo}, true
+		case "project":
+			return "t.type = ?", []any{db.TaskTypeProject}, true
+		case "heading":
+			return "t.type = ?", []any{db.TaskTypeHeading}, true
+		}
+		return "0", nil, true
+	case "start":
+		switch value {
+		case "inbox":
+			return "IFNULL(t.start, -1) = 0", nil, true
+		case "anytime":
+			return "IFNULL(t.start, -1) = 1", nil, true
+		case "someday":
+			return "IFNULL(t.start, -1) = 2", nil, true
+		}
+		return "0", nil, true
+	case "has":
+		switch value {
+		case "checklist":
+			return "EXISTS (SELECT 1 FROM TMChecklistItem c WHERE c.task = t.uuid)", nil, true
+		case "deadline":
+			return "(t.deadline IS NOT NULL AND t.deadline > 0 AND t.deadline < ?)", []any{sentinelThingsDate}, true
+		case "notes":
+			return "trim(IFNULL(t.notes, ''), char(32, 9, 10, 13)) != ''", nil, true
+		case "tags":
+			return sqlTaskHasTag, nil, true
+		case "url":
+			return sqlTaskHasURL, nil, true
+		}
+		return "0", nil, true
+	case "deadline", "created", "modified", "stop":
+		// Substring matches on formatted dates stay in memory.
+		return "", nil, false
+	case "":
+		if !isASCII(m.Value) {
+			return "", nil, false
+		}
+		parts := []string{}
+		args := []any{}
+		for _, column := range []string{"t.title", "t.notes", "p.title", "a.title", "h.title"} {
+			parts = append(parts, "instr(lower(IFNULL("+column+", '')), ?) > 0")
+			args = append(args, m.Value)
+		}
+		parts = append(parts, sqlTaskTagHas)
+		args = append(args, m.Value)
+		return "(" + strings.Join(parts, " OR ") + ")", args, true
+	default:
+		return "0", nil, true
+	}
+}
+
+func compileContains(column, value string) (string, []any, bool) {
+	if !isASCII(value) {
+		return "", nil, false
+	}
+	return "instr(lower(IFNULL(" + column + ", '')), ?) > 0", []any{value}, true
+}
+
+// compileBoolPredicate mirrors matchBoolPredicate: true/false select rows, any
+// other value is a substring of "true" or "false".
+func compileBoolPredicate(value, cond string) (string, []any, bool) {
+	matchTrue := strings.Contains("true", value)
+	matchFalse := strings.Contains("false", value)
+	switch {
+	case value == "true" || (matchTrue && !matchFalse):
+		return cond, nil, true
+	case value == "false" || (matchFalse && !matchTrue):
+		return "NOT (" + cond + ")", nil, true
+	case matchTrue && matchFalse:
+		return "1", nil, true
+	default:
+		return "0", nil, true
+	}
+}
+
+// sentinelThingsDate is the packed year 4000; later deadlines are placeholders.
+const sentinelThingsDate = 4000 << 16
+
+func compileQueryCompare(q queryCompare) (string, []any, bool) {
+	date, err := time.ParseInLocation("2006-01-02", q.Date, time.Local)
+	if err != nil {
+		return "", nil, false
+	}
+	switch q.Field {
+	case "deadline":
+		return "(t.deadline IS NOT NULL AND t.deadline > 0 AND t.deadline < ? AND t.deadline " + sqlCompareOp(q.Op) + " ?)",
+			[]any{sentinelThingsDate, thingsDateValue(date)}, true
+	case "start":
+		return "(t.startDate IS NOT NULL AND t.startDate > 0 AND t.startDate " + sqlCompareOp(q.Op) + " ?)",
+			[]any{thingsDateValue(date)}, true
+	}
+
+	column := ""
+	switch q.Field {
+	case "created":
+		column = "t.creationDate"
+	case "modified":
+		column = "t.userModificationDate"
+	case "stop":
+		column = "t.stopDate"
+	default:
+		return "", nil, false
+	}
+	// Timestamps match by local calendar day, so compare against the
+	// midnights that start the day and the next one.
+	dayStart := float64(date.Unix())
+	nextStart := float64(date.AddDate(0, 0, 1).Unix())
+	prefix := "(" + column + " IS NOT NULL AND " + column + " > 0 AND "
+	switch q.Op {
+	case "<":
+		return prefix + column + " < ?)", []any{dayStart}, true
+	case "<=":
+		return prefix + column + " < ?)", []any{nextStart}, true
+	case ">":
+		return prefix + column + " >= ?)", []any{nextStart}, true
+	case ">=":
+		return prefix + column + " >= ?)", []any{dayStart}, true
+	default:
+		return prefix + column + " >= ? AND " + column + " < ?)", []any{dayStart, nextStart}, true
+	}
+}
+
+func sqlCompareOp(op string) string {
+	switch op {
+	case "<", "<=", ">", ">=":
+		return op
+	default:
+		return "="
+	}
+}
+
+func isASCII(value string) bool {
+	for i := 0; i < len(value); i++ {
+		if value[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
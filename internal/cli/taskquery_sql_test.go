@@ -0,0 +1,261 @@
+package cli
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ossianhempel/things3-cli/internal/db"
+)
+
+func TestCompileQuerySplitsRegexTerms(t *testing.T) {
+	expr, err := parseRichQuery("tag:urgent AND title:/one/i AND status:open")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	compiled, residual := compileQuery(expr)
+	if !strings.Contains(compiled.Where, "tag.title") || !strings.Contains(compiled.Where, "t.status = ?") {
+		t.Fatalf("expected tag and status pushed down, got %q", compiled.Where)
+	}
+	if len(compiled.Args) != 2 {
+		t.Fatalf("expected 2 args, got %v", compiled.Args)
+	}
+	predicate, ok := residual.(queryPredicate)
+	if !ok || predicate.Field != "title" || predicate.Matcher.Regex == nil {
+		t.Fatalf("expected regex title residual, got %#v", residual)
+	}
+
+	expr, err = parseRichQuery("title:/one/ OR tag:urgent")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	compiled, residual = compileQuery(expr)
+	if compiled.Where != "" || residual == nil {
+		t.Fatalf("expected OR with regex to stay in memory, got %q", compiled.Where)
+	}
+
+	expr, err = parseRichQuery("title:café")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if compiled, residual = compileQuery(expr); compiled.Where != "" || residual == nil {
+		t.Fatalf("expected non-ASCII match to stay in memory, got %q", compiled.Where)
+	}
+}
+
+func TestCompiledQueryMatchesInMemory(t *testing.T) {
+	dbPath := writeTestDB(t)
+	conn, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if _, err := conn.Exec(`UPDATE TMTask SET startBucket = 1 WHERE uuid = 'TODAY1'`); err != nil {
+		t.Fatalf("set evening: %v", err)
+	}
+	if _, err := conn.Exec(`UPDATE TMTask SET deadline = ?, rt1_recurrenceRule = x'00' WHERE uuid = 'SOM1'`, 4001<<16|1<<12|1<<7); err != nil {
+		t.Fatalf("set template: %v", err)
+	}
+	conn.Close()
+	store, _, err := db.OpenDefault(dbPath)
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer store.Close()
+
+	queries := []string{
+		"task",
+		"one OR home",
+		"title:task AND NOT tag:urgent",
+		"tag:urg",
+		"project:one",
+		"area:home",
+		"heading:head",
+		"notes:some",
+		"url:false",
+		"repeating:false",
+		"repeating:e",
+		"evening:true",
+		"NOT evening:true",
+		"status:completed OR status:canceled",
+		"status:any",
+		"type:todo",
+		"start:inbox OR start:someday",
+		"has:checklist",
+		"NOT has:deadline",
+		"has:notes AND has:tags",
+		"deadline <= tomorrow",
+		"NOT deadline > today",
+		"start = today OR start > today",
+		"created >= today",
+		"created < today",
+		"stop = today",
+		"NOT stop <= -1d",
+		"missing:value",
+	}
+	all := db.TaskFilter{IncludeTrashed: true, IncludeRepeating: true}
+	tasks, err := store.Tasks(all)
+	if err != nil {
+		t.Fatalf("load tasks: %v", err)
+	}
+	for _, query := range queries {
+		t.Run(query, func(t *testing.T) {
+			expr, err := parseRichQuery(query)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			compiled, residual := compileQuery(expr)
+			if residual != nil {
+				t.Fatalf("expected %q to compile fully", query)
+			}
+			filter := all
+			filter.Where = compiled.Where
+			filter.WhereArgs = compiled.Args
+			pushed, err := store.Tasks(filter)
+			if err != nil {
+				t.Fatalf("query %q: %v", compiled.Where, err)
+			}
+			got := taskUUIDs(pushed)
+			want := taskUUIDs(filterTasksByQuery(tasks, expr))
+			if got != want {
+				t.Fatalf("SQL matched %s, in-memory matched %s", got, want)
+			}
+		})
+	}
+}
+
+func TestFetchTasksPushesQueryLimitToSQL(t *testing.T) {
+	dbPath := writeTestDB(t)
+	store, _, err := db.OpenDefault(dbPath)
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer store.Close()
+
+	var seen db.TaskFilter
+	runner := func(filter db.TaskFilter) ([]db.Task, error) {
+		seen = filter
+		return store.Tasks(filter)
+	}
+	opts := TaskQueryOptions{Status: "incomplete", Query: "title:task AND start:anytime", Limit: 2}
+	tasks, err := fetchTasks(store, runner, opts, false, []int{db.TaskTypeTodo})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if seen.Limit != 2 || seen.Where == "" {
+		t.Fatalf("expected limit and query in SQL, got limit=%d where=%q", seen.Limit, seen.Where)
+	}
+	if len(tasks) != 2 {
+		t.Fatalf("expected 2 tasks, got %d", len(tasks))
+	}
+
+	opts.Query = "title:/task/i"
+	if _, err := fetchTasks(store, runner, opts, false, []int{db.TaskTypeTodo}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if seen.Limit != 0 || seen.Where != "" {
+		t.Fatalf("expected regex query to scan without limit, got limit=%d where=%q", seen.Limit, seen.Where)
+	}
+}
+
+func taskUUIDs(tasks []db.Task) string {
+	ids := make([]string, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.UUID
+	}
+	sort.Strings(ids)
+	return strings.Join(ids, ",")
+}
+
+// writeLargeTestDB adds n generated todos, most of them in the logbook, to the
+// regular test fixture.
+func writeLargeTestDB(tb testing.TB, n int) string {
+	tb.Helper()
+	path := writeTestDB(tb)
+	conn, err := sql.Open("sqlite", path)
+	if err != nil {
+		tb.Fatalf("open db: %v", err)
+	}
+	defer conn.Close()
+	tx, err := conn.Begin()
+	if err != nil {
+		tb.Fatalf("begin: %v", err)
+	}
+	stmt, err := tx.Prepare(`INSERT INTO TMTask (uuid, type, status, trashed, title, notes, project, area, start, deadline, creationDate, stopDate, "index") VALUES (?, 0, ?, 0, ?, ?, ?, ?, 1, ?, ?, ?, ?)`)
+	if err != nil {
+		tb.Fatalf("prepare: %v", err)
+	}
+	now := time.Now()
+	for i := 0; i < n; i++ {
+		status := db.StatusCompleted
+		var stopDate any = float64(now.AddDate(0, 0, -i%365).Unix())
+		if i%10 == 0 {
+			status = db.StatusIncomplete
+			stopDate = nil
+		}
+		var deadline any
+		if i%7 == 0 {
+			deadline = thingsDate(now.AddDate(0, 0, i%60-30))
+		}
+		project, area := any(nil), any(nil)
+		if i%3 == 0 {
+			project, area = "P1", "A1"
+		}
+		created := float64(now.AddDate(0, 0, -i%400).Unix())
+		if _, err := stmt.Exec(fmt.Sprintf("GEN%05d", i), status, fmt.Sprintf("Generated task %d", i), "Some generated notes", project, area, deadline, created, stopDate, i); err != nil {
+			tb.Fatalf("insert: %v", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		tb.Fatalf("commit: %v", err)
+	}
+	return path
+}
+
+const benchmarkQuery = "project:one AND deadline < +7d AND NOT status:canceled"
+
+func BenchmarkFetchTasksQuerySQL(b *testing.B) {
+	store := openLargeTestStore(b)
+	opts := TaskQueryOptions{Status: "any", Query: benchmarkQuery, Limit: 20}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := fetchTasks(store, store.Tasks, opts, false, []int{db.TaskTypeTodo}); err != nil {
+			b.Fatalf("fetch: %v", err)
+		}
+	}
+}
+
+func BenchmarkFetchTasksQueryInMemory(b *testing.B) {
+	store := openLargeTestStore(b)
+	opts := TaskQueryOptions{Status: "any", Limit: 20}
+	expr, err := parseRichQuery(benchmarkQuery)
+	if err != nil {
+		b.Fatalf("parse: %v", err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		filter, _, err := buildTaskFilter(store, opts)
+		if err != nil {
+			b.Fatalf("filter: %v", err)
+		}
+		filter.Types = []int{db.TaskTypeTodo}
+		filter.Limit = 0
+		tasks, err := store.Tasks(filter)
+		if err != nil {
+			b.Fatalf("fetch: %v", err)
+		}
+		applyOffsetLimit(filterTasksByQuery(tasks, expr), opts.Limit, opts.Offset)
+	}
+}
+
+func openLargeTestStore(b *testing.B) *db.Store {
+	b.Helper()
+	store, _, err := db.OpenDefault(writeLargeTestDB(b, 40000))
+	if err != nil {
+		b.Fatalf("open store: %v", err)
+	}
+	b.Cleanup(func() { store.Close() })
+	return store
+}
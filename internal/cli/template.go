@@ -0,0 +1,182 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ossianhempel/things3-cli/internal/templates"
+	"github.com/ossianhempel/things3-cli/internal/things"
+	"github.com/spf13/cobra"
+)
+
+// NewTemplateCommand builds the template command and its subcommands.
+func NewTemplateCommand(app *App) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "template <apply|list|show> [ARGS...]",
+		Short: "Create projects from YAML/JSON templates",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			printHelp(app.Out, formatHelpText(templateHelp, isTTY(app.Out)))
+			return ErrHelpPrinted
+		},
+	}
+
+	cmd.AddCommand(newTemplateApplyCommand(app))
+	cmd.AddCommand(newTemplateListCommand(app))
+	cmd.AddCommand(newTemplateShowCommand(app))
+	return cmd
+}
+
+func newTemplateApplyCommand(app *App) *cobra.Command {
+	var vars []string
+	var base string
+	var reveal bool
+
+	cmd := &cobra.Command{
+		Use:   "apply [OPTIONS...] <NAME|FILE>",
+		Short: "Render a template and create its items",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			values, err := parseTemplateVars(vars)
+			if err != nil {
+				return err
+			}
+			baseDate, err := parseTemplateBase(base)
+			if err != nil {
+				return err
+			}
+			tmpl, err := loadTemplate(args[0])
+			if err != nil {
+				return err
+			}
+			items, err := templates.Render(tmpl, values, baseDate)
+			if err != nil {
+				return err
+			}
+			url, err := things.BuildJSONURL(things.JSONOptions{Reveal: reveal}, items)
+			if err != nil {
+				return err
+			}
+			return openURL(app, url)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringArrayVar(&vars, "var", nil, "Template variable as NAME=VALUE (repeatable)")
+	flags.StringVar(&base, "base", "", "Base date for relative dates (YYYY-MM-DD, default today)")
+	flags.BoolVar(&reveal, "reveal", false, "Reveal the first created item")
+
+	return cmd
+}
+
+func newTemplateListCommand(app *App) *cobra.Command {
+	var asJSON bool
+	var noHeader bool
+
+	cmd := &cobra.Command{
+		Use:   "list [OPTIONS...]",
+		Short: "List templates in the templates directory",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir, err := templates.Dir()
+			if err != nil {
+				return err
+			}
+			entries, err := templates.List(dir)
+			if err != nil {
+				return err
+			}
+			if asJSON {
+				if entries == nil {
+					entries = []templates.Entry{}
+				}
+				return json.NewEncoder(app.Out).Encode(entries)
+			}
+			if len(entries) == 0 {
+				fmt.Fprintf(app.Err, "No templates found in %s\n", dir)
+				return nil
+			}
+			w := tabwriter.NewWriter(app.Out, 0, 2, 2, ' ', 0)
+			if !noHeader {
+				fmt.Fprintln(w, "NAME\tDESCRIPTION\tPATH")
+			}
+			for _, entry := range entries {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", entry.Name, entry.Description, entry.Path)
+			}
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Output JSON")
+	cmd.Flags().BoolVar(&noHeader, "no-header", false, "Suppress header row")
+	return cmd
+}
+
+func newTemplateShowCommand(app *App) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "show <NAME|FILE>",
+		Short: "Print a template",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir, err := templates.Dir()
+			if err != nil {
+				return err
+			}
+			path, err := templates.Resolve(dir, args[0])
+			if err != nil {
+				return err
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			if app.Debug {
+				fmt.Fprintf(app.Err, "Template: %s\n", path)
+			}
+			_, err = app.Out.Write(data)
+			return err
+		},
+	}
+	return cmd
+}
+
+func loadTemplate(nameOrPath string) (templates.Template, error) {
+	dir, err := templates.Dir()
+	if err != nil {
+		dir = ""
+	}
+	path, err := templates.Resolve(dir, nameOrPath)
+	if err != nil {
+		return templates.Template{}, err
+	}
+	return templates.Load(path)
+}
+
+func parseTemplateVars(values []string) (map[string]string, error) {
+	vars := make(map[string]string, len(values))
+	for _, value := range values {
+		key, val, ok := strings.Cut(value, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("Error: invalid --var %q (expected NAME=VALUE)", value)
+		}
+		vars[key] = val
+	}
+	return vars, nil
+}
+
+func parseTemplateBase(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		now := time.Now()
+		return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local), nil
+	}
+	base, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Error: invalid --base date %q (expected YYYY-MM-DD)", value)
+	}
+	return base, nil
+}
@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"bytes"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setConfigHome points the user config directory at a temp dir and returns it.
+func setConfigHome(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(home, ".cache"))
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		t.Fatalf("config dir: %v", err)
+	}
+	return dir
+}
+
+func writeTemplate(t *testing.T, configDir, name, content string) {
+	t.Helper()
+	dir := filepath.Join(configDir, "things3-cli", "templates")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+}
+
+func TestTemplateApplyByName(t *testing.T) {
+	configDir := setConfigHome(t)
+	writeTemplate(t, configDir, "release.yaml", `
+project:
+  title: "Release {{version}}"
+  headings:
+    - title: QA
+      todos:
+        - title: Smoke test
+          when: +3d
+`)
+
+	launcher := &recordLauncher{}
+	app := &App{
+		In:       strings.NewReader(""),
+		Out:      &bytes.Buffer{},
+		Err:      &bytes.Buffer{},
+		Launcher: launcher,
+	}
+
+	root := NewRoot(app)
+	root.SetArgs([]string{"template", "apply", "release", "--var", "version=1.4", "--base", "2026-11-01"})
+	root.SetOut(app.Out)
+	root.SetErr(app.Err)
+
+	if err := root.Execute(); err != nil {
+		t.Fatalf("execute failed: %v", err)
+	}
+
+	link := requireOpenURL(t, launcher)
+	if !strings.HasPrefix(link, "things:///json?data=") {
+		t.Fatalf("unexpected url: %q", link)
+	}
+	payload, err := url.PathUnescape(strings.TrimPrefix(link, "things:///json?data="))
+	if err != nil {
+		t.Fatalf("unescape: %v", err)
+	}
+	for _, want := range []string{`"title":"Release 1.4"`, `"type":"heading"`, `"when":"2026-11-04"`} {
+		if !strings.Contains(payload, want) {
+			t.Fatalf("expected %s in payload %s", want, payload)
+		}
+	}
+}
+
+func TestTemplateApplyMissingVar(t *testing.T) {
+	setConfigHome(t)
+	path := filepath.Join(t.TempDir(), "t.yaml")
+	if err := os.WriteFile(path, []byte("todos:\n  - title: \"{{name}}\"\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	launcher := &recordLauncher{}
+	app := &App{
+		In:       strings.NewReader(""),
+		Out:      &bytes.Buffer{},
+		Err:      &bytes.Buffer{},
+		Launcher: launcher,
+	}
+
+	root := NewRoot(app)
+	root.SetArgs([]string{"template", "apply", path})
+	root.SetOut(app.Out)
+	root.SetErr(app.Err)
+
+	err := root.Execute()
+	if err == nil || !strings.Contains(err.Error(), "missing template variables: name") {
+		t.Fatalf("expected missing variable error, got %v", err)
+	}
+	if len(launcher.args) != 0 {
+		t.Fatalf("expected no open invocation")
+	}
+}
+
+func TestTemplateListAndShow(t *testing.T) {
+	configDir := setConfigHome(t)
+	content := "description: Weekly review\ntodos:\n  - title: Review\n"
+	writeTemplate(t, configDir, "weekly.yml", content)
+
+	out := &bytes.Buffer{}
+	app := &App{In: strings.NewReader(""), Out: out, Err: &bytes.Buffer{}}
+	root := NewRoot(app)
+	root.SetArgs([]string{"template", "list"})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "weekly") || !strings.Contains(out.String(), "Weekly review") {
+		t.Fatalf("unexpected list output: %q", out.String())
+	}
+
+	out.Reset()
+	root = NewRoot(app)
+	root.SetArgs([]string{"template", "show", "weekly"})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("show failed: %v", err)
+	}
+	if out.String() != content {
+		t.Fatalf("unexpected show output: %q", out.String())
+	}
+}
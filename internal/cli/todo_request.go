@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"strings"
+
+	"github.com/ossianhempel/things3-cli/internal/things"
+)
+
+// todoRequest is the JSON shape used by the serve and mcp write endpoints.
+type todoRequest struct {
+	Title       string   `json:"title"`
+	Notes       string   `json:"notes"`
+	AppendNotes string   `json:"append_notes"`
+	When        string   `json:"when"`
+	Deadline    string   `json:"deadline"`
+	Tags        []string `json:"tags"`
+	AddTags     []string `json:"add_tags"`
+	Checklist   []string `json:"checklist"`
+	List        string   `json:"list"`
+	ListID      string   `json:"list_id"`
+	Heading     string   `json:"heading"`
+	Completed   bool     `json:"completed"`
+	Canceled    bool     `json:"canceled"`
+}
+
+func (req todoRequest) addOptions() things.AddOptions {
+	return things.AddOptions{
+		Notes:          req.Notes,
+		When:           req.When,
+		Deadline:       req.Deadline,
+		Tags:           strings.Join(req.Tags, ","),
+		ChecklistItems: req.Checklist,
+		List:           req.List,
+		ListID:         req.ListID,
+		Heading:        req.Heading,
+		Completed:      req.Completed,
+		Canceled:       req.Canceled,
+	}
+}
+
+func (req todoRequest) updateOptions(id string) things.UpdateOptions {
+	return things.UpdateOptions{
+		ID:             id,
+		Notes:          req.Notes,
+		AppendNotes:    req.AppendNotes,
+		When:           req.When,
+		Deadline:       req.Deadline,
+		Tags:           strings.Join(req.Tags, ","),
+		AddTags:        strings.Join(req.AddTags, ","),
+		ChecklistItems: req.Checklist,
+		List:           req.List,
+		ListID:         req.ListID,
+		Heading:        req.Heading,
+		Completed:      req.Completed,
+		Canceled:       req.Canceled,
+	}
+}
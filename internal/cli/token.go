@@ -19,6 +19,10 @@ func resolveAuthToken(app *App, explicit string) (string, error) {
 		token = authTokenFromEnv()
 		source = "THINGS_AUTH_TOKEN"
 	}
+	if token == "" && app != nil {
+		token = strings.TrimSpace(app.Config.AuthToken)
+		source = "config"
+	}
 	if token == "" {
 		return "", things.ErrMissingAuthToken
 	}
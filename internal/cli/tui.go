@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ossianhempel/things3-cli/internal/db"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// NewTUICommand builds the tui command.
+func NewTUICommand(app *App) *cobra.Command {
+	var dbPath string
+
+	cmd := &cobra.Command{
+		Use:   "tui [OPTIONS...]",
+		Short: "Browse and update tasks in an interactive terminal UI",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			in, inOK := app.In.(*os.File)
+			out, outOK := app.Out.(*os.File)
+			if !inOK || !outOK || !term.IsTerminal(int(in.Fd())) || !term.IsTerminal(int(out.Fd())) {
+				return fmt.Errorf("Error: tui requires an interactive terminal")
+			}
+
+			store, _, err := db.OpenDefault(dbPath)
+			if err != nil {
+				return formatDBError(err)
+			}
+			defer store.Close()
+
+			model, err := newTUIModel(app, store)
+			if err != nil {
+				return formatDBError(err)
+			}
+			model.color = true
+			return runTUI(model, in, out)
+		},
+	}
+
+	cmd.Flags().StringVarP(&dbPath, "db", "d", "", "Path to Things database (overrides THINGSDB)")
+	cmd.Flags().StringVar(&dbPath, "database", "", "Alias for --db")
+
+	return cmd
+}
+
+// runTUI puts the terminal in raw mode on the alternate screen and redraws
+// the model after every key until it asks to quit.
+func runTUI(model *tuiModel, in, out *os.File) error {
+	state, err := term.MakeRaw(int(in.Fd()))
+	if err != nil {
+		return fmt.Errorf("Error: %v", err)
+	}
+	defer term.Restore(int(in.Fd()), state)
+	fmt.Fprint(out, "\x1b[?1049h\x1b[?25l")
+	defer fmt.Fprint(out, "\x1b[?25h\x1b[?1049l")
+
+	reader := bufio.NewReader(in)
+	for {
+		width, height, err := term.GetSize(int(out.Fd()))
+		if err != nil {
+			width, height = 80, 24
+		}
+		lines := model.render(width, height)
+		fmt.Fprint(out, "\x1b[H\x1b[2J"+strings.Join(lines, "\r\n"))
+
+		key, err := readTUIKey(reader)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if model.handleKey(key) {
+			return nil
+		}
+	}
+}
+
+// readTUIKey reads one key press from a raw terminal and names it: printable
+// characters are returned as-is, others as "enter", "up", "ctrl+c", and so on.
+func readTUIKey(reader *bufio.Reader) (string, error) {
+	r, _, err := reader.ReadRune()
+	if err != nil {
+		return "", err
+	}
+	switch r {
+	case '\r', '\n':
+		return "enter", nil
+	case '\t':
+		return "tab", nil
+	case 0x7f, 0x08:
+		return "backspace", nil
+	case 0x03:
+		return "ctrl+c", nil
+	case 0x1b:
+		if reader.Buffered() == 0 {
+			return "esc", nil
+		}
+		next, _, err := reader.ReadRune()
+		if err != nil || (next != '[' && next != 'O') {
+			return "esc", nil
+		}
+		code, _, err := reader.ReadRune()
+		if err != nil {
+			return "esc", nil
+		}
+		switch code {
+		case 'A':
+			return "up", nil
+		case 'B':
+			return "down", nil
+		case 'C':
+			return "right", nil
+		case 'D':
+			return "left", nil
+		}
+		// Skip the rest of longer sequences such as "\x1b[3~".
+		for code >= '0' && code <= '9' || code == ';' {
+			if code, _, err = reader.ReadRune(); err != nil {
+				break
+			}
+		}
+		return "", nil
+	}
+	if r < 0x20 {
+		return "", nil
+	}
+	return string(r), nil
+}
@@ -0,0 +1,597 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ossianhempel/things3-cli/internal/db"
+	"github.com/ossianhempel/things3-cli/internal/things"
+)
+
+// tuiEntry is a row in the TUI sidebar: a built-in list, an area, or a
+// project.
+type tuiEntry struct {
+	Kind   string
+	ID     string
+	Title  string
+	Indent int
+}
+
+type tuiPane int
+
+const (
+	tuiSidebar tuiPane = iota
+	tuiTasks
+)
+
+// tuiPrompt is the action waiting for a line of input.
+type tuiPrompt string
+
+const (
+	tuiPromptNone   tuiPrompt = ""
+	tuiPromptSearch tuiPrompt = "Query: "
+	tuiPromptMove   tuiPrompt = "Move to: "
+	tuiPromptWhen   tuiPrompt = "When: "
+	tuiPromptTags   tuiPrompt = "Add tags: "
+)
+
+const tuiListLimit = 200
+
+// tuiRefreshTimeout bounds how long the TUI waits for Things to write a change
+// back to the database before reloading anyway.
+var tuiRefreshTimeout = 3 * time.Second
+
+var tuiBuiltinLists = []tuiEntry{
+	{Kind: "list", ID: "inbox", Title: "Inbox"},
+	{Kind: "list", ID: "today", Title: "Today"},
+	{Kind: "list", ID: "upcoming", Title: "Upcoming"},
+	{Kind: "list", ID: "anytime", Title: "Anytime"},
+	{Kind: "list", ID: "someday", Title: "Someday"},
+	{Kind: "list", ID: "logbook", Title: "Logbook"},
+}
+
+// tuiModel is the state of the interactive UI. It is independent of the
+// terminal so key handling and rendering can be tested directly.
+type tuiModel struct {
+	app   *App
+	store *db.Store
+	color bool
+
+	sidebar  []tuiEntry
+	selected int
+	tasks    []db.Task
+	cursor   int
+	focus    tuiPane
+	query    string
+
+	prompt tuiPrompt
+	input  []rune
+	status string
+
+	checklists map[string][]db.ChecklistItem
+}
+
+func newTUIModel(app *App, store *db.Store) (*tuiModel, error) {
+	m := &tuiModel{app: app, store: store, checklists: map[string][]db.ChecklistItem{}}
+	if err := m.loadSidebar(); err != nil {
+		return nil, err
+	}
+	m.selected = 1 // Today
+	if err := m.loadTasks(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (m *tuiModel) loadSidebar() error {
+	status := db.StatusIncomplete
+	filter := db.TaskFilter{Status: &status, ExcludeTrashedContext: true}
+	entries := append([]tuiEntry{}, tuiBuiltinLists...)
+
+	loose, err := m.store.ProjectsWithoutAreaTree(filter, true)
+	if err != nil {
+		return err
+	}
+	for _, project := range loose {
+		entries = append(entries, tuiEntry{Kind: "project", ID: project.UUID, Title: project.Title})
+	}
+	areas, err := m.store.AreasTree(filter, true)
+	if err != nil {
+		return err
+	}
+	for _, area := range areas {
+		entries = append(entries, tuiEntry{Kind: "area", ID: area.UUID, Title: area.Title})
+		for _, project := range area.Items {
+			entries = append(entries, tuiEntry{Kind: "project", ID: project.UUID, Title: project.Title, Indent: 1})
+		}
+	}
+	m.sidebar = entries
+	if m.selected >= len(entries) {
+		m.selected = len(entries) - 1
+	}
+	return nil
+}
+
+// loadTasks reloads the task list for the selected sidebar entry, or the
+// search results when a query is active, keeping the cursor on the same task
+// when it is still listed and at the same position otherwise.
+func (m *tuiModel) loadTasks() error {
+	current := ""
+	if task := m.currentTask(); task != nil {
+		current = task.UUID
+	}
+
+	opts := TaskQueryOptions{Status: "incomplete", Limit: tuiListLimit, Exact: true}
+	runner := m.store.Tasks
+	if m.query != "" {
+		opts.Query = m.query
+	} else {
+		entry := m.sidebar[m.selected]
+		switch entry.Kind {
+		case "area":
+			opts.Area = entry.ID
+		case "project":
+			opts.Project = entry.ID
+		default:
+			switch entry.ID {
+			case "inbox":
+				runner = m.store.InboxTasks
+			case "today":
+				runner = m.store.TodayTasks
+			case "upcoming":
+				runner = m.store.UpcomingTasks
+			case "anytime":
+				runner = m.store.AnytimeTasks
+			case "someday":
+				runner = m.store.SomedayTasks
+			case "logbook":
+				runner = m.store.LogbookTasks
+				opts.Status = "any"
+			}
+		}
+	}
+
+	tasks, err := fetchTasks(m.store, runner, opts, false, []int{db.TaskTypeTodo})
+	if err != nil {
+		return err
+	}
+	m.tasks = tasks
+	m.checklists = map[string][]db.ChecklistItem{}
+	m.cursor = max(0, min(m.cursor, len(tasks)-1))
+	for i, task := range tasks {
+		if task.UUID == current {
+			m.cursor = i
+			break
+		}
+	}
+	return nil
+}
+
+func (m *tuiModel) currentTask() *db.Task {
+	if m.cursor < 0 || m.cursor >= len(m.tasks) {
+		return nil
+	}
+	return &m.tasks[m.cursor]
+}
+
+func (m *tuiModel) checklist(task *db.Task) []db.ChecklistItem {
+	if task == nil || !task.HasChecklist {
+		return nil
+	}
+	if items, ok := m.checklists[task.UUID]; ok {
+		return items
+	}
+	items, err := m.store.ChecklistItems([]string{task.UUID})
+	if err != nil {
+		return nil
+	}
+	m.checklists[task.UUID] = items[task.UUID]
+	return items[task.UUID]
+}
+
+// handleKey applies a key and reports whether the UI should exit.
+func (m *tuiModel) handleKey(key string) bool {
+	if m.prompt != tuiPromptNone {
+		m.handlePromptKey(key)
+		return false
+	}
+	m.status = ""
+	switch key {
+	case "q", "ctrl+c":
+		return true
+	case "j", "down":
+		m.move(1)
+	case "k", "up":
+		m.move(-1)
+	case "h", "left":
+		m.focus = tuiSidebar
+	case "l", "right", "enter":
+		if len(m.tasks) > 0 {
+			m.focus = tuiTasks
+		}
+	case "tab":
+		if m.focus == tuiSidebar && len(m.tasks) > 0 {
+			m.focus = tuiTasks
+		} else {
+			m.focus = tuiSidebar
+		}
+	case "r":
+		m.reload()
+	case "esc":
+		if m.query != "" {
+			m.query = ""
+			m.reload()
+		}
+	case "/":
+		m.startPrompt(tuiPromptSearch, m.query)
+	case "c", "m", "s", "t":
+		if m.currentTask() == nil {
+			m.status = "No task selected"
+			return false
+		}
+		switch key {
+		case "c":
+			m.apply(things.UpdateOptions{Completed: true}, "Completed")
+		case "m":
+			m.startPrompt(tuiPromptMove, "")
+		case "s":
+			m.startPrompt(tuiPromptWhen, "")
+		case "t":
+			m.startPrompt(tuiPromptTags, "")
+		}
+	}
+	return false
+}
+
+func (m *tuiModel) move(delta int) {
+	if m.focus == tuiSidebar {
+		next := m.selected + delta
+		if next < 0 || next >= len(m.sidebar) {
+			return
+		}
+		m.selected = next
+		m.query = ""
+		m.cursor = 0
+		m.tasks = nil
+		if err := m.loadTasks(); err != nil {
+			m.status = formatDBError(err).Error()
+		}
+		return
+	}
+	next := m.cursor + delta
+	if next >= 0 && next < len(m.tasks) {
+		m.cursor = next
+	}
+}
+
+func (m *tuiModel) reload() {
+	if err := m.loadSidebar(); err != nil {
+		m.status = formatDBError(err).Error()
+		return
+	}
+	if err := m.loadTasks(); err != nil {
+		m.status = formatDBError(err).Error()
+	}
+}
+
+func (m *tuiModel) startPrompt(prompt tuiPrompt, initial string) {
+	m.prompt = prompt
+	m.input = []rune(initial)
+}
+
+func (m *tuiModel) handlePromptKey(key string) {
+	switch key {
+	case "esc", "ctrl+c":
+		m.prompt = tuiPromptNone
+		m.input = nil
+	case "backspace":
+		if len(m.input) > 0 {
+			m.input = m.input[:len(m.input)-1]
+		}
+	case "enter":
+		prompt, value := m.prompt, strings.TrimSpace(string(m.input))
+		m.prompt = tuiPromptNone
+		m.input = nil
+		m.submitPrompt(prompt, value)
+	default:
+		if utf8.RuneCountInString(key) == 1 {
+			m.input = append(m.input, []rune(key)...)
+		}
+	}
+}
+
+func (m *tuiModel) submitPrompt(prompt tuiPrompt, value string) {
+	if prompt == tuiPromptSearch {
+		if _, err := parseRichQuery(value); err != nil {
+			m.status = err.Error()
+			return
+		}
+		m.query = value
+		m.cursor = 0
+		m.tasks = nil
+		if err := m.loadTasks(); err != nil {
+			m.status = formatDBError(err).Error()
+			return
+		}
+		if len(m.tasks) > 0 {
+			m.focus = tuiTasks
+		}
+		return
+	}
+	if value == "" {
+		return
+	}
+	switch prompt {
+	case tuiPromptMove:
+		opts := things.UpdateOptions{List: value}
+		candidate, exact, err := m.store.ResolveListFuzzy(value)
+		var ambiguous *db.AmbiguousError
+		if errors.As(err, &ambiguous) {
+			m.status = "Error: " + err.Error()
+			return
+		}
+		if err == nil && !exact {
+			opts = things.UpdateOptions{ListID: candidate.UUID}
+			value = candidate.Title
+		}
+		m.apply(opts, "Moved to "+value)
+	case tuiPromptWhen:
+		if err := validateWhenInput(value); err != nil {
+			m.status = err.Error()
+			return
+		}
+		m.apply(things.UpdateOptions{When: value}, "Scheduled for "+value)
+	case tuiPromptTags:
+		m.apply(things.UpdateOptions{AddTags: value}, "Tagged "+value)
+	}
+}
+
+// apply sends an update for the selected task through the URL scheme, then
+// waits for the change to reach the database and reloads the view.
+func (m *tuiModel) apply(opts things.UpdateOptions, done string) {
+	task := m.currentTask()
+	if task == nil {
+		return
+	}
+	token, err := resolveAuthToken(m.app, "")
+	if err != nil {
+		m.status = err.Error()
+		return
+	}
+	opts.AuthToken = token
+	opts.ID = task.UUID
+	url, err := things.BuildUpdateURL(opts, "")
+	if err != nil {
+		m.status = err.Error()
+		return
+	}
+	if m.app.DryRun {
+		m.status = "Would open " + url
+		return
+	}
+
+	before := task.Modified
+	entry := ActionEntry{Type: ActionUpdate, Items: actionItemsFromTasks(m.store, []db.Task{*task})}
+	if err := appendAction(entry); err != nil {
+		m.status = fmt.Sprintf("Warning: failed to write action log: %v", err)
+	}
+	if err := openURL(m.app, url); err != nil {
+		m.status = err.Error()
+		return
+	}
+	m.waitForChange(task.UUID, before)
+	m.reload()
+	if m.status == "" {
+		m.status = done
+	}
+}
+
+func (m *tuiModel) waitForChange(id, before string) {
+	deadline := time.Now().Add(tuiRefreshTimeout)
+	for time.Now().Before(deadline) {
+		task, err := m.store.TaskByID(id)
+		if err != nil || task.Modified != before {
+			return
+		}
+		time.Sleep(150 * time.Millisecond)
+	}
+}
+
+const tuiKeyHelp = "j/k move  h/l pane  / query  c complete  m move  s schedule  t tag  r reload  q quit"
+
+// render draws the sidebar, task list, and detail pane into height lines of
+// at most width cells.
+func (m *tuiModel) render(width, height int) []string {
+	if width < 40 {
+		width = 40
+	}
+	if height < 6 {
+		height = 6
+	}
+	bodyHeight := height - 2
+	sideWidth := min(24, width/4)
+	detailWidth := (width - sideWidth) * 2 / 5
+	listWidth := width - sideWidth - detailWidth - 2
+
+	side := m.renderSidebar(sideWidth, bodyHeight)
+	list := m.renderTasks(listWidth, bodyHeight)
+	detail := m.renderDetail(detailWidth, bodyHeight)
+
+	lines := make([]string, 0, height)
+	for i := 0; i < bodyHeight; i++ {
+		lines = append(lines, side[i]+"│"+list[i]+"│"+detail[i])
+	}
+	footer := m.status
+	if m.prompt != tuiPromptNone {
+		footer = string(m.prompt) + string(m.input) + "█"
+	}
+	lines = append(lines, tuiFit(footer, width), tuiFit(tuiKeyHelp, width))
+	return lines
+}
+
+func (m *tuiModel) renderSidebar(width, height int) []string {
+	rows := make([]string, 0, len(m.sidebar))
+	for _, entry := range m.sidebar {
+		label := strings.Repeat("  ", entry.Indent) + entry.Title
+		if entry.Kind == "area" {
+			label = strings.ToUpper(entry.Title)
+		}
+		rows = append(rows, label)
+	}
+	return m.renderColumn(rows, m.selected, m.focus == tuiSidebar, width, height)
+}
+
+func (m *tuiModel) renderTasks(width, height int) []string {
+	title := m.sidebar[m.selected].Title
+	if m.query != "" {
+		title = "Query: " + m.query
+	}
+	rows := []string{}
+	for _, task := range m.tasks {
+		mark := "[ ]"
+		switch task.Status {
+		case db.StatusCompleted:
+			mark = "[x]"
+		case db.StatusCanceled:
+			mark = "[-]"
+		}
+		row := mark + " " + task.Title
+		if task.Deadline != "" {
+			row += "  ⚑ " + task.Deadline
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		rows = append(rows, "(no tasks)")
+	}
+	column := m.renderColumn(rows, m.cursor, m.focus == tuiTasks && len(m.tasks) > 0, width, height-2)
+	return append([]string{tuiFit(" "+title, width), tuiFit("", width)}, column...)
+}
+
+func (m *tuiModel) renderDetail(width, height int) []string {
+	task := m.currentTask()
+	lines := []string{}
+	if task != nil {
+		lines = append(lines, tuiWrap(task.Title, width-1)...)
+		lines = append(lines, "")
+		add := func(label, value string) {
+			if value != "" {
+				lines = append(lines, tuiWrap(label+": "+value, width-1)...)
+			}
+		}
+		add("Project", task.ProjectTitle)
+		add("Area", task.AreaTitle)
+		add("Heading", task.HeadingTitle)
+		when := task.Start
+		if task.StartDate != "" {
+			when = task.StartDate
+		}
+		if task.Evening {
+			when += " (evening)"
+		}
+		add("When", when)
+		add("Deadline", task.Deadline)
+		add("Tags", strings.Join(task.Tags, ", "))
+		if task.Notes != "" {
+			lines = append(lines, "")
+			for _, line := range strings.Split(strings.TrimRight(task.Notes, "\n"), "\n") {
+				lines = append(lines, tuiWrap(line, width-1)...)
+			}
+		}
+		if items := m.checklist(task); len(items) > 0 {
+			lines = append(lines, "", "Checklist:")
+			for _, item := range items {
+				mark := "[ ]"
+				if item.Status == db.StatusCompleted {
+					mark = "[x]"
+				}
+				lines = append(lines, tuiWrap(mark+" "+item.Title, width-1)...)
+			}
+		}
+	}
+	out := make([]string, height)
+	for i := range out {
+		value := ""
+		if i < len(lines) {
+			value = " " + lines[i]
+		}
+		out[i] = tuiFit(value, width)
+	}
+	return out
+}
+
+// renderColumn renders rows with the selected one marked, scrolled so the
+// selection stays visible.
+func (m *tuiModel) renderColumn(rows []string, selected int, focused bool, width, height int) []string {
+	offset := 0
+	if selected >= height {
+		offset = selected - height + 1
+	}
+	out := make([]string, height)
+	for i := range out {
+		index := offset + i
+		if index >= len(rows) {
+			out[i] = tuiFit("", width)
+			continue
+		}
+		prefix := "  "
+		if index == selected {
+			prefix = "> "
+		}
+		line := tuiFit(prefix+rows[index], width)
+		if index == selected && focused && m.color {
+			line = "\x1b[7m" + line + "\x1b[0m"
+		}
+		out[i] = line
+	}
+	return out
+}
+
+// tuiFit truncates or pads value to width runes.
+func tuiFit(value string, width int) string {
+	runes := []rune(value)
+	if len(runes) > width {
+		if width > 1 {
+			return string(runes[:width-1]) + "…"
+		}
+		return string(runes[:width])
+	}
+	return value + strings.Repeat(" ", width-len(runes))
+}
+
+// tuiWrap breaks value into lines of at most width runes at spaces.
+func tuiWrap(value string, width int) []string {
+	if width < 1 {
+		width = 1
+	}
+	words := strings.Fields(value)
+	if len(words) == 0 {
+		return []string{""}
+	}
+	var lines []string
+	line := ""
+	for _, word := range words {
+		for utf8.RuneCountInString(word) > width {
+			if line != "" {
+				lines = append(lines, line)
+				line = ""
+			}
+			runes := []rune(word)
+			lines = append(lines, string(runes[:width]))
+			word = string(runes[width:])
+		}
+		switch {
+		case line == "":
+			line = word
+		case utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) <= width:
+			line += " " + word
+		default:
+			lines = append(lines, line)
+			line = word
+		}
+	}
+	return append(lines, line)
+}
@@ -0,0 +1,177 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ossianhempel/things3-cli/internal/db"
+)
+
+func newTestTUIModel(t *testing.T, launcher *recordLauncher) *tuiModel {
+	t.Helper()
+	setConfigHome(t)
+	t.Setenv("THINGS_AUTH_TOKEN", "token")
+	previous := tuiRefreshTimeout
+	tuiRefreshTimeout = 0
+	t.Cleanup(func() { tuiRefreshTimeout = previous })
+
+	store, _, err := db.OpenDefault(writeTestDB(t))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	app := &App{In: strings.NewReader(""), Out: &bytes.Buffer{}, Err: &bytes.Buffer{}, Launcher: launcher}
+	model, err := newTUIModel(app, store)
+	if err != nil {
+		t.Fatalf("new model: %v", err)
+	}
+	return model
+}
+
+func pressKeys(model *tuiModel, keys ...string) {
+	for _, key := range keys {
+		model.handleKey(key)
+	}
+}
+
+func typeKeys(model *tuiModel, text string) {
+	for _, r := range text {
+		model.handleKey(string(r))
+	}
+	model.handleKey("enter")
+}
+
+func TestTUISidebarAndDetail(t *testing.T) {
+	model := newTestTUIModel(t, &recordLauncher{})
+
+	var titles []string
+	for _, entry := range model.sidebar {
+		titles = append(titles, strings.Repeat(" ", entry.Indent)+entry.Title)
+	}
+	if got, want := strings.Join(titles, "|"), "Inbox|Today|Upcoming|Anytime|Someday|Logbook|Home| Project One"; got != want {
+		t.Fatalf("unexpected sidebar: %s", got)
+	}
+	if got := taskUUIDs(model.tasks); got != "TODAY1" {
+		t.Fatalf("expected Today to be selected, got %s", got)
+	}
+
+	pressKeys(model, "k")
+	if got := taskUUIDs(model.tasks); got != "INBOX1" {
+		t.Fatalf("expected inbox tasks, got %s", got)
+	}
+
+	pressKeys(model, "j", "j", "j", "j", "j", "j", "j", "l")
+	if model.focus != tuiTasks || taskUUIDs(model.tasks) != "T1" {
+		t.Fatalf("expected Project One tasks focused, got %s", taskUUIDs(model.tasks))
+	}
+	screen := strings.Join(model.render(120, 20), "\n")
+	for _, want := range []string{"> [ ] Task One", "Project: Project One", "Tags: urgent", "Some notes", "Checklist:", "[ ] Check Item"} {
+		if !strings.Contains(screen, want) {
+			t.Fatalf("expected %q on screen:\n%s", want, screen)
+		}
+	}
+	for _, line := range model.render(120, 20) {
+		if n := len([]rune(line)); n != 120 {
+			t.Fatalf("expected 120-cell lines, got %d: %q", n, line)
+		}
+	}
+}
+
+func TestTUISearchUsesRichQuery(t *testing.T) {
+	model := newTestTUIModel(t, &recordLauncher{})
+
+	pressKeys(model, "/")
+	typeKeys(model, "tag:urgent OR title:/^inbox/i")
+	if model.query == "" || taskUUIDs(model.tasks) != "INBOX1,T1" {
+		t.Fatalf("unexpected search results: %s", taskUUIDs(model.tasks))
+	}
+	if !strings.Contains(strings.Join(model.render(100, 12), "\n"), "Query: tag:urgent") {
+		t.Fatalf("expected query title on screen")
+	}
+
+	pressKeys(model, "/")
+	typeKeys(model, "title:(")
+	if !strings.Contains(model.status, "Error") {
+		t.Fatalf("expected parse error in status, got %q", model.status)
+	}
+
+	pressKeys(model, "esc")
+	if model.query != "" || taskUUIDs(model.tasks) != "TODAY1" {
+		t.Fatalf("expected esc to return to Today, got %s", taskUUIDs(model.tasks))
+	}
+}
+
+func TestTUIActionsUseUpdateURLs(t *testing.T) {
+	launcher := &recordLauncher{}
+	model := newTestTUIModel(t, launcher)
+
+	pressKeys(model, "l", "c")
+	url := requireOpenURL(t, launcher)
+	if !strings.HasPrefix(url, "things:///update?") || !strings.Contains(url, "id=TODAY1") || !strings.Contains(url, "completed=true") {
+		t.Fatalf("unexpected complete URL: %s", url)
+	}
+	if model.status != "Completed" {
+		t.Fatalf("unexpected status %q", model.status)
+	}
+
+	pressKeys(model, "m")
+	typeKeys(model, "proj one")
+	if url := requireOpenURL(t, launcher); !strings.Contains(url, "list-id=P1") {
+		t.Fatalf("expected fuzzy move to list-id, got %s", url)
+	}
+
+	pressKeys(model, "s")
+	typeKeys(model, "tomorrow")
+	if url := requireOpenURL(t, launcher); !strings.Contains(url, "when=tomorrow") {
+		t.Fatalf("unexpected schedule URL: %s", url)
+	}
+
+	pressKeys(model, "t")
+	typeKeys(model, "errand")
+	if url := requireOpenURL(t, launcher); !strings.Contains(url, "add-tags=errand") {
+		t.Fatalf("unexpected tag URL: %s", url)
+	}
+
+	launcher.args = nil
+	pressKeys(model, "s")
+	typeKeys(model, "whenever")
+	if launcher.args != nil || !strings.Contains(model.status, "invalid --when") {
+		t.Fatalf("expected invalid when to be rejected, got %q", model.status)
+	}
+
+	entries, err := readActions()
+	if err != nil {
+		t.Fatalf("read action log: %v", err)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("expected 4 logged updates, got %d", len(entries))
+	}
+}
+
+func TestReadTUIKey(t *testing.T) {
+	reader := bufio.NewReader(strings.NewReader("\x1b[Aq\r\x7f\x1b[3~é\t"))
+	var keys []string
+	for {
+		key, err := readTUIKey(reader)
+		if err != nil {
+			break
+		}
+		keys = append(keys, key)
+	}
+	if got, want := strings.Join(keys, ","), "up,q,enter,backspace,,é,tab"; got != want {
+		t.Fatalf("unexpected keys: got %s want %s", got, want)
+	}
+}
+
+func TestTUICommandRequiresTerminal(t *testing.T) {
+	app := &App{In: strings.NewReader(""), Out: &bytes.Buffer{}, Err: &bytes.Buffer{}}
+	root := NewRoot(app)
+	root.SetArgs([]string{"tui", "--db", writeTestDB(t)})
+	root.SetOut(app.Out)
+	root.SetErr(app.Err)
+	if err := root.Execute(); err == nil || !strings.Contains(err.Error(), "interactive terminal") {
+		t.Fatalf("expected terminal error, got %v", err)
+	}
+}
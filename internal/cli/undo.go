@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"time"
 
 	"github.com/ossianhempel/things3-cli/internal/db"
 	"github.com/ossianhempel/things3-cli/internal/things"
@@ -13,104 +14,97 @@ import (
 // NewUndoCommand builds the undo subcommand.
 func NewUndoCommand(app *App) *cobra.Command {
 	var authToken string
+	var dbPath string
+	var id int
+	var steps int
 	var yes bool
 
 	cmd := &cobra.Command{
 		Use:   "undo",
-		Short: "Undo the last bulk action",
+		Short: "Undo logged actions",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			entry, err := readLastAction()
+			if steps < 1 {
+				return fmt.Errorf("Error: --steps must be at least 1")
+			}
+			entries, err := readActions()
 			if err != nil {
 				return fmt.Errorf("Error: %s", err)
 			}
-			if len(entry.Items) == 0 {
-				return fmt.Errorf("Error: no actions logged")
+			var targets []ActionEntry
+			if cmd.Flags().Changed("id") {
+				entry, err := findAction(entries, id)
+				if err != nil {
+					return fmt.Errorf("Error: %s", err)
+				}
+				if entry.UndoneAt != "" {
+					return fmt.Errorf("Error: action %d is already undone", id)
+				}
+				targets = []ActionEntry{entry}
+			} else {
+				targets = lastActions(entries, steps, false)
+			}
+			if len(targets) == 0 {
+				return fmt.Errorf("Error: no actions to undo")
 			}
 
+			total := 0
+			for _, entry := range targets {
+				total += len(entry.Items)
+			}
 			if app.DryRun {
-				fmt.Fprintf(app.Out, "Would undo %s for %d tasks\n", entry.Type, len(entry.Items))
-				return previewActionItems(app.Out, entry.Items)
+				for _, entry := range targets {
+					fmt.Fprintf(app.Out, "Would undo #%d %s for %d tasks\n", entry.ID, entry.Type, len(entry.Items))
+					if err := previewActionItems(app.Out, entry.Items); err != nil {
+						return err
+					}
+				}
+				return nil
 			}
-
-			if len(entry.Items) > 1 && !yes {
-				return fmt.Errorf("Error: %d tasks matched (rerun with --yes to apply)", len(entry.Items))
+			if total > 1 && !yes {
+				return fmt.Errorf("Error: %d tasks matched (rerun with --yes to apply)", total)
 			}
 
-			switch entry.Type {
-			case ActionUpdate:
-				token, err := resolveAuthToken(app, authToken)
-				if err != nil {
-					return err
-				}
-				warnIncomplete := 0
-				for _, item := range entry.Items {
-					opts := things.UpdateOptions{
-						AuthToken: token,
-						ID:        item.UUID,
-						Notes:     item.Notes,
-						Tags:      strings.Join(item.Tags, ","),
-						Deadline:  item.Deadline,
-						Heading:   item.HeadingTitle,
+			seq := nextUndoSeq(entries)
+			var store *db.Store
+			for _, entry := range targets {
+				if (entry.Type == ActionUpdate || entry.Type == ActionCreate) && store == nil {
+					if opened, _, err := db.OpenDefault(dbPath); err == nil {
+						store = opened
+						defer store.Close()
 					}
-					when := whenFromActionItem(item)
-					if when != "" {
-						opts.When = when
-					}
-					if item.ProjectID != "" {
-						opts.ListID = item.ProjectID
-					} else if item.AreaID != "" {
-						opts.ListID = item.AreaID
-					}
-					switch item.Status {
-					case db.StatusCompleted:
-						opts.Completed = true
-					case db.StatusCanceled:
-						opts.Canceled = true
-					case db.StatusIncomplete:
-						warnIncomplete++
-					}
-					url, err := things.BuildUpdateURL(opts, item.Title)
+				}
+			}
+
+			for _, entry := range targets {
+				switch entry.Type {
+				case ActionUpdate:
+					token, err := resolveAuthToken(app, authToken)
 					if err != nil {
 						return err
 					}
-					if err := openURL(app, url); err != nil {
+					entry.Redo = snapshotActionItems(store, entry.Items)
+					if err := applyActionItems(app, store, token, entry.Items); err != nil {
 						return err
 					}
-				}
-				if warnIncomplete > 0 {
-					fmt.Fprintln(app.Err, "Warning: Things URL scheme cannot un-complete tasks; some items may remain completed.")
-				}
-			case ActionTrash:
-				for _, item := range entry.Items {
-					opts := things.AddOptions{
-						Notes:    item.Notes,
-						Tags:     strings.Join(item.Tags, ","),
-						Deadline: item.Deadline,
-					}
-					when := whenFromActionItem(item)
-					if when != "" {
-						opts.When = when
-					}
-					if item.ProjectID != "" {
-						opts.ListID = item.ProjectID
-					} else if item.AreaID != "" {
-						opts.ListID = item.AreaID
-					}
-					if item.HeadingTitle != "" {
-						opts.Heading = item.HeadingTitle
+				case ActionTrash:
+					if err := restoreActionItems(app, authToken, entry.Items); err != nil {
+						return err
 					}
-					url := things.BuildAddURL(opts, item.Title)
-					if err := openURL(app, url); err != nil {
+				case ActionCreate:
+					entry.Redo = snapshotActionItems(store, entry.Items)
+					if err := trashActionItems(app, entry.Items); err != nil {
 						return err
 					}
+				default:
+					return fmt.Errorf("Error: unsupported action type %q", entry.Type)
 				}
-				fmt.Fprintln(app.Err, "Warning: restored tasks are new items; trashed originals remain in Trash.")
-			default:
-				return fmt.Errorf("Error: unsupported action type %q", entry.Type)
-			}
 
-			if err := removeLastAction(); err != nil {
-				fmt.Fprintf(app.Err, "Warning: failed to update action log: %v\n", err)
+				entry.UndoneAt = time.Now().Format(time.RFC3339)
+				entry.UndoSeq = seq
+				seq++
+				if err := saveAction(entry); err != nil {
+					fmt.Fprintf(app.Err, "Warning: failed to update action log: %v\n", err)
+				}
 			}
 			return nil
 		},
@@ -118,11 +112,175 @@ func NewUndoCommand(app *App) *cobra.Command {
 
 	flags := cmd.Flags()
 	flags.StringVar(&authToken, "auth-token", "", "Things URL scheme authorization token")
+	flags.StringVarP(&dbPath, "db", "d", "", "Path to Things database (overrides THINGSDB)")
+	flags.StringVar(&dbPath, "database", "", "Alias for --db")
+	flags.IntVar(&id, "id", 0, "Undo the action with this history ID")
+	flags.IntVar(&steps, "steps", 1, "Undo the last N actions")
 	flags.BoolVar(&yes, "yes", false, "Confirm undo for multiple tasks")
 
 	return cmd
 }
 
+// applyActionItems restores each item to its logged state via the update URL.
+// With a store, checklists that differ from the snapshot are replaced.
+func applyActionItems(app *App, store *db.Store, token string, items []ActionItem) error {
+	warnIncomplete := 0
+	warnChecklist := false
+	for _, item := range items {
+		opts := things.UpdateOptions{
+			AuthToken: token,
+			ID:        item.UUID,
+			Notes:     item.Notes,
+			Tags:      strings.Join(item.Tags, ","),
+			Deadline:  item.Deadline,
+			Heading:   item.HeadingTitle,
+		}
+		when := whenFromActionItem(item)
+		if when != "" {
+			opts.When = when
+		}
+		if item.ProjectID != "" {
+			opts.ListID = item.ProjectID
+		} else if item.AreaID != "" {
+			opts.ListID = item.AreaID
+		}
+		switch item.Status {
+		case db.StatusCompleted:
+			opts.Completed = true
+		case db.StatusCanceled:
+			opts.Canceled = true
+		case db.StatusIncomplete:
+			warnIncomplete++
+		}
+		if checklistChanged(store, item) {
+			for _, checklistItem := range item.Checklist {
+				opts.ChecklistItems = append(opts.ChecklistItems, checklistItem.Title)
+				if checklistItem.Status != db.StatusIncomplete {
+					warnChecklist = true
+				}
+			}
+		}
+		url, err := things.BuildUpdateURL(opts, item.Title)
+		if err != nil {
+			return err
+		}
+		if err := openURL(app, url); err != nil {
+			return err
+		}
+	}
+	if warnIncomplete > 0 {
+		fmt.Fprintln(app.Err, "Warning: Things URL scheme cannot un-complete tasks; some items may remain completed.")
+	}
+	if warnChecklist {
+		fmt.Fprintln(app.Err, "Warning: restored checklist items are recreated as incomplete.")
+	}
+	return nil
+}
+
+// checklistChanged reports whether the item's current checklist titles differ
+// from the snapshot.
+func checklistChanged(store *db.Store, item ActionItem) bool {
+	if store == nil || len(item.Checklist) == 0 {
+		return false
+	}
+	current, err := store.ChecklistItems([]string{item.UUID})
+	if err != nil {
+		return false
+	}
+	existing := current[item.UUID]
+	if len(existing) != len(item.Checklist) {
+		return true
+	}
+	for i, checklistItem := range item.Checklist {
+		if existing[i].Title != checklistItem.Title {
+			return true
+		}
+	}
+	return false
+}
+
+// restoreActionItems moves trashed items back to their recorded list using
+// AppleScript. Heading placement needs the URL scheme, so it is only
+// restored when an auth token is available.
+func restoreActionItems(app *App, authToken string, items []ActionItem) error {
+	targets := make([]things.RestoreTarget, 0, len(items))
+	var headed []ActionItem
+	for _, item := range items {
+		target := things.RestoreTarget{
+			ID:        item.UUID,
+			List:      item.Start,
+			ProjectID: item.ProjectID,
+			AreaID:    item.AreaID,
+			When:      item.StartDate,
+		}
+		targets = append(targets, target)
+		if item.HeadingTitle != "" && item.ProjectID != "" {
+			headed = append(headed, item)
+		}
+	}
+	script, err := things.BuildRestoreScript(targets)
+	if err != nil {
+		return err
+	}
+	if err := runScript(app, script); err != nil {
+		return err
+	}
+	if len(headed) == 0 {
+		return nil
+	}
+
+	token, err := resolveAuthToken(app, authToken)
+	if err != nil {
+		fmt.Fprintln(app.Err, "Warning: headings were not restored (requires a Things URL scheme token).")
+		return nil
+	}
+	for _, item := range headed {
+		url, err := things.BuildUpdateURL(things.UpdateOptions{
+			AuthToken: token,
+			ID:        item.UUID,
+			ListID:    item.ProjectID,
+			Heading:   item.HeadingTitle,
+		}, "")
+		if err != nil {
+			return err
+		}
+		if err := openURL(app, url); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// trashActionItems moves the logged items to Trash.
+func trashActionItems(app *App, items []ActionItem) error {
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.UUID)
+	}
+	script, err := things.BuildTrashScript(ids)
+	if err != nil {
+		return err
+	}
+	return runScript(app, script)
+}
+
+// snapshotActionItems captures the current database state of items so redo
+// can re-apply it. Items that cannot be read are skipped.
+func snapshotActionItems(store *db.Store, items []ActionItem) []ActionItem {
+	if store == nil {
+		return nil
+	}
+	tasks := make([]db.Task, 0, len(items))
+	for _, item := range items {
+		task, err := store.TaskByID(item.UUID)
+		if err != nil {
+			continue
+		}
+		tasks = append(tasks, *task)
+	}
+	return actionItemsFromTasks(store, tasks)
+}
+
 func whenFromActionItem(item ActionItem) string {
 	if item.StartDate != "" {
 		return item.StartDate
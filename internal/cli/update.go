@@ -46,6 +46,9 @@ func NewUpdateCommand(app *App) *cobra.Command {
 			if err := validateWhenInput(opts.When); err != nil {
 				return err
 			}
+			if err := resolveListOption(dbPath, queryOpts.Exact, &opts.List, &opts.ListID); err != nil {
+				return err
+			}
 			verifyWhen := resolveWhenValue(opts.When, opts.Later)
 			verifyWhenEnabled := verifyWhen != "" && !noVerify && !app.DryRun
 			guardEvening := strings.EqualFold(verifyWhen, "evening") && !allowNonToday
@@ -117,10 +120,7 @@ func NewUpdateCommand(app *App) *cobra.Command {
 
 				entry := ActionEntry{
 					Type:  ActionUpdate,
-					Items: make([]ActionItem, 0, len(tasks)),
-				}
-				for _, task := range tasks {
-					entry.Items = append(entry.Items, taskToActionItem(task))
+					Items: actionItemsFromTasks(store, tasks),
 				}
 				if err := appendAction(entry); err != nil {
 					fmt.Fprintf(app.Err, "Warning: failed to write action log: %v\n", err)
@@ -197,7 +197,7 @@ func NewUpdateCommand(app *App) *cobra.Command {
 					if task, err := logStore.TaskByID(opts.ID); err == nil {
 						entry := ActionEntry{
 							Type:  ActionUpdate,
-							Items: []ActionItem{taskToActionItem(*task)},
+							Items: actionItemsFromTasks(logStore, []db.Task{*task}),
 						}
 						if err := appendAction(entry); err != nil {
 							fmt.Fprintf(app.Err, "Warning: failed to write action log: %v\n", err)
@@ -275,7 +275,7 @@ func NewUpdateCommand(app *App) *cobra.Command {
 					if task, err := logStore.TaskByID(opts.ID); err == nil {
 						entry := ActionEntry{
 							Type:  ActionUpdate,
-							Items: []ActionItem{taskToActionItem(*task)},
+							Items: actionItemsFromTasks(logStore, []db.Task{*task}),
 						}
 						if err := appendAction(entry); err != nil {
 							fmt.Fprintf(app.Err, "Warning: failed to write action log: %v\n", err)
@@ -1,6 +1,10 @@
 package cli
 
 import (
+	"fmt"
+	"strings"
+
+	"github.com/ossianhempel/things3-cli/internal/db"
 	"github.com/ossianhempel/things3-cli/internal/things"
 	"github.com/spf13/cobra"
 )
@@ -8,6 +12,8 @@ import (
 // NewUpdateProjectCommand builds the update-project subcommand.
 func NewUpdateProjectCommand(app *App) *cobra.Command {
 	opts := things.UpdateProjectOptions{}
+	repeatOpts := RepeatOptions{}
+	var dbPath string
 	var allowUnsafeTitle bool
 
 	cmd := &cobra.Command{
@@ -18,6 +24,13 @@ func NewUpdateProjectCommand(app *App) *cobra.Command {
 			if err != nil {
 				return err
 			}
+			repeatSpec, err := parseRepeatSpec(cmd, repeatOpts)
+			if err != nil {
+				return err
+			}
+			if repeatSpec.Enabled && strings.TrimSpace(opts.ID) == "" {
+				return fmt.Errorf("Error: repeating updates require --id")
+			}
 			title := extractTitle(rawInput, "")
 			if err := guardUnsafeTitle(title, allowUnsafeTitle); err != nil {
 				return err
@@ -26,21 +39,55 @@ func NewUpdateProjectCommand(app *App) *cobra.Command {
 				return err
 			}
 
-			token, err := resolveAuthToken(app, opts.AuthToken)
+			if !repeatSpec.Enabled || hasProjectUpdateChanges(opts, rawInput) {
+				token, err := resolveAuthToken(app, opts.AuthToken)
+				if err != nil {
+					return err
+				}
+				opts.AuthToken = token
+
+				url, err := things.BuildUpdateProjectURL(opts, rawInput)
+				if err != nil {
+					return err
+				}
+				if err := openURL(app, url); err != nil {
+					return err
+				}
+				if !repeatSpec.Enabled {
+					return nil
+				}
+			} else if app.DryRun {
+				fmt.Fprintf(app.Out, "Would update repeating rule for %s\n", opts.ID)
+				return nil
+			}
+			if app.DryRun {
+				fmt.Fprintln(app.Err, "Note: --repeat is skipped in --dry-run mode.")
+				return nil
+			}
+
+			store, _, err := db.OpenDefaultWritable(dbPath)
 			if err != nil {
-				return err
+				return formatDBError(err)
 			}
-			opts.AuthToken = token
+			defer store.Close()
 
-			url, err := things.BuildUpdateProjectURL(opts, rawInput)
+			targetID, usedTemplate, err := resolveRepeatTarget(store, opts.ID, db.TaskTypeProject)
 			if err != nil {
-				return err
+				return formatDBError(err)
 			}
-			return openURL(app, url)
+			if usedTemplate {
+				fmt.Fprintf(app.Err, "Note: resolved repeating template %s for update\n", targetID)
+			}
+			if err := applyRepeatSpec(store, targetID, repeatSpec); err != nil {
+				return formatDBError(err)
+			}
+			return nil
 		},
 	}
 
 	flags := cmd.Flags()
+	flags.StringVarP(&dbPath, "db", "d", "", "Path to Things database (overrides THINGSDB)")
+	flags.StringVar(&dbPath, "database", "", "Alias for --db")
 	flags.StringVar(&opts.AuthToken, "auth-token", "", "Things URL scheme authorization token")
 	flags.StringVar(&opts.ID, "id", "", "ID of the project to update")
 	flags.StringVar(&opts.Notes, "notes", "", "Replace notes")
@@ -61,6 +108,32 @@ func NewUpdateProjectCommand(app *App) *cobra.Command {
 	flags.StringVar(&opts.CreationDate, "creation-date", "", "Creation date (ISO8601)")
 	flags.StringArrayVar(&opts.Todos, "todo", nil, "Todo title to add (repeatable)")
 	flags.BoolVar(&allowUnsafeTitle, "allow-unsafe-title", false, "Allow titles that look like flag assignments")
+	addRepeatFlags(cmd, &repeatOpts, true)
 
 	return cmd
 }
+
+func hasProjectUpdateChanges(opts things.UpdateProjectOptions, rawInput string) bool {
+	if strings.TrimSpace(rawInput) != "" {
+		return true
+	}
+	if opts.Notes != "" || opts.PrependNotes != "" || opts.AppendNotes != "" {
+		return true
+	}
+	if opts.When != "" || opts.Deadline != "" {
+		return true
+	}
+	if opts.Tags != "" || opts.AddTags != "" {
+		return true
+	}
+	if opts.AreaID != "" || opts.Area != "" {
+		return true
+	}
+	if opts.Completed || opts.Canceled || opts.Reveal || opts.Duplicate {
+		return true
+	}
+	if opts.CompletionDate != "" || opts.CreationDate != "" {
+		return true
+	}
+	return len(opts.Todos) > 0
+}
@@ -79,3 +79,87 @@ func TestUpdateProjectCommandAddTags(t *testing.T) {
 		t.Fatalf("expected add-tags in url, got %q", url)
 	}
 }
+
+func TestUpdateProjectCommandRepeatWritesTemplate(t *testing.T) {
+	dbPath := writeTestDB(t)
+	launcher := &recordLauncher{}
+	out := &bytes.Buffer{}
+	app := &App{
+		In:       strings.NewReader(""),
+		Out:      out,
+		Err:      &bytes.Buffer{},
+		Launcher: launcher,
+	}
+
+	root := NewRoot(app)
+	root.SetArgs([]string{"update-project", "--db", dbPath, "--id", "P1", "--repeat=week", "--repeat-on=mon,thu", "--repeat-mode=schedule"})
+	root.SetOut(app.Out)
+	root.SetErr(app.Err)
+	if err := root.Execute(); err != nil {
+		t.Fatalf("execute failed: %v", err)
+	}
+	if len(launcher.args) != 0 {
+		t.Fatalf("expected no URL for a repeat-only update, got %v", launcher.args)
+	}
+
+	root = NewRoot(app)
+	root.SetArgs([]string{"repeating", "--db", dbPath, "--format", "csv", "--select", "uuid,type,repeat_rule"})
+	root.SetOut(app.Out)
+	root.SetErr(app.Err)
+	if err := root.Execute(); err != nil {
+		t.Fatalf("repeating failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "P1,project,every week on Monday and Thursday") {
+		t.Fatalf("expected repeating project, got %q", out.String())
+	}
+
+	// Template contents stay attached to the project but leave the lists.
+	out.Reset()
+	root = NewRoot(app)
+	root.SetArgs([]string{"anytime", "--db", dbPath, "--format", "csv", "--select", "uuid"})
+	root.SetOut(app.Out)
+	root.SetErr(app.Err)
+	if err := root.Execute(); err != nil {
+		t.Fatalf("anytime failed: %v", err)
+	}
+	if strings.Contains(out.String(), "T1") {
+		t.Fatalf("expected template todo to be hidden, got %q", out.String())
+	}
+
+	out.Reset()
+	root = NewRoot(app)
+	root.SetArgs([]string{"update-project", "--db", dbPath, "--id", "P1", "--repeat-clear"})
+	root.SetOut(app.Out)
+	root.SetErr(app.Err)
+	if err := root.Execute(); err != nil {
+		t.Fatalf("repeat-clear failed: %v", err)
+	}
+	root = NewRoot(app)
+	root.SetArgs([]string{"anytime", "--db", dbPath, "--format", "csv", "--select", "uuid"})
+	root.SetOut(app.Out)
+	root.SetErr(app.Err)
+	if err := root.Execute(); err != nil {
+		t.Fatalf("anytime failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "T1") {
+		t.Fatalf("expected todo back in anytime, got %q", out.String())
+	}
+}
+
+func TestUpdateProjectCommandRepeatRejectsTodo(t *testing.T) {
+	dbPath := writeTestDB(t)
+	app := &App{
+		In:       strings.NewReader(""),
+		Out:      &bytes.Buffer{},
+		Err:      &bytes.Buffer{},
+		Launcher: &recordLauncher{},
+	}
+
+	root := NewRoot(app)
+	root.SetArgs([]string{"update-project", "--db", dbPath, "--id", "T1", "--repeat=day"})
+	root.SetOut(app.Out)
+	root.SetErr(app.Err)
+	if err := root.Execute(); err == nil {
+		t.Fatalf("expected type mismatch error")
+	}
+}
@@ -0,0 +1,306 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Settings are the values a config file or profile can set.
+type Settings struct {
+	DB         string `toml:"db,omitempty"`
+	AuthToken  string `toml:"auth-token,omitempty"`
+	Format     string `toml:"format,omitempty"`
+	Limit      int    `toml:"limit,omitempty"`
+	Foreground *bool  `toml:"foreground,omitempty"`
+}
+
+// Config is the contents of config.toml: top-level defaults, the default
+// profile name, and named profiles that override the defaults.
+type Config struct {
+	DB         string              `toml:"db,omitempty"`
+	AuthToken  string              `toml:"auth-token,omitempty"`
+	Format     string              `toml:"format,omitempty"`
+	Limit      int                 `toml:"limit,omitempty"`
+	Foreground *bool               `toml:"foreground,omitempty"`
+	Profile    string              `toml:"profile,omitempty"`
+	Profiles   map[string]Settings `toml:"profiles,omitempty"`
+}
+
+// SettingKeys lists the keys valid at the top level and inside profiles.
+var SettingKeys = []string{"db", "auth-token", "format", "limit", "foreground"}
+
+// Path returns the default config file path.
+func Path() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "things3-cli", "config.toml"), nil
+}
+
+// Load reads a config file. A missing file yields an empty config.
+func Load(path string) (Config, error) {
+	var cfg Config
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return cfg, nil
+		}
+		return cfg, err
+	}
+	meta, err := toml.Decode(string(data), &cfg)
+	if err != nil {
+		return cfg, fmt.Errorf("%s: %w", path, err)
+	}
+	if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+		return cfg, fmt.Errorf("%s: unknown key %q", path, undecoded[0].String())
+	}
+	return cfg, nil
+}
+
+// Save writes cfg to path, creating the directory if needed.
+func Save(path string, cfg Config) error {
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(cfg); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	// The file can hold an auth token.
+	return os.WriteFile(path, buf.Bytes(), 0o600)
+}
+
+// Resolve merges the named profile over the top-level settings. An empty
+// name selects the config's default profile, if any.
+func (c Config) Resolve(name string) (Settings, error) {
+	settings := Settings{
+		DB:         c.DB,
+		AuthToken:  c.AuthToken,
+		Format:     c.Format,
+		Limit:      c.Limit,
+		Foreground: c.Foreground,
+	}
+	if name == "" {
+		name = c.Profile
+	}
+	if name == "" {
+		return settings, nil
+	}
+	profile, ok := c.Profiles[name]
+	if !ok {
+		return settings, fmt.Errorf("unknown profile %q", name)
+	}
+	if profile.DB != "" {
+		settings.DB = profile.DB
+	}
+	if profile.AuthToken != "" {
+		settings.AuthToken = profile.AuthToken
+	}
+	if profile.Format != "" {
+		settings.Format = profile.Format
+	}
+	if profile.Limit != 0 {
+		settings.Limit = profile.Limit
+	}
+	if profile.Foreground != nil {
+		settings.Foreground = profile.Foreground
+	}
+	return settings, nil
+}
+
+// Get returns the value of key, which is a setting name, "profile", or
+// "profiles.NAME.SETTING".
+func (c Config) Get(key string) (string, error) {
+	profile, setting, err := splitKey(key)
+	if err != nil {
+		return "", err
+	}
+	if setting == "profile" {
+		return c.Profile, nil
+	}
+	if profile == "" {
+		return c.settings().get(setting), nil
+	}
+	return c.Profiles[profile].get(setting), nil
+}
+
+// Set assigns value to key. An empty value clears it; clearing the last
+// setting of a profile removes the profile.
+func (c *Config) Set(key, value string) error {
+	profile, setting, err := splitKey(key)
+	if err != nil {
+		return err
+	}
+	if setting == "profile" {
+		if value != "" {
+			if _, ok := c.Profiles[value]; !ok {
+				return fmt.Errorf("unknown profile %q", value)
+			}
+		}
+		c.Profile = value
+		return nil
+	}
+	if profile == "" {
+		settings := c.settings()
+		if err := settings.set(setting, value); err != nil {
+			return err
+		}
+		c.DB = settings.DB
+		c.AuthToken = settings.AuthToken
+		c.Format = settings.Format
+		c.Limit = settings.Limit
+		c.Foreground = settings.Foreground
+		return nil
+	}
+	settings := c.Profiles[profile]
+	if err := settings.set(setting, value); err != nil {
+		return err
+	}
+	if c.Profiles == nil {
+		c.Profiles = map[string]Settings{}
+	}
+	if settings == (Settings{}) {
+		delete(c.Profiles, profile)
+		if c.Profile == profile {
+			c.Profile = ""
+		}
+		return nil
+	}
+	c.Profiles[profile] = settings
+	return nil
+}
+
+// Entries returns every set key and value, sorted by key.
+func (c Config) Entries() [][2]string {
+	var entries [][2]string
+	add := func(prefix string, settings Settings) {
+		for _, key := range SettingKeys {
+			if value := settings.get(key); value != "" {
+				entries = append(entries, [2]string{prefix + key, value})
+			}
+		}
+	}
+	add("", c.settings())
+	if c.Profile != "" {
+		entries = append(entries, [2]string{"profile", c.Profile})
+	}
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		add("profiles."+name+".", c.Profiles[name])
+	}
+	return entries
+}
+
+func (c Config) settings() Settings {
+	return Settings{
+		DB:         c.DB,
+		AuthToken:  c.AuthToken,
+		Format:     c.Format,
+		Limit:      c.Limit,
+		Foreground: c.Foreground,
+	}
+}
+
+func (s Settings) get(key string) string {
+	switch key {
+	case "db":
+		return s.DB
+	case "auth-token":
+		return s.AuthToken
+	case "format":
+		return s.Format
+	case "limit":
+		if s.Limit == 0 {
+			return ""
+		}
+		return strconv.Itoa(s.Limit)
+	case "foreground":
+		if s.Foreground == nil {
+			return ""
+		}
+		return strconv.FormatBool(*s.Foreground)
+	}
+	return ""
+}
+
+func (s *Settings) set(key, value string) error {
+	value = strings.TrimSpace(value)
+	switch key {
+	case "db":
+		s.DB = value
+	case "auth-token":
+		s.AuthToken = value
+	case "format":
+		switch strings.ToLower(value) {
+		case "", "table", "json", "jsonl", "csv":
+			s.Format = strings.ToLower(value)
+		default:
+			return fmt.Errorf("invalid format %q (use table, json, jsonl, or csv)", value)
+		}
+	case "limit":
+		if value == "" {
+			s.Limit = 0
+			return nil
+		}
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 0 {
+			return fmt.Errorf("invalid limit %q", value)
+		}
+		s.Limit = limit
+	case "foreground":
+		if value == "" {
+			s.Foreground = nil
+			return nil
+		}
+		foreground, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid foreground value %q (use true or false)", value)
+		}
+		s.Foreground = &foreground
+	}
+	return nil
+}
+
+func splitKey(key string) (string, string, error) {
+	key = strings.TrimSpace(key)
+	if key == "profile" {
+		return "", key, nil
+	}
+	if rest, ok := strings.CutPrefix(key, "profiles."); ok {
+		idx := strings.LastIndex(rest, ".")
+		if idx <= 0 {
+			return "", "", fmt.Errorf("invalid key %q (use profiles.NAME.SETTING)", key)
+		}
+		name, setting := rest[:idx], rest[idx+1:]
+		if !isSettingKey(setting) {
+			return "", "", fmt.Errorf("unknown setting %q", setting)
+		}
+		return name, setting, nil
+	}
+	if !isSettingKey(key) {
+		return "", "", fmt.Errorf("unknown key %q", key)
+	}
+	return "", key, nil
+}
+
+func isSettingKey(key string) bool {
+	for _, candidate := range SettingKeys {
+		if key == candidate {
+			return true
+		}
+	}
+	return false
+}
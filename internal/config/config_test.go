@@ -0,0 +1,122 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "things3-cli", "config.toml")
+	var cfg Config
+	for _, kv := range [][2]string{
+		{"db", "~/Things/main.sqlite"},
+		{"limit", "25"},
+		{"foreground", "true"},
+		{"profiles.fixture.db", "/tmp/fixture.sqlite"},
+		{"profiles.fixture.format", "JSON"},
+		{"profile", "fixture"},
+	} {
+		if err := cfg.Set(kv[0], kv[1]); err != nil {
+			t.Fatalf("Set(%s) failed: %v", kv[0], err)
+		}
+	}
+	if err := Save(path, cfg); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Fatalf("expected 0600 permissions, got %v", info.Mode().Perm())
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	settings, err := loaded.Resolve("")
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if settings.DB != "/tmp/fixture.sqlite" || settings.Format != "json" || settings.Limit != 25 || settings.Foreground == nil || !*settings.Foreground {
+		t.Fatalf("unexpected settings: %+v", settings)
+	}
+	if got, _ := loaded.Get("profiles.fixture.format"); got != "json" {
+		t.Fatalf("unexpected profile format %q", got)
+	}
+
+	entries := loaded.Entries()
+	want := [][2]string{
+		{"db", "~/Things/main.sqlite"},
+		{"limit", "25"},
+		{"foreground", "true"},
+		{"profile", "fixture"},
+		{"profiles.fixture.db", "/tmp/fixture.sqlite"},
+		{"profiles.fixture.format", "json"},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("unexpected entries: %v", entries)
+	}
+	for i := range want {
+		if entries[i] != want[i] {
+			t.Fatalf("entry %d: got %v want %v", i, entries[i], want[i])
+		}
+	}
+}
+
+func TestLoadMissingAndUnknownKeys(t *testing.T) {
+	dir := t.TempDir()
+	cfg, err := Load(filepath.Join(dir, "missing.toml"))
+	if err != nil {
+		t.Fatalf("expected missing file to load, got %v", err)
+	}
+	if len(cfg.Entries()) != 0 {
+		t.Fatalf("expected empty config, got %v", cfg.Entries())
+	}
+
+	path := filepath.Join(dir, "config.toml")
+	if err := os.WriteFile(path, []byte("dbpath = \"x\"\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Fatalf("expected error for unknown key")
+	}
+}
+
+func TestSetValidatesKeysAndValues(t *testing.T) {
+	var cfg Config
+	for _, kv := range [][2]string{
+		{"colour", "red"},
+		{"limit", "many"},
+		{"format", "xml"},
+		{"foreground", "maybe"},
+		{"profiles.db", "x"},
+		{"profiles.work.colour", "x"},
+		{"profile", "missing"},
+	} {
+		if err := cfg.Set(kv[0], kv[1]); err == nil {
+			t.Fatalf("expected error for %s=%s", kv[0], kv[1])
+		}
+	}
+}
+
+func TestClearingProfileRemovesIt(t *testing.T) {
+	var cfg Config
+	if err := cfg.Set("profiles.work.limit", "10"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if err := cfg.Set("profile", "work"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if err := cfg.Set("profiles.work.limit", ""); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if _, ok := cfg.Profiles["work"]; ok || cfg.Profile != "" {
+		t.Fatalf("expected profile removed, got %+v", cfg)
+	}
+	if _, err := cfg.Resolve("work"); err == nil {
+		t.Fatalf("expected unknown profile error")
+	}
+}
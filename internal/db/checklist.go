@@ -52,3 +52,11 @@ func loadChecklistItems(conn *sql.DB, taskIDs []string) (map[string][]ChecklistI
 	}
 	return items, rows.Err()
 }
+
+// ChecklistItems returns checklist items keyed by task UUID.
+func (s *Store) ChecklistItems(taskIDs []string) (map[string][]ChecklistItem, error) {
+	if s == nil || s.conn == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	return loadChecklistItems(s.conn, taskIDs)
+}
@@ -0,0 +1,273 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Candidate is a project, area, or tag considered by the fuzzy resolver.
+type Candidate struct {
+	UUID  string `json:"uuid"`
+	Title string `json:"title"`
+	Kind  string `json:"kind"`
+	Score int    `json:"score"`
+}
+
+// AmbiguousError is returned when several items match a name equally well.
+type AmbiguousError struct {
+	Kind       string
+	Input      string
+	Candidates []Candidate
+}
+
+func (e *AmbiguousError) Error() string {
+	parts := make([]string, 0, len(e.Candidates))
+	for _, candidate := range e.Candidates {
+		label := fmt.Sprintf("%q (%s)", candidate.Title, candidate.UUID)
+		if candidate.Kind != "" && candidate.Kind != e.Kind {
+			label = candidate.Kind + " " + label
+		}
+		parts = append(parts, label)
+	}
+	return fmt.Sprintf("%s %q is ambiguous: %s; use a longer name, the UUID, or --exact", e.Kind, e.Input, strings.Join(parts, ", "))
+}
+
+// maxAmbiguousCandidates caps the candidates listed in an AmbiguousError.
+const maxAmbiguousCandidates = 5
+
+// ResolveProjectIDFuzzy resolves a project by UUID, exact title, or the best
+// fuzzy title match (prefix, word prefixes, substring, then small typos).
+func (s *Store) ResolveProjectIDFuzzy(input string) (string, error) {
+	if input == "" {
+		return "", nil
+	}
+	if id, err := lookupProjectID(s.conn, input); err != nil || id != "" {
+		return id, err
+	}
+	candidates, err := loadCandidates(s.conn, "project", "SELECT uuid, title FROM TMTask WHERE type = ? AND trashed = 0", TaskTypeProject)
+	if err != nil {
+		return "", err
+	}
+	return resolveFuzzy("project", input, candidates)
+}
+
+// ResolveAreaIDFuzzy resolves an area by UUID, exact title, or fuzzy title.
+func (s *Store) ResolveAreaIDFuzzy(input string) (string, error) {
+	if input == "" {
+		return "", nil
+	}
+	if id, err := lookupAreaID(s.conn, input); err != nil || id != "" {
+		return id, err
+	}
+	candidates, err := loadCandidates(s.conn, "area", "SELECT uuid, title FROM TMArea")
+	if err != nil {
+		return "", err
+	}
+	return resolveFuzzy("area", input, candidates)
+}
+
+// ResolveTagIDFuzzy resolves a tag by UUID, exact title, or fuzzy title.
+func (s *Store) ResolveTagIDFuzzy(input string) (string, error) {
+	if input == "" {
+		return "", nil
+	}
+	if id, err := lookupTagID(s.conn, input); err != nil || id != "" {
+		return id, err
+	}
+	candidates, err := loadCandidates(s.conn, "tag", "SELECT uuid, title FROM TMTag")
+	if err != nil {
+		return "", err
+	}
+	return resolveFuzzy("tag", input, candidates)
+}
+
+// ResolveListFuzzy resolves a project or area title for --list. exact reports
+// whether input already names one exactly (case-insensitive), in which case
+// the returned candidate is that item.
+func (s *Store) ResolveListFuzzy(input string) (Candidate, bool, error) {
+	projects, err := loadCandidates(s.conn, "project", "SELECT uuid, title FROM TMTask WHERE type = ? AND trashed = 0 AND status = ?", TaskTypeProject, StatusIncomplete)
+	if err != nil {
+		return Candidate{}, false, err
+	}
+	areas, err := loadCandidates(s.conn, "area", "SELECT uuid, title FROM TMArea")
+	if err != nil {
+		return Candidate{}, false, err
+	}
+	candidates := append(projects, areas...)
+	for _, candidate := range candidates {
+		if strings.EqualFold(candidate.Title, input) {
+			return candidate, true, nil
+		}
+	}
+	best, err := bestFuzzyMatch("list", input, candidates)
+	return best, false, err
+}
+
+func loadCandidates(conn *sql.DB, kind string, query string, args ...any) ([]Candidate, error) {
+	rows, err := conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var candidates []Candidate
+	for rows.Next() {
+		var candidate Candidate
+		var title sql.NullString
+		if err := rows.Scan(&candidate.UUID, &title); err != nil {
+			return nil, err
+		}
+		candidate.Title = title.String
+		candidate.Kind = kind
+		candidates = append(candidates, candidate)
+	}
+	return candidates, rows.Err()
+}
+
+func resolveFuzzy(kind, input string, candidates []Candidate) (string, error) {
+	best, err := bestFuzzyMatch(kind, input, candidates)
+	if err != nil {
+		return "", err
+	}
+	return best.UUID, nil
+}
+
+// bestFuzzyMatch returns the single highest-scoring candidate, a not-found
+// error when nothing scores, or an AmbiguousError when the top score is tied.
+func bestFuzzyMatch(kind, input string, candidates []Candidate) (Candidate, error) {
+	scored := make([]Candidate, 0, len(candidates))
+	for _, candidate := range candidates {
+		candidate.Score = FuzzyScore(input, candidate.Title)
+		if candidate.Score > 0 {
+			scored = append(scored, candidate)
+		}
+	}
+	if len(scored) == 0 {
+		return Candidate{}, fmt.Errorf("%s not found: %s", kind, input)
+	}
+	sort.SliceStable(scored, func(i, j int) bool {
+		if scored[i].Score != scored[j].Score {
+			return scored[i].Score > scored[j].Score
+		}
+		return strings.ToLower(scored[i].Title) < strings.ToLower(scored[j].Title)
+	})
+	tied := 1
+	for tied < len(scored) && scored[tied].Score == scored[0].Score {
+		tied++
+	}
+	if tied == 1 {
+		return scored[0], nil
+	}
+	if tied > maxAmbiguousCandidates {
+		tied = maxAmbiguousCandidates
+	}
+	return Candidate{}, &AmbiguousError{Kind: kind, Input: input, Candidates: scored[:tied]}
+}
+
+// FuzzyScore rates how well input matches title, from 0 (no match) to 100
+// (same words, ignoring case, punctuation, and emoji).
+func FuzzyScore(input, title string) int {
+	inputWords := fuzzyWords(input)
+	titleWords := fuzzyWords(title)
+	if len(inputWords) == 0 || len(titleWords) == 0 {
+		return 0
+	}
+	in := strings.Join(inputWords, " ")
+	full := strings.Join(titleWords, " ")
+	switch {
+	case in == full:
+		return 100
+	case strings.HasPrefix(full, in):
+		return 80
+	case allWords(inputWords, titleWords, strings.HasPrefix):
+		return 60
+	case strings.Contains(full, in):
+		return 50
+	}
+
+	distance := 0
+	for _, word := range inputWords {
+		limit := maxTypos(word)
+		best := limit + 1
+		for _, candidate := range titleWords {
+			if d := editDistance(word, candidate); d < best {
+				best = d
+			}
+			// Allow a typo in a word prefix too ("plannig" ~ "planning").
+			if runes := []rune(candidate); len(runes) > len([]rune(word)) {
+				if d := editDistance(word, string(runes[:len([]rune(word))])); d < best {
+					best = d
+				}
+			}
+		}
+		if best > limit {
+			return 0
+		}
+		distance += best
+	}
+	score := 40 - 5*distance
+	if score < 1 {
+		score = 1
+	}
+	return score
+}
+
+func allWords(inputWords, titleWords []string, match func(string, string) bool) bool {
+	for _, word := range inputWords {
+		found := false
+		for _, candidate := range titleWords {
+			if match(candidate, word) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// fuzzyWords lowercases value and splits it on anything that is not a letter
+// or digit, so emoji and punctuation are ignored.
+func fuzzyWords(value string) []string {
+	return strings.FieldsFunc(strings.ToLower(value), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func maxTypos(word string) int {
+	switch n := len([]rune(word)); {
+	case n <= 3:
+		return 0
+	case n <= 6:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// editDistance is the Levenshtein distance between a and b in runes.
+func editDistance(a, b string) int {
+	left := []rune(a)
+	right := []rune(b)
+	prev := make([]int, len(right)+1)
+	curr := make([]int, len(right)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(left); i++ {
+		curr[0] = i
+		for j := 1; j <= len(right); j++ {
+			cost := 1
+			if left[i-1] == right[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(right)]
+}
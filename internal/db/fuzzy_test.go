@@ -0,0 +1,82 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestFuzzyScore(t *testing.T) {
+	cases := []struct {
+		input string
+		title string
+		want  int
+	}{
+		{"q4 planning", "Q4 Planning 🚀", 100},
+		{"q4 plan", "Q4 Planning 🚀", 80},
+		{"planning q4", "Q4 Planning 🚀", 60},
+		{"anning", "Q4 Planning", 50},
+		{"plannig", "Q4 Planning", 35},
+		{"q4 plannign", "Q4 Planning", 30},
+		{"q5", "Q4 Planning", 0},
+		{"groceries", "Q4 Planning", 0},
+		{"🚀", "Q4 Planning 🚀", 0},
+	}
+	for _, tc := range cases {
+		if got := FuzzyScore(tc.input, tc.title); got != tc.want {
+			t.Errorf("FuzzyScore(%q, %q) = %d, want %d", tc.input, tc.title, got, tc.want)
+		}
+	}
+}
+
+func TestResolveFuzzy(t *testing.T) {
+	conn, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer conn.Close()
+	if err := seedTestDB(conn); err != nil {
+		t.Fatalf("seed db: %v", err)
+	}
+	if _, err := conn.Exec(`INSERT INTO TMTask (uuid, type, status, trashed, title) VALUES ('P3', ?, ?, 0, 'Q4 Planning 🚀');`, TaskTypeProject, StatusIncomplete); err != nil {
+		t.Fatalf("insert project: %v", err)
+	}
+	store := &Store{conn: conn, path: ":memory:"}
+
+	if id, err := store.ResolveProjectIDFuzzy("q4 plan"); err != nil || id != "P3" {
+		t.Fatalf("expected P3, got %q, %v", id, err)
+	}
+	if id, err := store.ResolveProjectIDFuzzy("P1"); err != nil || id != "P1" {
+		t.Fatalf("expected UUID lookup, got %q, %v", id, err)
+	}
+	if _, err := store.ResolveProjectID("q4 plan"); err == nil {
+		t.Fatalf("expected strict resolver to reject a partial title")
+	}
+
+	_, err = store.ResolveProjectIDFuzzy("proj")
+	var ambiguous *AmbiguousError
+	if !errors.As(err, &ambiguous) {
+		t.Fatalf("expected ambiguous error, got %v", err)
+	}
+	if len(ambiguous.Candidates) != 2 || !strings.Contains(err.Error(), `"Project Done" (P2)`) || !strings.Contains(err.Error(), `"Project One" (P1)`) {
+		t.Fatalf("expected both projects listed, got %v", err)
+	}
+
+	if _, err := store.ResolveAreaIDFuzzy("office"); err == nil || err.Error() != "area not found: office" {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+	if id, err := store.ResolveAreaIDFuzzy("hom"); err != nil || id != "A1" {
+		t.Fatalf("expected A1, got %q, %v", id, err)
+	}
+
+	// Completed projects are not --list targets, so "proj" is unambiguous.
+	candidate, exact, err := store.ResolveListFuzzy("proj")
+	if err != nil || exact || candidate.UUID != "P1" {
+		t.Fatalf("expected fuzzy list match P1, got %+v, %v, %v", candidate, exact, err)
+	}
+	candidate, exact, err = store.ResolveListFuzzy("home")
+	if err != nil || !exact || candidate.UUID != "A1" || candidate.Kind != "area" {
+		t.Fatalf("expected exact area match, got %+v, %v, %v", candidate, exact, err)
+	}
+}
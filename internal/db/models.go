@@ -44,7 +44,11 @@ type Task struct {
 	Notes        string          `json:"notes,omitempty"`
 	Start        string          `json:"start,omitempty"`
 	StartDate    string          `json:"start_date,omitempty"`
+	Evening      bool            `json:"evening,omitempty"`
 	Repeating    bool            `json:"repeating,omitempty"`
+	RepeatRule   string          `json:"repeat_rule,omitempty"`
+	RepeatNext   string          `json:"repeat_next,omitempty"`
+	RepeatUntil  string          `json:"repeat_until,omitempty"`
 	Deadline     string          `json:"deadline,omitempty"`
 	StopDate     string          `json:"stop_date,omitempty"`
 	Created      string          `json:"created,omitempty"`
@@ -53,12 +57,14 @@ type Task struct {
 	TodayIndex   *int            `json:"today_index,omitempty"`
 	Tags         []string        `json:"tags,omitempty"`
 	Checklist    []ChecklistItem `json:"checklist,omitempty"`
+	HasChecklist bool            `json:"-"`
 	ProjectID    string          `json:"project_id,omitempty"`
 	ProjectTitle string          `json:"project_title,omitempty"`
 	AreaID       string          `json:"area_id,omitempty"`
 	AreaTitle    string          `json:"area_title,omitempty"`
 	HeadingID    string          `json:"heading_id,omitempty"`
 	HeadingTitle string          `json:"heading_title,omitempty"`
+	Snippet      string          `json:"snippet,omitempty"`
 }
 
 type ChecklistItem struct {
@@ -122,6 +128,10 @@ type TaskFilter struct {
 	Order                 string
 	IncludeRepeating      bool
 	RepeatingOnly         bool
+	// Where is an extra SQL condition on the task alias t (and the p, a, h
+	// joins); WhereArgs holds its parameters.
+	Where     string
+	WhereArgs []any
 }
 
 func StatusLabel(status int) string {
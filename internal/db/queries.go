@@ -520,6 +520,19 @@ func resolveAreaID(conn *sql.DB, input string) (string, error) {
 	if input == "" {
 		return "", nil
 	}
+	id, err := lookupAreaID(conn, input)
+	if err != nil {
+		return "", err
+	}
+	if id == "" {
+		return "", fmt.Errorf("area not found: %s", input)
+	}
+	return id, nil
+}
+
+// lookupAreaID matches a UUID or a case-insensitive title, returning "" when
+// nothing matches.
+func lookupAreaID(conn *sql.DB, input string) (string, error) {
 	var id string
 	if err := conn.QueryRow("SELECT uuid FROM TMArea WHERE uuid = ?", input).Scan(&id); err == nil {
 		return id, nil
@@ -531,13 +544,26 @@ func resolveAreaID(conn *sql.DB, input string) (string, error) {
 	} else if err != sql.ErrNoRows {
 		return "", err
 	}
-	return "", fmt.Errorf("area not found: %s", input)
+	return "", nil
 }
 
 func resolveProjectID(conn *sql.DB, input string) (string, error) {
 	if input == "" {
 		return "", nil
 	}
+	id, err := lookupProjectID(conn, input)
+	if err != nil {
+		return "", err
+	}
+	if id == "" {
+		return "", fmt.Errorf("project not found: %s", input)
+	}
+	return id, nil
+}
+
+// lookupProjectID matches a UUID or a case-insensitive title, returning "" when
+// nothing matches.
+func lookupProjectID(conn *sql.DB, input string) (string, error) {
 	var id string
 	if err := conn.QueryRow("SELECT uuid FROM TMTask WHERE type = ? AND uuid = ?", TaskTypeProject, input).Scan(&id); err == nil {
 		return id, nil
@@ -549,13 +575,26 @@ func resolveProjectID(conn *sql.DB, input string) (string, error) {
 	} else if err != sql.ErrNoRows {
 		return "", err
 	}
-	return "", fmt.Errorf("project not found: %s", input)
+	return "", nil
 }
 
 func resolveTagID(conn *sql.DB, input string) (string, error) {
 	if input == "" {
 		return "", nil
 	}
+	id, err := lookupTagID(conn, input)
+	if err != nil {
+		return "", err
+	}
+	if id == "" {
+		return "", fmt.Errorf("tag not found: %s", input)
+	}
+	return id, nil
+}
+
+// lookupTagID matches a UUID or a case-insensitive title, returning "" when
+// nothing matches.
+func lookupTagID(conn *sql.DB, input string) (string, error) {
 	var id string
 	if err := conn.QueryRow("SELECT uuid FROM TMTag WHERE uuid = ?", input).Scan(&id); err == nil {
 		return id, nil
@@ -567,7 +606,7 @@ func resolveTagID(conn *sql.DB, input string) (string, error) {
 	} else if err != sql.ErrNoRows {
 		return "", err
 	}
-	return "", fmt.Errorf("tag not found: %s", input)
+	return "", nil
 }
 
 func thingsDateTodayExpr() string {
@@ -579,7 +618,8 @@ func (s *Store) queryTasks(where string, args []any, filter TaskFilter, order st
 		return nil, fmt.Errorf("database not initialized")
 	}
 	var b strings.Builder
-	b.WriteString("SELECT t.uuid, t.type, t.title, t.status, t.trashed, t.notes, t.start, t.startDate, t.deadline, t.stopDate, t.creationDate, t.userModificationDate, t.\"index\", t.todayIndex, (t.rt1_recurrenceRule IS NOT NULL) AS repeating, ")
+	b.WriteString("SELECT t.uuid, t.type, t.title, t.status, t.trashed, t.notes, t.start, t.startDate, t.deadline, t.stopDate, t.creationDate, t.userModificationDate, t.\"index\", t.todayIndex, (t.rt1_recurrenceRule IS NOT NULL) AS repeating, t.startBucket, ")
+	b.WriteString("EXISTS (SELECT 1 FROM TMChecklistItem c WHERE c.task = t.uuid), ")
 	b.WriteString("t.project, p.title, t.area, a.title, t.heading, h.title, ")
 	b.WriteString("(SELECT group_concat(title, '" + tagSeparator + "') FROM (")
 	b.WriteString("SELECT tag.title AS title FROM TMTag tag ")
@@ -606,6 +646,10 @@ func (s *Store) queryTasks(where string, args []any, filter TaskFilter, order st
 		b.WriteString(" AND (" + where + ")")
 		params = append(params, args...)
 	}
+	if filter.Where != "" {
+		b.WriteString(" AND (" + filter.Where + ")")
+		params = append(params, filter.WhereArgs...)
+	}
 	if !filter.IncludeTrashed {
 		b.WriteString(" AND t.trashed = 0")
 	}
@@ -668,7 +712,10 @@ func (s *Store) queryTasks(where string, args []any, filter TaskFilter, order st
 	if filter.RepeatingOnly {
 		b.WriteString(" AND t.rt1_recurrenceRule IS NOT NULL")
 	} else if !filter.IncludeRepeating {
+		// Items inside a repeating project template belong to the template,
+		// not to any list.
 		b.WriteString(" AND t.rt1_recurrenceRule IS NULL")
+		b.WriteString(" AND p.rt1_recurrenceRule IS NULL AND hp.rt1_recurrenceRule IS NULL")
 	}
 
 	orderClause := order
@@ -734,6 +781,7 @@ func scanTaskRows(rows *sql.Rows) ([]Task, error) {
 		var index sql.NullInt64
 		var todayIndex sql.NullInt64
 		var repeating sql.NullInt64
+		var startBucket sql.NullInt64
 		var projectID sql.NullString
 		var projectTitle sql.NullString
 		var areaID sql.NullString
@@ -741,7 +789,7 @@ func scanTaskRows(rows *sql.Rows) ([]Task, error) {
 		var headingID sql.NullString
 		var headingTitle sql.NullString
 		var tagTitles sql.NullString
-		if err := rows.Scan(&t.UUID, &taskType, &t.Title, &t.Status, &t.Trashed, &notes, &start, &startDate, &deadline, &stopDate, &created, &modified, &index, &todayIndex, &repeating, &projectID, &projectTitle, &areaID, &areaTitle, &headingID, &headingTitle, &tagTitles); err != nil {
+		if err := rows.Scan(&t.UUID, &taskType, &t.Title, &t.Status, &t.Trashed, &notes, &start, &startDate, &deadline, &stopDate, &created, &modified, &index, &todayIndex, &repeating, &startBucket, &t.HasChecklist, &projectID, &projectTitle, &areaID, &areaTitle, &headingID, &headingTitle, &tagTitles); err != nil {
 			return nil, err
 		}
 		t.Type = taskTypeLabel(taskType)
@@ -755,6 +803,9 @@ func scanTaskRows(rows *sql.Rows) ([]Task, error) {
 		if repeating.Valid {
 			t.Repeating = repeating.Int64 != 0
 		}
+		if startBucket.Valid {
+			t.Evening = startBucket.Int64 == 1
+		}
 		if notes.Valid {
 			t.Notes = notes.String
 		}
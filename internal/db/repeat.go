@@ -95,6 +95,39 @@ func (s *Store) TasksByTitleSince(title string, taskType int, since float64) ([]
 	return matches, rows.Err()
 }
 
+// CreatedTasksByTitle returns untrashed items with the given title created at or after the timestamp.
+func (s *Store) CreatedTasksByTitle(title string, taskType int, since float64) ([]TaskMatch, error) {
+	if s == nil || s.conn == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	if strings.TrimSpace(title) == "" {
+		return nil, fmt.Errorf("title required")
+	}
+	rows, err := s.conn.Query(
+		`SELECT uuid, creationDate
+		 FROM TMTask
+		 WHERE type = ? AND trashed = 0 AND lower(title) = lower(?) AND creationDate >= ?
+		 ORDER BY creationDate DESC`,
+		taskType,
+		title,
+		since,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var matches []TaskMatch
+	for rows.Next() {
+		var match TaskMatch
+		if err := rows.Scan(&match.UUID, &match.Created); err != nil {
+			return nil, err
+		}
+		matches = append(matches, match)
+	}
+	return matches, rows.Err()
+}
+
 // TaskMatch identifies a task result for matching repeat updates after creation.
 type TaskMatch struct {
 	UUID    string
@@ -172,3 +205,50 @@ func (s *Store) ClearRepeatRule(id string) error {
 	)
 	return err
 }
+
+// RecurrenceRule holds the raw recurrence data stored on a repeating item.
+type RecurrenceRule struct {
+	UUID          string
+	Rule          []byte
+	NextStartDate string
+}
+
+// RecurrenceRules returns the recurrence rules for the given IDs, keyed by UUID.
+// IDs without a rule are omitted.
+func (s *Store) RecurrenceRules(ids []string) (map[string]RecurrenceRule, error) {
+	if s == nil || s.conn == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	rules := make(map[string]RecurrenceRule, len(ids))
+	if len(ids) == 0 {
+		return rules, nil
+	}
+	placeholders := strings.TrimRight(strings.Repeat("?,", len(ids)), ",")
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	rows, err := s.conn.Query(
+		`SELECT uuid, rt1_recurrenceRule, rt1_nextInstanceStartDate
+		 FROM TMTask
+		 WHERE uuid IN (`+placeholders+`) AND rt1_recurrenceRule IS NOT NULL`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var rule RecurrenceRule
+		var next sql.NullInt64
+		if err := rows.Scan(&rule.UUID, &rule.Rule, &next); err != nil {
+			return nil, err
+		}
+		if next.Valid {
+			rule.NextStartDate = formatThingsDate(next.Int64)
+		}
+		rules[rule.UUID] = rule
+	}
+	return rules, rows.Err()
+}
@@ -155,3 +155,92 @@ func TestTasksByTitleSinceUsesModificationDate(t *testing.T) {
 		t.Fatalf("expected T1, got %s", matches[0].UUID)
 	}
 }
+
+func TestRecurrenceRules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "things.sqlite3")
+	conn, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if _, err := conn.Exec(`CREATE TABLE TMTask (
+		uuid TEXT PRIMARY KEY,
+		title TEXT,
+		rt1_recurrenceRule BLOB,
+		rt1_nextInstanceStartDate INTEGER
+	);`); err != nil {
+		t.Fatalf("create schema: %v", err)
+	}
+	next := 2026<<16 | 3<<12 | 14<<7
+	if _, err := conn.Exec(`INSERT INTO TMTask (uuid, title, rt1_recurrenceRule, rt1_nextInstanceStartDate) VALUES
+		('R1', 'Repeating', X'0102', ?),
+		('R2', 'Paused', X'03', NULL),
+		('T1', 'Plain', NULL, NULL);`, next); err != nil {
+		t.Fatalf("insert tasks: %v", err)
+	}
+	if err := conn.Close(); err != nil {
+		t.Fatalf("close db: %v", err)
+	}
+
+	store, err := OpenWritable(path)
+	if err != nil {
+		t.Fatalf("open writable: %v", err)
+	}
+	defer store.Close()
+
+	rules, err := store.RecurrenceRules([]string{"R1", "R2", "T1", "missing"})
+	if err != nil {
+		t.Fatalf("recurrence rules: %v", err)
+	}
+	if len(rules) != 2 {
+		t.Fatalf("expected 2 rules, got %d", len(rules))
+	}
+	if got := rules["R1"]; len(got.Rule) != 2 || got.NextStartDate != "2026-03-14" {
+		t.Fatalf("unexpected R1 rule: %+v", got)
+	}
+	if got := rules["R2"]; got.NextStartDate != "" {
+		t.Fatalf("expected no next start for R2, got %q", got.NextStartDate)
+	}
+}
+
+func TestRepeatingProjectContentsHiddenFromLists(t *testing.T) {
+	conn, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer conn.Close()
+	if err := seedListDB(conn); err != nil {
+		t.Fatalf("seed db: %v", err)
+	}
+	statements := []string{
+		`INSERT INTO TMTask (uuid, type, status, trashed, title, start, rt1_recurrenceRule) VALUES ('P_REP', 1, 0, 0, 'Repeating Project', 1, X'00');`,
+		`INSERT INTO TMTask (uuid, type, status, trashed, title, project) VALUES ('H_REP', 2, 0, 0, 'Template Heading', 'P_REP');`,
+		`INSERT INTO TMTask (uuid, type, status, trashed, title, start, project) VALUES ('REPT1', 0, 0, 0, 'Template Todo', 1, 'P_REP');`,
+		`INSERT INTO TMTask (uuid, type, status, trashed, title, start, heading) VALUES ('REPT2', 0, 0, 0, 'Template Heading Todo', 1, 'H_REP');`,
+	}
+	for _, stmt := range statements {
+		if _, err := conn.Exec(stmt); err != nil {
+			t.Fatalf("insert: %v", err)
+		}
+	}
+
+	store := &Store{conn: conn, path: ":memory:"}
+	incomplete := StatusIncomplete
+	tasks, err := store.AnytimeTasks(TaskFilter{Status: &incomplete})
+	assertHasTask(t, tasks, err, "Anytime Task")
+	assertNotHasTask(t, tasks, "Template Todo")
+	assertNotHasTask(t, tasks, "Template Heading Todo")
+
+	tasks, err = store.Tasks(TaskFilter{Status: &incomplete, RepeatingOnly: true, Types: []int{TaskTypeTodo, TaskTypeProject}})
+	assertHasTask(t, tasks, err, "Repeating Project")
+	if len(tasks) != 1 || tasks[0].Type != "project" || !tasks[0].Repeating {
+		t.Fatalf("expected only the repeating project, got %#v", tasks)
+	}
+
+	tasks, err = store.Tasks(TaskFilter{Status: &incomplete, IncludeRepeating: true})
+	assertHasTask(t, tasks, err, "Template Todo")
+	for _, task := range tasks {
+		if task.UUID == "REPT2" && task.HeadingID != "H_REP" {
+			t.Fatalf("expected template heading todo to keep its heading, got %#v", task)
+		}
+	}
+}
@@ -0,0 +1,348 @@
+package db
+
+import (
+	"crypto/sha1"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// SearchIndex is a full-text (FTS5) index over task titles, notes and
+// checklist item titles. It lives in its own SQLite file because the Things
+// database is opened read-only.
+type SearchIndex struct {
+	conn *sql.DB
+	path string
+}
+
+// SearchHit is a ranked match from the search index. Lower ranks are better.
+type SearchHit struct {
+	UUID    string
+	Rank    float64
+	Snippet string
+}
+
+// SearchIndexStats reports what a Sync changed.
+type SearchIndexStats struct {
+	Indexed int
+	Removed int
+	Rebuilt bool
+}
+
+const searchIndexVersion = "1"
+
+// SearchIndexPath returns the default index location for a Things database:
+// one file per database under the user cache directory.
+func SearchIndexPath(dbPath string) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	abs, err := filepath.Abs(dbPath)
+	if err != nil {
+		return "", err
+	}
+	sum := sha1.Sum([]byte(abs))
+	name := "search-" + hex.EncodeToString(sum[:])[:12] + ".sqlite"
+	return filepath.Join(dir, "things3-cli", name), nil
+}
+
+// OpenSearchIndex opens or creates the search index at path.
+func OpenSearchIndex(path string) (*SearchIndex, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("create index directory: %w", err)
+	}
+	conn, err := sql.Open("sqlite", sqliteDSN(path, "rwc"))
+	if err != nil {
+		return nil, fmt.Errorf("open search index: %w", err)
+	}
+	index := &SearchIndex{conn: conn, path: path}
+	if err := index.ensureSchema(); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return index, nil
+}
+
+// Close closes the index connection.
+func (ix *SearchIndex) Close() error {
+	if ix == nil || ix.conn == nil {
+		return nil
+	}
+	return ix.conn.Close()
+}
+
+// Path returns the index file path.
+func (ix *SearchIndex) Path() string {
+	if ix == nil {
+		return ""
+	}
+	return ix.path
+}
+
+func (ix *SearchIndex) ensureSchema() error {
+	var version string
+	err := ix.conn.QueryRow(`SELECT value FROM meta WHERE key = 'version'`).Scan(&version)
+	if err == nil && version == searchIndexVersion {
+		return nil
+	}
+	statements := []string{
+		`DROP TABLE IF EXISTS task_fts`,
+		`DROP TABLE IF EXISTS task_docs`,
+		`DROP TABLE IF EXISTS meta`,
+		`CREATE TABLE meta (key TEXT PRIMARY KEY, value TEXT)`,
+		`CREATE TABLE task_docs (id INTEGER PRIMARY KEY, uuid TEXT NOT NULL UNIQUE)`,
+		`CREATE VIRTUAL TABLE task_fts USING fts5(title, notes, checklist, tokenize = 'unicode61 remove_diacritics 2')`,
+		`INSERT INTO meta (key, value) VALUES ('version', '` + searchIndexVersion + `')`,
+	}
+	for _, stmt := range statements {
+		if _, err := ix.conn.Exec(stmt); err != nil {
+			return fmt.Errorf("create search index: %w", err)
+		}
+	}
+	return nil
+}
+
+// Reset drops all indexed documents so the next Sync rebuilds from scratch.
+func (ix *SearchIndex) Reset() error {
+	for _, stmt := range []string{`DELETE FROM task_fts`, `DELETE FROM task_docs`, `DELETE FROM meta WHERE key != 'version'`} {
+		if _, err := ix.conn.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Sync brings the index up to date with the Things database. Only tasks whose
+// userModificationDate (or one of whose checklist items') is after the
+// last sync are reindexed; tasks that no longer exist are removed.
+func (ix *SearchIndex) Sync(store *Store) (SearchIndexStats, error) {
+	var stats SearchIndexStats
+	if store == nil || store.conn == nil {
+		return stats, fmt.Errorf("database not initialized")
+	}
+
+	meta, err := ix.meta()
+	if err != nil {
+		return stats, err
+	}
+	since := -1.0
+	if meta["source"] == store.path {
+		if value, err := strconv.ParseFloat(meta["synced_through"], 64); err == nil {
+			since = value
+		}
+	}
+	if since < 0 {
+		if err := ix.Reset(); err != nil {
+			return stats, err
+		}
+		stats.Rebuilt = true
+	}
+
+	// Read the watermark before the changes so edits made while syncing are
+	// picked up next time.
+	var watermark sql.NullFloat64
+	if err := store.conn.QueryRow(`SELECT MAX(m) FROM (
+		SELECT MAX(userModificationDate) AS m FROM TMTask
+		UNION ALL SELECT MAX(userModificationDate) FROM TMChecklistItem
+	)`).Scan(&watermark); err != nil {
+		return stats, err
+	}
+
+	docs, err := loadSearchDocs(store.conn, since)
+	if err != nil {
+		return stats, err
+	}
+	live, err := liveTaskIDs(store.conn)
+	if err != nil {
+		return stats, err
+	}
+
+	tx, err := ix.conn.Begin()
+	if err != nil {
+		return stats, err
+	}
+	defer tx.Rollback()
+
+	indexed := map[string]int64{}
+	rows, err := tx.Query(`SELECT id, uuid FROM task_docs`)
+	if err != nil {
+		return stats, err
+	}
+	for rows.Next() {
+		var id int64
+		var uuid string
+		if err := rows.Scan(&id, &uuid); err != nil {
+			rows.Close()
+			return stats, err
+		}
+		indexed[uuid] = id
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return stats, err
+	}
+
+	for uuid, id := range indexed {
+		if live[uuid] {
+			continue
+		}
+		if err := deleteSearchDoc(tx, id); err != nil {
+			return stats, err
+		}
+		stats.Removed++
+	}
+	for _, doc := range docs {
+		id, ok := indexed[doc.UUID]
+		if ok {
+			if _, err := tx.Exec(`DELETE FROM task_fts WHERE rowid = ?`, id); err != nil {
+				return stats, err
+			}
+		} else {
+			result, err := tx.Exec(`INSERT INTO task_docs (uuid) VALUES (?)`, doc.UUID)
+			if err != nil {
+				return stats, err
+			}
+			if id, err = result.LastInsertId(); err != nil {
+				return stats, err
+			}
+		}
+		if _, err := tx.Exec(`INSERT INTO task_fts (rowid, title, notes, checklist) VALUES (?, ?, ?, ?)`, id, doc.Title, doc.Notes, doc.Checklist); err != nil {
+			return stats, err
+		}
+		stats.Indexed++
+	}
+
+	through := since
+	if watermark.Valid && watermark.Float64 > through {
+		through = watermark.Float64
+	}
+	if through < 0 {
+		through = 0
+	}
+	for key, value := range map[string]string{
+		"source":         store.path,
+		"synced_through": strconv.FormatFloat(through, 'f', -1, 64),
+	} {
+		if _, err := tx.Exec(`INSERT INTO meta (key, value) VALUES (?, ?) ON CONFLICT(key) DO UPDATE SET value = excluded.value`, key, value); err != nil {
+			return stats, err
+		}
+	}
+	return stats, tx.Commit()
+}
+
+func deleteSearchDoc(tx *sql.Tx, id int64) error {
+	if _, err := tx.Exec(`DELETE FROM task_fts WHERE rowid = ?`, id); err != nil {
+		return err
+	}
+	_, err := tx.Exec(`DELETE FROM task_docs WHERE id = ?`, id)
+	return err
+}
+
+func (ix *SearchIndex) meta() (map[string]string, error) {
+	rows, err := ix.conn.Query(`SELECT key, value FROM meta`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	meta := map[string]string{}
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, err
+		}
+		meta[key] = value
+	}
+	return meta, rows.Err()
+}
+
+type searchDoc struct {
+	UUID      string
+	Title     string
+	Notes     string
+	Checklist string
+}
+
+func loadSearchDocs(conn *sql.DB, since float64) ([]searchDoc, error) {
+	rows, err := conn.Query(`SELECT t.uuid, IFNULL(t.title, ''), IFNULL(t.notes, ''),
+		IFNULL((SELECT group_concat(title, char(10)) FROM (
+			SELECT c.title AS title FROM TMChecklistItem c WHERE c.task = t.uuid ORDER BY c."index"
+		)), '')
+		FROM TMTask t
+		WHERE IFNULL(t.userModificationDate, 0) > ?
+		OR t.uuid IN (SELECT task FROM TMChecklistItem WHERE IFNULL(userModificationDate, 0) > ?)`, since, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var docs []searchDoc
+	for rows.Next() {
+		var doc searchDoc
+		if err := rows.Scan(&doc.UUID, &doc.Title, &doc.Notes, &doc.Checklist); err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+	return docs, rows.Err()
+}
+
+func liveTaskIDs(conn *sql.DB) (map[string]bool, error) {
+	rows, err := conn.Query(`SELECT uuid FROM TMTask`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	ids := map[string]bool{}
+	for rows.Next() {
+		var uuid string
+		if err := rows.Scan(&uuid); err != nil {
+			return nil, err
+		}
+		ids[uuid] = true
+	}
+	return ids, rows.Err()
+}
+
+// Search returns matches for the words in query, best first. Each word is
+// matched as a prefix, and all words must match. open and close wrap the
+// matched terms in snippets.
+func (ix *SearchIndex) Search(query, open, close string) ([]SearchHit, error) {
+	match := searchMatchExpr(query)
+	if match == "" {
+		return nil, fmt.Errorf("empty search query")
+	}
+	rows, err := ix.conn.Query(`SELECT d.uuid, bm25(task_fts, 10.0, 1.0, 2.0) AS rank,
+		snippet(task_fts, -1, ?, ?, '…', 12)
+		FROM task_fts JOIN task_docs d ON d.id = task_fts.rowid
+		WHERE task_fts MATCH ?
+		ORDER BY rank`, open, close, match)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var hits []SearchHit
+	for rows.Next() {
+		var hit SearchHit
+		if err := rows.Scan(&hit.UUID, &hit.Rank, &hit.Snippet); err != nil {
+			return nil, err
+		}
+		hit.Snippet = strings.Join(strings.Fields(hit.Snippet), " ")
+		hits = append(hits, hit)
+	}
+	return hits, rows.Err()
+}
+
+// searchMatchExpr quotes each word so FTS5 operators in user input are taken
+// literally, and turns it into a prefix query.
+func searchMatchExpr(query string) string {
+	words := strings.Fields(query)
+	terms := make([]string, 0, len(words))
+	for _, word := range words {
+		terms = append(terms, `"`+strings.ReplaceAll(word, `"`, `""`)+`"*`)
+	}
+	return strings.Join(terms, " ")
+}
@@ -0,0 +1,74 @@
+package db
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSearchIndexSyncAndSearch(t *testing.T) {
+	conn, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+	if err := seedTestDB(conn); err != nil {
+		t.Fatalf("seed db: %v", err)
+	}
+	store := &Store{conn: conn, path: ":memory:"}
+
+	index, err := OpenSearchIndex(filepath.Join(t.TempDir(), "search.sqlite"))
+	if err != nil {
+		t.Fatalf("open index: %v", err)
+	}
+	defer index.Close()
+
+	stats, err := index.Sync(store)
+	if err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if !stats.Rebuilt || stats.Indexed != 5 {
+		t.Fatalf("unexpected first sync stats: %+v", stats)
+	}
+
+	hits, err := index.Search("chec", "[", "]")
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if len(hits) != 1 || hits[0].UUID != "T1" || hits[0].Snippet != "[Check] Item" {
+		t.Fatalf("unexpected checklist hits: %+v", hits)
+	}
+
+	if _, err := conn.Exec(`INSERT INTO TMTask (uuid, type, status, trashed, title, notes, userModificationDate) VALUES ('T3', 0, 0, 0, 'Notes about the project', 'project plan', ?)`, float64(time.Now().Unix())); err != nil {
+		t.Fatalf("insert task: %v", err)
+	}
+	if _, err := conn.Exec(`DELETE FROM TMTask WHERE uuid = 'P2'`); err != nil {
+		t.Fatalf("delete task: %v", err)
+	}
+	stats, err = index.Sync(store)
+	if err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if stats.Rebuilt || stats.Removed != 1 || stats.Indexed != 1 {
+		t.Fatalf("expected incremental sync of T3 and removal of P2, got %+v", stats)
+	}
+
+	hits, err = index.Search("project", "<", ">")
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if len(hits) != 2 || hits[0].UUID != "P1" || hits[1].UUID != "T3" {
+		t.Fatalf("expected title match ranked first, got %+v", hits)
+	}
+	if hits, err = index.Search(`done" OR "one`, "[", "]"); err != nil || len(hits) != 0 {
+		t.Fatalf("expected operators to be quoted, got %+v, %v", hits, err)
+	}
+}
+
+func TestSearchMatchExpr(t *testing.T) {
+	if got, want := searchMatchExpr(` foo  "bar `), `"foo"* """bar"*`; got != want {
+		t.Fatalf("searchMatchExpr mismatch: got %s want %s", got, want)
+	}
+}
@@ -0,0 +1,253 @@
+// Package diff compares two snapshots of the Things library item by item and
+// field by field.
+package diff
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/ossianhempel/things3-cli/internal/backup"
+	"github.com/ossianhempel/things3-cli/internal/db"
+)
+
+// Change kinds.
+const (
+	Added    = "added"
+	Removed  = "removed"
+	Modified = "modified"
+)
+
+// Snapshot is the library at one point in time. Tasks holds projects and
+// todos with their placement titles filled in.
+type Snapshot struct {
+	Areas []db.Area
+	Tasks []db.Task
+}
+
+// Change is one added, removed, or modified area, project, or todo.
+type Change struct {
+	Change string        `json:"change"`
+	Type   string        `json:"type"`
+	UUID   string        `json:"uuid"`
+	Title  string        `json:"title"`
+	Fields []FieldChange `json:"fields,omitempty"`
+}
+
+// FieldChange is the old and new value of one field. Added items have only
+// new values and removed items only old ones.
+type FieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from,omitempty"`
+	To    string `json:"to,omitempty"`
+}
+
+// FromArchive flattens a backup archive into a snapshot.
+func FromArchive(archive *backup.Archive) Snapshot {
+	var snap Snapshot
+	addTodos := func(todos []backup.Todo, area, project, heading string) {
+		for _, todo := range todos {
+			task := db.Task{
+				Type:         "to-do",
+				UUID:         todo.UUID,
+				Title:        todo.Title,
+				Notes:        todo.Notes,
+				Status:       parseStatus(todo.Status),
+				Start:        todo.Start,
+				StartDate:    todo.StartDate,
+				Evening:      todo.Evening,
+				Deadline:     todo.Deadline,
+				StopDate:     todo.StopDate,
+				Tags:         todo.Tags,
+				AreaTitle:    area,
+				ProjectTitle: project,
+				HeadingTitle: heading,
+			}
+			for _, item := range todo.Checklist {
+				task.Checklist = append(task.Checklist, db.ChecklistItem{Title: item.Title, Status: parseStatus(item.Status)})
+			}
+			if todo.Repeat != nil {
+				task.Repeating = true
+				task.RepeatRule = todo.Repeat.Description
+			}
+			snap.Tasks = append(snap.Tasks, task)
+		}
+	}
+	addProject := func(project backup.Project, area string) {
+		task := db.Task{
+			Type:      "project",
+			UUID:      project.UUID,
+			Title:     project.Title,
+			Notes:     project.Notes,
+			Status:    parseStatus(project.Status),
+			Start:     project.Start,
+			StartDate: project.StartDate,
+			Deadline:  project.Deadline,
+			StopDate:  project.StopDate,
+			Tags:      project.Tags,
+			AreaTitle: area,
+		}
+		if project.Repeat != nil {
+			task.Repeating = true
+			task.RepeatRule = project.Repeat.Description
+		}
+		snap.Tasks = append(snap.Tasks, task)
+		addTodos(project.Todos, "", project.Title, "")
+		for _, heading := range project.Headings {
+			addTodos(heading.Todos, "", project.Title, heading.Title)
+		}
+	}
+
+	for _, area := range archive.Areas {
+		snap.Areas = append(snap.Areas, db.Area{UUID: area.UUID, Title: area.Title})
+		for _, project := range area.Projects {
+			addProject(project, area.Title)
+		}
+		addTodos(area.Todos, area.Title, "", "")
+	}
+	for _, project := range archive.Projects {
+		addProject(project, "")
+	}
+	addTodos(archive.Todos, "", "", "")
+	return snap
+}
+
+func parseStatus(label string) int {
+	switch label {
+	case "completed":
+		return db.StatusCompleted
+	case "canceled":
+		return db.StatusCanceled
+	default:
+		return db.StatusIncomplete
+	}
+}
+
+type field struct {
+	name  string
+	value string
+}
+
+func areaFields(area db.Area) []field {
+	return []field{{"title", area.Title}}
+}
+
+func taskFields(task db.Task) []field {
+	tags := append([]string(nil), task.Tags...)
+	sort.Slice(tags, func(i, j int) bool { return strings.ToLower(tags[i]) < strings.ToLower(tags[j]) })
+	checklist := make([]string, 0, len(task.Checklist))
+	for _, item := range task.Checklist {
+		box := "[ ] "
+		switch item.Status {
+		case db.StatusCompleted:
+			box = "[x] "
+		case db.StatusCanceled:
+			box = "[-] "
+		}
+		checklist = append(checklist, box+item.Title)
+	}
+	start := task.Start
+	if task.Evening {
+		start += " (evening)"
+	}
+	return []field{
+		{"title", task.Title},
+		{"notes", task.Notes},
+		{"status", db.StatusLabel(task.Status)},
+		{"start", start},
+		{"start_date", task.StartDate},
+		{"deadline", task.Deadline},
+		{"stop_date", task.StopDate},
+		{"tags", strings.Join(tags, ", ")},
+		{"area", task.AreaTitle},
+		{"project", task.ProjectTitle},
+		{"heading", task.HeadingTitle},
+		{"checklist", strings.Join(checklist, "\n")},
+		{"repeat", task.RepeatRule},
+	}
+}
+
+type entry struct {
+	typ    string
+	uuid   string
+	title  string
+	fields []field
+}
+
+func entries(snap Snapshot) []entry {
+	list := make([]entry, 0, len(snap.Areas)+len(snap.Tasks))
+	for _, area := range snap.Areas {
+		list = append(list, entry{typ: "area", uuid: area.UUID, title: area.Title, fields: areaFields(area)})
+	}
+	for _, task := range snap.Tasks {
+		if task.Type != "project" && task.Type != "to-do" {
+			continue
+		}
+		list = append(list, entry{typ: task.Type, uuid: task.UUID, title: task.Title, fields: taskFields(task)})
+	}
+	return list
+}
+
+var typeOrder = map[string]int{"area": 0, "project": 1, "to-do": 2}
+
+// Compare matches items by UUID and returns the changes from one snapshot
+// to the other: areas first, then projects, then todos, each in the order
+// of the snapshot they appear in (removed and modified items in from's
+// order, then added items in to's order).
+func Compare(from, to Snapshot) []Change {
+	before := entries(from)
+	after := entries(to)
+	afterByID := make(map[string]entry, len(after))
+	for _, e := range after {
+		afterByID[e.uuid] = e
+	}
+	beforeIDs := make(map[string]bool, len(before))
+
+	var changes []Change
+	for _, old := range before {
+		beforeIDs[old.uuid] = true
+		current, ok := afterByID[old.uuid]
+		if !ok {
+			changes = append(changes, Change{Change: Removed, Type: old.typ, UUID: old.uuid, Title: old.title, Fields: fieldValues(old.fields, false)})
+			continue
+		}
+		var fields []FieldChange
+		values := make(map[string]string, len(current.fields))
+		for _, f := range current.fields {
+			values[f.name] = f.value
+		}
+		for _, f := range old.fields {
+			if values[f.name] != f.value {
+				fields = append(fields, FieldChange{Field: f.name, From: f.value, To: values[f.name]})
+			}
+		}
+		if len(fields) > 0 {
+			changes = append(changes, Change{Change: Modified, Type: current.typ, UUID: current.uuid, Title: current.title, Fields: fields})
+		}
+	}
+	for _, current := range after {
+		if !beforeIDs[current.uuid] {
+			changes = append(changes, Change{Change: Added, Type: current.typ, UUID: current.uuid, Title: current.title, Fields: fieldValues(current.fields, true)})
+		}
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		return typeOrder[changes[i].Type] < typeOrder[changes[j].Type]
+	})
+	return changes
+}
+
+// fieldValues lists the non-empty fields of an added (new values) or
+// removed (old values) item.
+func fieldValues(fields []field, added bool) []FieldChange {
+	var values []FieldChange
+	for _, f := range fields {
+		if f.value == "" {
+			continue
+		}
+		if added {
+			values = append(values, FieldChange{Field: f.name, To: f.value})
+		} else {
+			values = append(values, FieldChange{Field: f.name, From: f.value})
+		}
+	}
+	return values
+}
@@ -0,0 +1,72 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ossianhempel/things3-cli/internal/backup"
+	"github.com/ossianhempel/things3-cli/internal/db"
+)
+
+func TestCompareReportsChangesFieldByField(t *testing.T) {
+	from := Snapshot{
+		Areas: []db.Area{{UUID: "A1", Title: "Home"}},
+		Tasks: []db.Task{
+			{Type: "to-do", UUID: "T1", Title: "Call plumber", Status: db.StatusIncomplete, Tags: []string{"b", "a"}, AreaTitle: "Home"},
+			{Type: "to-do", UUID: "T2", Title: "Old", Status: db.StatusIncomplete},
+			{Type: "heading", UUID: "H1", Title: "Ignored"},
+		},
+	}
+	to := Snapshot{
+		Areas: []db.Area{{UUID: "A1", Title: "Home"}, {UUID: "A2", Title: "Work"}},
+		Tasks: []db.Task{
+			{Type: "project", UUID: "P1", Title: "Launch", Status: db.StatusIncomplete},
+			{Type: "to-do", UUID: "T1", Title: "Call plumber", Status: db.StatusCompleted, StopDate: "2026-10-18 09:00:00", Tags: []string{"a", "b"}, ProjectTitle: "Launch"},
+		},
+	}
+
+	var got []string
+	for _, change := range Compare(from, to) {
+		line := change.Change + " " + change.Type + " " + change.UUID
+		for _, field := range change.Fields {
+			line += " " + field.Field + "=" + field.From + ">" + field.To
+		}
+		got = append(got, line)
+	}
+	want := []string{
+		"added area A2 title=>Work",
+		"added project P1 title=>Launch status=>incomplete",
+		"modified to-do T1 status=incomplete>completed stop_date=>2026-10-18 09:00:00 area=Home> project=>Launch",
+		"removed to-do T2 title=Old> status=incomplete>",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected changes:\n%s", strings.Join(got, "\n"))
+	}
+	if changes := Compare(to, to); len(changes) != 0 {
+		t.Fatalf("expected no changes, got %v", changes)
+	}
+}
+
+func TestFromArchiveKeepsPlacement(t *testing.T) {
+	archive := &backup.Archive{
+		Version: backup.Version,
+		Areas: []backup.Area{{
+			UUID:     "A1",
+			Title:    "Home",
+			Projects: []backup.Project{{UUID: "P1", Title: "Garden", Status: "incomplete", Headings: []backup.Heading{{UUID: "H1", Title: "Spring", Todos: []backup.Todo{{UUID: "T1", Title: "Rake", Status: "completed"}}}}}},
+		}},
+		Todos: []backup.Todo{{UUID: "T2", Title: "Weekly", Status: "incomplete", Repeat: &backup.Repeat{Description: "every week"}, Checklist: []backup.ChecklistItem{{Title: "Step", Status: "canceled"}}}},
+	}
+	snap := FromArchive(archive)
+	if len(snap.Areas) != 1 || len(snap.Tasks) != 3 {
+		t.Fatalf("unexpected snapshot: %+v", snap)
+	}
+	rake := snap.Tasks[1]
+	if rake.UUID != "T1" || rake.ProjectTitle != "Garden" || rake.HeadingTitle != "Spring" || rake.Status != db.StatusCompleted {
+		t.Fatalf("unexpected todo: %+v", rake)
+	}
+	weekly := snap.Tasks[2]
+	if weekly.RepeatRule != "every week" || len(weekly.Checklist) != 1 || weekly.Checklist[0].Status != db.StatusCanceled {
+		t.Fatalf("unexpected repeating todo: %+v", weekly)
+	}
+}
@@ -0,0 +1,148 @@
+// Package importer converts task exports from other apps into outline
+// entries, repeating rules, and a report of what could not be mapped.
+package importer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ossianhempel/things3-cli/internal/outline"
+	"github.com/ossianhempel/things3-cli/internal/repeat"
+	"github.com/ossianhempel/things3-cli/internal/things"
+)
+
+// Import accumulates the entries parsed from one or more export files.
+type Import struct {
+	Entries  []*outline.Entry
+	Repeats  []Repeat
+	Unmapped []Issue
+}
+
+// Repeat is a repeating rule to apply to an item once Things has created it.
+type Repeat struct {
+	Title   string
+	Project bool
+	Spec    repeat.Spec
+}
+
+// Issue describes a value that was dropped or approximated.
+type Issue struct {
+	Item   string `json:"item"`
+	Field  string `json:"field"`
+	Value  string `json:"value"`
+	Reason string `json:"reason"`
+}
+
+// Items converts the accumulated entries into Things JSON items; see
+// outline.Build for how sections map to areas, projects, and headings.
+func (imp *Import) Items(isArea func(string) bool) []things.JSONItem {
+	return outline.Build(imp.Entries, isArea)
+}
+
+func (imp *Import) report(item, field, value, reason string) {
+	imp.Unmapped = append(imp.Unmapped, Issue{Item: item, Field: field, Value: value, Reason: reason})
+}
+
+// readCSV reads a CSV file with a header row and returns its rows keyed by
+// upper-cased column name.
+func readCSV(data []byte) ([]map[string]string, error) {
+	reader := csv.NewReader(strings.NewReader(strings.TrimPrefix(string(data), "\ufeff")))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("Error: read CSV header: %v", err)
+	}
+	for i := range header {
+		header[i] = strings.ToUpper(strings.TrimSpace(header[i]))
+	}
+	var rows []map[string]string
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Error: read CSV: %v", err)
+		}
+		row := make(map[string]string, len(header))
+		for i, value := range record {
+			if i < len(header) {
+				row[header[i]] = strings.TrimSpace(value)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+var isoDate = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})(?:[ T](.*))?$`)
+
+// parseDate resolves an export date to YYYY-MM-DD. It accepts ISO dates with
+// an optional time, "today", "tomorrow", and month-name dates such as
+// "Oct 20" or "20 Oct 2026". The second result holds anything dropped, such
+// as a time of day.
+func parseDate(value string, now time.Time) (string, string, bool) {
+	value = strings.TrimSpace(value)
+	if match := isoDate.FindStringSubmatch(value); match != nil {
+		if _, err := time.Parse("2006-01-02", match[1]); err != nil {
+			return "", "", false
+		}
+		rest := strings.TrimSpace(match[2])
+		if rest == "00:00" || rest == "00:00:00" || strings.HasPrefix(rest, "00:00:00 ") {
+			rest = ""
+		}
+		return match[1], rest, true
+	}
+
+	words := strings.Fields(strings.ToLower(strings.ReplaceAll(value, ",", " ")))
+	if len(words) == 0 {
+		return "", "", false
+	}
+	switch words[0] {
+	case "today", "tod":
+		return now.Format("2006-01-02"), strings.Join(words[1:], " "), true
+	case "tomorrow", "tom":
+		return now.AddDate(0, 0, 1).Format("2006-01-02"), strings.Join(words[1:], " "), true
+	}
+	for _, layout := range []string{"Jan 2 2006", "January 2 2006", "2 Jan 2006", "2 January 2006"} {
+		n := len(strings.Fields(layout))
+		if len(words) < n {
+			continue
+		}
+		if parsed, err := time.Parse(layout, strings.Join(words[:n], " ")); err == nil {
+			return parsed.Format("2006-01-02"), strings.Join(words[n:], " "), true
+		}
+	}
+	for _, layout := range []string{"Jan 2", "January 2", "2 Jan", "2 January"} {
+		if len(words) < 2 {
+			continue
+		}
+		parsed, err := time.Parse(layout, strings.Join(words[:2], " "))
+		if err != nil {
+			continue
+		}
+		// Dates without a year are the next occurrence.
+		date := time.Date(now.Year(), parsed.Month(), parsed.Day(), 0, 0, 0, 0, now.Location())
+		if date.Before(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())) {
+			date = date.AddDate(1, 0, 0)
+		}
+		return date.Format("2006-01-02"), strings.Join(words[2:], " "), true
+	}
+	return "", "", false
+}
+
+// splitList splits a comma-separated list, dropping empty values.
+func splitList(value string) []string {
+	var values []string
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			values = append(values, part)
+		}
+	}
+	return values
+}
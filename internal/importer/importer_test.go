@@ -0,0 +1,178 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ossianhempel/things3-cli/internal/repeat"
+	"github.com/ossianhempel/things3-cli/internal/things"
+)
+
+var testNow = time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+
+const todoistCSV = "TYPE,CONTENT,DESCRIPTION,PRIORITY,INDENT,AUTHOR,RESPONSIBLE,DATE,DATE_LANG,TIMEZONE,DURATION,DURATION_UNIT,DEADLINE,DEADLINE_LANG\n" +
+	"task,Pay rent @bills,,1,1,Me,,every month on the 1st,en,UTC,,,,\n" +
+	"note,Use the landlord portal,,,,Me,,,,,,,,\n" +
+	"task,Call bank,Ask about fees,4,1,Me,Sam,Oct 20 10am,en,UTC,30,minute,,\n" +
+	"task,Find statement,,4,2,Me,,,en,UTC,,,,\n" +
+	"task,Check balance,,4,2,Me,,every week,en,UTC,,,,\n" +
+	"section,Later,,,,,,,,,,,,\n" +
+	"task,Water plants,,4,1,Me,,every! 3 days,en,UTC,,,,\n" +
+	"task,Yearly review,,4,1,Me,,every jan 1,en,UTC,,,,\n"
+
+func TestAddTodoistMapsProjectSectionsAndRecurrence(t *testing.T) {
+	imp := &Import{}
+	if err := imp.AddTodoist([]byte(todoistCSV), "Finances", testNow); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	items := imp.Items(nil)
+	if len(items) != 1 || items[0].Type != "project" || items[0].Attributes.Title != "Finances" {
+		t.Fatalf("unexpected items: %+v", items)
+	}
+	project := items[0].Attributes
+	var titles []string
+	for _, item := range project.Items {
+		titles = append(titles, item.Type+":"+item.Attributes.Title)
+	}
+	if got := strings.Join(titles, " "); got != "to-do:Pay rent to-do:Call bank heading:Later to-do:Water plants to-do:Yearly review" {
+		t.Fatalf("unexpected project items: %s", got)
+	}
+	rent := project.Items[0].Attributes
+	if rent.Notes != "Use the landlord portal" || strings.Join(rent.Tags, ",") != "bills,p1" {
+		t.Fatalf("unexpected rent todo: %+v", rent)
+	}
+	bank := project.Items[1].Attributes
+	if bank.Deadline != "2026-10-20" || len(bank.ChecklistItems) != 2 {
+		t.Fatalf("unexpected bank todo: %+v", bank)
+	}
+	if err := things.ValidateJSONItems(items); err != nil {
+		t.Fatalf("built items are invalid: %v", err)
+	}
+
+	if len(imp.Repeats) != 2 {
+		t.Fatalf("expected 2 repeats, got %+v", imp.Repeats)
+	}
+	rentRule := imp.Repeats[0].Spec
+	if rentRule.Unit != repeat.UnitMonth || rentRule.Mode != repeat.ModeSchedule || len(rentRule.Offsets) != 1 || rentRule.Offsets[0].Day != 1 {
+		t.Fatalf("unexpected rent rule: %+v", rentRule)
+	}
+	plants := imp.Repeats[1].Spec
+	if plants.Unit != repeat.UnitDay || plants.Every != 3 || plants.Mode != repeat.ModeAfterCompletion {
+		t.Fatalf("unexpected plants rule: %+v", plants)
+	}
+
+	var fields []string
+	for _, issue := range imp.Unmapped {
+		fields = append(fields, issue.Item+"/"+issue.Field)
+	}
+	if got := strings.Join(fields, " "); got != "Call bank/DATE Call bank/RESPONSIBLE Call bank/DURATION Check balance/DATE Yearly review/DATE" {
+		t.Fatalf("unexpected report: %s", got)
+	}
+}
+
+func TestParseTodoistRecurrence(t *testing.T) {
+	cases := []struct {
+		value   string
+		unit    repeat.Unit
+		every   int
+		offsets int
+		dropped string
+	}{
+		{"every day", repeat.UnitDay, 1, 0, ""},
+		{"every other week", repeat.UnitWeek, 2, 0, ""},
+		{"every mon, fri at 9am", repeat.UnitWeek, 1, 2, "at 9am"},
+		{"every weekday", repeat.UnitWeek, 1, 5, ""},
+		{"every 2 weeks on tue", repeat.UnitWeek, 2, 1, ""},
+		{"every 15th", repeat.UnitMonth, 1, 1, ""},
+		{"every last fri", repeat.UnitMonth, 1, 1, ""},
+		{"every 3 months until Dec 31 2027", repeat.UnitMonth, 3, 0, ""},
+	}
+	for _, tc := range cases {
+		spec, dropped, err := parseTodoistRecurrence(tc.value, testNow)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.value, err)
+		}
+		if spec.Unit != tc.unit || spec.Every != tc.every || len(spec.Offsets) != tc.offsets || dropped != tc.dropped {
+			t.Fatalf("%s: unexpected spec %+v (dropped %q)", tc.value, spec, dropped)
+		}
+	}
+	if _, _, err := parseTodoistRecurrence("every full moon", testNow); err == nil {
+		t.Fatalf("expected error for unsupported recurrence")
+	}
+}
+
+const omniFocusTaskPaper = `Work:
+	- Launch @parallel(false) @autodone(false) @due(2026-11-01 17:00)
+		- Write release notes @context(Office : Desk) @flagged
+		- Ship build @estimated-minutes(30)
+- Water plants @tags(Home, Errands) @repeat-method(start-after-completion) @repeat-rule(FREQ=DAILY;INTERVAL=3)
+`
+
+func TestAddOmniFocusTaskPaper(t *testing.T) {
+	imp := &Import{}
+	if err := imp.AddOmniFocus([]byte(omniFocusTaskPaper), testNow); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	items := imp.Items(nil)
+	if len(items) != 2 || items[0].Type != "project" || items[0].Attributes.Area != "Work" || items[1].Type != "to-do" {
+		t.Fatalf("unexpected items: %+v", items)
+	}
+	launch := items[0].Attributes
+	if launch.Deadline != "2026-11-01" || len(launch.Tags) != 0 || len(launch.Items) != 2 {
+		t.Fatalf("unexpected project: %+v", launch)
+	}
+	if tags := strings.Join(launch.Items[0].Attributes.Tags, ","); tags != "Desk,flagged" {
+		t.Fatalf("unexpected action tags: %s", tags)
+	}
+	if tags := strings.Join(items[1].Attributes.Tags, ","); tags != "Home,Errands" {
+		t.Fatalf("unexpected todo tags: %s", tags)
+	}
+	if len(imp.Repeats) != 1 || imp.Repeats[0].Spec.Every != 3 || imp.Repeats[0].Spec.Mode != repeat.ModeAfterCompletion {
+		t.Fatalf("unexpected repeats: %+v", imp.Repeats)
+	}
+
+	var fields []string
+	for _, issue := range imp.Unmapped {
+		fields = append(fields, issue.Item+"/"+issue.Field)
+	}
+	if got := strings.Join(fields, " "); got != "Launch/parallel Launch/due Ship build/estimated-minutes" {
+		t.Fatalf("unexpected report: %s", got)
+	}
+}
+
+const omniFocusCSV = "Task ID,Type,Name,Status,Project,Context,Start Date,Due Date,Completion Date,Duration,Flagged,Notes,Tags\n" +
+	"1,Folder,Home,active,,,,,,,0,,\n" +
+	"1.1,Project,Garden,on hold,,,,,,,0,Spring work,\n" +
+	"1.1.1,Action,Buy seeds,active,Garden,Errands,2026-10-20,2026-10-25 12:00:00 +0000,,15m,1,,Shopping\n" +
+	"1.1.2,Action,Rake leaves,completed,Garden,,,,2026-10-01,,0,,\n" +
+	"2,Action,Inbox item,active,,,,,,,0,,\n"
+
+func TestAddOmniFocusCSV(t *testing.T) {
+	imp := &Import{}
+	if err := imp.AddOmniFocus([]byte(omniFocusCSV), testNow); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	items := imp.Items(nil)
+	if len(items) != 2 || items[0].Type != "project" || items[0].Attributes.Area != "Home" || items[1].Attributes.Title != "Inbox item" {
+		t.Fatalf("unexpected items: %+v", items)
+	}
+	garden := items[0].Attributes
+	if garden.When != "someday" || garden.Notes != "Spring work" || len(garden.Items) != 2 {
+		t.Fatalf("unexpected project: %+v", garden)
+	}
+	seeds := garden.Items[0].Attributes
+	if seeds.When != "2026-10-20" || seeds.Deadline != "2026-10-25" || strings.Join(seeds.Tags, ",") != "Errands,Shopping,flagged" {
+		t.Fatalf("unexpected action: %+v", seeds)
+	}
+	if !garden.Items[1].Attributes.Completed {
+		t.Fatalf("expected completed action")
+	}
+	var fields []string
+	for _, issue := range imp.Unmapped {
+		fields = append(fields, issue.Item+"/"+issue.Field)
+	}
+	if got := strings.Join(fields, " "); got != "Buy seeds/Due Date Buy seeds/Duration" {
+		t.Fatalf("unexpected report: %s", got)
+	}
+}
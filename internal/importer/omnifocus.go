@@ -0,0 +1,222 @@
+package importer
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ossianhempel/things3-cli/internal/outline"
+	"github.com/ossianhempel/things3-cli/internal/repeat"
+)
+
+// AddOmniFocus adds an OmniFocus export, either the CSV written by File >
+// Export or TaskPaper text copied from OmniFocus.
+//
+// Folders become areas, projects become projects, contexts and tags become
+// tags, @flagged becomes the "flagged" tag, and repeat rules become repeating
+// rules. Action groups become todos with checklist items.
+func (imp *Import) AddOmniFocus(data []byte, now time.Time) error {
+	if bytes.HasPrefix(bytes.TrimPrefix(data, []byte("\ufeff")), []byte("Task ID")) {
+		return imp.addOmniFocusCSV(data, now)
+	}
+	entries, err := outline.ParseTaskPaper(data)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		imp.normalizeOmniFocus(entry, true, now)
+	}
+	imp.Entries = append(imp.Entries, entries...)
+	return nil
+}
+
+// normalizeOmniFocus maps OmniFocus TaskPaper tags onto entry and its
+// children. It reports whether entry is a project.
+func (imp *Import) normalizeOmniFocus(entry *outline.Entry, top bool, now time.Time) bool {
+	names := entry.Tags
+	entry.Tags = nil
+	project := false
+	for _, name := range names {
+		key := strings.ToLower(name)
+		value := entry.Values[key]
+		switch key {
+		case "tags":
+			for _, tag := range splitList(value) {
+				entry.Tags = appendTag(entry.Tags, omniFocusTag(tag))
+			}
+		case "context":
+			if value != "" {
+				entry.Tags = appendTag(entry.Tags, omniFocusTag(value))
+			}
+		case "flagged":
+			entry.Tags = appendTag(entry.Tags, "flagged")
+		case "dropped":
+			entry.Canceled = true
+		case "parallel":
+			project = true
+			if value == "false" {
+				imp.report(entry.Title, "parallel", value, "sequential projects are not supported; imported as parallel")
+			}
+		case "autodone":
+			project = true
+		case "repeat-rule", "repeat-method":
+		case "estimated-minutes":
+			imp.report(entry.Title, key, value, "estimates are not supported")
+		default:
+			if value != "" {
+				imp.report(entry.Title, key, value, "tag value dropped")
+			}
+			entry.Tags = appendTag(entry.Tags, name)
+		}
+	}
+
+	if !entry.Section && (project || (top && len(entry.Children) > 0)) {
+		entry.Section = true
+		project = true
+	}
+	if value := entry.Defer; value != "" {
+		entry.Defer = ""
+		imp.setDate(entry.Title, "defer", value, &entry.Defer, now)
+	}
+	if value := entry.Due; value != "" {
+		entry.Due = ""
+		imp.setDate(entry.Title, "due", value, &entry.Due, now)
+	}
+	if rule := entry.Values["repeat-rule"]; rule != "" {
+		imp.omniFocusRepeat(entry, rule, entry.Values["repeat-method"], now)
+	}
+
+	for _, child := range entry.Children {
+		if imp.normalizeOmniFocus(child, false, now) && top && entry.Section {
+			// A folder holding projects.
+			entry.Area = true
+		}
+	}
+	return project
+}
+
+func (imp *Import) omniFocusRepeat(entry *outline.Entry, rule, method string, now time.Time) {
+	anchor := now
+	for _, date := range []string{entry.Defer, entry.Due} {
+		if parsed, err := time.ParseInLocation("2006-01-02", date, now.Location()); err == nil {
+			anchor = parsed
+			break
+		}
+	}
+	spec, err := repeat.ParseRRule(rule, anchor)
+	if err != nil {
+		imp.report(entry.Title, "repeat-rule", rule, err.Error())
+		return
+	}
+	switch method {
+	case "", "fixed":
+	case "start-after-completion", "due-after-completion", "defer-after-completion":
+		spec.Mode = repeat.ModeAfterCompletion
+	default:
+		imp.report(entry.Title, "repeat-method", method, "unknown repeat method; repeating on a fixed schedule")
+	}
+	imp.Repeats = append(imp.Repeats, Repeat{Title: entry.Title, Project: entry.Section, Spec: spec})
+}
+
+// addOmniFocusCSV builds the outline from the dotted Task IDs of an
+// OmniFocus CSV export ("1", "1.1", "1.1.1", ...).
+func (imp *Import) addOmniFocusCSV(data []byte, now time.Time) error {
+	rows, err := readCSV(data)
+	if err != nil {
+		return err
+	}
+	byID := map[string]*outline.Entry{}
+	folders := map[string]bool{}
+	var roots []*outline.Entry
+	for _, row := range rows {
+		id := row["TASK ID"]
+		entry := &outline.Entry{Title: row["NAME"], Notes: row["NOTES"]}
+		parentID := ""
+		if i := strings.LastIndex(id, "."); i >= 0 {
+			parentID = id[:i]
+		}
+		parent := byID[parentID]
+
+		switch strings.ToLower(row["TYPE"]) {
+		case "folder":
+			if parent != nil && folders[parentID] {
+				// Things areas cannot nest; keep the projects in the outer folder.
+				imp.report(entry.Title, "Type", row["TYPE"], "nested folder merged into "+parent.Title)
+				byID[id] = parent
+				folders[id] = true
+				continue
+			}
+			entry.Section = true
+			entry.Area = true
+			folders[id] = true
+		case "project":
+			entry.Section = true
+		case "action", "":
+		default:
+			imp.report(entry.Title, "Type", row["TYPE"], "unknown row type")
+			continue
+		}
+
+		switch strings.ToLower(row["STATUS"]) {
+		case "completed", "done":
+			entry.Done = true
+		case "dropped":
+			entry.Canceled = true
+		case "on hold":
+			entry.Defer = "someday"
+		}
+		if row["COMPLETION DATE"] != "" {
+			entry.Done = true
+		}
+		if value := row["START DATE"]; value != "" && entry.Defer == "" {
+			imp.setDate(entry.Title, "Start Date", value, &entry.Defer, now)
+		}
+		if value := row["DUE DATE"]; value != "" {
+			imp.setDate(entry.Title, "Due Date", value, &entry.Due, now)
+		}
+		if value := row["CONTEXT"]; value != "" {
+			entry.Tags = appendTag(entry.Tags, omniFocusTag(value))
+		}
+		for _, tag := range splitList(row["TAGS"]) {
+			entry.Tags = appendTag(entry.Tags, omniFocusTag(tag))
+		}
+		if row["FLAGGED"] == "1" || strings.EqualFold(row["FLAGGED"], "true") {
+			entry.Tags = appendTag(entry.Tags, "flagged")
+		}
+		if row["DURATION"] != "" {
+			imp.report(entry.Title, "Duration", row["DURATION"], "estimates are not supported")
+		}
+
+		byID[id] = entry
+		if parent == nil {
+			if parentID != "" {
+				imp.report(entry.Title, "Task ID", id, fmt.Sprintf("parent %s not found; imported at the top level", parentID))
+			}
+			roots = append(roots, entry)
+			continue
+		}
+		parent.Children = append(parent.Children, entry)
+	}
+	imp.Entries = append(imp.Entries, roots...)
+	return nil
+}
+
+// omniFocusTag returns the last component of a nested OmniFocus tag such as
+// "Home : Errands".
+func omniFocusTag(value string) string {
+	parts := strings.Split(value, ":")
+	return strings.TrimSpace(parts[len(parts)-1])
+}
+
+func appendTag(tags []string, tag string) []string {
+	if tag == "" {
+		return tags
+	}
+	for _, existing := range tags {
+		if strings.EqualFold(existing, tag) {
+			return tags
+		}
+	}
+	return append(tags, tag)
+}
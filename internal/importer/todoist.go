@@ -0,0 +1,325 @@
+package importer
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ossianhempel/things3-cli/internal/outline"
+	"github.com/ossianhempel/things3-cli/internal/repeat"
+)
+
+var todoistLabel = regexp.MustCompile(`(?:^|\s)@([^\s@]+)`)
+
+// AddTodoist adds one Todoist project CSV export as a project named project.
+//
+// Sections become headings, labels and priorities p1-p3 become tags, DATE
+// becomes the deadline (or when, if the row also has a DEADLINE), recurring
+// dates become repeating rules, subtasks become checklist items, and notes
+// are appended to the task above them.
+func (imp *Import) AddTodoist(data []byte, project string, now time.Time) error {
+	rows, err := readCSV(data)
+	if err != nil {
+		return err
+	}
+	if len(rows) > 0 {
+		if _, ok := rows[0]["TYPE"]; !ok {
+			return fmt.Errorf("Error: not a Todoist CSV export (missing TYPE column)")
+		}
+	}
+
+	root := &outline.Entry{Title: project, Section: true}
+	parent := root
+	// tasks[i] is the most recent task at indent i+1.
+	var tasks []*outline.Entry
+	for i, row := range rows {
+		line := i + 2
+		switch strings.ToLower(row["TYPE"]) {
+		case "", "meta":
+			continue
+		case "section":
+			parent = &outline.Entry{Title: row["CONTENT"], Section: true}
+			root.Children = append(root.Children, parent)
+			tasks = nil
+		case "note":
+			target := root
+			if len(tasks) > 0 {
+				target = tasks[len(tasks)-1]
+			}
+			appendNotes(target, row["CONTENT"])
+		case "task":
+			indent, err := strconv.Atoi(row["INDENT"])
+			if err != nil || indent < 1 {
+				indent = 1
+			}
+			if indent > len(tasks)+1 {
+				indent = len(tasks) + 1
+			}
+			entry := imp.todoistTask(row, indent > 1, now)
+			tasks = append(tasks[:indent-1], entry)
+			if indent == 1 {
+				parent.Children = append(parent.Children, entry)
+				continue
+			}
+			owner := tasks[indent-2]
+			owner.Children = append(owner.Children, entry)
+			if entry.Notes != "" || entry.Due != "" || len(entry.Tags) > 0 {
+				imp.report(entry.Title, "subtask", "", "imported as a checklist item of "+strconv.Quote(owner.Title)+"; notes, dates, and labels dropped")
+			}
+		default:
+			imp.report(row["CONTENT"], "TYPE", row["TYPE"], fmt.Sprintf("line %d: unknown row type", line))
+		}
+	}
+	imp.Entries = append(imp.Entries, root)
+	return nil
+}
+
+// todoistTask converts a task row. Subtasks become checklist items, so
+// their recurrence is reported instead of queued.
+func (imp *Import) todoistTask(row map[string]string, subtask bool, now time.Time) *outline.Entry {
+	content := row["CONTENT"]
+	entry := &outline.Entry{
+		Title: strings.TrimSpace(todoistLabel.ReplaceAllString(content, "")),
+		Notes: row["DESCRIPTION"],
+	}
+	for _, match := range todoistLabel.FindAllStringSubmatch(content, -1) {
+		entry.Tags = append(entry.Tags, match[1])
+	}
+	switch row["PRIORITY"] {
+	case "1", "2", "3":
+		entry.Tags = append(entry.Tags, "p"+row["PRIORITY"])
+	}
+
+	date := row["DATE"]
+	deadline := row["DEADLINE"]
+	if isTodoistRecurring(date) {
+		spec, dropped, err := parseTodoistRecurrence(date, now)
+		if subtask {
+			imp.report(entry.Title, "DATE", date, "subtasks become checklist items, which cannot repeat")
+		} else if err != nil {
+			imp.report(entry.Title, "DATE", date, err.Error())
+		} else {
+			imp.Repeats = append(imp.Repeats, Repeat{Title: entry.Title, Spec: spec})
+			if dropped != "" {
+				imp.report(entry.Title, "DATE", date, "dropped "+strconv.Quote(dropped))
+			}
+		}
+		date = ""
+	}
+	if date != "" {
+		target := &entry.Due
+		if deadline != "" {
+			target = &entry.Defer
+		}
+		imp.setDate(entry.Title, "DATE", date, target, now)
+	}
+	if deadline != "" {
+		imp.setDate(entry.Title, "DEADLINE", deadline, &entry.Due, now)
+	}
+
+	if row["RESPONSIBLE"] != "" {
+		imp.report(entry.Title, "RESPONSIBLE", row["RESPONSIBLE"], "assignees are not supported")
+	}
+	if row["DURATION"] != "" {
+		imp.report(entry.Title, "DURATION", strings.TrimSpace(row["DURATION"]+" "+row["DURATION_UNIT"]), "durations are not supported")
+	}
+	return entry
+}
+
+func (imp *Import) setDate(item, field, value string, target *string, now time.Time) {
+	date, dropped, ok := parseDate(value, now)
+	if !ok {
+		imp.report(item, field, value, "unrecognized date")
+		return
+	}
+	*target = date
+	if dropped != "" {
+		imp.report(item, field, value, "dropped "+strconv.Quote(dropped))
+	}
+}
+
+func appendNotes(entry *outline.Entry, text string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+	if entry.Notes != "" {
+		entry.Notes += "\n\n"
+	}
+	entry.Notes += text
+}
+
+func isTodoistRecurring(date string) bool {
+	lower := strings.ToLower(strings.TrimSpace(date))
+	return strings.HasPrefix(lower, "every") || strings.HasPrefix(lower, "ev ") || strings.HasPrefix(lower, "ev!")
+}
+
+var todoistWeekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tues": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thurs": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+var todoistOrdinals = map[string]int{
+	"1st": 1, "first": 1, "2nd": 2, "second": 2, "3rd": 3, "third": 3,
+	"4th": 4, "fourth": 4, "5th": 5, "fifth": 5, "last": -1,
+}
+
+var todoistTime = regexp.MustCompile(`^(at|@)$|^\d{1,2}(:\d{2})?(am|pm)$|^\d{1,2}:\d{2}$|^(morning|afternoon|evening|night|noon|midnight)$`)
+
+// parseTodoistRecurrence converts a Todoist recurring date such as
+// "every 2 weeks", "every! 3 days", "every mon, fri", "every 15th", or
+// "every last fri" into a repeat spec. "every!" repeats after completion.
+// Times of day are returned as dropped text.
+func parseTodoistRecurrence(value string, now time.Time) (repeat.Spec, string, error) {
+	lower := strings.ToLower(strings.TrimSpace(value))
+	spec := repeat.Spec{Mode: repeat.ModeSchedule, Every: 1, Anchor: now}
+	for _, prefix := range []string{"every!", "ev!", "every", "ev"} {
+		if strings.HasPrefix(lower, prefix) {
+			if strings.HasSuffix(prefix, "!") {
+				spec.Mode = repeat.ModeAfterCompletion
+			}
+			lower = lower[len(prefix):]
+			break
+		}
+	}
+	if rest, until, ok := cutAny(lower, " until ", " ending ", " ends "); ok {
+		lower = rest
+		date, _, ok := parseDate(until, now)
+		if !ok {
+			return repeat.Spec{}, "", fmt.Errorf("unrecognized end date %q", strings.TrimSpace(until))
+		}
+		end, _ := time.ParseInLocation("2006-01-02", date, now.Location())
+		spec.EndDate = &end
+	}
+	if rest, starting, ok := cutAny(lower, " starting ", " from "); ok {
+		lower = rest
+		date, _, ok := parseDate(starting, now)
+		if !ok {
+			return repeat.Spec{}, "", fmt.Errorf("unrecognized start date %q", strings.TrimSpace(starting))
+		}
+		spec.Anchor, _ = time.ParseInLocation("2006-01-02", date, now.Location())
+	}
+
+	var dropped []string
+	var words []string
+	for _, word := range strings.Fields(strings.NewReplacer(",", " ", " and ", " ").Replace(lower)) {
+		if todoistTime.MatchString(word) {
+			dropped = append(dropped, word)
+			continue
+		}
+		words = append(words, word)
+	}
+	if len(words) == 0 {
+		return repeat.Spec{}, "", fmt.Errorf("recurrence has no interval")
+	}
+
+	if n, err := strconv.Atoi(words[0]); err == nil && n > 0 && len(words) > 1 {
+		spec.Every = n
+		words = words[1:]
+	} else if words[0] == "other" && len(words) > 1 {
+		spec.Every = 2
+		words = words[1:]
+	}
+
+	unitWords := map[string]repeat.Unit{
+		"day": repeat.UnitDay, "days": repeat.UnitDay, "daily": repeat.UnitDay,
+		"week": repeat.UnitWeek, "weeks": repeat.UnitWeek, "weekly": repeat.UnitWeek,
+		"month": repeat.UnitMonth, "months": repeat.UnitMonth, "monthly": repeat.UnitMonth,
+		"year": repeat.UnitYear, "years": repeat.UnitYear, "yearly": repeat.UnitYear,
+	}
+	if unit, ok := unitWords[words[0]]; ok && len(words) == 1 {
+		spec.Unit = unit
+		return spec, strings.Join(dropped, " "), nil
+	}
+
+	switch words[0] {
+	case "weekday", "workday", "weekdays", "workdays":
+		spec.Unit = repeat.UnitWeek
+		for day := time.Monday; day <= time.Friday; day++ {
+			spec.Offsets = append(spec.Offsets, repeat.Offset{Weekday: day, HasWeekday: true})
+		}
+		return spec, strings.Join(dropped, " "), checkRest(words[1:], value)
+	case "weekend", "weekends":
+		spec.Unit = repeat.UnitWeek
+		spec.Offsets = []repeat.Offset{{Weekday: time.Saturday, HasWeekday: true}, {Weekday: time.Sunday, HasWeekday: true}}
+		return spec, strings.Join(dropped, " "), checkRest(words[1:], value)
+	}
+
+	// "every mon fri" or "every 2 weeks on mon"
+	if unit, ok := unitWords[words[0]]; ok && unit == repeat.UnitWeek && len(words) > 2 && words[1] == "on" {
+		words = words[2:]
+	}
+	if _, ok := todoistWeekdays[words[0]]; ok {
+		spec.Unit = repeat.UnitWeek
+		for _, word := range words {
+			day, ok := todoistWeekdays[word]
+			if !ok {
+				return repeat.Spec{}, "", fmt.Errorf("unsupported recurrence %q", value)
+			}
+			spec.Offsets = append(spec.Offsets, repeat.Offset{Weekday: day, HasWeekday: true})
+		}
+		return spec, strings.Join(dropped, " "), nil
+	}
+
+	// "every month on the 15th" or "every 15th", "every last day",
+	// "every 2nd tue".
+	if unit, ok := unitWords[words[0]]; ok && unit == repeat.UnitMonth && len(words) > 1 {
+		words = words[1:]
+		if words[0] == "on" {
+			words = words[1:]
+		}
+	}
+	if len(words) > 0 && words[0] == "the" {
+		words = words[1:]
+	}
+	spec.Unit = repeat.UnitMonth
+	for len(words) > 0 {
+		word := words[0]
+		if ordinal, ok := todoistOrdinals[word]; ok && len(words) > 1 {
+			if day, ok := todoistWeekdays[words[1]]; ok {
+				spec.Offsets = append(spec.Offsets, repeat.Offset{Weekday: day, HasWeekday: true, Ordinal: ordinal})
+				words = words[2:]
+				continue
+			}
+			if word == "last" && words[1] == "day" {
+				spec.Offsets = append(spec.Offsets, repeat.Offset{Day: -1})
+				words = words[2:]
+				continue
+			}
+		}
+		day, err := strconv.Atoi(strings.TrimRight(word, "stndrh"))
+		if err != nil || day < 1 || day > 31 {
+			return repeat.Spec{}, "", fmt.Errorf("unsupported recurrence %q", value)
+		}
+		spec.Offsets = append(spec.Offsets, repeat.Offset{Day: day})
+		words = words[1:]
+	}
+	if len(spec.Offsets) == 0 {
+		return repeat.Spec{}, "", fmt.Errorf("unsupported recurrence %q", value)
+	}
+	return spec, strings.Join(dropped, " "), nil
+}
+
+func checkRest(words []string, value string) error {
+	if len(words) > 0 {
+		return fmt.Errorf("unsupported recurrence %q", value)
+	}
+	return nil
+}
+
+// cutAny cuts value around the first of the separators it contains.
+func cutAny(value string, separators ...string) (string, string, bool) {
+	for _, sep := range separators {
+		if before, after, ok := strings.Cut(value, sep); ok {
+			return before, after, true
+		}
+	}
+	return value, "", false
+}
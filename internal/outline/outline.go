@@ -0,0 +1,359 @@
+// Package outline parses TaskPaper and Markdown outlines and converts them
+// into Things JSON items.
+package outline
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ossianhempel/things3-cli/internal/things"
+)
+
+// Entry is one line of an outline with the notes and entries nested under it.
+type Entry struct {
+	Title    string
+	Notes    string
+	Tags     []string
+	Due      string
+	Defer    string
+	Done     bool
+	Canceled bool
+	// Values holds the values of tags written as @name(value), keyed by the
+	// lowercased tag name.
+	Values map[string]string
+	// Section marks a "Title:" line or a Markdown heading: an area, project,
+	// or heading depending on where it sits. Other entries are items.
+	Section bool
+	// Area forces a top-level section to be treated as an area.
+	Area     bool
+	Children []*Entry
+
+	indent int
+	level  int
+	// hashes is the number of "#" of a Markdown heading, zero otherwise.
+	hashes int
+}
+
+func (e *Entry) addNote(line string) {
+	if e.Notes == "" {
+		e.Notes = line
+		return
+	}
+	e.Notes += "\n" + line
+}
+
+var tagPattern = regexp.MustCompile(`(?:^|\s)@([\p{L}\p{N}_.\-]+)(?:\(([^)]*)\))?`)
+
+// parseEntry extracts @tags from text. @due, @defer (or @start), @done, and
+// @canceled set the matching fields; any other tag keeps its name and drops
+// its value.
+func parseEntry(text string) *Entry {
+	entry := &Entry{}
+	for _, match := range tagPattern.FindAllStringSubmatch(text, -1) {
+		name, value := match[1], strings.TrimSpace(match[2])
+		if value != "" {
+			if entry.Values == nil {
+				entry.Values = map[string]string{}
+			}
+			entry.Values[strings.ToLower(name)] = value
+		}
+		switch strings.ToLower(name) {
+		case "due":
+			entry.Due = value
+		case "defer", "start":
+			entry.Defer = value
+		case "done":
+			entry.Done = true
+		case "canceled", "cancelled":
+			entry.Canceled = true
+		default:
+			entry.Tags = append(entry.Tags, name)
+		}
+	}
+	entry.Title = strings.TrimSpace(tagPattern.ReplaceAllString(text, ""))
+	return entry
+}
+
+// indentWidth returns the width of line's leading whitespace, counting a tab
+// as four spaces.
+func indentWidth(line string) int {
+	width := 0
+	for _, r := range line {
+		switch r {
+		case ' ':
+			width++
+		case '\t':
+			width += 4
+		default:
+			return width
+		}
+	}
+	return width
+}
+
+// ParseTaskPaper parses a TaskPaper outline. Lines starting with "- " are
+// items, lines ending in ":" are sections, and other lines are notes for the
+// entry they are indented under.
+func ParseTaskPaper(data []byte) ([]*Entry, error) {
+	root := &Entry{indent: -1}
+	stack := []*Entry{root}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		text := strings.TrimSpace(line)
+		if text == "" {
+			continue
+		}
+		indent := indentWidth(line)
+		for len(stack) > 1 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+		parent := stack[len(stack)-1]
+
+		var entry *Entry
+		switch {
+		case strings.HasPrefix(text, "- "):
+			entry = parseEntry(text[2:])
+		case isSectionLine(text):
+			entry = parseEntry(text)
+			entry.Title = strings.TrimSpace(strings.TrimSuffix(entry.Title, ":"))
+			entry.Section = true
+		default:
+			if parent == root {
+				return nil, fmt.Errorf("Error: note %q is not indented under an item", text)
+			}
+			parent.addNote(strings.TrimSpace(line))
+			continue
+		}
+		entry.indent = indent
+		parent.Children = append(parent.Children, entry)
+		stack = append(stack, entry)
+	}
+	return root.Children, nil
+}
+
+// isSectionLine reports whether text, once tags are removed, ends in ":".
+func isSectionLine(text string) bool {
+	title := strings.TrimSpace(tagPattern.ReplaceAllString(text, ""))
+	return len(title) > 1 && strings.HasSuffix(title, ":")
+}
+
+var (
+	markdownHeading = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*$`)
+	markdownItem    = regexp.MustCompile(`^(?:[-*+]|\d+[.)])\s+(?:\[([ xX-])\]\s+)?(.*)$`)
+)
+
+// ParseMarkdown parses a Markdown outline. Headings nest by level, list items
+// (with optional task checkboxes) nest by indentation, and "Title:" lines
+// start a section one level below the enclosing heading. Indented text is a
+// note for the list item above it; other text belongs to the section. A "#"
+// heading that holds "##" headings is an area.
+func ParseMarkdown(data []byte) ([]*Entry, error) {
+	root := &Entry{}
+	sections := []*Entry{root}
+	var items []*Entry
+	headingLevel := 0
+	inFence := false
+
+	section := func() *Entry { return sections[len(sections)-1] }
+	pushSection := func(entry *Entry) {
+		for len(sections) > 1 && section().level >= entry.level {
+			sections = sections[:len(sections)-1]
+		}
+		section().Children = append(section().Children, entry)
+		sections = append(sections, entry)
+		items = nil
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		text := strings.TrimSpace(line)
+		if strings.HasPrefix(text, "```") {
+			inFence = !inFence
+			continue
+		}
+		if text == "" || inFence {
+			continue
+		}
+		indent := indentWidth(line)
+
+		if match := markdownHeading.FindStringSubmatch(line); match != nil {
+			entry := parseEntry(match[2])
+			entry.Title = strings.TrimSpace(strings.TrimSuffix(entry.Title, ":"))
+			entry.Section = true
+			entry.level = len(match[1])
+			entry.hashes = entry.level
+			headingLevel = entry.level
+			pushSection(entry)
+			continue
+		}
+		if match := markdownItem.FindStringSubmatch(text); match != nil {
+			entry := parseEntry(match[2])
+			switch match[1] {
+			case "x", "X":
+				entry.Done = true
+			case "-":
+				entry.Canceled = true
+			}
+			entry.indent = indent
+			for len(items) > 0 && items[len(items)-1].indent >= indent {
+				items = items[:len(items)-1]
+			}
+			parent := section()
+			if len(items) > 0 {
+				parent = items[len(items)-1]
+			}
+			parent.Children = append(parent.Children, entry)
+			items = append(items, entry)
+			continue
+		}
+		if indent == 0 && isSectionLine(text) {
+			entry := parseEntry(text)
+			entry.Title = strings.TrimSpace(strings.TrimSuffix(entry.Title, ":"))
+			entry.Section = true
+			entry.level = headingLevel + 1
+			pushSection(entry)
+			continue
+		}
+
+		// Indented text continues the closest list item above it.
+		for i := len(items) - 1; i >= 0; i-- {
+			if items[i].indent < indent {
+				items[i].addNote(text)
+				text = ""
+				break
+			}
+		}
+		if text == "" {
+			continue
+		}
+		if section() == root {
+			return nil, fmt.Errorf("Error: text %q is not under a heading or list item", text)
+		}
+		section().addNote(text)
+		items = nil
+	}
+	// "things export markdown" writes areas as "#" headings and their
+	// projects as "##" headings.
+	for _, entry := range root.Children {
+		if entry.hashes != 1 {
+			continue
+		}
+		for _, child := range entry.Children {
+			if child.hashes == 2 {
+				entry.Area = true
+				break
+			}
+		}
+	}
+	return root.Children, nil
+}
+
+// Build converts parsed entries into Things JSON items.
+//
+// Top-level sections are projects, and sections inside a project are
+// headings. A top-level section is an area instead when isArea reports its
+// title as an existing area, or when it nests sections two levels deep (as
+// written by "things export"); areas themselves are not created. Items
+// nested under a todo become checklist items.
+func Build(entries []*Entry, isArea func(title string) bool) []things.JSONItem {
+	var items []things.JSONItem
+	for _, entry := range entries {
+		switch {
+		case !entry.Section:
+			items = append(items, todoItem(entry, ""))
+		case entry.Area || sectionDepth(entry) >= 3 || (isArea != nil && isArea(entry.Title)):
+			for _, child := range entry.Children {
+				if child.Section {
+					items = append(items, projectItem(child, entry.Title))
+				} else {
+					items = append(items, todoItem(child, entry.Title))
+				}
+			}
+		default:
+			items = append(items, projectItem(entry, ""))
+		}
+	}
+	return items
+}
+
+// sectionDepth counts the levels of sections nested in entry, itself included.
+func sectionDepth(entry *Entry) int {
+	if !entry.Section {
+		return 0
+	}
+	depth := 0
+	for _, child := range entry.Children {
+		depth = max(depth, sectionDepth(child))
+	}
+	return depth + 1
+}
+
+func projectItem(entry *Entry, area string) things.JSONItem {
+	attrs := entryAttributes(entry)
+	attrs.Area = area
+	var headings []*Entry
+	for _, child := range entry.Children {
+		if child.Section {
+			headings = append(headings, child)
+			continue
+		}
+		attrs.Items = append(attrs.Items, todoItem(child, ""))
+	}
+	for len(headings) > 0 {
+		heading := headings[0]
+		headings = headings[1:]
+		attrs.Items = append(attrs.Items, things.JSONItem{
+			Type:       things.JSONTypeHeading,
+			Attributes: things.JSONAttributes{Title: heading.Title},
+		})
+		// Headings cannot nest, so deeper sections follow as siblings.
+		var nested []*Entry
+		for _, child := range heading.Children {
+			if child.Section {
+				nested = append(nested, child)
+				continue
+			}
+			attrs.Items = append(attrs.Items, todoItem(child, ""))
+		}
+		headings = append(nested, headings...)
+	}
+	return things.JSONItem{Type: things.JSONTypeProject, Attributes: attrs}
+}
+
+func todoItem(entry *Entry, list string) things.JSONItem {
+	attrs := entryAttributes(entry)
+	attrs.List = list
+	for _, child := range flattenEntries(entry.Children) {
+		attrs.ChecklistItems = append(attrs.ChecklistItems, things.JSONItem{
+			Type: things.JSONTypeChecklistItem,
+			Attributes: things.JSONAttributes{
+				Title:     child.Title,
+				Completed: child.Done,
+				Canceled:  child.Canceled,
+			},
+		})
+	}
+	return things.JSONItem{Type: things.JSONTypeTodo, Attributes: attrs}
+}
+
+func entryAttributes(entry *Entry) things.JSONAttributes {
+	return things.JSONAttributes{
+		Title:     entry.Title,
+		Notes:     entry.Notes,
+		When:      entry.Defer,
+		Deadline:  entry.Due,
+		Tags:      entry.Tags,
+		Completed: entry.Done,
+		Canceled:  entry.Canceled && !entry.Done,
+	}
+}
+
+func flattenEntries(entries []*Entry) []*Entry {
+	var flat []*Entry
+	for _, entry := range entries {
+		flat = append(flat, entry)
+		flat = append(flat, flattenEntries(entry.Children)...)
+	}
+	return flat
+}
@@ -0,0 +1,140 @@
+package outline
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ossianhempel/things3-cli/internal/things"
+)
+
+const taskPaperDoc = `Home:
+	Renovation: @house @due(2026-11-01)
+		Budget is tight.
+		- Get quotes @errand
+			Call three contractors
+			- Plumber
+			- Electrician @done
+		Paint:
+			- Pick colours @defer(2026-10-20)
+	- Water plants
+Trip:
+	- Book flights @done(2026-09-01)
+	Packing:
+		- Passport
+- Loose todo @due(tomorrow)
+`
+
+func TestParseTaskPaperAndBuild(t *testing.T) {
+	entries, err := ParseTaskPaper([]byte(taskPaperDoc))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	items := Build(entries, func(title string) bool { return title == "Home" })
+	if got := summarize(items); got != "project:Renovation[area=Home] to-do:Water plants[list=Home] project:Trip to-do:Loose todo" {
+		t.Fatalf("unexpected top-level items: %s", got)
+	}
+
+	renovation := items[0].Attributes
+	if renovation.Notes != "Budget is tight." || renovation.Deadline != "2026-11-01" || strings.Join(renovation.Tags, ",") != "house" {
+		t.Fatalf("unexpected project attributes: %+v", renovation)
+	}
+	if got := summarize(renovation.Items); got != "to-do:Get quotes heading:Paint to-do:Pick colours" {
+		t.Fatalf("unexpected project items: %s", got)
+	}
+	quotes := renovation.Items[0].Attributes
+	if quotes.Notes != "Call three contractors" || len(quotes.ChecklistItems) != 2 || !quotes.ChecklistItems[1].Attributes.Completed {
+		t.Fatalf("unexpected todo: %+v", quotes)
+	}
+	if when := renovation.Items[2].Attributes.When; when != "2026-10-20" {
+		t.Fatalf("expected @defer to set when, got %q", when)
+	}
+	if !items[2].Attributes.Items[0].Attributes.Completed {
+		t.Fatalf("expected @done todo to be completed")
+	}
+	if err := things.ValidateJSONItems(items); err != nil {
+		t.Fatalf("built items are invalid: %v", err)
+	}
+}
+
+func TestBuildDetectsAreasByDepth(t *testing.T) {
+	entries, err := ParseTaskPaper([]byte("Work:\n\tLaunch:\n\t\tQA:\n\t\t\t- Smoke test\n"))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	items := Build(entries, nil)
+	if got := summarize(items); got != "project:Launch[area=Work]" {
+		t.Fatalf("unexpected items: %s", got)
+	}
+}
+
+const markdownDoc = "# Home\n\n" +
+	"## Project One @work\n\n" +
+	"Project notes\n\n" +
+	"- [ ] Loose task\n\n" +
+	"### Heading\n\n" +
+	"- [ ] Task One @urgent @due(2026-10-20)\n" +
+	"  Some notes\n" +
+	"  - [x] Check Item\n" +
+	"- [x] Done task\n\n" +
+	"Later:\n" +
+	"- Someday thing\n"
+
+func TestParseMarkdownAreaWithoutHeadings(t *testing.T) {
+	entries, err := ParseMarkdown([]byte("## Loose\n\n- [ ] One\n\n# Work\n\n## Launch\n\n- [ ] Ship\n\n# Trip\n\n### Packing\n\n- [ ] Passport\n"))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	items := Build(entries, nil)
+	if got := summarize(items); got != "project:Loose project:Launch[area=Work] project:Trip" {
+		t.Fatalf("unexpected items: %s", got)
+	}
+}
+
+func TestParseMarkdownAndBuild(t *testing.T) {
+	entries, err := ParseMarkdown([]byte(markdownDoc))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	items := Build(entries, nil)
+	if got := summarize(items); got != "project:Project One[area=Home]" {
+		t.Fatalf("unexpected items: %s", got)
+	}
+	project := items[0].Attributes
+	if project.Notes != "Project notes" || strings.Join(project.Tags, ",") != "work" {
+		t.Fatalf("unexpected project attributes: %+v", project)
+	}
+	if got := summarize(project.Items); got != "to-do:Loose task heading:Heading to-do:Task One to-do:Done task heading:Later to-do:Someday thing" {
+		t.Fatalf("unexpected project items: %s", got)
+	}
+	task := project.Items[2].Attributes
+	if task.Notes != "Some notes" || task.Deadline != "2026-10-20" || len(task.ChecklistItems) != 1 || !task.ChecklistItems[0].Attributes.Completed {
+		t.Fatalf("unexpected task: %+v", task)
+	}
+	if !project.Items[3].Attributes.Completed {
+		t.Fatalf("expected checked task to be completed")
+	}
+}
+
+func TestParseRejectsStrayText(t *testing.T) {
+	if _, err := ParseTaskPaper([]byte("just a note\n")); err == nil {
+		t.Fatalf("expected TaskPaper error")
+	}
+	if _, err := ParseMarkdown([]byte("Intro paragraph\n# Project\n")); err == nil {
+		t.Fatalf("expected Markdown error")
+	}
+}
+
+func summarize(items []things.JSONItem) string {
+	parts := make([]string, 0, len(items))
+	for _, item := range items {
+		part := item.Type + ":" + item.Attributes.Title
+		if item.Attributes.Area != "" {
+			part += "[area=" + item.Attributes.Area + "]"
+		}
+		if item.Attributes.List != "" {
+			part += "[list=" + item.Attributes.List + "]"
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,479 @@
+package repeat
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"howett.net/plist"
+)
+
+// Parse decodes a stored rt1_recurrenceRule plist into a Spec.
+func Parse(data []byte) (Spec, error) {
+	if len(data) == 0 {
+		return Spec{}, fmt.Errorf("empty recurrence rule")
+	}
+	var rule map[string]any
+	if _, err := plist.Unmarshal(data, &rule); err != nil {
+		return Spec{}, fmt.Errorf("decode recurrence rule: %w", err)
+	}
+
+	spec := Spec{Every: 1}
+	switch plistInt(rule["fu"]) {
+	case 16:
+		spec.Unit = UnitDay
+	case 256:
+		spec.Unit = UnitWeek
+	case 8:
+		spec.Unit = UnitMonth
+	case 4:
+		spec.Unit = UnitYear
+	default:
+		return Spec{}, fmt.Errorf("unsupported recurrence unit %v", rule["fu"])
+	}
+	if every := plistInt(rule["fa"]); every > 0 {
+		spec.Every = every
+	}
+	if plistInt(rule["tp"]) == 0 {
+		spec.Mode = ModeSchedule
+	} else {
+		spec.Mode = ModeAfterCompletion
+	}
+	if ia, ok := plistFloat(rule["ia"]); ok && ia > 0 {
+		spec.Anchor = normalizeDate(time.Unix(int64(ia), 0).In(time.Local))
+	}
+	if ed, ok := plistFloat(rule["ed"]); ok && ed > 0 {
+		end := time.Unix(int64(ed), 0).In(time.Local)
+		if end.Year() < 4000 {
+			end = normalizeDate(end)
+			spec.EndDate = &end
+		}
+	}
+	if ts := plistInt(rule["ts"]); ts < 0 {
+		offset := -ts
+		spec.DeadlineOffset = &offset
+	}
+
+	if list, ok := rule["of"].([]any); ok {
+		for _, raw := range list {
+			entry, ok := raw.(map[string]any)
+			if !ok {
+				continue
+			}
+			spec.Offsets = append(spec.Offsets, parseOffset(entry, spec.Unit))
+		}
+	}
+	// Daily offsets and single-date yearly offsets only restate the anchor,
+	// so they are folded into it and the spec stays buildable.
+	switch spec.Unit {
+	case UnitDay:
+		spec.Offsets = nil
+	case UnitYear:
+		if len(spec.Offsets) == 1 && !spec.Offsets[0].HasWeekday && spec.Offsets[0].Day > 0 && spec.Offsets[0].Month != 0 {
+			if anchor, ok := yearlyDate(spec.Anchor, spec.Offsets[0].Month, spec.Offsets[0].Day); ok {
+				spec.Anchor = anchor
+				spec.Offsets = nil
+			}
+		}
+	}
+	return spec, nil
+}
+
+// yearlyDate returns the first month and day on or after anchor, looking a
+// few years ahead so February 29 lands on a leap year.
+func yearlyDate(anchor time.Time, month time.Month, day int) (time.Time, bool) {
+	anchor = normalizeDate(anchor)
+	for year := anchor.Year(); year <= anchor.Year()+8; year++ {
+		date := time.Date(year, month, day, 0, 0, 0, 0, anchor.Location())
+		if date.Day() == day && !date.Before(anchor) {
+			return date, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func parseOffset(entry map[string]any, unit Unit) Offset {
+	var offset Offset
+	if value, ok := entry["wd"]; ok {
+		offset.Weekday = time.Weekday(((plistInt(value) % 7) + 7) % 7)
+		offset.HasWeekday = true
+	}
+	if value, ok := entry["wdo"]; ok {
+		offset.Ordinal = plistInt(value)
+	}
+	if value, ok := entry["dy"]; ok && unit != UnitDay {
+		day := plistInt(value)
+		if day < 0 {
+			offset.Day = -1
+		} else {
+			offset.Day = day + 1
+		}
+	}
+	if value, ok := entry["mo"]; ok {
+		offset.Month = time.Month(plistInt(value) + 1)
+	}
+	return offset
+}
+
+// RRule renders the spec as an iCalendar RRULE value (without the "RRULE:"
+// prefix). After-completion rules are approximated by their fixed interval.
+func (s Spec) RRule() string {
+	parts := make([]string, 0, 6)
+	switch s.Unit {
+	case UnitDay:
+		parts = append(parts, "FREQ=DAILY")
+	case UnitWeek:
+		parts = append(parts, "FREQ=WEEKLY")
+	case UnitMonth:
+		parts = append(parts, "FREQ=MONTHLY")
+	case UnitYear:
+		parts = append(parts, "FREQ=YEARLY")
+	}
+	if s.Every > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(s.Every))
+	}
+
+	var byDay, byMonthDay, byMonth []string
+	for _, offset := range s.effectiveOffsets() {
+		if offset.HasWeekday {
+			prefix := ""
+			if offset.Ordinal != 0 && s.Unit != UnitWeek {
+				prefix = strconv.Itoa(offset.Ordinal)
+			}
+			byDay = appendUnique(byDay, prefix+rruleWeekdays[offset.Weekday])
+		} else if offset.Day != 0 && s.Unit != UnitDay {
+			byMonthDay = appendUnique(byMonthDay, strconv.Itoa(offset.Day))
+		}
+		if offset.Month != 0 && s.Unit == UnitYear {
+			byMonth = appendUnique(byMonth, strconv.Itoa(int(offset.Month)))
+		}
+	}
+	if len(byMonth) > 0 {
+		parts = append(parts, "BYMONTH="+strings.Join(byMonth, ","))
+	}
+	if len(byMonthDay) > 0 {
+		parts = append(parts, "BYMONTHDAY="+strings.Join(byMonthDay, ","))
+	}
+	if len(byDay) > 0 {
+		parts = append(parts, "BYDAY="+strings.Join(byDay, ","))
+	}
+	if s.EndDate != nil {
+		parts = append(parts, "UNTIL="+s.EndDate.Format("20060102"))
+	}
+	return strings.Join(parts, ";")
+}
+
+// ParseRRule parses an iCalendar RRULE value (with or without the "RRULE:"
+// prefix) into a fixed-schedule spec anchored at anchor. Yearly rules with
+// BYMONTH or BYMONTHDAY are anchored on that month and day instead, on or
+// after anchor. Rules Things cannot express, such as COUNT or hourly
+// frequencies, are rejected.
+func ParseRRule(value string, anchor time.Time) (Spec, error) {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "RRULE:")
+	spec := Spec{Mode: ModeSchedule, Every: 1, Anchor: anchor}
+	var byDay, byMonthDay, byMonth []string
+	hasFreq := false
+	for _, part := range strings.Split(value, ";") {
+		key, val, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			if part == "" {
+				continue
+			}
+			return Spec{}, fmt.Errorf("invalid RRULE part %q", part)
+		}
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			hasFreq = true
+			switch strings.ToUpper(val) {
+			case "DAILY":
+				spec.Unit = UnitDay
+			case "WEEKLY":
+				spec.Unit = UnitWeek
+			case "MONTHLY":
+				spec.Unit = UnitMonth
+			case "YEARLY":
+				spec.Unit = UnitYear
+			default:
+				return Spec{}, fmt.Errorf("unsupported RRULE frequency %q", val)
+			}
+		case "INTERVAL":
+			every, err := strconv.Atoi(val)
+			if err != nil || every < 1 {
+				return Spec{}, fmt.Errorf("invalid RRULE interval %q", val)
+			}
+			spec.Every = every
+		case "UNTIL":
+			if len(val) < 8 {
+				return Spec{}, fmt.Errorf("invalid RRULE until %q", val)
+			}
+			until, err := time.ParseInLocation("20060102", val[:8], time.Local)
+			if err != nil {
+				return Spec{}, fmt.Errorf("invalid RRULE until %q", val)
+			}
+			spec.EndDate = &until
+		case "BYDAY":
+			byDay = strings.Split(strings.ToUpper(val), ",")
+		case "BYMONTHDAY":
+			byMonthDay = strings.Split(val, ",")
+		case "WKST":
+		case "BYMONTH":
+			byMonth = strings.Split(val, ",")
+		default:
+			return Spec{}, fmt.Errorf("unsupported RRULE part %s", key)
+		}
+	}
+	if !hasFreq {
+		return Spec{}, fmt.Errorf("RRULE %q has no FREQ", value)
+	}
+	if spec.Unit == UnitYear {
+		// Yearly rules repeat on the anchor's month and day.
+		yearly, err := yearlyAnchor(anchor, byMonth, byMonthDay)
+		if err != nil {
+			return Spec{}, err
+		}
+		spec.Anchor = yearly
+		byMonthDay = nil
+	} else if len(byMonth) > 0 {
+		return Spec{}, fmt.Errorf("unsupported RRULE part BYMONTH")
+	}
+
+	for _, token := range byDay {
+		offset := Offset{HasWeekday: true}
+		if len(token) < 2 {
+			return Spec{}, fmt.Errorf("invalid RRULE weekday %q", token)
+		}
+		code := token[len(token)-2:]
+		found := false
+		for weekday, name := range rruleWeekdays {
+			if name == code {
+				offset.Weekday = time.Weekday(weekday)
+				found = true
+			}
+		}
+		if !found {
+			return Spec{}, fmt.Errorf("invalid RRULE weekday %q", token)
+		}
+		if prefix := token[:len(token)-2]; prefix != "" {
+			ordinal, err := strconv.Atoi(prefix)
+			if err != nil || ordinal == 0 || ordinal > 5 || ordinal < -1 || spec.Unit != UnitMonth {
+				return Spec{}, fmt.Errorf("unsupported RRULE weekday %q", token)
+			}
+			offset.Ordinal = ordinal
+		}
+		if spec.Unit != UnitWeek && spec.Unit != UnitMonth {
+			return Spec{}, fmt.Errorf("BYDAY is only supported for weekly and monthly rules")
+		}
+		spec.Offsets = append(spec.Offsets, offset)
+	}
+	for _, token := range byMonthDay {
+		day, err := strconv.Atoi(strings.TrimSpace(token))
+		if err != nil || day == 0 || day > 31 || day < -1 || spec.Unit != UnitMonth {
+			return Spec{}, fmt.Errorf("unsupported RRULE month day %q", token)
+		}
+		spec.Offsets = append(spec.Offsets, Offset{Day: day})
+	}
+	return spec, nil
+}
+
+// yearlyAnchor moves anchor to the BYMONTH and BYMONTHDAY of a yearly rule,
+// keeping it on or after anchor. Things repeats yearly rules on one date, so
+// lists and negative days are rejected.
+func yearlyAnchor(anchor time.Time, byMonth, byMonthDay []string) (time.Time, error) {
+	if len(byMonth) == 0 && len(byMonthDay) == 0 {
+		return anchor, nil
+	}
+	if len(byMonth) > 1 || len(byMonthDay) > 1 {
+		return time.Time{}, fmt.Errorf("yearly RRULE with several dates is not supported")
+	}
+	month, day := anchor.Month(), anchor.Day()
+	if len(byMonth) == 1 {
+		value, err := strconv.Atoi(strings.TrimSpace(byMonth[0]))
+		if err != nil || value < 1 || value > 12 {
+			return time.Time{}, fmt.Errorf("invalid RRULE month %q", byMonth[0])
+		}
+		month = time.Month(value)
+	}
+	if len(byMonthDay) == 1 {
+		value, err := strconv.Atoi(strings.TrimSpace(byMonthDay[0]))
+		if err != nil || value < 1 || value > 31 {
+			return time.Time{}, fmt.Errorf("unsupported RRULE month day %q", byMonthDay[0])
+		}
+		day = value
+	}
+	date := time.Date(anchor.Year(), month, day, 0, 0, 0, 0, anchor.Location())
+	if date.Day() != day {
+		return time.Time{}, fmt.Errorf("invalid RRULE date %s %d", month, day)
+	}
+	if date.Before(normalizeDate(anchor)) {
+		date = date.AddDate(1, 0, 0)
+	}
+	return date, nil
+}
+
+// Describe renders the spec as text such as
+// "every 2 weeks on Monday (after completion)".
+func (s Spec) Describe() string {
+	every := s.Every
+	if every < 1 {
+		every = 1
+	}
+	unit := [...]string{"day", "week", "month", "year"}[s.Unit]
+	var b strings.Builder
+	if every == 1 {
+		b.WriteString("every " + unit)
+	} else {
+		fmt.Fprintf(&b, "every %d %ss", every, unit)
+	}
+
+	var days []string
+	for _, offset := range s.effectiveOffsets() {
+		if day := describeOffset(offset, s.Unit); day != "" {
+			days = appendUnique(days, day)
+		}
+	}
+	if len(days) > 0 {
+		b.WriteString(" on " + joinWords(days))
+	}
+	if s.EndDate != nil {
+		b.WriteString(" until " + s.EndDate.Format("2006-01-02"))
+	}
+	if s.Mode == ModeAfterCompletion {
+		b.WriteString(" (after completion)")
+	}
+	return b.String()
+}
+
+func describeOffset(offset Offset, unit Unit) string {
+	var day string
+	switch {
+	case offset.HasWeekday && offset.Ordinal != 0 && unit != UnitWeek:
+		day = "the " + ordinalWord(offset.Ordinal) + " " + offset.Weekday.String()
+	case offset.HasWeekday:
+		day = offset.Weekday.String()
+	case offset.Day == -1 && unit != UnitDay:
+		day = "the last day"
+	case offset.Day > 0 && unit == UnitYear && offset.Month != 0:
+		return fmt.Sprintf("%s %d", offset.Month, offset.Day)
+	case offset.Day > 0 && unit != UnitDay:
+		day = "the " + ordinalWord(offset.Day)
+	default:
+		return ""
+	}
+	if unit == UnitYear && offset.Month != 0 {
+		day += " of " + offset.Month.String()
+	}
+	return day
+}
+
+func ordinalWord(n int) string {
+	if n < 0 {
+		return "last"
+	}
+	suffix := "th"
+	switch {
+	case n%100 >= 11 && n%100 <= 13:
+	case n%10 == 1:
+		suffix = "st"
+	case n%10 == 2:
+		suffix = "nd"
+	case n%10 == 3:
+		suffix = "rd"
+	}
+	return strconv.Itoa(n) + suffix
+}
+
+func joinWords(words []string) string {
+	switch len(words) {
+	case 0:
+		return ""
+	case 1:
+		return words[0]
+	default:
+		return strings.Join(words[:len(words)-1], ", ") + " and " + words[len(words)-1]
+	}
+}
+
+// effectiveOffsets returns the explicit offsets, or the single offset implied
+// by the anchor date.
+func (s Spec) effectiveOffsets() []Offset {
+	if len(s.Offsets) > 0 {
+		offsets := append([]Offset(nil), s.Offsets...)
+		sort.SliceStable(offsets, func(i, j int) bool {
+			if offsets[i].Month != offsets[j].Month {
+				return offsets[i].Month < offsets[j].Month
+			}
+			if offsets[i].Weekday != offsets[j].Weekday {
+				return offsets[i].Weekday < offsets[j].Weekday
+			}
+			return daySortKey(offsets[i].Day) < daySortKey(offsets[j].Day)
+		})
+		return offsets
+	}
+	anchor := normalizeDate(s.Anchor)
+	switch s.Unit {
+	case UnitWeek:
+		return []Offset{{Weekday: anchor.Weekday(), HasWeekday: true}}
+	case UnitMonth:
+		return []Offset{{Day: anchor.Day()}}
+	case UnitYear:
+		return []Offset{{Day: anchor.Day(), Month: anchor.Month()}}
+	default:
+		return nil
+	}
+}
+
+// daySortKey orders "last day" after the numbered days.
+func daySortKey(day int) int {
+	if day < 0 {
+		return 32
+	}
+	return day
+}
+
+var rruleWeekdays = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+func appendUnique(values []string, value string) []string {
+	for _, existing := range values {
+		if existing == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
+func plistInt(value any) int {
+	switch v := value.(type) {
+	case int:
+		return v
+	case int64:
+		return int(v)
+	case uint64:
+		return int(v)
+	case float64:
+		return int(math.Round(v))
+	case float32:
+		return int(math.Round(float64(v)))
+	default:
+		return 0
+	}
+}
+
+func plistFloat(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,221 @@
+package repeat
+
+import (
+	"testing"
+	"time"
+
+	"howett.net/plist"
+)
+
+func TestParseRoundTrip(t *testing.T) {
+	anchor := time.Date(2026, 1, 6, 0, 0, 0, 0, time.Local)
+	end := time.Date(2026, 6, 30, 0, 0, 0, 0, time.Local)
+	offset := 2
+	update, err := BuildUpdate(Spec{
+		Mode:           ModeSchedule,
+		Unit:           UnitWeek,
+		Every:          2,
+		Anchor:         anchor,
+		EndDate:        &end,
+		DeadlineOffset: &offset,
+	})
+	if err != nil {
+		t.Fatalf("BuildUpdate failed: %v", err)
+	}
+
+	spec, err := Parse(update.RecurrenceRule)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if spec.Mode != ModeSchedule || spec.Unit != UnitWeek || spec.Every != 2 {
+		t.Fatalf("unexpected spec: %+v", spec)
+	}
+	if !spec.Anchor.Equal(anchor) {
+		t.Fatalf("anchor mismatch: got %s want %s", spec.Anchor, anchor)
+	}
+	if spec.EndDate == nil || !spec.EndDate.Equal(end) {
+		t.Fatalf("end date mismatch: %v", spec.EndDate)
+	}
+	if spec.DeadlineOffset == nil || *spec.DeadlineOffset != 2 {
+		t.Fatalf("deadline offset mismatch: %v", spec.DeadlineOffset)
+	}
+	if len(spec.Offsets) != 1 || !spec.Offsets[0].HasWeekday || spec.Offsets[0].Weekday != time.Tuesday {
+		t.Fatalf("offsets mismatch: %+v", spec.Offsets)
+	}
+
+	if got, want := spec.RRule(), "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU;UNTIL=20260630"; got != want {
+		t.Fatalf("RRule mismatch: got %q want %q", got, want)
+	}
+}
+
+func TestParseRebuildsEveryUnit(t *testing.T) {
+	anchor := time.Date(2026, 3, 15, 0, 0, 0, 0, time.Local)
+	for _, unit := range []Unit{UnitDay, UnitWeek, UnitMonth, UnitYear} {
+		for _, mode := range []Mode{ModeSchedule, ModeAfterCompletion} {
+			original, err := BuildUpdate(Spec{Mode: mode, Unit: unit, Every: 2, Anchor: anchor})
+			if err != nil {
+				t.Fatalf("BuildUpdate(%v, %v) failed: %v", unit, mode, err)
+			}
+			spec, err := Parse(original.RecurrenceRule)
+			if err != nil {
+				t.Fatalf("Parse(%v, %v) failed: %v", unit, mode, err)
+			}
+			if !spec.Anchor.Equal(anchor) {
+				t.Fatalf("anchor mismatch for %v: got %s", unit, spec.Anchor)
+			}
+			rebuilt, err := BuildUpdate(spec)
+			if err != nil {
+				t.Fatalf("rebuild (%v, %v) failed: %v", unit, mode, err)
+			}
+			if string(rebuilt.RecurrenceRule) != string(original.RecurrenceRule) {
+				t.Fatalf("rule mismatch for %v, %v:\n%s\n%s", unit, mode, original.RecurrenceRule, rebuilt.RecurrenceRule)
+			}
+		}
+	}
+}
+
+func TestParseFarFutureEndDate(t *testing.T) {
+	rule := encodeRule(t, map[string]any{
+		"ed": float64(64092211200),
+		"fa": 1,
+		"fu": 256,
+		"ia": float64(1616889600),
+		"of": []any{map[string]any{"wd": 0}},
+		"tp": 1,
+		"ts": -99,
+	})
+	spec, err := Parse(rule)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if spec.Mode != ModeAfterCompletion {
+		t.Fatalf("expected after-completion mode")
+	}
+	if spec.EndDate != nil {
+		t.Fatalf("expected no end date, got %v", spec.EndDate)
+	}
+	if spec.DeadlineOffset == nil || *spec.DeadlineOffset != 99 {
+		t.Fatalf("deadline offset mismatch: %v", spec.DeadlineOffset)
+	}
+	if got, want := spec.RRule(), "FREQ=WEEKLY;BYDAY=SU"; got != want {
+		t.Fatalf("RRule mismatch: got %q want %q", got, want)
+	}
+}
+
+func TestRRuleMonthlyOffsets(t *testing.T) {
+	tests := []struct {
+		name   string
+		offset map[string]any
+		want   string
+	}{
+		{"day", map[string]any{"dy": 14}, "FREQ=MONTHLY;BYMONTHDAY=15"},
+		{"last day", map[string]any{"dy": -1}, "FREQ=MONTHLY;BYMONTHDAY=-1"},
+		{"nth weekday", map[string]any{"wd": 2, "wdo": 2}, "FREQ=MONTHLY;BYDAY=2TU"},
+		{"last weekday", map[string]any{"wd": 5, "wdo": -1}, "FREQ=MONTHLY;BYDAY=-1FR"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := encodeRule(t, map[string]any{
+				"fa": 1,
+				"fu": 8,
+				"ia": float64(1767225600),
+				"of": []any{tt.offset},
+				"tp": 0,
+			})
+			spec, err := Parse(rule)
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			if got := spec.RRule(); got != tt.want {
+				t.Fatalf("RRule mismatch: got %q want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRRule(t *testing.T) {
+	anchor := time.Date(2026, 10, 5, 0, 0, 0, 0, time.Local)
+	for _, rule := range []string{
+		"FREQ=DAILY",
+		"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR",
+		"FREQ=MONTHLY;BYMONTHDAY=-1",
+		"FREQ=MONTHLY;BYDAY=2TU",
+		"FREQ=YEARLY;BYMONTH=10;BYMONTHDAY=5;UNTIL=20301231",
+	} {
+		spec, err := ParseRRule("RRULE:"+rule, anchor)
+		if err != nil {
+			t.Fatalf("ParseRRule(%q) failed: %v", rule, err)
+		}
+		if spec.Mode != ModeSchedule || !spec.Anchor.Equal(anchor) {
+			t.Fatalf("unexpected spec for %q: %+v", rule, spec)
+		}
+		if got := spec.RRule(); got != rule {
+			t.Fatalf("round trip mismatch: got %q want %q", got, rule)
+		}
+	}
+
+	spec, err := ParseRRule("FREQ=YEARLY;BYMONTH=3;BYMONTHDAY=15", anchor)
+	if err != nil {
+		t.Fatalf("ParseRRule failed: %v", err)
+	}
+	if want := time.Date(2027, 3, 15, 0, 0, 0, 0, time.Local); !spec.Anchor.Equal(want) {
+		t.Fatalf("yearly anchor mismatch: got %s want %s", spec.Anchor, want)
+	}
+	if got := spec.Describe(); got != "every year on March 15" {
+		t.Fatalf("unexpected yearly rule: %q", got)
+	}
+
+	for _, rule := range []string{"FREQ=HOURLY", "FREQ=DAILY;COUNT=3", "INTERVAL=2", "FREQ=WEEKLY;BYDAY=2MO", "FREQ=YEARLY;BYMONTH=1,7", "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=30", "FREQ=YEARLY;BYMONTHDAY=-1"} {
+		if _, err := ParseRRule(rule, anchor); err == nil {
+			t.Fatalf("expected %q to be rejected", rule)
+		}
+	}
+}
+
+func TestParseRejectsUnknownUnit(t *testing.T) {
+	rule := encodeRule(t, map[string]any{"fa": 1, "fu": 2})
+	if _, err := Parse(rule); err == nil {
+		t.Fatalf("expected error for unknown unit")
+	}
+	if _, err := Parse(nil); err == nil {
+		t.Fatalf("expected error for empty rule")
+	}
+}
+
+func encodeRule(t *testing.T, rule map[string]any) []byte {
+	t.Helper()
+	data, err := plist.Marshal(rule, plist.BinaryFormat)
+	if err != nil {
+		t.Fatalf("encode rule: %v", err)
+	}
+	return data
+}
+
+func TestDescribe(t *testing.T) {
+	end := time.Date(2026, 12, 31, 0, 0, 0, 0, time.Local)
+	anchor := time.Date(2026, 1, 5, 0, 0, 0, 0, time.Local)
+	tests := []struct {
+		name string
+		spec Spec
+		want string
+	}{
+		{"daily", Spec{Mode: ModeSchedule, Unit: UnitDay, Every: 1, Anchor: anchor}, "every day"},
+		{"weekly after completion", Spec{Mode: ModeAfterCompletion, Unit: UnitWeek, Every: 2, Anchor: anchor}, "every 2 weeks on Monday (after completion)"},
+		{"multiple weekdays", Spec{Mode: ModeSchedule, Unit: UnitWeek, Every: 1, Offsets: []Offset{
+			{Weekday: time.Friday, HasWeekday: true},
+			{Weekday: time.Monday, HasWeekday: true},
+			{Weekday: time.Wednesday, HasWeekday: true},
+		}}, "every week on Monday, Wednesday and Friday"},
+		{"monthly day", Spec{Mode: ModeSchedule, Unit: UnitMonth, Every: 1, Anchor: time.Date(2026, 1, 22, 0, 0, 0, 0, time.Local)}, "every month on the 22nd"},
+		{"monthly nth weekday", Spec{Mode: ModeSchedule, Unit: UnitMonth, Every: 3, Offsets: []Offset{{Weekday: time.Tuesday, HasWeekday: true, Ordinal: 2}}}, "every 3 months on the 2nd Tuesday"},
+		{"monthly last day", Spec{Mode: ModeSchedule, Unit: UnitMonth, Every: 1, Offsets: []Offset{{Day: -1}, {Day: 15}}, EndDate: &end}, "every month on the 15th and the last day until 2026-12-31"},
+		{"yearly", Spec{Mode: ModeSchedule, Unit: UnitYear, Every: 1, Anchor: time.Date(2026, 3, 5, 0, 0, 0, 0, time.Local)}, "every year on March 5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.spec.Describe(); got != tt.want {
+				t.Fatalf("Describe mismatch: got %q want %q", got, tt.want)
+			}
+		})
+	}
+}
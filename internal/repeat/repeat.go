@@ -2,6 +2,7 @@ package repeat
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -35,6 +36,25 @@ type Spec struct {
 	Anchor         time.Time
 	EndDate        *time.Time
 	DeadlineOffset *int
+	// Offsets lists the days the rule fires on. When empty, the day is
+	// derived from Anchor.
+	Offsets []Offset
+}
+
+// Offset is a single entry of the recurrence rule's offset list.
+type Offset struct {
+	// Weekday is set when HasWeekday is true (weekly rules and monthly
+	// "nth weekday" rules).
+	Weekday    time.Weekday
+	HasWeekday bool
+	// Ordinal selects the nth weekday of the month (1-5, or -1 for the last).
+	// Zero means every matching weekday.
+	Ordinal int
+	// Day is the 1-based day of the month, or -1 for the last day. Zero means
+	// unset.
+	Day int
+	// Month is set for yearly rules.
+	Month time.Month
 }
 
 // ParseMode parses a repeat mode string.
@@ -65,6 +85,82 @@ func ParseUnit(input string) (Unit, error) {
 	}
 }
 
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tues": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thurs": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+var ordinalNames = map[string]int{
+	"1st": 1, "first": 1,
+	"2nd": 2, "second": 2,
+	"3rd": 3, "third": 3,
+	"4th": 4, "fourth": 4,
+	"5th": 5, "fifth": 5,
+	"last": -1,
+}
+
+// ParseOffsets parses a comma-separated --repeat-on value. Weekly rules take
+// weekdays (mon,wed,fri); monthly rules take days of the month (1, 15th,
+// last-day) or nth weekdays (2nd-tue, last-fri).
+func ParseOffsets(input string, unit Unit) ([]Offset, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return nil, nil
+	}
+	if unit != UnitWeek && unit != UnitMonth {
+		return nil, fmt.Errorf("repeat days are only supported for weekly and monthly rules")
+	}
+	var offsets []Offset
+	seen := map[Offset]bool{}
+	for _, raw := range strings.Split(input, ",") {
+		token := strings.ToLower(strings.TrimSpace(raw))
+		if token == "" {
+			continue
+		}
+		offset, err := parseOffsetToken(token, unit)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[offset] {
+			seen[offset] = true
+			offsets = append(offsets, offset)
+		}
+	}
+	if len(offsets) == 0 {
+		return nil, fmt.Errorf("invalid repeat days %q", input)
+	}
+	return offsets, nil
+}
+
+func parseOffsetToken(token string, unit Unit) (Offset, error) {
+	if weekday, ok := weekdayNames[token]; ok {
+		return Offset{Weekday: weekday, HasWeekday: true}, nil
+	}
+	if unit != UnitMonth {
+		return Offset{}, fmt.Errorf("invalid weekday %q (use mon, tue, wed, thu, fri, sat, sun)", token)
+	}
+	if token == "last-day" || token == "last" {
+		return Offset{Day: -1}, nil
+	}
+	if ordinal, rest, ok := strings.Cut(token, "-"); ok {
+		n, okOrdinal := ordinalNames[ordinal]
+		weekday, okWeekday := weekdayNames[rest]
+		if okOrdinal && okWeekday {
+			return Offset{Weekday: weekday, HasWeekday: true, Ordinal: n}, nil
+		}
+	}
+	digits := strings.TrimRight(token, "stndrh")
+	if day, err := strconv.Atoi(digits); err == nil && day >= 1 && day <= 31 {
+		return Offset{Day: day}, nil
+	}
+	return Offset{}, fmt.Errorf("invalid monthly repeat day %q (use 15, last-day, 2nd-tue, or last-fri)", token)
+}
+
 // BuildUpdate builds a database update for a repeating item.
 func BuildUpdate(spec Spec) (db.RepeatUpdate, error) {
 	if spec.Every <= 0 {
@@ -78,7 +174,7 @@ func BuildUpdate(spec Spec) (db.RepeatUpdate, error) {
 			return db.RepeatUpdate{}, fmt.Errorf("repeat end date must be on or after the start date")
 		}
 	}
-	offsets, err := offsetsFor(anchor, spec.Unit)
+	offsets, err := offsetsFor(anchor, spec.Unit, spec.Offsets)
 	if err != nil {
 		return db.RepeatUpdate{}, err
 	}
@@ -123,7 +219,7 @@ func BuildUpdate(spec Spec) (db.RepeatUpdate, error) {
 	start := thingsDateValue(startDate)
 	var next *int
 	if spec.Mode == ModeSchedule {
-		nextDate, err := nextScheduleDate(anchor, startDate, spec.Unit, spec.Every)
+		nextDate, err := nextScheduleDate(anchor, startDate, spec.Unit, spec.Every, spec.Offsets)
 		if err != nil {
 			return db.RepeatUpdate{}, err
 		}
@@ -143,6 +239,49 @@ func BuildUpdate(spec Spec) (db.RepeatUpdate, error) {
 	}, nil
 }
 
+// RestoreUpdate builds a database update that writes a stored rule back
+// unchanged except for its anchor, which moves to the first instance on or
+// after next (or today when next is zero) so no instance lands in the past.
+func RestoreUpdate(data []byte, next, today time.Time) (db.RepeatUpdate, error) {
+	spec, err := Parse(data)
+	if err != nil {
+		return db.RepeatUpdate{}, err
+	}
+	var rule map[string]any
+	format, err := plist.Unmarshal(data, &rule)
+	if err != nil {
+		return db.RepeatUpdate{}, fmt.Errorf("decode recurrence rule: %w", err)
+	}
+
+	anchor := normalizeDate(next)
+	today = normalizeDate(today)
+	if next.IsZero() {
+		anchor = today
+	}
+	first, err := nextScheduleDate(anchor, today, spec.Unit, spec.Every, spec.Offsets)
+	if err != nil {
+		return db.RepeatUpdate{}, err
+	}
+	rule["ia"] = float64(first.Unix())
+	rule["sr"] = float64(first.Unix())
+	encoded, err := plist.Marshal(rule, format)
+	if err != nil {
+		return db.RepeatUpdate{}, fmt.Errorf("encode recurrence rule: %w", err)
+	}
+
+	update := db.RepeatUpdate{RecurrenceRule: encoded, InstanceCreationStartDate: thingsDateValue(first)}
+	if spec.Mode == ModeSchedule {
+		value := thingsDateValue(first)
+		update.NextInstanceStartDate = &value
+	}
+	if spec.DeadlineOffset != nil {
+		sentinel := thingsDateValue(time.Date(4001, 1, 1, 0, 0, 0, 0, time.Local))
+		update.Deadline = &sentinel
+		update.SetDeadline = true
+	}
+	return update, nil
+}
+
 func normalizeDate(t time.Time) time.Time {
 	if t.IsZero() {
 		t = time.Now()
@@ -150,7 +289,7 @@ func normalizeDate(t time.Time) time.Time {
 	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
 }
 
-func nextScheduleDate(anchor, start time.Time, unit Unit, every int) (time.Time, error) {
+func nextScheduleDate(anchor, start time.Time, unit Unit, every int, offsets []Offset) (time.Time, error) {
 	if every <= 0 {
 		return time.Time{}, fmt.Errorf("repeat interval must be >= 1")
 	}
@@ -159,6 +298,14 @@ func nextScheduleDate(anchor, start time.Time, unit Unit, every int) (time.Time,
 	if start.Before(anchor) {
 		start = anchor
 	}
+	if len(offsets) > 0 {
+		switch unit {
+		case UnitWeek:
+			return nextWeeklyOffset(anchor, start, every, offsets), nil
+		case UnitMonth:
+			return nextMonthlyOffset(anchor, start, every, offsets)
+		}
+	}
 	switch unit {
 	case UnitDay:
 		diff := daysBetween(anchor, start)
@@ -215,6 +362,83 @@ func nextYearly(anchor, start time.Time, every int) time.Time {
 	return candidate
 }
 
+// nextWeeklyOffset returns the first listed weekday on or after start that
+// falls in an active week (every N weeks, counted from the anchor's week).
+func nextWeeklyOffset(anchor, start time.Time, every int, offsets []Offset) time.Time {
+	days := map[time.Weekday]bool{}
+	for _, offset := range offsets {
+		days[offset.Weekday] = true
+	}
+	anchorWeek := anchor.AddDate(0, 0, -int(anchor.Weekday()))
+	candidate := start
+	for i := 0; i < 7*every+7; i++ {
+		week := daysBetween(anchorWeek, candidate) / 7
+		if week%every == 0 && days[candidate.Weekday()] {
+			return candidate
+		}
+		candidate = candidate.AddDate(0, 0, 1)
+	}
+	return candidate
+}
+
+// nextMonthlyOffset returns the earliest offset date on or after start in an
+// active month (every N months, counted from the anchor's month).
+func nextMonthlyOffset(anchor, start time.Time, every int, offsets []Offset) (time.Time, error) {
+	month := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, start.Location())
+	// A 5th weekday can be missing for several months; two years of active
+	// months is always enough to find one.
+	for i := 0; i < 24*every; i++ {
+		if monthsBetween(anchor, month)%every == 0 {
+			var best time.Time
+			for _, offset := range offsets {
+				date, ok := offsetDate(month.Year(), month.Month(), offset, month.Location())
+				if !ok || date.Before(start) {
+					continue
+				}
+				if best.IsZero() || date.Before(best) {
+					best = date
+				}
+			}
+			if !best.IsZero() {
+				return best, nil
+			}
+		}
+		month = month.AddDate(0, 1, 0)
+	}
+	return time.Time{}, fmt.Errorf("no matching repeat date found")
+}
+
+// offsetDate resolves an offset within the given month. It reports false
+// when the month has no such day (e.g. a 5th Monday).
+func offsetDate(year int, month time.Month, offset Offset, loc *time.Location) (time.Time, bool) {
+	last := daysInMonth(year, month, loc)
+	if offset.HasWeekday {
+		if offset.Ordinal < 0 {
+			date := time.Date(year, month, last, 0, 0, 0, 0, loc)
+			back := (int(date.Weekday()) - int(offset.Weekday) + 7) % 7
+			return date.AddDate(0, 0, -back), true
+		}
+		first := time.Date(year, month, 1, 0, 0, 0, 0, loc)
+		ahead := (int(offset.Weekday) - int(first.Weekday()) + 7) % 7
+		ordinal := offset.Ordinal
+		if ordinal < 1 {
+			ordinal = 1
+		}
+		day := 1 + ahead + 7*(ordinal-1)
+		if day > last {
+			return time.Time{}, false
+		}
+		return time.Date(year, month, day, 0, 0, 0, 0, loc), true
+	}
+	if offset.Day < 0 {
+		return time.Date(year, month, last, 0, 0, 0, 0, loc), true
+	}
+	if offset.Day < 1 || offset.Day > last {
+		return time.Time{}, false
+	}
+	return time.Date(year, month, offset.Day, 0, 0, 0, 0, loc), true
+}
+
 func daysBetween(a, b time.Time) int {
 	ua := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
 	ub := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
@@ -258,7 +482,33 @@ func daysInMonth(year int, month time.Month, loc *time.Location) int {
 	return time.Date(year, month+1, 0, 0, 0, 0, 0, loc).Day()
 }
 
-func offsetsFor(anchor time.Time, unit Unit) ([]map[string]int, error) {
+func offsetsFor(anchor time.Time, unit Unit, offsets []Offset) ([]map[string]int, error) {
+	if len(offsets) > 0 {
+		if unit != UnitWeek && unit != UnitMonth {
+			return nil, fmt.Errorf("repeat days are only supported for weekly and monthly rules")
+		}
+		encoded := make([]map[string]int, 0, len(offsets))
+		for _, offset := range offsets {
+			entry := map[string]int{}
+			switch {
+			case offset.HasWeekday:
+				entry["wd"] = int(offset.Weekday)
+				if unit == UnitMonth && offset.Ordinal != 0 {
+					entry["wdo"] = offset.Ordinal
+				}
+			case unit == UnitWeek:
+				return nil, fmt.Errorf("weekly repeat days must be weekdays")
+			case offset.Day < 0:
+				entry["dy"] = -1
+			case offset.Day >= 1:
+				entry["dy"] = offset.Day - 1
+			default:
+				return nil, fmt.Errorf("invalid repeat day")
+			}
+			encoded = append(encoded, entry)
+		}
+		return encoded, nil
+	}
 	switch unit {
 	case UnitDay:
 		return []map[string]int{{"dy": 0}}, nil
@@ -118,3 +118,206 @@ func assertInt(t *testing.T, value any, expected int) {
 		t.Fatalf("unexpected int type %T", value)
 	}
 }
+
+func TestParseOffsets(t *testing.T) {
+	offsets, err := ParseOffsets("mon, wed,fri,mon", UnitWeek)
+	if err != nil {
+		t.Fatalf("ParseOffsets failed: %v", err)
+	}
+	if len(offsets) != 3 || offsets[0].Weekday != time.Monday || offsets[2].Weekday != time.Friday {
+		t.Fatalf("unexpected weekly offsets: %+v", offsets)
+	}
+
+	offsets, err = ParseOffsets("2nd-tue,last-fri,last-day,15th", UnitMonth)
+	if err != nil {
+		t.Fatalf("ParseOffsets failed: %v", err)
+	}
+	want := []Offset{
+		{Weekday: time.Tuesday, HasWeekday: true, Ordinal: 2},
+		{Weekday: time.Friday, HasWeekday: true, Ordinal: -1},
+		{Day: -1},
+		{Day: 15},
+	}
+	if len(offsets) != len(want) {
+		t.Fatalf("unexpected monthly offsets: %+v", offsets)
+	}
+	for i := range want {
+		if offsets[i] != want[i] {
+			t.Fatalf("offset %d mismatch: got %+v want %+v", i, offsets[i], want[i])
+		}
+	}
+
+	for _, tc := range []struct {
+		input string
+		unit  Unit
+	}{
+		{"2nd-tue", UnitWeek},
+		{"mon", UnitDay},
+		{"32", UnitMonth},
+		{"6th-mon", UnitMonth},
+	} {
+		if _, err := ParseOffsets(tc.input, tc.unit); err == nil {
+			t.Fatalf("expected error for %q", tc.input)
+		}
+	}
+}
+
+func TestBuildUpdateMultipleWeekdays(t *testing.T) {
+	anchor := time.Date(2026, 1, 5, 0, 0, 0, 0, time.Local) // Monday
+	spec := Spec{
+		Mode:   ModeSchedule,
+		Unit:   UnitWeek,
+		Every:  1,
+		Anchor: anchor,
+		Offsets: []Offset{
+			{Weekday: time.Monday, HasWeekday: true},
+			{Weekday: time.Wednesday, HasWeekday: true},
+			{Weekday: time.Friday, HasWeekday: true},
+		},
+	}
+	update, err := BuildUpdate(spec)
+	if err != nil {
+		t.Fatalf("BuildUpdate failed: %v", err)
+	}
+	var decoded map[string]any
+	if _, err := plist.Unmarshal(update.RecurrenceRule, &decoded); err != nil {
+		t.Fatalf("unmarshal rule: %v", err)
+	}
+	offsets := decoded["of"].([]any)
+	if len(offsets) != 3 {
+		t.Fatalf("expected 3 offsets, got %d", len(offsets))
+	}
+	assertInt(t, offsets[1].(map[string]any)["wd"], int(time.Wednesday))
+
+	expectedNext := thingsDateValue(time.Date(2026, 1, 7, 0, 0, 0, 0, time.Local))
+	if update.NextInstanceStartDate == nil || *update.NextInstanceStartDate != expectedNext {
+		t.Fatalf("expected next instance on Wednesday, got %v", update.NextInstanceStartDate)
+	}
+}
+
+func TestBuildUpdateMonthlyOffsetsEncoding(t *testing.T) {
+	spec := Spec{
+		Mode:   ModeSchedule,
+		Unit:   UnitMonth,
+		Every:  1,
+		Anchor: time.Date(2026, 1, 5, 0, 0, 0, 0, time.Local),
+		Offsets: []Offset{
+			{Weekday: time.Tuesday, HasWeekday: true, Ordinal: 2},
+			{Day: -1},
+		},
+	}
+	update, err := BuildUpdate(spec)
+	if err != nil {
+		t.Fatalf("BuildUpdate failed: %v", err)
+	}
+	parsed, err := Parse(update.RecurrenceRule)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(parsed.Offsets) != 2 || parsed.Offsets[0] != spec.Offsets[0] || parsed.Offsets[1] != spec.Offsets[1] {
+		t.Fatalf("offsets did not round-trip: %+v", parsed.Offsets)
+	}
+
+	weekly := Spec{Mode: ModeSchedule, Unit: UnitWeek, Every: 1, Offsets: []Offset{{Day: 3}}}
+	if _, err := BuildUpdate(weekly); err == nil {
+		t.Fatalf("expected error for weekly rule with day offset")
+	}
+}
+
+func TestNextScheduleDateOffsets(t *testing.T) {
+	loc := time.Local
+	date := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, loc) }
+	tests := []struct {
+		name    string
+		anchor  time.Time
+		start   time.Time
+		unit    Unit
+		every   int
+		offsets []Offset
+		want    time.Time
+	}{
+		{
+			name:    "weekly same week",
+			anchor:  date(2026, 1, 5),
+			start:   date(2026, 1, 6),
+			unit:    UnitWeek,
+			every:   1,
+			offsets: []Offset{{Weekday: time.Monday, HasWeekday: true}, {Weekday: time.Friday, HasWeekday: true}},
+			want:    date(2026, 1, 9),
+		},
+		{
+			name:    "biweekly skips inactive week",
+			anchor:  date(2026, 1, 5),
+			start:   date(2026, 1, 10),
+			unit:    UnitWeek,
+			every:   2,
+			offsets: []Offset{{Weekday: time.Monday, HasWeekday: true}, {Weekday: time.Wednesday, HasWeekday: true}},
+			want:    date(2026, 1, 19),
+		},
+		{
+			name:    "2nd tuesday",
+			anchor:  date(2026, 1, 5),
+			start:   date(2026, 1, 14),
+			unit:    UnitMonth,
+			every:   1,
+			offsets: []Offset{{Weekday: time.Tuesday, HasWeekday: true, Ordinal: 2}},
+			want:    date(2026, 2, 10),
+		},
+		{
+			name:    "last day in february",
+			anchor:  date(2026, 1, 31),
+			start:   date(2026, 2, 1),
+			unit:    UnitMonth,
+			every:   1,
+			offsets: []Offset{{Day: -1}},
+			want:    date(2026, 2, 28),
+		},
+		{
+			name:    "last friday",
+			anchor:  date(2026, 1, 1),
+			start:   date(2026, 1, 2),
+			unit:    UnitMonth,
+			every:   1,
+			offsets: []Offset{{Weekday: time.Friday, HasWeekday: true, Ordinal: -1}},
+			want:    date(2026, 1, 30),
+		},
+		{
+			name:    "fifth monday skips short months",
+			anchor:  date(2026, 1, 1),
+			start:   date(2026, 2, 1),
+			unit:    UnitMonth,
+			every:   1,
+			offsets: []Offset{{Weekday: time.Monday, HasWeekday: true, Ordinal: 5}},
+			want:    date(2026, 3, 30),
+		},
+		{
+			name:    "every 2 months on the 15th",
+			anchor:  date(2026, 1, 1),
+			start:   date(2026, 1, 16),
+			unit:    UnitMonth,
+			every:   2,
+			offsets: []Offset{{Day: 15}},
+			want:    date(2026, 3, 15),
+		},
+		{
+			name:    "31st skips short months",
+			anchor:  date(2026, 1, 1),
+			start:   date(2026, 2, 1),
+			unit:    UnitMonth,
+			every:   1,
+			offsets: []Offset{{Day: 31}},
+			want:    date(2026, 3, 31),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := nextScheduleDate(tt.anchor, tt.start, tt.unit, tt.every, tt.offsets)
+			if err != nil {
+				t.Fatalf("nextScheduleDate failed: %v", err)
+			}
+			if !got.Equal(tt.want) {
+				t.Fatalf("got %s want %s", got.Format("2006-01-02"), tt.want.Format("2006-01-02"))
+			}
+		})
+	}
+}
@@ -0,0 +1,320 @@
+package templates
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ossianhempel/things3-cli/internal/things"
+	"gopkg.in/yaml.v3"
+)
+
+// Extensions lists the file extensions recognized as templates, in lookup order.
+var Extensions = []string{".yaml", ".yml", ".json"}
+
+// Template describes a project and/or loose todos to create in one batch.
+type Template struct {
+	Name        string            `yaml:"name"`
+	Description string            `yaml:"description"`
+	Vars        map[string]string `yaml:"vars"`
+	Project     *Project          `yaml:"project"`
+	Todos       []Todo            `yaml:"todos"`
+}
+
+// Project is a templated project with optional headings.
+type Project struct {
+	Title    string    `yaml:"title"`
+	Notes    string    `yaml:"notes"`
+	When     string    `yaml:"when"`
+	Deadline string    `yaml:"deadline"`
+	Area     string    `yaml:"area"`
+	AreaID   string    `yaml:"area-id"`
+	Tags     []string  `yaml:"tags"`
+	Todos    []Todo    `yaml:"todos"`
+	Headings []Heading `yaml:"headings"`
+}
+
+// Heading groups todos inside a templated project.
+type Heading struct {
+	Title string `yaml:"title"`
+	Todos []Todo `yaml:"todos"`
+}
+
+// Todo is a templated todo. List and ListID only apply to top-level todos.
+type Todo struct {
+	Title     string   `yaml:"title"`
+	Notes     string   `yaml:"notes"`
+	When      string   `yaml:"when"`
+	Deadline  string   `yaml:"deadline"`
+	Tags      []string `yaml:"tags"`
+	Checklist []string `yaml:"checklist"`
+	List      string   `yaml:"list"`
+	ListID    string   `yaml:"list-id"`
+}
+
+// Entry is a template found in the templates directory.
+type Entry struct {
+	Name        string `json:"name"`
+	Path        string `json:"path"`
+	Description string `json:"description,omitempty"`
+}
+
+// Dir returns the default templates directory.
+func Dir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "things3-cli", "templates"), nil
+}
+
+// Parse decodes a YAML or JSON template.
+func Parse(data []byte) (Template, error) {
+	var tmpl Template
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&tmpl); err != nil {
+		return Template{}, fmt.Errorf("Error: invalid template: %v", err)
+	}
+	if tmpl.Project == nil && len(tmpl.Todos) == 0 {
+		return Template{}, errors.New("Error: template must define a project or todos")
+	}
+	return tmpl, nil
+}
+
+// Load reads and parses a template file.
+func Load(path string) (Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Template{}, fmt.Errorf("Error: read template %s: %v", path, err)
+	}
+	tmpl, err := Parse(data)
+	if err != nil {
+		return Template{}, fmt.Errorf("%s (%s)", err, path)
+	}
+	if tmpl.Name == "" {
+		tmpl.Name = nameFromPath(path)
+	}
+	return tmpl, nil
+}
+
+// Resolve finds a template by file path or by name in dir.
+func Resolve(dir, nameOrPath string) (string, error) {
+	if info, err := os.Stat(nameOrPath); err == nil && !info.IsDir() {
+		return nameOrPath, nil
+	}
+	if dir != "" && !strings.ContainsRune(nameOrPath, filepath.Separator) {
+		for _, ext := range Extensions {
+			candidate := filepath.Join(dir, nameOrPath+ext)
+			if _, err := os.Stat(candidate); err == nil {
+				return candidate, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("Error: template not found: %s", nameOrPath)
+}
+
+// List returns the templates stored in dir, sorted by name.
+func List(dir string) ([]Entry, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	seen := map[string]bool{}
+	var out []Entry
+	for _, entry := range entries {
+		if entry.IsDir() || !hasTemplateExt(entry.Name()) {
+			continue
+		}
+		name := nameFromPath(entry.Name())
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		path := filepath.Join(dir, entry.Name())
+		item := Entry{Name: name, Path: path}
+		if tmpl, err := Load(path); err == nil {
+			item.Description = tmpl.Description
+		}
+		out = append(out, item)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+var varPattern = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_.-]+)\s*\}\}`)
+
+var relativeDatePattern = regexp.MustCompile(`^([+-])(\d+)([dwmy])$`)
+
+// Render substitutes variables, resolves relative dates against base, and
+// returns the Things JSON items to create.
+func Render(tmpl Template, vars map[string]string, base time.Time) ([]things.JSONItem, error) {
+	r := renderer{vars: map[string]string{}, missing: map[string]bool{}, base: base}
+	for key, value := range tmpl.Vars {
+		r.vars[key] = value
+	}
+	for key, value := range vars {
+		r.vars[key] = value
+	}
+
+	var items []things.JSONItem
+	if tmpl.Project != nil {
+		items = append(items, r.project(*tmpl.Project))
+	}
+	for _, todo := range tmpl.Todos {
+		item := r.todo(todo)
+		item.Attributes.List = r.text(todo.List)
+		item.Attributes.ListID = r.text(todo.ListID)
+		items = append(items, item)
+	}
+
+	if len(r.missing) > 0 {
+		names := make([]string, 0, len(r.missing))
+		for name := range r.missing {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("Error: missing template variables: %s (pass --var NAME=VALUE)", strings.Join(names, ", "))
+	}
+	if len(r.errs) > 0 {
+		return nil, r.errs[0]
+	}
+	if err := things.ValidateJSONItems(items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// ResolveDate converts a relative date like +3d, -1w, +2m, or +1y into an
+// ISO date relative to base. Other values are returned unchanged.
+func ResolveDate(value string, base time.Time) (string, error) {
+	match := relativeDatePattern.FindStringSubmatch(strings.TrimSpace(value))
+	if match == nil {
+		return value, nil
+	}
+	n, err := strconv.Atoi(match[2])
+	if err != nil {
+		return "", fmt.Errorf("Error: invalid relative date %q", value)
+	}
+	if match[1] == "-" {
+		n = -n
+	}
+	var date time.Time
+	switch match[3] {
+	case "d":
+		date = base.AddDate(0, 0, n)
+	case "w":
+		date = base.AddDate(0, 0, 7*n)
+	case "m":
+		date = base.AddDate(0, n, 0)
+	case "y":
+		date = base.AddDate(n, 0, 0)
+	}
+	return date.Format("2006-01-02"), nil
+}
+
+type renderer struct {
+	vars    map[string]string
+	missing map[string]bool
+	errs    []error
+	base    time.Time
+}
+
+func (r *renderer) text(value string) string {
+	return varPattern.ReplaceAllStringFunc(value, func(token string) string {
+		name := varPattern.FindStringSubmatch(token)[1]
+		if replacement, ok := r.vars[name]; ok {
+			return replacement
+		}
+		r.missing[name] = true
+		return token
+	})
+}
+
+func (r *renderer) texts(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	out := make([]string, 0, len(values))
+	for _, value := range values {
+		out = append(out, r.text(value))
+	}
+	return out
+}
+
+func (r *renderer) date(value string) string {
+	rendered := r.text(value)
+	resolved, err := ResolveDate(rendered, r.base)
+	if err != nil {
+		r.errs = append(r.errs, err)
+		return rendered
+	}
+	return resolved
+}
+
+func (r *renderer) project(project Project) things.JSONItem {
+	attrs := things.JSONAttributes{
+		Title:    r.text(project.Title),
+		Notes:    r.text(project.Notes),
+		When:     r.date(project.When),
+		Deadline: r.date(project.Deadline),
+		Area:     r.text(project.Area),
+		AreaID:   r.text(project.AreaID),
+		Tags:     r.texts(project.Tags),
+	}
+	for _, todo := range project.Todos {
+		attrs.Items = append(attrs.Items, r.todo(todo))
+	}
+	for _, heading := range project.Headings {
+		attrs.Items = append(attrs.Items, things.JSONItem{
+			Type:       things.JSONTypeHeading,
+			Attributes: things.JSONAttributes{Title: r.text(heading.Title)},
+		})
+		for _, todo := range heading.Todos {
+			attrs.Items = append(attrs.Items, r.todo(todo))
+		}
+	}
+	return things.JSONItem{Type: things.JSONTypeProject, Attributes: attrs}
+}
+
+func (r *renderer) todo(todo Todo) things.JSONItem {
+	attrs := things.JSONAttributes{
+		Title:    r.text(todo.Title),
+		Notes:    r.text(todo.Notes),
+		When:     r.date(todo.When),
+		Deadline: r.date(todo.Deadline),
+		Tags:     r.texts(todo.Tags),
+	}
+	for _, title := range todo.Checklist {
+		attrs.ChecklistItems = append(attrs.ChecklistItems, things.JSONItem{
+			Type:       things.JSONTypeChecklistItem,
+			Attributes: things.JSONAttributes{Title: r.text(title)},
+		})
+	}
+	return things.JSONItem{Type: things.JSONTypeTodo, Attributes: attrs}
+}
+
+func hasTemplateExt(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	for _, candidate := range Extensions {
+		if ext == candidate {
+			return true
+		}
+	}
+	return false
+}
+
+func nameFromPath(path string) string {
+	base := filepath.Base(path)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
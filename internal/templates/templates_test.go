@@ -0,0 +1,161 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ossianhempel/things3-cli/internal/things"
+)
+
+const releaseTemplate = `
+name: release
+description: Sprint release checklist
+vars:
+  owner: qa
+project:
+  title: "Release {{ version }}"
+  deadline: "{{date}}"
+  tags: [release]
+  todos:
+    - title: Write notes
+  headings:
+    - title: QA
+      todos:
+        - title: "Smoke test {{version}} ({{owner}})"
+          when: +3d
+          checklist: [iOS, macOS]
+todos:
+  - title: Announce
+    list: Inbox
+    deadline: +1w
+`
+
+func TestRenderBuildsProjectTree(t *testing.T) {
+	tmpl, err := Parse([]byte(releaseTemplate))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	base := time.Date(2026, 10, 30, 0, 0, 0, 0, time.Local)
+	items, err := Render(tmpl, map[string]string{"version": "1.4", "date": "2026-11-01"}, base)
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(items))
+	}
+
+	project := items[0]
+	if project.Type != things.JSONTypeProject || project.Attributes.Title != "Release 1.4" {
+		t.Fatalf("unexpected project: %+v", project)
+	}
+	if project.Attributes.Deadline != "2026-11-01" {
+		t.Fatalf("unexpected deadline: %q", project.Attributes.Deadline)
+	}
+	children := project.Attributes.Items
+	if len(children) != 3 {
+		t.Fatalf("expected 3 project items, got %d", len(children))
+	}
+	if children[0].Type != things.JSONTypeTodo || children[1].Type != things.JSONTypeHeading || children[2].Type != things.JSONTypeTodo {
+		t.Fatalf("unexpected item order: %+v", children)
+	}
+	smoke := children[2].Attributes
+	if smoke.Title != "Smoke test 1.4 (qa)" {
+		t.Fatalf("unexpected title: %q", smoke.Title)
+	}
+	if smoke.When != "2026-11-02" {
+		t.Fatalf("expected relative when to resolve, got %q", smoke.When)
+	}
+	if len(smoke.ChecklistItems) != 2 || smoke.ChecklistItems[1].Attributes.Title != "macOS" {
+		t.Fatalf("unexpected checklist: %+v", smoke.ChecklistItems)
+	}
+
+	loose := items[1].Attributes
+	if loose.List != "Inbox" || loose.Deadline != "2026-11-06" {
+		t.Fatalf("unexpected loose todo: %+v", loose)
+	}
+}
+
+func TestRenderReportsMissingVars(t *testing.T) {
+	tmpl, err := Parse([]byte(releaseTemplate))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	_, err = Render(tmpl, nil, time.Now())
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+	if !strings.Contains(err.Error(), "date, version") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestParseRejectsUnknownFields(t *testing.T) {
+	if _, err := Parse([]byte("project:\n  title: x\n  heading: y\n")); err == nil {
+		t.Fatalf("expected unknown field error")
+	}
+	if _, err := Parse([]byte("name: empty\n")); err == nil {
+		t.Fatalf("expected error for empty template")
+	}
+}
+
+func TestParseAcceptsJSON(t *testing.T) {
+	tmpl, err := Parse([]byte(`{"todos": [{"title": "One", "when": "today"}]}`))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(tmpl.Todos) != 1 || tmpl.Todos[0].When != "today" {
+		t.Fatalf("unexpected template: %+v", tmpl)
+	}
+}
+
+func TestResolveDate(t *testing.T) {
+	base := time.Date(2026, 1, 31, 0, 0, 0, 0, time.Local)
+	cases := map[string]string{
+		"+0d":        "2026-01-31",
+		"-1d":        "2026-01-30",
+		"+2w":        "2026-02-14",
+		"+1y":        "2027-01-31",
+		"today":      "today",
+		"2026-05-01": "2026-05-01",
+	}
+	for input, want := range cases {
+		got, err := ResolveDate(input, base)
+		if err != nil {
+			t.Fatalf("%s: %v", input, err)
+		}
+		if got != want {
+			t.Fatalf("%s: expected %s, got %s", input, want, got)
+		}
+	}
+}
+
+func TestListAndResolve(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	write("release.yaml", releaseTemplate)
+	write("inbox.json", `{"description": "Inbox zero", "todos": [{"title": "x"}]}`)
+	write("notes.txt", "ignored")
+
+	entries, err := List(dir)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Name != "inbox" || entries[1].Description != "Sprint release checklist" {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+
+	path, err := Resolve(dir, "release")
+	if err != nil || filepath.Base(path) != "release.yaml" {
+		t.Fatalf("unexpected resolve result: %q %v", path, err)
+	}
+	if _, err := Resolve(dir, "missing"); err == nil {
+		t.Fatalf("expected error for missing template")
+	}
+}
@@ -10,3 +10,4 @@ var errMissingAreaTarget = errors.New("Error: Must specify --id=ID or area title
 var errMissingAreaUpdate = errors.New("Error: Must specify --tags, --add-tags, or --title")
 var errMissingTodoTarget = errors.New("Error: Must specify --id=ID or todo title")
 var errMissingProjectTarget = errors.New("Error: Must specify --id=ID or project title")
+var errMissingJSONItems = errors.New("Error: Must specify at least one JSON item")
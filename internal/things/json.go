@@ -0,0 +1,211 @@
+package things
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Things JSON item types.
+const (
+	JSONTypeTodo          = "to-do"
+	JSONTypeProject       = "project"
+	JSONTypeHeading       = "heading"
+	JSONTypeChecklistItem = "checklist-item"
+)
+
+// Things JSON operations.
+const (
+	JSONOperationCreate = "create"
+	JSONOperationUpdate = "update"
+)
+
+// JSONItem is a single object in a Things JSON payload.
+type JSONItem struct {
+	Type       string         `json:"type"`
+	Operation  string         `json:"operation,omitempty"`
+	ID         string         `json:"id,omitempty"`
+	Attributes JSONAttributes `json:"attributes"`
+}
+
+// JSONAttributes holds the attributes of a Things JSON item.
+//
+// Which attributes apply depends on the item type; see the Things URL scheme
+// documentation for the json command.
+type JSONAttributes struct {
+	Title                 string     `json:"title,omitempty"`
+	Notes                 string     `json:"notes,omitempty"`
+	PrependNotes          string     `json:"prepend-notes,omitempty"`
+	AppendNotes           string     `json:"append-notes,omitempty"`
+	When                  string     `json:"when,omitempty"`
+	Deadline              string     `json:"deadline,omitempty"`
+	Tags                  []string   `json:"tags,omitempty"`
+	AddTags               []string   `json:"add-tags,omitempty"`
+	ChecklistItems        []JSONItem `json:"checklist-items,omitempty"`
+	PrependChecklistItems []JSONItem `json:"prepend-checklist-items,omitempty"`
+	AppendChecklistItems  []JSONItem `json:"append-checklist-items,omitempty"`
+	ListID                string     `json:"list-id,omitempty"`
+	List                  string     `json:"list,omitempty"`
+	HeadingID             string     `json:"heading-id,omitempty"`
+	Heading               string     `json:"heading,omitempty"`
+	AreaID                string     `json:"area-id,omitempty"`
+	Area                  string     `json:"area,omitempty"`
+	Items                 []JSONItem `json:"items,omitempty"`
+	Completed             bool       `json:"completed,omitempty"`
+	Canceled              bool       `json:"canceled,omitempty"`
+	Archived              bool       `json:"archived,omitempty"`
+	CreationDate          string     `json:"creation-date,omitempty"`
+	CompletionDate        string     `json:"completion-date,omitempty"`
+}
+
+// JSONOptions defines options for the json command.
+type JSONOptions struct {
+	AuthToken string
+	Reveal    bool
+}
+
+// ParseJSONItems decodes a Things JSON payload (an array or a single object).
+func ParseJSONItems(data []byte) ([]JSONItem, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, errMissingJSONItems
+	}
+	var items []JSONItem
+	if data[0] == '{' {
+		var item JSONItem
+		if err := json.Unmarshal(data, &item); err != nil {
+			return nil, fmt.Errorf("Error: invalid JSON payload: %v", err)
+		}
+		items = []JSONItem{item}
+	} else if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("Error: invalid JSON payload: %v", err)
+	}
+	if len(items) == 0 {
+		return nil, errMissingJSONItems
+	}
+	return items, nil
+}
+
+// JSONHasUpdates reports whether any item in the payload is an update operation.
+func JSONHasUpdates(items []JSONItem) bool {
+	for _, item := range items {
+		if item.Operation == JSONOperationUpdate {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateJSONItems checks item types, operations, and nesting rules.
+func ValidateJSONItems(items []JSONItem) error {
+	if len(items) == 0 {
+		return errMissingJSONItems
+	}
+	for i, item := range items {
+		if err := validateJSONItem(item, fmt.Sprintf("item %d", i+1), JSONTypeTodo, JSONTypeProject); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateJSONItem(item JSONItem, path string, allowed ...string) error {
+	if !containsString(allowed, item.Type) {
+		if item.Type == "" {
+			return fmt.Errorf("Error: %s: missing type", path)
+		}
+		return fmt.Errorf("Error: %s: type %q is not allowed here (expected %s)", path, item.Type, strings.Join(allowed, " or "))
+	}
+	switch item.Operation {
+	case "", JSONOperationCreate:
+	case JSONOperationUpdate:
+		if item.Type != JSONTypeTodo && item.Type != JSONTypeProject {
+			return fmt.Errorf("Error: %s: only to-dos and projects can be updated", path)
+		}
+		if strings.TrimSpace(item.ID) == "" {
+			return fmt.Errorf("Error: %s: update requires an id", path)
+		}
+	default:
+		return fmt.Errorf("Error: %s: invalid operation %q", path, item.Operation)
+	}
+
+	attrs := item.Attributes
+	switch item.Type {
+	case JSONTypeTodo:
+		if len(attrs.Items) > 0 {
+			return fmt.Errorf("Error: %s: to-dos cannot contain items (use checklist-items)", path)
+		}
+		for _, list := range [][]JSONItem{attrs.ChecklistItems, attrs.PrependChecklistItems, attrs.AppendChecklistItems} {
+			for j, child := range list {
+				if err := validateJSONItem(child, fmt.Sprintf("%s checklist item %d", path, j+1), JSONTypeChecklistItem); err != nil {
+					return err
+				}
+			}
+		}
+	case JSONTypeProject:
+		if len(attrs.ChecklistItems) > 0 || len(attrs.PrependChecklistItems) > 0 || len(attrs.AppendChecklistItems) > 0 {
+			return fmt.Errorf("Error: %s: projects cannot contain checklist items", path)
+		}
+		if item.Operation == JSONOperationUpdate && len(attrs.Items) > 0 {
+			return fmt.Errorf("Error: %s: project updates cannot add items", path)
+		}
+		for j, child := range attrs.Items {
+			if child.Operation == JSONOperationUpdate {
+				return fmt.Errorf("Error: %s item %d: nested items cannot be updates", path, j+1)
+			}
+			if err := validateJSONItem(child, fmt.Sprintf("%s item %d", path, j+1), JSONTypeTodo, JSONTypeHeading); err != nil {
+				return err
+			}
+		}
+	case JSONTypeHeading, JSONTypeChecklistItem:
+		if strings.TrimSpace(attrs.Title) == "" {
+			return fmt.Errorf("Error: %s: %s requires a title", path, item.Type)
+		}
+		if len(attrs.Items) > 0 || len(attrs.ChecklistItems) > 0 {
+			return fmt.Errorf("Error: %s: %s cannot contain items", path, item.Type)
+		}
+	}
+	return nil
+}
+
+// BuildJSONURL builds a Things URL for the json command.
+func BuildJSONURL(opts JSONOptions, items []JSONItem) (string, error) {
+	if err := ValidateJSONItems(items); err != nil {
+		return "", err
+	}
+	hasUpdates := JSONHasUpdates(items)
+	if hasUpdates && opts.AuthToken == "" {
+		return "", ErrMissingAuthToken
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(items); err != nil {
+		return "", fmt.Errorf("Error: encode JSON payload: %v", err)
+	}
+	data := strings.TrimRight(buf.String(), "\n")
+
+	params := make([]string, 0, 3)
+	params = append(params, "data="+URLEncode(data))
+
+	if opts.Reveal {
+		params = append(params, "reveal=true")
+	}
+
+	if hasUpdates {
+		params = append(params, "auth-token="+URLEncode(opts.AuthToken))
+	}
+
+	return "things:///json?" + strings.Join(params, "&") + "&", nil
+}
+
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
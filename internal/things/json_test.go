@@ -0,0 +1,106 @@
+package things
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestBuildJSONURLEncodesProjectTree(t *testing.T) {
+	items := []JSONItem{{
+		Type: JSONTypeProject,
+		Attributes: JSONAttributes{
+			Title: "Trip",
+			Items: []JSONItem{
+				{Type: JSONTypeHeading, Attributes: JSONAttributes{Title: "Before"}},
+				{Type: JSONTypeTodo, Attributes: JSONAttributes{
+					Title:          "Pack",
+					Tags:           []string{"Home"},
+					ChecklistItems: []JSONItem{{Type: JSONTypeChecklistItem, Attributes: JSONAttributes{Title: "Passport"}}},
+				}},
+			},
+		},
+	}}
+
+	got, err := BuildJSONURL(JSONOptions{Reveal: true}, items)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(got, "things:///json?data=") {
+		t.Fatalf("unexpected url: %q", got)
+	}
+	if !contains(got, "&reveal=true&") {
+		t.Fatalf("expected reveal in %q", got)
+	}
+	if contains(got, "auth-token") {
+		t.Fatalf("did not expect auth-token in %q", got)
+	}
+
+	raw := strings.TrimPrefix(got, "things:///json?data=")
+	raw = raw[:strings.Index(raw, "&")]
+	data, err := url.PathUnescape(raw)
+	if err != nil {
+		t.Fatalf("unescape: %v", err)
+	}
+	want := `[{"type":"project","attributes":{"title":"Trip","items":[{"type":"heading","attributes":{"title":"Before"}},{"type":"to-do","attributes":{"title":"Pack","tags":["Home"],"checklist-items":[{"type":"checklist-item","attributes":{"title":"Passport"}}]}}]}}]`
+	if data != want {
+		t.Fatalf("unexpected payload:\n got %s\nwant %s", data, want)
+	}
+}
+
+func TestBuildJSONURLRequiresTokenForUpdates(t *testing.T) {
+	items := []JSONItem{{Type: JSONTypeTodo, Operation: JSONOperationUpdate, ID: "abc", Attributes: JSONAttributes{Completed: true}}}
+
+	_, err := BuildJSONURL(JSONOptions{}, items)
+	if !errors.Is(err, ErrMissingAuthToken) {
+		t.Fatalf("expected missing auth token error, got %v", err)
+	}
+
+	got, err := BuildJSONURL(JSONOptions{AuthToken: "tok"}, items)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !contains(got, "&auth-token=tok&") {
+		t.Fatalf("expected auth-token in %q", got)
+	}
+}
+
+func TestValidateJSONItemsRejectsInvalidNesting(t *testing.T) {
+	cases := []struct {
+		name  string
+		items []JSONItem
+		want  string
+	}{
+		{"empty", nil, "at least one JSON item"},
+		{"top-level heading", []JSONItem{{Type: JSONTypeHeading, Attributes: JSONAttributes{Title: "H"}}}, `type "heading" is not allowed`},
+		{"update without id", []JSONItem{{Type: JSONTypeTodo, Operation: JSONOperationUpdate}}, "update requires an id"},
+		{"bad operation", []JSONItem{{Type: JSONTypeTodo, Operation: "delete"}}, `invalid operation "delete"`},
+		{"todo with items", []JSONItem{{Type: JSONTypeTodo, Attributes: JSONAttributes{Items: []JSONItem{{Type: JSONTypeTodo}}}}}, "cannot contain items"},
+		{"checklist in project", []JSONItem{{Type: JSONTypeProject, Attributes: JSONAttributes{Items: []JSONItem{{Type: JSONTypeChecklistItem, Attributes: JSONAttributes{Title: "x"}}}}}}, `item 1 item 1: type "checklist-item"`},
+		{"untitled checklist item", []JSONItem{{Type: JSONTypeTodo, Attributes: JSONAttributes{ChecklistItems: []JSONItem{{Type: JSONTypeChecklistItem}}}}}, "requires a title"},
+	}
+	for _, tc := range cases {
+		err := ValidateJSONItems(tc.items)
+		if err == nil {
+			t.Fatalf("%s: expected error", tc.name)
+		}
+		if !strings.Contains(err.Error(), tc.want) {
+			t.Fatalf("%s: unexpected error: %v", tc.name, err)
+		}
+	}
+}
+
+func TestParseJSONItemsAcceptsSingleObject(t *testing.T) {
+	items, err := ParseJSONItems([]byte(`  {"type":"to-do","attributes":{"title":"One","when":"today"}}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(items) != 1 || items[0].Attributes.Title != "One" || items[0].Attributes.When != "today" {
+		t.Fatalf("unexpected items: %+v", items)
+	}
+
+	if _, err := ParseJSONItems([]byte(`[{"type":`)); err == nil {
+		t.Fatalf("expected error for invalid JSON")
+	}
+}
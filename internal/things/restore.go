@@ -0,0 +1,91 @@
+package things
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RestoreTarget describes where a trashed todo is moved back to.
+type RestoreTarget struct {
+	ID string
+	// List is the built-in list to move to: Inbox, Anytime, or Someday.
+	// Defaults to Anytime.
+	List      string
+	ProjectID string
+	AreaID    string
+	// When is a YYYY-MM-DD date to schedule the todo for. Optional.
+	When string
+}
+
+// BuildRestoreScript builds an AppleScript snippet that moves todos out of
+// Trash and back to their recorded list, project, or area. Every todo is
+// attempted; the script fails with the IDs that could not be restored.
+func BuildRestoreScript(targets []RestoreTarget) (string, error) {
+	var b strings.Builder
+	b.WriteString("tell application \"Things3\"\n")
+	b.WriteString("  set restoreFailures to {}\n")
+	count := 0
+	for _, target := range targets {
+		id := strings.TrimSpace(target.ID)
+		if id == "" {
+			continue
+		}
+		list := "Anytime"
+		switch strings.ToLower(strings.TrimSpace(target.List)) {
+		case "inbox":
+			list = "Inbox"
+		case "someday":
+			list = "Someday"
+		}
+		var when time.Time
+		if strings.TrimSpace(target.When) != "" {
+			parsed, err := time.Parse("2006-01-02", strings.TrimSpace(target.When))
+			if err != nil {
+				return "", fmt.Errorf("Error: invalid restore date %q", target.When)
+			}
+			when = parsed
+		}
+
+		count++
+		b.WriteString("  try\n")
+		b.WriteString("    set restoredToDo to to do id \"")
+		b.WriteString(escapeAppleScriptString(id))
+		b.WriteString("\"\n")
+		b.WriteString("    move restoredToDo to list \"")
+		b.WriteString(list)
+		b.WriteString("\"\n")
+		if projectID := strings.TrimSpace(target.ProjectID); projectID != "" {
+			b.WriteString("    set project of restoredToDo to project id \"")
+			b.WriteString(escapeAppleScriptString(projectID))
+			b.WriteString("\"\n")
+		} else if areaID := strings.TrimSpace(target.AreaID); areaID != "" {
+			b.WriteString("    set area of restoredToDo to area id \"")
+			b.WriteString(escapeAppleScriptString(areaID))
+			b.WriteString("\"\n")
+		}
+		if !when.IsZero() {
+			// Build the date field by field; date literals depend on the locale.
+			b.WriteString("    set restoreDate to current date\n")
+			b.WriteString("    set day of restoreDate to 1\n")
+			fmt.Fprintf(&b, "    set year of restoreDate to %d\n", when.Year())
+			fmt.Fprintf(&b, "    set month of restoreDate to %d\n", int(when.Month()))
+			fmt.Fprintf(&b, "    set day of restoreDate to %d\n", when.Day())
+			b.WriteString("    schedule restoredToDo for restoreDate\n")
+		}
+		b.WriteString("  on error errorMessage\n")
+		b.WriteString("    set end of restoreFailures to \"")
+		b.WriteString(escapeAppleScriptString(id))
+		b.WriteString(": \" & errorMessage\n")
+		b.WriteString("  end try\n")
+	}
+	if count == 0 {
+		return "", fmt.Errorf("Error: Must specify --id=ID or query")
+	}
+	b.WriteString("  if restoreFailures is not {} then\n")
+	b.WriteString("    set AppleScript's text item delimiters to \"; \"\n")
+	b.WriteString("    error \"Could not restore \" & (restoreFailures as text)\n")
+	b.WriteString("  end if\n")
+	b.WriteString("end tell")
+	return b.String(), nil
+}
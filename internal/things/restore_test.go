@@ -0,0 +1,40 @@
+package things
+
+import "testing"
+
+func TestBuildRestoreScriptRequiresIDs(t *testing.T) {
+	if _, err := BuildRestoreScript(nil); err == nil {
+		t.Fatalf("expected error")
+	}
+	if _, err := BuildRestoreScript([]RestoreTarget{{ID: " "}}); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestBuildRestoreScriptPlacement(t *testing.T) {
+	script, err := BuildRestoreScript([]RestoreTarget{
+		{ID: "T1", ProjectID: "P1", When: "2026-03-09"},
+		{ID: "T2", List: "someday", AreaID: "A1"},
+		{ID: "T3", List: "inbox"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"set restoredToDo to to do id \"T1\"\n    move restoredToDo to list \"Anytime\"\n    set project of restoredToDo to project id \"P1\"",
+		"set year of restoreDate to 2026\n    set month of restoreDate to 3\n    set day of restoreDate to 9\n    schedule restoredToDo for restoreDate",
+		"move restoredToDo to list \"Someday\"\n    set area of restoredToDo to area id \"A1\"",
+		"set restoredToDo to to do id \"T3\"\n    move restoredToDo to list \"Inbox\"\n  on error errorMessage\n    set end of restoreFailures to \"T3: \" & errorMessage\n  end try",
+		"if restoreFailures is not {} then\n    set AppleScript's text item delimiters to \"; \"\n    error \"Could not restore \" & (restoreFailures as text)\n  end if\nend tell",
+	} {
+		if !contains(script, want) {
+			t.Fatalf("expected %q in script:\n%s", want, script)
+		}
+	}
+}
+
+func TestBuildRestoreScriptRejectsBadDate(t *testing.T) {
+	if _, err := BuildRestoreScript([]RestoreTarget{{ID: "T1", When: "next week"}}); err == nil {
+		t.Fatalf("expected error")
+	}
+}
@@ -0,0 +1,45 @@
+package things
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AddTagOptions describes a tag to create.
+type AddTagOptions struct {
+	Title string
+	// Parent is the title of the parent tag. The parent must already exist or
+	// be created earlier in the same script.
+	Parent   string
+	Shortcut string
+}
+
+// BuildAddTagsScript builds an AppleScript snippet that creates tags in
+// order, skipping tags that already exist.
+func BuildAddTagsScript(tags []AddTagOptions) (string, error) {
+	var b strings.Builder
+	b.WriteString("tell application \"Things3\"\n")
+	count := 0
+	for _, tag := range tags {
+		title := strings.TrimSpace(tag.Title)
+		if title == "" {
+			continue
+		}
+		count++
+		name := escapeAppleScriptString(title)
+		fmt.Fprintf(&b, "  if not (exists tag \"%s\") then\n", name)
+		fmt.Fprintf(&b, "    set newTag to make new tag with properties {name:\"%s\"}\n", name)
+		if parent := strings.TrimSpace(tag.Parent); parent != "" {
+			fmt.Fprintf(&b, "    set parent tag of newTag to tag \"%s\"\n", escapeAppleScriptString(parent))
+		}
+		if shortcut := strings.TrimSpace(tag.Shortcut); shortcut != "" {
+			fmt.Fprintf(&b, "    set keyboard shortcut of newTag to \"%s\"\n", escapeAppleScriptString(shortcut))
+		}
+		b.WriteString("  end if\n")
+	}
+	if count == 0 {
+		return "", errMissingTitle
+	}
+	b.WriteString("end tell")
+	return b.String(), nil
+}
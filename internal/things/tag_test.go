@@ -0,0 +1,29 @@
+package things
+
+import "testing"
+
+func TestBuildAddTagsScript(t *testing.T) {
+	script, err := BuildAddTagsScript([]AddTagOptions{
+		{Title: "Places"},
+		{Title: "Home \"base\"", Parent: "Places", Shortcut: "h"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "tell application \"Things3\"\n" +
+		"  if not (exists tag \"Places\") then\n" +
+		"    set newTag to make new tag with properties {name:\"Places\"}\n" +
+		"  end if\n" +
+		"  if not (exists tag \"Home \\\"base\\\"\") then\n" +
+		"    set newTag to make new tag with properties {name:\"Home \\\"base\\\"\"}\n" +
+		"    set parent tag of newTag to tag \"Places\"\n" +
+		"    set keyboard shortcut of newTag to \"h\"\n" +
+		"  end if\n" +
+		"end tell"
+	if script != want {
+		t.Fatalf("unexpected script:\n%s", script)
+	}
+	if _, err := BuildAddTagsScript(nil); err == nil {
+		t.Fatalf("expected error without tags")
+	}
+}